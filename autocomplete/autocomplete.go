@@ -0,0 +1,80 @@
+package autocomplete
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/services"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// Source loads all the suggestions to index.
+type Source func(ctx context.Context) ([]services.Suggestion, error)
+
+// Autocompleter serves the suggestions from an index rebuilt from the source when
+// invalidated and periodically. It implements services.AutocompleteService.
+type Autocompleter struct {
+	source     Source
+	index      atomic.Pointer[Index]
+	invalidate chan struct{}
+}
+
+// New creates a new Autocompleter loading the suggestions from the source.
+func New(source Source) *Autocompleter {
+	return &Autocompleter{
+		source:     source,
+		invalidate: make(chan struct{}, 1),
+	}
+}
+
+// Rebuild rebuilds the index from the source.
+func (a *Autocompleter) Rebuild(ctx context.Context) error {
+	suggestions, err := a.source(ctx)
+	if err != nil {
+		return err
+	}
+
+	a.index.Store(NewIndex(suggestions))
+	return nil
+}
+
+// Invalidate schedules a rebuild of the index by Run. It does not block.
+func (a *Autocompleter) Invalidate() {
+	select {
+	case a.invalidate <- struct{}{}:
+	default:
+	}
+}
+
+// Run rebuilds the index when invalidated and every interval until the context is done.
+func (a *Autocompleter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-a.invalidate:
+		case <-ticker.C:
+		}
+
+		if err := a.Rebuild(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("error rebuilding the autocomplete index: %v", err)
+		}
+	}
+}
+
+// Suggest returns up to limit suggestions whose words start with the words of the query.
+// The index is built on the first call if it has not been built yet.
+func (a *Autocompleter) Suggest(ctx context.Context, query string, limit int) ([]services.Suggestion, error) {
+	index := a.index.Load()
+	if index == nil {
+		if err := a.Rebuild(ctx); err != nil {
+			return nil, err
+		}
+		index = a.index.Load()
+	}
+
+	return index.Lookup(query, limit), nil
+}
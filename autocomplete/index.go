@@ -0,0 +1,157 @@
+// Package autocomplete implements the search-as-you-type suggestions over an in-memory
+// prefix index of the course titles, instructor names and category titles.
+package autocomplete
+
+import (
+	"github.com/plaja-app/back-end/services"
+	"sort"
+	"strings"
+)
+
+// kindWeights rank the suggestion kinds matching equally well.
+var kindWeights = map[string]float64{
+	services.SuggestionCategory:   0.3,
+	services.SuggestionCourse:     0.2,
+	services.SuggestionInstructor: 0.1,
+}
+
+// entry is a word of a suggestion in the index.
+type entry struct {
+	word       string
+	suggestion int
+}
+
+// Index is an immutable prefix index of the suggestions.
+type Index struct {
+	suggestions []services.Suggestion
+	words       [][]string
+	// entries are the words of all suggestions sorted for the prefix lookups.
+	entries []entry
+}
+
+// NewIndex indexes the words of the suggestions.
+func NewIndex(suggestions []services.Suggestion) *Index {
+	ix := &Index{
+		suggestions: suggestions,
+		words:       make([][]string, len(suggestions)),
+	}
+
+	for i, s := range suggestions {
+		ix.words[i] = Words(s.Text)
+		for _, w := range ix.words[i] {
+			ix.entries = append(ix.entries, entry{word: w, suggestion: i})
+		}
+	}
+
+	sort.Slice(ix.entries, func(i, j int) bool {
+		return ix.entries[i].word < ix.entries[j].word
+	})
+
+	return ix
+}
+
+// Len returns the number of indexed suggestions.
+func (ix *Index) Len() int {
+	return len(ix.suggestions)
+}
+
+// prefixRange returns the range of the entries starting with the prefix.
+func (ix *Index) prefixRange(prefix string) (int, int) {
+	lo := sort.Search(len(ix.entries), func(i int) bool { return ix.entries[i].word >= prefix })
+	hi := lo
+	for hi < len(ix.entries) && strings.HasPrefix(ix.entries[hi].word, prefix) {
+		hi++
+	}
+	return lo, hi
+}
+
+// Lookup returns up to limit suggestions having, for every word of the query, a word
+// starting with it. Whole-word matches and matches at the start of the text rank first.
+func (ix *Index) Lookup(query string, limit int) []services.Suggestion {
+	terms := Words(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	// the candidates come from the narrowest term range
+	lo, hi := ix.prefixRange(terms[0])
+	for _, term := range terms[1:] {
+		if l, h := ix.prefixRange(term); h-l < hi-lo {
+			lo, hi = l, h
+		}
+	}
+
+	type match struct {
+		suggestion int
+		score      float64
+	}
+
+	seen := make(map[int]bool)
+	var matches []match
+
+	for _, e := range ix.entries[lo:hi] {
+		if seen[e.suggestion] {
+			continue
+		}
+		seen[e.suggestion] = true
+
+		if score, ok := ix.score(e.suggestion, terms); ok {
+			matches = append(matches, match{suggestion: e.suggestion, score: score})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		ta, tb := ix.suggestions[a.suggestion].Text, ix.suggestions[b.suggestion].Text
+		if len(ta) != len(tb) {
+			return len(ta) < len(tb)
+		}
+		return ta < tb
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	suggestions := make([]services.Suggestion, len(matches))
+	for i, m := range matches {
+		suggestions[i] = ix.suggestions[m.suggestion]
+	}
+
+	return suggestions
+}
+
+// score scores the suggestion against the query terms. It reports false unless every
+// term is a prefix of a word of the suggestion.
+func (ix *Index) score(suggestion int, terms []string) (float64, bool) {
+	words := ix.words[suggestion]
+	score := kindWeights[ix.suggestions[suggestion].Kind]
+
+	for i, term := range terms {
+		best := 0.0
+		for j, w := range words {
+			if !strings.HasPrefix(w, term) {
+				continue
+			}
+
+			s := 1.0
+			if w == term {
+				s = 2
+			}
+			if i == 0 && j == 0 {
+				s += 1
+			}
+			best = max(best, s)
+		}
+
+		if best == 0 {
+			return 0, false
+		}
+		score += best
+	}
+
+	return score, true
+}
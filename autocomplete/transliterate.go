@@ -0,0 +1,69 @@
+package autocomplete
+
+import (
+	"strings"
+	"unicode"
+)
+
+// cyrillic maps the Cyrillic letters to Latin following the Ukrainian national
+// transliteration (2010). The letters of the "initial" map are used at the start of a word.
+var (
+	cyrillic = map[rune]string{
+		'а': "a", 'б': "b", 'в': "v", 'г': "h", 'ґ': "g", 'д': "d", 'е': "e", 'є': "ie",
+		'ж': "zh", 'з': "z", 'и': "y", 'і': "i", 'ї': "i", 'й': "i", 'к': "k", 'л': "l",
+		'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+		'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ь': "", 'ю': "iu",
+		'я': "ia",
+		// Russian letters
+		'ё': "io", 'ъ': "", 'ы': "y", 'э': "e",
+	}
+	initial = map[rune]string{
+		'є': "ye", 'ї': "yi", 'й': "y", 'ю': "yu", 'я': "ya",
+	}
+)
+
+// apostrophes are dropped by the transliteration, e.g. "м'ята" is "miata".
+var apostrophes = strings.NewReplacer("'", "", "’", "", "ʼ", "", "`", "")
+
+// Words returns the lowercase words of the text transliterated to Latin, so that the text
+// written in either script yields the same words.
+func Words(text string) []string {
+	words := strings.FieldsFunc(apostrophes.Replace(strings.ToLower(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, w := range words {
+		words[i] = transliterate(w)
+	}
+
+	return words
+}
+
+// transliterate transliterates a lowercase word to Latin.
+func transliterate(word string) string {
+	var b strings.Builder
+
+	runes := []rune(word)
+	for i, r := range runes {
+		latin, ok := cyrillic[r]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+
+		if i == 0 {
+			if s, ok := initial[r]; ok {
+				latin = s
+			}
+		}
+
+		// "зг" is "zgh" to tell it apart from "ж"
+		if r == 'г' && i > 0 && runes[i-1] == 'з' {
+			latin = "gh"
+		}
+
+		b.WriteString(latin)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,25 @@
+package autocomplete
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Розробка", []string{"rozrobka"}},
+		{"Шаблони проєктування в C++/C#", []string{"shablony", "proiektuvannia", "v", "c", "c"}},
+		{"Юлія Їжакевич", []string{"yuliia", "yizhakevych"}},
+		{"Згорани, м'ята", []string{"zghorany", "miata"}},
+		{"Svelte та SvelteKit", []string{"svelte", "ta", "sveltekit"}},
+	}
+
+	for _, tt := range tests {
+		if got := Words(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("Words(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
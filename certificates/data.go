@@ -0,0 +1,48 @@
+package certificates
+
+import (
+	"github.com/plaja-app/back-end/models"
+	"gorm.io/gorm"
+)
+
+// LoadData loads the information printed on the certificate with the given ID.
+func LoadData(db *gorm.DB, id uint) (Data, error) {
+	var certificate models.CourseCertificate
+	err := db.Preload("User").Preload("Course.Instructor").First(&certificate, "id = ?", id).Error
+	if err != nil {
+		return Data{}, err
+	}
+
+	return Data{
+		ID:          certificate.ID,
+		FullName:    certificate.User.FirstName + " " + certificate.User.LastName,
+		CourseTitle: certificate.Course.Title,
+		Instructor:  certificate.Course.Instructor.FirstName + " " + certificate.Course.Instructor.LastName,
+		Length:      certificate.Course.Length,
+		IssuedAt:    certificate.CreatedAt,
+	}, nil
+}
+
+// LoadPathData loads the information printed on the learning path certificate with the
+// given ID. The length is the total length of the courses of the path.
+func LoadPathData(db *gorm.DB, id uint) (Data, error) {
+	var certificate models.PathCertificate
+	err := db.Preload("User").Preload("Path.Courses.Course").First(&certificate, "id = ?", id).Error
+	if err != nil {
+		return Data{}, err
+	}
+
+	var length uint
+	for _, c := range certificate.Path.Courses {
+		length += c.Course.Length
+	}
+
+	return Data{
+		ID:          certificate.ID,
+		FullName:    certificate.User.FirstName + " " + certificate.User.LastName,
+		CourseTitle: certificate.Path.Title,
+		Length:      length,
+		Courses:     uint(len(certificate.Path.Courses)),
+		IssuedAt:    certificate.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,182 @@
+package certificates
+
+import (
+	"fmt"
+	"github.com/fogleman/gg"
+	"image"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// months are the Ukrainian month names in the genitive case.
+var months = [...]string{
+	"січня", "лютого", "березня", "квітня", "травня", "червня",
+	"липня", "серпня", "вересня", "жовтня", "листопада", "грудня",
+}
+
+// Data holds the information printed on a certificate.
+type Data struct {
+	ID          uint
+	FullName    string
+	CourseTitle string
+	Instructor  string
+	// Length is the course length in minutes.
+	Length uint
+	// Courses is the number of the courses of a learning path certificate, printed instead
+	// of the instructor; the path title is the CourseTitle. Zero for the course certificates.
+	Courses  uint
+	IssuedAt time.Time
+}
+
+// Generator renders the course certificates into the storage.
+type Generator struct {
+	StorageRoot string
+}
+
+// NewGenerator creates a new Generator using the given storage root.
+func NewGenerator(storageRoot string) *Generator {
+	return &Generator{StorageRoot: storageRoot}
+}
+
+// Path returns the storage-relative path of the certificate with the given ID.
+func Path(id uint) string {
+	return path.Join("certificates", fmt.Sprintf("%d-certificate.png", id))
+}
+
+// PathCertificatePath returns the storage-relative path of the learning path certificate
+// with the given ID.
+func PathCertificatePath(id uint) string {
+	return path.Join("certificates", "paths", fmt.Sprintf("%d-certificate.png", id))
+}
+
+// storagePath returns the filesystem path of the given storage-relative path.
+func (g *Generator) storagePath(p string) string {
+	return filepath.Join(g.StorageRoot, filepath.FromSlash(p))
+}
+
+// loadImage loads an image from the specified storage-relative path.
+func (g *Generator) loadImage(p string) (image.Image, error) {
+	img, err := gg.LoadPNG(g.storagePath(p))
+	if err != nil {
+		return nil, fmt.Errorf("error loading image from %s: %v", p, err)
+	}
+	return img, nil
+}
+
+// drawString draws the string with specified parameters.
+func (g *Generator) drawString(dc *gg.Context, text string, x, y float64, width float64, alignment gg.Align, fontSize float64, font string) error {
+	if err := dc.LoadFontFace(g.storagePath(path.Join("service/fonts", font+".ttf")), fontSize); err != nil {
+		return fmt.Errorf("error loading font %s: %v", font, err)
+	}
+	dc.DrawStringWrapped(text, x, y, 0, 0, width, 1.5, alignment)
+
+	return nil
+}
+
+// Generate renders the course or learning path certificate (.png) and saves it to the
+// certificates storage directory, overwriting the previous version. Returns the storage-relative path to the generated certificate.
+func (g *Generator) Generate(data Data) (string, error) {
+	dc := gg.NewContext(1200, 800)
+
+	// Add background
+	img, err := g.loadImage("service/certificates/background.png")
+	if err != nil {
+		return "", err
+	}
+	dc.DrawImage(img, 0, 0)
+
+	// Add logo and signature
+	img, err = g.loadImage("service/logo/logo-dark.png")
+	if err != nil {
+		return "", err
+	}
+	dc.DrawImage(img, 63, 590)
+
+	img, err = g.loadImage("service/other/signature.png")
+	if err != nil {
+		return "", err
+	}
+	dc.DrawImage(img, 875, 615)
+
+	completed, detail, detailValue := "успішно завершив (-ла) курс", "інструктор:", orNA(data.Instructor)
+	if data.Courses > 0 {
+		completed, detail, detailValue = "успішно завершив (-ла) навчальний шлях", "курсів:", fmt.Sprint(data.Courses)
+	}
+
+	texts := []struct {
+		text     string
+		x, y     float64
+		width    float64
+		align    gg.Align
+		fontSize float64
+		font     string
+		alpha    float64
+	}{
+		// base text
+		{"цей сертифікат засвідчує, що", 110, 220, 500, gg.AlignLeft, 24, "Onest-Regular", 1},
+		{completed, 110, 380, 500, gg.AlignLeft, 24, "Onest-Regular", 1},
+		{detail, 110, 540, 200, gg.AlignLeft, 24, "Onest-Regular", 1},
+		{"тривалість:", 110, 572, 200, gg.AlignLeft, 24, "Onest-Regular", 1},
+		{"засновник, Plaja", 910, 695, 200, gg.AlignRight, 24, "Onest-Regular", 1},
+		// semi-transparent text
+		{fmt.Sprintf("ідентифікатор: %d", data.ID), 615, 85, 500, gg.AlignRight, 14, "Onest-Regular", 0.3},
+		{fmt.Sprintf("видано %s", FormatDate(data.IssuedAt)), 615, 105, 500, gg.AlignRight, 14, "Onest-Regular", 0.3},
+		// actual information with different font sizes
+		{data.FullName, 110, 257, 980, gg.AlignLeft, 56, "Onest-Medium", 1},
+		{data.CourseTitle, 110, 415, 980, gg.AlignLeft, 36, "Onest-Medium", 1},
+		{detailValue, 246, 540, 500, gg.AlignLeft, 24, "Onest-Medium", 1},
+		{orNA(FormatLength(data.Length)), 246, 572, 500, gg.AlignLeft, 24, "Onest-Medium", 1},
+	}
+
+	for _, t := range texts {
+		dc.SetRGBA(0, 0, 0, t.alpha)
+		err = g.drawString(dc, t.text, t.x, t.y, t.width, t.align, t.fontSize, t.font)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// Save the final image
+	p := Path(data.ID)
+	if data.Courses > 0 {
+		p = PathCertificatePath(data.ID)
+	}
+	if err := os.MkdirAll(filepath.Dir(g.storagePath(p)), os.ModePerm); err != nil {
+		return "", err
+	}
+
+	if err := dc.SavePNG(g.storagePath(p)); err != nil {
+		return "", fmt.Errorf("error saving image to %s: %v", p, err)
+	}
+
+	return p, nil
+}
+
+// FormatDate formats the date in Ukrainian, e.g. "11 березня 2023".
+func FormatDate(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), months[t.Month()-1], t.Year())
+}
+
+// FormatLength formats the length given in minutes, e.g. "2 год 15 хв".
+func FormatLength(minutes uint) string {
+	switch {
+	case minutes == 0:
+		return ""
+	case minutes < 60:
+		return fmt.Sprintf("%d хв", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%d год", minutes/60)
+	default:
+		return fmt.Sprintf("%d год %d хв", minutes/60, minutes%60)
+	}
+}
+
+// orNA returns "N/A" for empty strings.
+func orNA(s string) string {
+	if s == "" {
+		return "N/A"
+	}
+	return s
+}
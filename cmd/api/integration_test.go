@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/plaja-app/back-end/config"
+	"github.com/plaja-app/back-end/database"
+	"github.com/plaja-app/back-end/database/migrations"
+	"github.com/plaja-app/back-end/database/seed"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/payments"
+	"github.com/plaja-app/back-end/services"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testPaymentSecret signs the webhooks of the fake payment provider.
+const testPaymentSecret = "test-payment-secret"
+
+// testApp is the API served by newHandler() against an in-memory SQLite database.
+type testApp struct {
+	t      *testing.T
+	app    *config.AppConfig
+	server *httptest.Server
+}
+
+// newTestApp migrates a fresh in-memory database, loads the given fixture datasets
+// and starts the API on a test server.
+func newTestApp(t *testing.T, datasets ...string) *testApp {
+	t.Helper()
+
+	env := &config.EnvVariables{
+		DBDriver:      "sqlite",
+		SQLitePath:    ":memory:",
+		JWTSecret:     "test-secret",
+		AppEnv:        "development",
+		PublicBaseURL: "http://plaja.test",
+		StorageRoot:   newTestStorage(t),
+		CORSOrigins:   []string{"http://front.plaja.test"},
+
+		PaymentProvider:      "fake",
+		PaymentCurrency:      "UAH",
+		PaymentResultURL:     "http://front.plaja.test/orders",
+		PaymentWebhookSecret: testPaymentSecret,
+		RefundWindowDays:     14,
+		RefundMaxProgress:    30,
+		RevenueShare:         70,
+
+		SubscriptionGraceDays: 3,
+
+		SellerName:    "Plaja",
+		SellerCountry: "UA",
+	}
+
+	db, err := database.Open(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	datasets = append([]string{seed.DatasetReference}, datasets...)
+	if _, err := seed.New(db, seed.Fixtures()).Seed(datasets...); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	app := &config.AppConfig{DB: db, Env: env}
+	server := httptest.NewServer(newHandler(ctx, app))
+	t.Cleanup(server.Close)
+
+	return &testApp{t: t, app: app, server: server}
+}
+
+// newTestStorage creates a temporary storage root holding a copy of the service files.
+func newTestStorage(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	src := filepath.Join("..", "..", "storage", "service")
+
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, _ := filepath.Rel(src, path)
+		dst := filepath.Join(root, "service", rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(dst, os.ModePerm)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(dst, content, 0o644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return root
+}
+
+// testClient is an HTTP client keeping the session cookie between requests.
+type testClient struct {
+	t      *testing.T
+	base   string
+	client *http.Client
+}
+
+// client returns a new client without a session.
+func (a *testApp) client() *testClient {
+	jar, _ := cookiejar.New(nil)
+	return &testClient{t: a.t, base: a.server.URL, client: &http.Client{Jar: jar}}
+}
+
+// login returns a new client logged in with the given credentials.
+func (a *testApp) login(email, password string) *testClient {
+	c := a.client()
+	resp := c.postJSON("/api/v1/users/login", map[string]string{"email": email, "password": password})
+	c.expect(resp, http.StatusOK)
+	return c
+}
+
+// signUp registers a new learner and returns a client logged in as them.
+func (a *testApp) signUp(email string) *testClient {
+	c := a.client()
+	resp := c.postJSON("/api/v1/users/signup", map[string]string{
+		"firstName": "Тарас",
+		"lastName":  "Шевченко",
+		"email":     email,
+		"password":  "password123",
+	})
+	c.expect(resp, http.StatusCreated)
+	return a.login(email, "password123")
+}
+
+// checkout starts the checkout of the course and returns the created order.
+func (c *testClient) checkout(courseID uint) models.Order {
+	c.t.Helper()
+
+	resp := c.postJSON("/api/v1/orders/checkout", map[string]any{"CourseID": courseID})
+	c.expect(resp, http.StatusCreated)
+
+	var session services.CheckoutSession
+	c.decode(resp, &session)
+	return session.Order
+}
+
+// webhook posts the fake payment webhook signed with the secret.
+func (c *testClient) webhook(webhook payments.FakeWebhook, secret string) *http.Response {
+	c.t.Helper()
+
+	body, err := json.Marshal(webhook)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.base+"/api/v1/payments/webhook", bytes.NewReader(body))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(payments.FakeSignatureHeader, payments.NewFake(secret).Sign(body))
+	return c.do(req)
+}
+
+// buy pays for the course with the fake payment provider, enrolling the client's user.
+func (c *testClient) buy(courseID uint) {
+	c.t.Helper()
+
+	order := c.checkout(courseID)
+	c.expect(c.webhook(payments.FakeWebhook{
+		EventID:  fmt.Sprintf("paid-%d", order.ID),
+		OrderID:  order.ID,
+		Status:   payments.StatusSucceeded,
+		Amount:   order.Amount,
+		Currency: order.Currency,
+	}, testPaymentSecret), http.StatusOK)
+}
+
+// do sends the request and returns the response with the body read into memory.
+func (c *testClient) do(req *http.Request) *http.Response {
+	c.t.Helper()
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return resp
+}
+
+// get sends a GET request.
+func (c *testClient) get(path string) *http.Response {
+	c.t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, c.base+path, nil)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return c.do(req)
+}
+
+// postJSON sends a POST request with the JSON-encoded body.
+func (c *testClient) postJSON(path string, body any) *http.Response {
+	c.t.Helper()
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.base+path, bytes.NewReader(payload))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req)
+}
+
+// postForm sends a POST request with the multipart form values.
+func (c *testClient) postForm(path string, values map[string]string) *http.Response {
+	c.t.Helper()
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for k, v := range values {
+		w.WriteField(k, v)
+	}
+	w.Close()
+
+	req, err := http.NewRequest(http.MethodPost, c.base+path, &buf)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return c.do(req)
+}
+
+// postFile sends a POST request with the multipart form values and the file in the field.
+func (c *testClient) postFile(path string, values map[string]string, field string, content []byte) *http.Response {
+	c.t.Helper()
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for k, v := range values {
+		w.WriteField(k, v)
+	}
+	fw, err := w.CreateFormFile(field, field+".csv")
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	fw.Write(content)
+	w.Close()
+
+	req, err := http.NewRequest(http.MethodPost, c.base+path, &buf)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return c.do(req)
+}
+
+// expect fails the test if the response status differs from the expected one.
+func (c *testClient) expect(resp *http.Response, status int) {
+	c.t.Helper()
+
+	if resp.StatusCode != status {
+		body, _ := io.ReadAll(resp.Body)
+		c.t.Fatalf("%s %s: expected status %d, got %d: %s",
+			resp.Request.Method, resp.Request.URL.Path, status, resp.StatusCode, body)
+	}
+}
+
+// decode decodes the JSON response body into v.
+func (c *testClient) decode(resp *http.Response, v any) {
+	c.t.Helper()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		c.t.Fatalf("%s %s: error decoding response: %v", resp.Request.Method, resp.Request.URL.Path, err)
+	}
+}
@@ -1,25 +1,46 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/plaja-app/back-end/config"
 	"log"
 	"net/http"
+	"os"
 )
 
 var app config.AppConfig
 
 func main() {
+	if len(os.Args) > 1 {
+		var err error
+		switch os.Args[1] {
+		case "migrate":
+			err = runMigrateCommand(os.Args[2:])
+		case "seed":
+			err = runSeedCommand(os.Args[2:])
+		default:
+			err = fmt.Errorf("unknown command %q, expected migrate or seed", os.Args[1])
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	err := setup(&app)
 	if err != nil {
-		log.Fatal()
+		log.Fatal(err)
 	}
 
-	fmt.Println("Running on port :8080...")
+	fmt.Printf("Running on %s (public URL %s)...\n", app.Env.ServerAddr, app.Env.PublicBaseURL)
 
 	srv := &http.Server{
-		Addr:    ":8080",
-		Handler: routes(&app),
+		Addr:         app.Env.ServerAddr,
+		Handler:      newHandler(context.Background(), &app),
+		ReadTimeout:  app.Env.ReadTimeout,
+		WriteTimeout: app.Env.WriteTimeout,
+		IdleTimeout:  app.Env.IdleTimeout,
 	}
 
 	err = srv.ListenAndServe()
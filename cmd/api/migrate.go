@@ -0,0 +1,32 @@
+package main
+
+import (
+	"errors"
+	"github.com/plaja-app/back-end/config"
+	"github.com/plaja-app/back-end/database"
+	"github.com/plaja-app/back-end/database/migrations"
+	"os"
+)
+
+// migrateUsage is the usage of the migrate command.
+const migrateUsage = "usage: api migrate up | down [steps] | status"
+
+// runMigrateCommand runs the migrate subcommand with the given arguments.
+func runMigrateCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	env, err := config.LoadEnvVariables()
+	if err != nil {
+		return err
+	}
+
+	db, err := database.Open(env)
+	if err != nil {
+		return err
+	}
+
+	cmd := migrations.Command{Out: os.Stdout, Usage: migrateUsage}
+	return cmd.Run(db, args)
+}
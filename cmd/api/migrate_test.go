@@ -0,0 +1,51 @@
+package main
+
+import (
+	"github.com/plaja-app/back-end/database/migrations"
+	"testing"
+)
+
+func TestMigrateCommand(t *testing.T) {
+	setCommandEnv(t, "production")
+
+	for _, args := range [][]string{nil, {"sideways"}, {"down", "0"}, {"down", "x"}} {
+		if err := runMigrateCommand(args); err == nil || err.Error() != migrateUsage {
+			t.Errorf("migrate %q: expected the usage, got %v", args, err)
+		}
+	}
+
+	check := func() error {
+		migrator, err := migrations.New(openCommandDB(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return migrator.Check()
+	}
+
+	if err := check(); err == nil {
+		t.Fatal("expected a pending schema before migrating")
+	}
+
+	for _, args := range [][]string{{"up"}, {"up"}, {"status"}} {
+		if err := runMigrateCommand(args); err != nil {
+			t.Fatalf("migrate %q: %v", args, err)
+		}
+	}
+	if err := check(); err != nil {
+		t.Fatalf("expected an up-to-date schema, got %v", err)
+	}
+
+	if err := runMigrateCommand([]string{"down", "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := check(); err == nil {
+		t.Fatal("expected a pending schema after reverting")
+	}
+
+	if err := runMigrateCommand([]string{"up"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := check(); err != nil {
+		t.Fatalf("expected the reverted migrations to be applied again, got %v", err)
+	}
+}
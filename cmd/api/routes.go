@@ -3,51 +3,152 @@ package main
 import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/plaja-app/back-end/config"
 	c "github.com/plaja-app/back-end/controllers"
 	m "github.com/plaja-app/back-end/middleware"
 	"net/http"
 )
 
-func routes(app *config.AppConfig) http.Handler {
+func routes(ctrl *c.BaseController, mw *m.BaseMiddleware) http.Handler {
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
+	r.Use(mw.CORS)
 
-	r.Get("/api/v1/course-categories", c.Controller.GetCourseCategories)
-	r.Get("/api/v1/course-levels", c.Controller.GetCourseLevels)
-	r.Get("/api/v1/courses", c.Controller.GetCourses)
+	r.Get("/api/v1/course-categories", ctrl.GetCourseCategories)
+	r.Get("/api/v1/course-levels", ctrl.GetCourseLevels)
+	r.Group(func(r chi.Router) {
+		r.Use(mw.OptionalAuth)
+		r.Get("/api/v1/courses", ctrl.GetCourses)
+		r.Get("/api/v1/courses/search", ctrl.SearchCourses)
+		r.Get("/api/v1/catalogue", ctrl.GetCatalogue)
+		r.Get("/api/v1/course-exercises", ctrl.GetCourseExercises)
+		r.Get("/api/v1/subscription-plans", ctrl.GetSubscriptionPlans)
+		r.Get("/api/v1/courses/also-took", ctrl.GetAlsoTook)
+	})
+	r.Get("/api/v1/autocomplete", ctrl.GetSuggestions)
+
+	r.Get("/api/v1/course-certificates", ctrl.GetCourseCertificates)
+
+	r.Get("/api/v1/course-prerequisites", ctrl.GetCoursePrerequisites)
+
+	r.Get("/api/v1/learning-paths", ctrl.GetLearningPaths)
+
+	r.Get("/api/v1/course-reviews", ctrl.GetCourseReviews)
 
-	r.Get("/api/v1/course-certificates", c.Controller.GetCourseCertificates)
+	r.Get("/api/v1/users", ctrl.GetUsers)
+	r.Post("/api/v1/users/signup", ctrl.SignUp)
+	r.Post("/api/v1/users/login", ctrl.Login)
+	r.Post("/api/v1/users/logout", ctrl.Logout)
+	r.Post("/api/v1/users/set-password", ctrl.AcceptInvitation)
 
-	r.Get("/api/v1/users", c.Controller.GetUsers)
-	r.Post("/api/v1/users/signup", c.Controller.SignUp)
-	r.Post("/api/v1/users/login", c.Controller.Login)
-	r.Post("/api/v1/users/logout", c.Controller.Logout)
+	r.Get("/api/v1/enrollments", ctrl.GetEnrollments)
 
-	r.Get("/api/v1/enrollments", c.Controller.GetEnrollments)
+	r.Post("/api/v1/payments/webhook", ctrl.PaymentWebhook)
 
-	r.Get("/api/v1/course-exercises", c.Controller.GetCourseExercises)
+	r.Get("/api/v1/exchange-rates", ctrl.GetExchangeRates)
 
-	r.Get("/api/v1/stats/categories", c.Controller.GetCourseCategoriesStats)
-	r.Get("/api/v1/stats/course-levels", c.Controller.GetCourseCategoriesAndLevelsStats)
+	r.Get("/api/v1/stats/categories", ctrl.GetCourseCategoriesStats)
+	r.Get("/api/v1/stats/course-levels", ctrl.GetCourseCategoriesAndLevelsStats)
 
 	r.Group(func(r chi.Router) {
-		r.Use(m.Middleware.RequireAuth)
-		r.Get("/api/v1/users/getme", c.Controller.GetMe)
-		r.Post("/api/v1/users/update-general", c.Controller.UpdateUser)
+		r.Use(mw.RequireAuth)
+		r.Get("/api/v1/users/getme", ctrl.GetMe)
+		r.Post("/api/v1/users/update-general", ctrl.UpdateUser)
+
+		r.Get("/api/v1/recommendations", ctrl.GetRecommendations)
+
+		r.Post("/api/v1/courses/create", ctrl.CreateCourse)
+		r.Post("/api/v1/courses/update-general", ctrl.UpdateGeneralCourse)
+		r.Post("/api/v1/courses/update-sale", ctrl.UpdateCourseSale)
+		r.Post("/api/v1/courses/update-prices", ctrl.UpdateCoursePrices)
+		r.Post("/api/v1/courses/update-prerequisites", ctrl.UpdateCoursePrerequisites)
+
+		r.Post("/api/v1/enrollments/create", ctrl.CreateEnrollment)
+		r.Get("/api/v1/enrollments/access", ctrl.GetCourseAccess)
+		r.Get("/api/v1/enrollments/prerequisites", ctrl.GetEnrollmentPrerequisites)
+
+		r.Get("/api/v1/orders", ctrl.GetOrders)
+		r.Post("/api/v1/orders/checkout", ctrl.Checkout)
+
+		r.Get("/api/v1/subscriptions", ctrl.GetSubscriptions)
+		r.Post("/api/v1/subscriptions/create", ctrl.Subscribe)
+		r.Post("/api/v1/subscriptions/cancel", ctrl.CancelSubscription)
+		r.Post("/api/v1/subscription-plans/create", ctrl.CreateSubscriptionPlan)
+		r.Post("/api/v1/subscription-plans/update", ctrl.UpdateSubscriptionPlan)
+
+		r.Get("/api/v1/invoices", ctrl.GetInvoices)
+		r.Get("/api/v1/invoices/document", ctrl.GetInvoiceDocument)
+		r.Get("/api/v1/billing-profile", ctrl.GetBillingProfile)
+		r.Post("/api/v1/billing-profile/update", ctrl.UpdateBillingProfile)
+
+		r.Post("/api/v1/exchange-rates/import", ctrl.ImportExchangeRates)
+
+		r.Get("/api/v1/organizations", ctrl.GetOrganizations)
+		r.Post("/api/v1/organizations/create", ctrl.CreateOrganization)
+		r.Get("/api/v1/organizations/dashboard", ctrl.GetOrganizationDashboard)
+		r.Post("/api/v1/organizations/buy-seats", ctrl.BuySeats)
+		r.Post("/api/v1/organizations/join", ctrl.JoinOrganization)
+		r.Get("/api/v1/organization-members", ctrl.GetOrganizationMembers)
+		r.Post("/api/v1/organization-members/add", ctrl.AddOrganizationMember)
+		r.Post("/api/v1/organization-members/update", ctrl.UpdateOrganizationMember)
+		r.Post("/api/v1/organization-members/remove", ctrl.RemoveOrganizationMember)
+		r.Get("/api/v1/course-assignments", ctrl.GetCourseAssignments)
+		r.Post("/api/v1/course-assignments/create", ctrl.AssignCourse)
+		r.Post("/api/v1/course-assignments/remove", ctrl.UnassignCourse)
+		r.Get("/api/v1/user-imports", ctrl.GetUserImport)
+		r.Post("/api/v1/user-imports/create", ctrl.StartUserImport)
+
+		r.Post("/api/v1/learning-paths/create", ctrl.CreateLearningPath)
+		r.Post("/api/v1/learning-paths/update", ctrl.UpdateLearningPath)
+		r.Post("/api/v1/learning-paths/enroll", ctrl.EnrollLearningPath)
+		r.Post("/api/v1/learning-paths/checkout", ctrl.CheckoutLearningPath)
+		r.Get("/api/v1/learning-paths/progress", ctrl.GetLearningPathProgress)
+		r.Post("/api/v1/learning-paths/certificate", ctrl.CreateLearningPathCertificate)
+
+		r.Get("/api/v1/coupons", ctrl.GetCoupons)
+		r.Get("/api/v1/coupons/report", ctrl.GetCouponReport)
+		r.Post("/api/v1/coupons/create", ctrl.CreateCoupon)
+		r.Post("/api/v1/coupons/update", ctrl.UpdateCoupon)
+
+		r.Get("/api/v1/refunds", ctrl.GetRefunds)
+		r.Get("/api/v1/refunds/requests", ctrl.GetRefundRequests)
+		r.Post("/api/v1/refunds/create", ctrl.RequestRefund)
+		r.Post("/api/v1/refunds/decide", ctrl.DecideRefund)
+
+		r.Get("/api/v1/audit-log", ctrl.GetAuditLog)
+
+		r.Get("/api/v1/ledger", ctrl.GetLedger)
+		r.Get("/api/v1/earnings", ctrl.GetEarnings)
+		r.Get("/api/v1/earnings/statement", ctrl.GetStatement)
+		r.Post("/api/v1/payouts/create", ctrl.CreatePayout)
+
+		r.Post("/api/v1/teaching-applications/create", ctrl.CreateTeachingApplication)
+
+		r.Post("/api/v1/course-certificates/create", ctrl.CreateCourseCertificate)
+		r.Post("/api/v1/course-exercises/create-update", ctrl.CreateOrUpdateCourseExercises)
 
-		r.Post("/api/v1/courses/create", c.Controller.CreateCourse)
-		r.Post("/api/v1/courses/update-general", c.Controller.UpdateGeneralCourse)
+		r.Post("/api/v1/course-reviews/create", ctrl.CreateCourseReview)
+		r.Post("/api/v1/course-reviews/update", ctrl.UpdateCourseReview)
+		r.Post("/api/v1/course-reviews/reply", ctrl.ReplyCourseReview)
+		r.Post("/api/v1/course-reviews/vote", ctrl.VoteCourseReview)
+		r.Get("/api/v1/course-reviews/moderation", ctrl.GetModeratedCourseReviews)
+		r.Post("/api/v1/course-reviews/moderate", ctrl.ModerateCourseReview)
 
-		r.Post("/api/v1/enrollments/create", c.Controller.CreateEnrollment)
+		r.Get("/api/v1/discussions", ctrl.GetDiscussions)
+		r.Get("/api/v1/discussions/thread", ctrl.GetDiscussion)
+		r.Post("/api/v1/discussions/create", ctrl.CreateDiscussion)
+		r.Post("/api/v1/discussions/vote", ctrl.VoteDiscussion)
+		r.Post("/api/v1/discussion-posts/create", ctrl.CreateDiscussionPost)
+		r.Post("/api/v1/discussion-posts/accept", ctrl.AcceptDiscussionPost)
 
-		r.Post("/api/v1/teaching-applications/create", c.Controller.CreateTeachingApplication)
+		r.Get("/api/v1/wishlist", ctrl.GetWishlist)
+		r.Post("/api/v1/wishlist/add", ctrl.AddToWishlist)
+		r.Post("/api/v1/wishlist/remove", ctrl.RemoveFromWishlist)
 
-		r.Post("/api/v1/course-certificates/create", c.Controller.CreateCourseCertificate)
-		r.Post("/api/v1/course-exercises/create-update", c.Controller.CreateOrUpdateCourseExercises)
+		r.Get("/api/v1/notifications", ctrl.GetNotifications)
+		r.Post("/api/v1/notifications/mark-read", ctrl.MarkNotificationsRead)
 	})
 
-	r.Get("/api/v1/storage/*", c.Controller.GetImage)
+	r.Get("/api/v1/storage/*", ctrl.GetImage)
 
 	return r
 }
@@ -0,0 +1,2338 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/plaja-app/back-end/database/migrations"
+	"github.com/plaja-app/back-end/database/seed"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/payments"
+	"github.com/plaja-app/back-end/services"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMigrationsUpDown(t *testing.T) {
+	a := newTestApp(t)
+
+	migrator, err := migrations.New(a.app.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrator.Check(); err != nil {
+		t.Fatalf("expected schema to be up to date: %v", err)
+	}
+
+	if _, err := migrator.Down(math.MaxInt); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrator.Check(); err == nil {
+		t.Fatal("expected pending migrations after reverting")
+	}
+
+	if _, err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	// tamper with an applied migration
+	a.app.DB.Exec("UPDATE schema_migrations SET checksum = 'x' WHERE version = 1")
+
+	if err := migrator.Check(); err == nil || !strings.Contains(err.Error(), "drifted") {
+		t.Fatalf("expected drifted schema, got %v", err)
+	}
+}
+
+func TestSeedIsIdempotent(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+
+	results, err := seed.New(a.app.DB, seed.Fixtures()).Seed(seed.DatasetReference, seed.DatasetDev)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range results {
+		if r.Created != 0 {
+			t.Errorf("%s/%s: expected nothing to be created, got %d", r.Dataset, r.File, r.Created)
+		}
+	}
+}
+
+func TestSignUpLoginGetMe(t *testing.T) {
+	a := newTestApp(t)
+
+	anonymous := a.client()
+	anonymous.expect(anonymous.get("/api/v1/users/getme"), http.StatusUnauthorized)
+
+	learner := a.signUp("learner@plaja.test")
+
+	resp := learner.get("/api/v1/users/getme")
+	learner.expect(resp, http.StatusOK)
+
+	var me models.User
+	learner.decode(resp, &me)
+
+	if me.Email != "learner@plaja.test" || me.UserType.Title != "Learner" {
+		t.Fatalf("unexpected user %+v", me)
+	}
+
+	learner.expect(learner.postJSON("/api/v1/users/logout", nil), http.StatusOK)
+	learner.expect(learner.get("/api/v1/users/getme"), http.StatusUnauthorized)
+}
+
+func TestCourseLifecycle(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+
+	instructor := a.login("mail@plaja.io", "plaja-dev-password")
+
+	resp := instructor.postJSON("/api/v1/courses/create", map[string]any{
+		"Title":          "Тестування у Go",
+		"LevelID":        1,
+		"HasCertificate": true,
+		"Categories":     []map[string]any{{"ID": 1, "Title": "Go"}},
+	})
+	instructor.expect(resp, http.StatusCreated)
+
+	var course models.Course
+	a.app.DB.First(&course, "title = ?", "Тестування у Go")
+
+	instructor.expect(instructor.postForm("/api/v1/courses/update-general", map[string]string{
+		"Title":            "Тестування у Go",
+		"ShortDescription": "Табличні тести та інтеграційні тести",
+		"Description":      "Опис",
+		"Price":            "0",
+		"CourseID":         fmt.Sprint(course.ID),
+	}), http.StatusOK)
+
+	instructor.expect(instructor.postJSON("/api/v1/course-exercises/create-update", map[string]any{
+		"CourseID":     course.ID,
+		"InstructorID": course.InstructorID,
+		"Exercises": []map[string]any{
+			{"Title": "Вступ", "Content": strings.Repeat("слово ", 400)},
+		},
+	}), http.StatusCreated)
+
+	resp = a.client().get(fmt.Sprintf("/api/v1/courses?id=%d", course.ID))
+	instructor.expect(resp, http.StatusOK)
+
+	var courses []models.Course
+	instructor.decode(resp, &courses)
+
+	if len(courses) != 1 || courses[0].Length != 2 || len(courses[0].Categories) != 1 {
+		t.Fatalf("unexpected courses %+v", courses)
+	}
+
+	if courses[0].Thumbnail != "http://plaja.test/api/v1/storage/service/courses/no-thumbnail.png" {
+		t.Errorf("unexpected thumbnail URL %q", courses[0].Thumbnail)
+	}
+
+	// enroll and complete the course
+	learner := a.signUp("learner@plaja.test")
+	learner.expect(learner.postJSON("/api/v1/enrollments/create", map[string]any{"CourseID": course.ID}), http.StatusCreated)
+
+	resp = learner.get("/api/v1/users/getme")
+	var me models.User
+	learner.decode(resp, &me)
+
+	resp = a.client().get(fmt.Sprintf("/api/v1/courses?user_id=%d", me.ID))
+	courses = nil
+	learner.decode(resp, &courses)
+	if len(courses) != 1 || courses[0].ID != course.ID {
+		t.Fatalf("expected the enrolled course, got %+v", courses)
+	}
+
+	learner.expect(learner.postJSON("/api/v1/course-certificates/create", map[string]any{"CourseID": course.ID}), http.StatusBadRequest)
+
+	a.app.DB.Model(&models.Enrollment{}).Where("user_id = ? AND course_id = ?", me.ID, course.ID).Update("progress", 100)
+
+	learner.expect(learner.postJSON("/api/v1/course-certificates/create", map[string]any{"CourseID": course.ID}), http.StatusCreated)
+	learner.expect(learner.postJSON("/api/v1/course-certificates/create", map[string]any{"CourseID": course.ID}), http.StatusConflict)
+
+	resp = a.client().get(fmt.Sprintf("/api/v1/course-certificates?user_id=%d", me.ID))
+	var certificates []models.CourseCertificate
+	learner.decode(resp, &certificates)
+	if len(certificates) != 1 {
+		t.Fatalf("expected one certificate, got %d", len(certificates))
+	}
+
+	learner.expect(a.client().get(fmt.Sprintf("/api/v1/storage/certificates/%d-certificate.png", certificates[0].ID)), http.StatusOK)
+}
+
+func TestStats(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+	c := a.client()
+
+	resp := c.get("/api/v1/stats/categories")
+	c.expect(resp, http.StatusOK)
+
+	var stats []struct {
+		Title        string
+		CoursesCount int
+	}
+	c.decode(resp, &stats)
+
+	counts := make(map[string]int)
+	for _, s := range stats {
+		counts[s.Title] = s.CoursesCount
+	}
+
+	if counts["Go"] != 2 || counts["Rust"] != 0 {
+		t.Fatalf("unexpected category stats %v", counts)
+	}
+
+	c.expect(c.get("/api/v1/stats/course-levels"), http.StatusOK)
+}
+
+func TestCORSPreflight(t *testing.T) {
+	a := newTestApp(t)
+	c := a.client()
+
+	req, _ := http.NewRequest(http.MethodOptions, c.base+"/api/v1/users/login", nil)
+	req.Header.Set("Origin", "http://front.plaja.test")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+
+	resp := c.do(req)
+	c.expect(resp, http.StatusNoContent)
+
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "http://front.plaja.test" {
+		t.Fatalf("unexpected Access-Control-Allow-Origin %q", got)
+	}
+
+	// the wildcard is never matched, since the responses allow credentials
+	a.app.Env.CORSOrigins = append(a.app.Env.CORSOrigins, "*")
+	req.Header.Set("Origin", "http://evil.test")
+	resp = c.do(req)
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("expected the origin to be refused, got Access-Control-Allow-Origin %q", got)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+	c := a.client()
+
+	for _, sort := range []string{"", "-id", "title", "-created_at"} {
+		var all []models.Course
+		resp := c.get("/api/v1/courses?limit=100&sort=" + sort)
+		c.expect(resp, http.StatusOK)
+		c.decode(resp, &all)
+
+		var paged []models.Course
+		path := "/api/v1/courses?limit=2&count=true&sort=" + sort
+		for path != "" {
+			resp := c.get(path)
+			c.expect(resp, http.StatusOK)
+
+			if got := resp.Header.Get("X-Total-Count"); got != fmt.Sprint(len(all)) {
+				t.Fatalf("sort %q: expected total count %d, got %q", sort, len(all), got)
+			}
+
+			var page []models.Course
+			c.decode(resp, &page)
+			if len(page) > 2 {
+				t.Fatalf("sort %q: expected at most 2 courses, got %d", sort, len(page))
+			}
+			paged = append(paged, page...)
+
+			path = ""
+			if cursor := resp.Header.Get("X-Next-Cursor"); cursor != "" {
+				path = fmt.Sprintf("/api/v1/courses?limit=2&count=true&sort=%s&cursor=%s", sort, cursor)
+			}
+		}
+
+		if len(paged) != len(all) {
+			t.Fatalf("sort %q: expected %d courses, got %d", sort, len(all), len(paged))
+		}
+		for i := range all {
+			if paged[i].ID != all[i].ID {
+				t.Fatalf("sort %q: course %d: expected ID %d, got %d", sort, i, all[i].ID, paged[i].ID)
+			}
+		}
+	}
+
+	// the cursor is bound to the sort
+	resp := c.get("/api/v1/courses?limit=1&sort=title")
+	c.expect(c.get("/api/v1/courses?sort=-title&cursor="+resp.Header.Get("X-Next-Cursor")), http.StatusBadRequest)
+
+	c.expect(c.get("/api/v1/courses?sort=password"), http.StatusBadRequest)
+	c.expect(c.get("/api/v1/courses?id=1,x"), http.StatusBadRequest)
+}
+
+func TestSparseFields(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+	c := a.client()
+
+	resp := c.get("/api/v1/courses?fields=id,title")
+	c.expect(resp, http.StatusOK)
+
+	var courses []map[string]any
+	c.decode(resp, &courses)
+
+	if len(courses) == 0 {
+		t.Fatal("expected courses")
+	}
+	for _, course := range courses {
+		if len(course) != 2 || course["ID"] == nil || course["Title"] == nil {
+			t.Fatalf("expected only ID and Title, got %v", course)
+		}
+	}
+}
+
+func TestSearchCourses(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+	c := a.client()
+
+	type result struct {
+		ID      uint
+		Title   string
+		Rank    float64
+		Snippet string
+	}
+
+	search := func(q string) []result {
+		resp := c.get("/api/v1/courses/search?q=" + url.QueryEscape(q))
+		c.expect(resp, http.StatusOK)
+
+		var results []result
+		c.decode(resp, &results)
+		return results
+	}
+
+	// prefixes of the words in any case, ranked by relevance
+	results := search("МІКРОСЕРВІС")
+	if len(results) != 1 || results[0].Title != "Використання мікросервісів у Go" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if !strings.Contains(results[0].Snippet, "<mark>мікросервісів</mark>") {
+		t.Errorf("expected highlighted snippet, got %q", results[0].Snippet)
+	}
+
+	results = search("веб-застосунки")
+	if len(results) != 2 || results[0].Rank < results[1].Rank {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	// the index follows the exercise changes
+	instructor := a.login("mail@plaja.io", "plaja-dev-password")
+	instructor.expect(instructor.postJSON("/api/v1/course-exercises/create-update", map[string]any{
+		"CourseID":  results[0].ID,
+		"Exercises": []map[string]any{{"Title": "Горутини та канали", "Content": "<b>Текст</b>"}},
+	}), http.StatusCreated)
+
+	results = search("горутин")
+	if len(results) != 1 {
+		t.Fatalf("expected the course with the exercise, got %+v", results)
+	}
+
+	// drafts are not searchable
+	instructor.expect(instructor.postJSON("/api/v1/courses/create", map[string]any{
+		"Title": "Горутини для початківців", "LevelID": 1,
+	}), http.StatusCreated)
+
+	if results := search("горутин"); len(results) != 1 {
+		t.Fatalf("expected drafts to be excluded, got %+v", results)
+	}
+
+	if results := search("кобол"); len(results) != 0 {
+		t.Fatalf("expected no results, got %+v", results)
+	}
+
+	// ranked keyset pagination
+	all := search("go")
+	var paged []result
+	for path := "/api/v1/courses/search?q=go&limit=1"; path != ""; {
+		resp := c.get(path)
+		c.expect(resp, http.StatusOK)
+
+		var page []result
+		c.decode(resp, &page)
+		paged = append(paged, page...)
+
+		path = ""
+		if cursor := resp.Header.Get("X-Next-Cursor"); cursor != "" {
+			path = "/api/v1/courses/search?q=go&limit=1&cursor=" + cursor
+		}
+	}
+	if len(all) < 2 || len(paged) != len(all) {
+		t.Fatalf("expected %d paged results, got %d", len(all), len(paged))
+	}
+
+	c.expect(c.get("/api/v1/courses/search?q=%20"), http.StatusBadRequest)
+}
+
+func TestCatalogueFacets(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+	c := a.client()
+
+	type facetValue struct {
+		ID    uint
+		Title string
+		Key   string
+		Count int64
+	}
+
+	var catalogue struct {
+		Courses []models.Course
+		Facets  struct {
+			Categories []facetValue
+			Levels     []facetValue
+			Prices     []facetValue
+			Lengths    []facetValue
+		}
+	}
+
+	counts := func(values []facetValue) map[string]int64 {
+		m := make(map[string]int64)
+		for _, v := range values {
+			key := v.Key
+			if key == "" {
+				key = v.Title
+			}
+			m[key] = v.Count
+		}
+		return m
+	}
+
+	// Go courses at the beginner level or paid under 500 UAH
+	resp := c.get("/api/v1/catalogue?category_id=1&price=1-499,free")
+	c.expect(resp, http.StatusOK)
+	c.decode(resp, &catalogue)
+
+	if len(catalogue.Courses) != 1 || catalogue.Courses[0].Price != 39900 {
+		t.Fatalf("unexpected courses %+v", catalogue.Courses)
+	}
+
+	// the counts of a facet ignore its own filter
+	if got := counts(catalogue.Facets.Categories); got["Go"] != 1 || got["C++"] != 0 || got["Rust"] != 0 {
+		t.Errorf("unexpected category counts %v", got)
+	}
+	if got := counts(catalogue.Facets.Prices); got["free"] != 0 || got["1-499"] != 1 || got["500-999"] != 1 || got["1000+"] != 0 {
+		t.Errorf("unexpected price counts %v", got)
+	}
+	if got := counts(catalogue.Facets.Levels); got["Початковий"] != 1 || got["Середній"] != 0 {
+		t.Errorf("unexpected level counts %v", got)
+	}
+
+	// the prices are only sorted within a currency
+	c.expect(c.get("/api/v1/catalogue?free=false&level_id=1,2&sort=-price"), http.StatusBadRequest)
+
+	resp = c.get("/api/v1/catalogue?free=false&level_id=1,2&sort=-price&price_currency=uah")
+	c.expect(resp, http.StatusOK)
+	c.decode(resp, &catalogue)
+
+	if len(catalogue.Courses) != 3 || catalogue.Courses[0].Price != 119900 {
+		t.Fatalf("unexpected courses %+v", catalogue.Courses)
+	}
+
+	resp = c.get("/api/v1/catalogue?free=false&sort=price&price_currency=EUR")
+	c.expect(resp, http.StatusOK)
+	c.decode(resp, &catalogue)
+
+	if len(catalogue.Courses) != 0 {
+		t.Fatalf("expected no courses priced in EUR, got %+v", catalogue.Courses)
+	}
+
+	c.expect(c.get("/api/v1/catalogue?price=cheap"), http.StatusBadRequest)
+}
+
+func TestAutocomplete(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+	c := a.client()
+
+	type suggestion struct {
+		Kind string
+		ID   uint
+		Text string
+	}
+
+	suggest := func(q string) []suggestion {
+		resp := c.get("/api/v1/autocomplete?q=" + url.QueryEscape(q))
+		c.expect(resp, http.StatusOK)
+
+		var suggestions []suggestion
+		c.decode(resp, &suggestions)
+		return suggestions
+	}
+
+	// Latin transliteration of the Cyrillic titles
+	suggestions := suggest("rozrobka")
+	if len(suggestions) != 1 || suggestions[0].Kind != "course" || suggestions[0].Text != "Розробка сучасних веб-застосунків із Go" {
+		t.Fatalf("unexpected suggestions %+v", suggestions)
+	}
+
+	if cyrillic := suggest("розр"); len(cyrillic) != 1 || cyrillic[0].ID != suggestions[0].ID {
+		t.Fatalf("unexpected suggestions %+v", cyrillic)
+	}
+
+	if instructors := suggest("plaja te"); len(instructors) != 1 || instructors[0].Kind != "instructor" {
+		t.Fatalf("expected the instructor, got %+v", instructors)
+	}
+
+	if empty := suggest(""); len(empty) != 0 {
+		t.Fatalf("expected no suggestions, got %+v", empty)
+	}
+
+	c.expect(c.get("/api/v1/autocomplete?q=go&limit=x"), http.StatusBadRequest)
+
+	// the index follows the course changes
+	instructor := a.login("mail@plaja.io", "plaja-dev-password")
+	instructor.expect(instructor.postForm("/api/v1/courses/update-general", map[string]string{
+		"Title":            "Горутини на практиці",
+		"ShortDescription": "Конкурентність у Go",
+		"Description":      "Опис",
+		"Price":            "0",
+		"CourseID":         fmt.Sprint(suggestions[0].ID),
+	}), http.StatusOK)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		renamed := suggest("horutyny")
+		if len(renamed) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the renamed course, got %+v", renamed)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCourseReviews(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+	admin := a.login("mail@plaja.io", "plaja-dev-password")
+
+	var course models.Course
+	a.app.DB.First(&course, "title = ?", "Використання мікросервісів у Go")
+
+	learner := a.signUp("learner@plaja.test")
+	other := a.signUp("other@plaja.test")
+	outsider := a.signUp("outsider@plaja.test")
+
+	learner.buy(course.ID)
+	other.buy(course.ID)
+
+	review := func(c *testClient, rating uint) *http.Response {
+		return c.postJSON("/api/v1/course-reviews/create", map[string]any{
+			"CourseID": course.ID, "Rating": rating, "Text": "Чудовий курс",
+		})
+	}
+
+	// only enrolled learners, one review per course
+	outsider.expect(review(outsider, 5), http.StatusForbidden)
+	learner.expect(review(learner, 6), http.StatusBadRequest)
+
+	resp := review(learner, 5)
+	learner.expect(resp, http.StatusCreated)
+
+	var first models.CourseReview
+	learner.decode(resp, &first)
+
+	learner.expect(review(learner, 4), http.StatusConflict)
+
+	resp = review(other, 4)
+	other.expect(resp, http.StatusCreated)
+
+	var second models.CourseReview
+	other.decode(resp, &second)
+
+	rating := func() models.Course {
+		resp := outsider.get(fmt.Sprintf("/api/v1/courses?id=%d", course.ID))
+		outsider.expect(resp, http.StatusOK)
+
+		var courses []models.Course
+		outsider.decode(resp, &courses)
+		return courses[0]
+	}
+
+	if c := rating(); c.RatingCount != 2 || c.RatingAverage != 4.5 || c.RatingDistribution != (models.RatingDistribution{0, 0, 0, 1, 1}) {
+		t.Fatalf("unexpected ratings %v %v %v", c.RatingCount, c.RatingAverage, c.RatingDistribution)
+	}
+
+	// editable
+	learner.expect(learner.postJSON("/api/v1/course-reviews/update", map[string]any{
+		"CourseID": course.ID, "Rating": 3, "Text": "Непоганий курс",
+	}), http.StatusOK)
+
+	if c := rating(); c.RatingAverage != 3.5 || c.RatingDistribution != (models.RatingDistribution{0, 0, 1, 1, 0}) {
+		t.Fatalf("unexpected ratings %v %v", c.RatingAverage, c.RatingDistribution)
+	}
+
+	// helpful votes, not for the own review
+	vote := map[string]any{"ReviewID": first.ID, "Helpful": true}
+	other.expect(other.postJSON("/api/v1/course-reviews/vote", vote), http.StatusOK)
+	other.expect(other.postJSON("/api/v1/course-reviews/vote", vote), http.StatusOK)
+	outsider.expect(outsider.postJSON("/api/v1/course-reviews/vote", vote), http.StatusOK)
+	learner.expect(learner.postJSON("/api/v1/course-reviews/vote", vote), http.StatusForbidden)
+	outsider.expect(outsider.postJSON("/api/v1/course-reviews/vote", map[string]any{"ReviewID": first.ID}), http.StatusOK)
+
+	// instructor replies
+	reply := map[string]any{"ReviewID": first.ID, "Reply": "Дякуємо!"}
+	learner.expect(learner.postJSON("/api/v1/course-reviews/reply", reply), http.StatusForbidden)
+	admin.expect(admin.postJSON("/api/v1/course-reviews/reply", reply), http.StatusOK)
+
+	reviews := func(query string) []models.CourseReview {
+		resp := outsider.get(fmt.Sprintf("/api/v1/course-reviews?course_id=%d&%s", course.ID, query))
+		outsider.expect(resp, http.StatusOK)
+
+		var reviews []models.CourseReview
+		outsider.decode(resp, &reviews)
+		return reviews
+	}
+
+	list := reviews("sort=-helpful_count")
+	if len(list) != 2 || list[0].ID != first.ID || list[0].HelpfulCount != 1 || list[0].Reply != "Дякуємо!" || list[0].RepliedAt == nil {
+		t.Fatalf("unexpected reviews %+v", list)
+	}
+	if list[0].User.FirstName != "Тарас" {
+		t.Errorf("expected the review author, got %+v", list[0].User)
+	}
+
+	// admin moderation
+	moderation := map[string]any{"ReviewID": second.ID, "Flagged": true, "Hidden": true}
+	learner.expect(learner.postJSON("/api/v1/course-reviews/moderate", moderation), http.StatusForbidden)
+	learner.expect(learner.get("/api/v1/course-reviews/moderation"), http.StatusForbidden)
+	admin.expect(admin.postJSON("/api/v1/course-reviews/moderate", moderation), http.StatusOK)
+
+	if list := reviews("rating=4"); len(list) != 0 {
+		t.Fatalf("expected the hidden review to be excluded, got %+v", list)
+	}
+
+	if c := rating(); c.RatingCount != 1 || c.RatingAverage != 3 {
+		t.Fatalf("expected the hidden review to be excluded from the ratings, got %v %v", c.RatingCount, c.RatingAverage)
+	}
+
+	resp = admin.get("/api/v1/course-reviews/moderation?hidden=true")
+	admin.expect(resp, http.StatusOK)
+
+	var hidden []models.CourseReview
+	admin.decode(resp, &hidden)
+	if len(hidden) != 1 || hidden[0].ID != second.ID || !hidden[0].Flagged {
+		t.Fatalf("unexpected hidden reviews %+v", hidden)
+	}
+
+	// sorting by rating
+	resp = outsider.get("/api/v1/courses?sort=-rating_average&limit=1")
+	outsider.expect(resp, http.StatusOK)
+
+	var courses []models.Course
+	outsider.decode(resp, &courses)
+	if len(courses) != 1 || courses[0].ID != course.ID {
+		t.Fatalf("expected the rated course first, got %+v", courses)
+	}
+}
+
+func TestWishlist(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+	instructor := a.login("mail@plaja.io", "plaja-dev-password")
+	learner := a.signUp("learner@plaja.test")
+
+	var course models.Course
+	a.app.DB.First(&course, "title = ?", "Використання мікросервісів у Go")
+
+	instructor.expect(instructor.postJSON("/api/v1/courses/create", map[string]any{"Title": "Чернетка", "LevelID": 1}), http.StatusCreated)
+
+	var draft models.Course
+	a.app.DB.First(&draft, "title = ?", "Чернетка")
+
+	add := func(id uint) *http.Response {
+		return learner.postJSON("/api/v1/wishlist/add", map[string]any{"CourseID": id})
+	}
+	learner.expect(add(course.ID), http.StatusCreated)
+	learner.expect(add(course.ID), http.StatusCreated)
+	learner.expect(add(draft.ID), http.StatusNotFound)
+
+	resp := learner.get("/api/v1/wishlist")
+	learner.expect(resp, http.StatusOK)
+
+	var wishlist []models.Course
+	learner.decode(resp, &wishlist)
+	if len(wishlist) != 1 || wishlist[0].ID != course.ID {
+		t.Fatalf("unexpected wishlist %+v", wishlist)
+	}
+
+	// the flag is only set for the authenticated requests
+	inWishlist := func(c *testClient) map[uint]*bool {
+		resp := c.get("/api/v1/catalogue")
+		c.expect(resp, http.StatusOK)
+
+		var body struct{ Courses []models.Course }
+		c.decode(resp, &body)
+
+		flags := make(map[uint]*bool)
+		for _, course := range body.Courses {
+			flags[course.ID] = course.InWishlist
+		}
+		return flags
+	}
+
+	flags := inWishlist(learner)
+	if len(flags) == 0 {
+		t.Fatal("expected catalogue courses")
+	}
+	for id, flag := range flags {
+		if flag == nil || *flag != (id == course.ID) {
+			t.Errorf("course %d: unexpected wishlist flag %v", id, flag)
+		}
+	}
+
+	for id, flag := range inWishlist(a.client()) {
+		if flag != nil {
+			t.Errorf("course %d: expected no wishlist flag for anonymous requests", id)
+		}
+	}
+
+	// price drops notify the wishlisting users
+	setPrice := func(price int) {
+		instructor.expect(instructor.postForm("/api/v1/courses/update-general", map[string]string{
+			"Title":            course.Title,
+			"ShortDescription": course.ShortDescription,
+			"Description":      course.Description,
+			"Price":            fmt.Sprint(price),
+			"CourseID":         fmt.Sprint(course.ID),
+		}), http.StatusOK)
+	}
+
+	notifications := func(query string) []models.Notification {
+		resp := learner.get("/api/v1/notifications?" + query)
+		learner.expect(resp, http.StatusOK)
+
+		var notifications []models.Notification
+		learner.decode(resp, &notifications)
+		return notifications
+	}
+
+	setPrice(69900)
+	setPrice(49900)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(notifications("")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected a price drop notification")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	list := notifications("unread=true")
+	if len(list) != 1 || list[0].Kind != "price_drop" || list[0].CourseID == nil || *list[0].CourseID != course.ID ||
+		!strings.Contains(list[0].Body, "499.00 UAH замість 699.00 UAH") {
+		t.Fatalf("unexpected notifications %+v", list)
+	}
+
+	learner.expect(learner.postJSON("/api/v1/notifications/mark-read", map[string]any{}), http.StatusOK)
+	if list := notifications("unread=true"); len(list) != 0 {
+		t.Fatalf("expected the notifications to be read, got %+v", list)
+	}
+
+	learner.expect(learner.postJSON("/api/v1/wishlist/remove", map[string]any{"CourseID": course.ID}), http.StatusOK)
+	if flags := inWishlist(learner); *flags[course.ID] {
+		t.Error("expected the course to be removed from the wishlist")
+	}
+}
+
+func TestCheckout(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+
+	var course, free models.Course
+	a.app.DB.First(&course, "title = ?", "Використання мікросервісів у Go")
+	a.app.DB.First(&free, "title = ?", "Створення курсів на Plaja")
+
+	learner := a.signUp("learner@plaja.test")
+
+	// paid courses are bought, free ones are enrolled into
+	learner.expect(learner.postJSON("/api/v1/enrollments/create", map[string]any{"CourseID": course.ID}), http.StatusForbidden)
+	learner.expect(learner.postJSON("/api/v1/orders/checkout", map[string]any{"CourseID": free.ID}), http.StatusBadRequest)
+
+	resp := learner.postJSON("/api/v1/orders/checkout", map[string]any{"CourseID": course.ID})
+	learner.expect(resp, http.StatusCreated)
+
+	var session services.CheckoutSession
+	learner.decode(resp, &session)
+
+	order := session.Order
+	if order.Status != services.OrderStatusPending || order.Amount != course.Price || order.Currency != "UAH" ||
+		!strings.Contains(session.URL, fmt.Sprintf("order_id=%d", order.ID)) {
+		t.Fatalf("unexpected checkout session %+v", session)
+	}
+
+	if again := learner.checkout(course.ID); again.ID != order.ID {
+		t.Errorf("expected the pending order %d to be reused, got %d", order.ID, again.ID)
+	}
+
+	webhook := payments.FakeWebhook{
+		EventID:  "event-1",
+		OrderID:  order.ID,
+		Status:   payments.StatusSucceeded,
+		Amount:   order.Amount,
+		Currency: order.Currency,
+	}
+
+	// the webhooks are signed and must match the order
+	learner.expect(learner.webhook(webhook, "wrong-secret"), http.StatusUnauthorized)
+
+	underpaid := webhook
+	underpaid.EventID, underpaid.Amount = "event-0", 1
+	learner.expect(learner.webhook(underpaid, testPaymentSecret), http.StatusBadRequest)
+
+	orders := func() []models.Order {
+		resp := learner.get(fmt.Sprintf("/api/v1/orders?course_id=%d", course.ID))
+		learner.expect(resp, http.StatusOK)
+
+		var orders []models.Order
+		learner.decode(resp, &orders)
+		return orders
+	}
+
+	if list := orders(); len(list) != 1 || list[0].Status != services.OrderStatusPending {
+		t.Fatalf("expected the order to stay pending, got %+v", list)
+	}
+
+	// the paid order enrolls the learner, duplicates are ignored
+	learner.expect(learner.webhook(webhook, testPaymentSecret), http.StatusOK)
+	learner.expect(learner.webhook(webhook, testPaymentSecret), http.StatusOK)
+
+	if list := orders(); len(list) != 1 || list[0].Status != services.OrderStatusPaid || list[0].PaidAt == nil {
+		t.Fatalf("expected the order to be paid, got %+v", list)
+	}
+
+	var count int64
+	a.app.DB.Model(&models.Enrollment{}).Where("course_id = ?", course.ID).Count(&count)
+	if count != 1 {
+		t.Errorf("expected 1 enrollment, got %d", count)
+	}
+
+	learner.expect(learner.postJSON("/api/v1/orders/checkout", map[string]any{"CourseID": course.ID}), http.StatusConflict)
+}
+
+func TestCoupons(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+	admin := a.login("mail@plaja.io", "plaja-dev-password")
+
+	var course, svelte models.Course
+	a.app.DB.First(&course, "title = ?", "Використання мікросервісів у Go")
+	a.app.DB.First(&svelte, "title = ?", "Svelte та SvelteKit: повний курс")
+
+	learner := a.signUp("learner@plaja.test")
+	other := a.signUp("other@plaja.test")
+
+	// the sale lowers the price until it ends
+	tomorrow := time.Now().Add(24 * time.Hour)
+	sale := func(c *testClient, price uint) *http.Response {
+		return c.postJSON("/api/v1/courses/update-sale", map[string]any{"CourseID": course.ID, "SalePrice": price, "EndsAt": tomorrow})
+	}
+	learner.expect(sale(learner, 49900), http.StatusForbidden)
+	admin.expect(sale(admin, 69900), http.StatusBadRequest)
+	admin.expect(sale(admin, 49900), http.StatusOK)
+
+	coupon := func(c *testClient, body map[string]any) *http.Response {
+		return c.postJSON("/api/v1/coupons/create", body)
+	}
+	learner.expect(coupon(learner, map[string]any{"Code": "ALL50", "Kind": "percentage", "Value": 50}), http.StatusForbidden)
+	admin.expect(coupon(admin, map[string]any{"Code": "x", "Kind": "percentage", "Value": 10}), http.StatusBadRequest)
+	admin.expect(coupon(admin, map[string]any{"Code": "SPRING", "Kind": "percentage", "Value": 101}), http.StatusBadRequest)
+	admin.expect(coupon(admin, map[string]any{
+		"Code": " spring10 ", "CourseID": course.ID, "Kind": "percentage", "Value": 10, "MaxRedemptions": 1,
+	}), http.StatusCreated)
+	admin.expect(coupon(admin, map[string]any{"Code": "SPRING10", "Kind": "fixed", "Value": 10000}), http.StatusConflict)
+	admin.expect(coupon(admin, map[string]any{"Code": "FREE", "Kind": "percentage", "Value": 100}), http.StatusCreated)
+
+	prices := func(path string) map[uint]uint {
+		resp := a.client().get(path)
+		learner.expect(resp, http.StatusOK)
+
+		var body struct{ Courses []models.Course }
+		learner.decode(resp, &body)
+
+		prices := make(map[uint]uint)
+		for _, c := range body.Courses {
+			prices[c.ID] = *c.FinalPrice
+		}
+		return prices
+	}
+
+	if p := prices("/api/v1/catalogue"); p[course.ID] != 49900 || p[svelte.ID] != 19900 {
+		t.Fatalf("unexpected sale prices %v", p)
+	}
+	if p := prices("/api/v1/catalogue?coupon=spring10"); p[course.ID] != 44910 || p[svelte.ID] != 19900 {
+		t.Fatalf("unexpected coupon prices %v", p)
+	}
+	learner.expect(a.client().get("/api/v1/catalogue?coupon=UNKNOWN"), http.StatusNotFound)
+
+	checkout := func(c *testClient, code string) *http.Response {
+		return c.postJSON("/api/v1/orders/checkout", map[string]any{"CourseID": course.ID, "CouponCode": code})
+	}
+
+	// the coupon is redeemed once the order is paid
+	resp := checkout(learner, "spring10")
+	learner.expect(resp, http.StatusCreated)
+
+	var session services.CheckoutSession
+	learner.decode(resp, &session)
+	if session.Order.Amount != 44910 || session.Order.Discount != 4990 || session.Order.CouponID == nil {
+		t.Fatalf("unexpected order %+v", session.Order)
+	}
+
+	learner.expect(learner.webhook(payments.FakeWebhook{
+		EventID:  "event-1",
+		OrderID:  session.Order.ID,
+		Status:   payments.StatusSucceeded,
+		Amount:   session.Order.Amount,
+		Currency: session.Order.Currency,
+	}, testPaymentSecret), http.StatusOK)
+
+	other.expect(checkout(other, "SPRING10"), http.StatusBadRequest)
+
+	// the fully discounted order is paid right away
+	resp = checkout(other, "free")
+	other.expect(resp, http.StatusCreated)
+
+	session = services.CheckoutSession{}
+	other.decode(resp, &session)
+	if session.Order.Status != services.OrderStatusPaid || session.Order.Amount != 0 || session.URL != "" {
+		t.Fatalf("expected a paid order without a checkout page, got %+v", session)
+	}
+
+	var count int64
+	a.app.DB.Model(&models.Enrollment{}).Where("course_id = ?", course.ID).Count(&count)
+	if count != 2 {
+		t.Errorf("expected 2 enrollments, got %d", count)
+	}
+
+	// the redemptions in another currency are reported apart
+	var spring models.Coupon
+	a.app.DB.First(&spring, "code = ?", "SPRING10")
+	usdOrder := models.Order{
+		UserID: session.Order.UserID, CourseID: course.ID, Amount: 1349, Discount: 150, CouponID: &spring.ID,
+		Currency: "USD", Status: services.OrderStatusPaid, Provider: "fake",
+	}
+	a.app.DB.Create(&usdOrder)
+	a.app.DB.Create(&models.CouponRedemption{
+		CouponID: spring.ID, OrderID: usdOrder.ID, UserID: usdOrder.UserID, CourseID: course.ID, Discount: 150, Amount: 1349,
+	})
+
+	resp = admin.get("/api/v1/coupons/report")
+	admin.expect(resp, http.StatusOK)
+
+	var reports []services.CouponReport
+	admin.decode(resp, &reports)
+
+	if len(reports) != 3 ||
+		reports[0].Code != "SPRING10" || reports[0].Currency != "UAH" || reports[0].Redemptions != 1 || reports[0].Discount != 4990 || reports[0].Revenue != 44910 ||
+		reports[1].Code != "SPRING10" || reports[1].Currency != "USD" || reports[1].Redemptions != 1 || reports[1].Discount != 150 || reports[1].Revenue != 1349 ||
+		reports[2].Code != "FREE" || reports[2].Currency != "UAH" || reports[2].Redemptions != 1 || reports[2].Discount != 49900 || reports[2].Revenue != 0 {
+		t.Fatalf("unexpected coupon report %+v", reports)
+	}
+
+	resp = learner.get("/api/v1/coupons/report")
+	learner.expect(resp, http.StatusOK)
+	reports = nil
+	learner.decode(resp, &reports)
+	if len(reports) != 0 {
+		t.Errorf("expected no coupons of the learner, got %+v", reports)
+	}
+}
+
+func TestRefunds(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+	admin := a.login("mail@plaja.io", "plaja-dev-password")
+
+	var course models.Course
+	a.app.DB.First(&course, "title = ?", "Використання мікросервісів у Go")
+
+	learner := a.signUp("learner@plaja.test")
+	other := a.signUp("other@plaja.test")
+
+	learner.buy(course.ID)
+	other.buy(course.ID)
+
+	learner.expect(learner.postJSON("/api/v1/course-reviews/create", map[string]any{
+		"CourseID": course.ID, "Rating": 5, "Text": "Чудовий курс",
+	}), http.StatusCreated)
+
+	var order, otherOrder models.Order
+	a.app.DB.First(&order, "user_id = (SELECT id FROM users WHERE email = ?)", "learner@plaja.test")
+	a.app.DB.First(&otherOrder, "user_id = (SELECT id FROM users WHERE email = ?)", "other@plaja.test")
+
+	certificate := models.CourseCertificate{UserID: order.UserID, CourseID: course.ID}
+	a.app.DB.Create(&certificate)
+
+	request := func(c *testClient, orderID uint) *http.Response {
+		return c.postJSON("/api/v1/refunds/create", map[string]any{"OrderID": orderID, "Reason": "Не мій рівень"})
+	}
+
+	// only the own paid orders, once
+	learner.expect(request(learner, otherOrder.ID), http.StatusNotFound)
+
+	resp := request(learner, order.ID)
+	learner.expect(resp, http.StatusCreated)
+
+	var refund models.Refund
+	learner.decode(resp, &refund)
+	if refund.Status != services.RefundStatusRequested || refund.OrderID != order.ID {
+		t.Fatalf("unexpected refund %+v", refund)
+	}
+
+	learner.expect(request(learner, order.ID), http.StatusConflict)
+
+	// too much progress for a refund
+	a.app.DB.Model(&models.Enrollment{}).Where("user_id = ?", otherOrder.UserID).Update("progress", 50)
+	other.expect(request(other, otherOrder.ID), http.StatusBadRequest)
+
+	// the admins decide
+	decide := func(c *testClient, approve bool) *http.Response {
+		return c.postJSON("/api/v1/refunds/decide", map[string]any{"RefundID": refund.ID, "Approve": approve, "Note": "Гаразд"})
+	}
+	learner.expect(decide(learner, true), http.StatusForbidden)
+	learner.expect(learner.get("/api/v1/refunds/requests"), http.StatusForbidden)
+
+	resp = admin.get("/api/v1/refunds/requests?status=requested")
+	admin.expect(resp, http.StatusOK)
+
+	var requests []models.Refund
+	admin.decode(resp, &requests)
+	if len(requests) != 1 || requests[0].ID != refund.ID {
+		t.Fatalf("unexpected refund requests %+v", requests)
+	}
+
+	admin.expect(decide(admin, true), http.StatusOK)
+	admin.expect(decide(admin, false), http.StatusConflict)
+
+	// the payment is returned and the enrollment revoked
+	resp = learner.get("/api/v1/refunds")
+	learner.expect(resp, http.StatusOK)
+
+	var refunds []models.Refund
+	learner.decode(resp, &refunds)
+	if len(refunds) != 1 || refunds[0].Status != services.RefundStatusRefunded || refunds[0].DecidedAt == nil {
+		t.Fatalf("unexpected refunds %+v", refunds)
+	}
+
+	a.app.DB.First(&order, order.ID)
+	if order.Status != services.OrderStatusRefunded {
+		t.Errorf("expected the order to be refunded, got %s", order.Status)
+	}
+
+	var enrollments, reviews int64
+	a.app.DB.Model(&models.Enrollment{}).Where("user_id = ?", order.UserID).Count(&enrollments)
+	a.app.DB.Model(&models.CourseReview{}).Where("user_id = ?", order.UserID).Count(&reviews)
+	if enrollments != 0 || reviews != 0 {
+		t.Errorf("expected the enrollment and the review to be removed, got %d and %d", enrollments, reviews)
+	}
+
+	a.app.DB.First(&course, course.ID)
+	if course.RatingCount != 0 {
+		t.Errorf("expected the rating to be refreshed, got %d ratings", course.RatingCount)
+	}
+
+	a.app.DB.First(&certificate, certificate.ID)
+	if certificate.RevokedAt == nil {
+		t.Error("expected the certificate to be revoked")
+	}
+
+	// the audit log records every step
+	learner.expect(learner.get("/api/v1/audit-log"), http.StatusForbidden)
+
+	resp = admin.get("/api/v1/audit-log?sort=id")
+	admin.expect(resp, http.StatusOK)
+
+	var entries []models.AuditEntry
+	admin.decode(resp, &entries)
+
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+	}
+
+	expected := []string{
+		services.AuditRefundRequested,
+		services.AuditRefundApproved,
+		services.AuditOrderRefunded,
+		services.AuditEnrollmentRevoked,
+		services.AuditCertificateRevoked,
+	}
+	if !slices.Equal(actions, expected) {
+		t.Fatalf("expected audit actions %v, got %v", expected, actions)
+	}
+
+	resp = admin.get(fmt.Sprintf("/api/v1/audit-log?entity=orders&entity_id=%d", order.ID))
+	admin.expect(resp, http.StatusOK)
+
+	entries = nil
+	admin.decode(resp, &entries)
+	if len(entries) != 1 || entries[0].Action != services.AuditOrderRefunded || entries[0].ActorID == nil {
+		t.Fatalf("unexpected order audit entries %+v", entries)
+	}
+}
+
+func TestRefundCompletion(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+	admin := a.login("mail@plaja.io", "plaja-dev-password")
+
+	var course models.Course
+	a.app.DB.First(&course, "title = ?", "Використання мікросервісів у Go")
+
+	learner := a.signUp("learner@plaja.test")
+	learner.buy(course.ID)
+
+	var order models.Order
+	a.app.DB.First(&order, "user_id = (SELECT id FROM users WHERE email = ?)", "learner@plaja.test")
+
+	resp := learner.postJSON("/api/v1/refunds/create", map[string]any{"OrderID": order.ID, "Reason": "Не мій рівень"})
+	learner.expect(resp, http.StatusCreated)
+
+	var refund models.Refund
+	learner.decode(resp, &refund)
+
+	decide := func(approve bool) *http.Response {
+		return admin.postJSON("/api/v1/refunds/decide", map[string]any{"RefundID": refund.ID, "Approve": approve})
+	}
+
+	// the payment is returned, but refunding the order fails
+	err := a.app.DB.Exec(`CREATE TRIGGER fail_order_refund BEFORE UPDATE ON orders WHEN NEW.status = 'refunded'
+		BEGIN SELECT RAISE(ABORT, 'database is unavailable'); END`).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin.expect(decide(true), http.StatusInternalServerError)
+
+	a.app.DB.First(&refund, refund.ID)
+	if refund.Status != services.RefundStatusProcessing || refund.Reference != "refund-"+order.Reference {
+		t.Fatalf("expected the processing refund with the provider reference, got %+v", refund)
+	}
+
+	a.app.DB.First(&order, order.ID)
+	if order.Status != services.OrderStatusPaid {
+		t.Errorf("expected the order to stay paid, got %s", order.Status)
+	}
+
+	// the processing refund is completed, not rejected
+	admin.expect(decide(false), http.StatusConflict)
+
+	if err := a.app.DB.Exec("DROP TRIGGER fail_order_refund").Error; err != nil {
+		t.Fatal(err)
+	}
+	admin.expect(decide(true), http.StatusOK)
+	admin.expect(decide(true), http.StatusConflict)
+
+	a.app.DB.First(&refund, refund.ID)
+	a.app.DB.First(&order, order.ID)
+	if refund.Status != services.RefundStatusRefunded || order.Status != services.OrderStatusRefunded {
+		t.Fatalf("expected the refunded order, got refund %s and order %s", refund.Status, order.Status)
+	}
+
+	var enrollments, refunded int64
+	a.app.DB.Model(&models.Enrollment{}).Where("user_id = ?", order.UserID).Count(&enrollments)
+	a.app.DB.Model(&models.AuditEntry{}).Where("action = ?", services.AuditOrderRefunded).Count(&refunded)
+	if enrollments != 0 || refunded != 1 {
+		t.Errorf("expected the order to be refunded once, got %d enrollments and %d refunds", enrollments, refunded)
+	}
+
+	// a refund left approved by a crash before recording the returned payment is recovered
+	other := a.signUp("other@plaja.test")
+	other.buy(course.ID)
+	order = models.Order{}
+	a.app.DB.First(&order, "user_id = (SELECT id FROM users WHERE email = ?)", "other@plaja.test")
+
+	resp = other.postJSON("/api/v1/refunds/create", map[string]any{"OrderID": order.ID})
+	other.expect(resp, http.StatusCreated)
+	refund = models.Refund{}
+	other.decode(resp, &refund)
+	a.app.DB.Model(&refund).Update("status", services.RefundStatusApproved)
+
+	admin.expect(decide(false), http.StatusConflict)
+	admin.expect(decide(true), http.StatusOK)
+
+	a.app.DB.First(&refund, refund.ID)
+	a.app.DB.First(&order, order.ID)
+	if refund.Status != services.RefundStatusRefunded || refund.Reference != "refund-"+order.Reference || order.Status != services.OrderStatusRefunded {
+		t.Fatalf("expected the recovered refund, got refund %+v and order %s", refund, order.Status)
+	}
+}
+
+func TestLedger(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+	admin := a.login("mail@plaja.io", "plaja-dev-password")
+
+	var course models.Course
+	a.app.DB.First(&course, "title = ?", "Використання мікросервісів у Go")
+
+	learner := a.signUp("learner@plaja.test")
+	other := a.signUp("other@plaja.test")
+
+	learner.buy(course.ID)
+	other.buy(course.ID)
+
+	// the refund reverses the sale
+	var order models.Order
+	a.app.DB.First(&order, "user_id = (SELECT id FROM users WHERE email = ?)", "learner@plaja.test")
+
+	resp := learner.postJSON("/api/v1/refunds/create", map[string]any{"OrderID": order.ID})
+	learner.expect(resp, http.StatusCreated)
+
+	var refund models.Refund
+	learner.decode(resp, &refund)
+	admin.expect(admin.postJSON("/api/v1/refunds/decide", map[string]any{"RefundID": refund.ID, "Approve": true}), http.StatusOK)
+
+	earnings := func() services.Earnings {
+		resp := admin.get("/api/v1/earnings?period=month")
+		admin.expect(resp, http.StatusOK)
+
+		var earnings services.Earnings
+		admin.decode(resp, &earnings)
+		return earnings
+	}
+
+	e := earnings()
+	if len(e.Balances) != 1 || e.Balances[0] != (services.Balance{Currency: "UAH", Amount: 41930}) {
+		t.Fatalf("unexpected balances %+v", e.Balances)
+	}
+	if len(e.Periods) != 1 || e.Periods[0].Sales != 119800 || e.Periods[0].Refunds != 59900 ||
+		e.Periods[0].Fees != 17970 || e.Periods[0].Earnings != 41930 || e.Periods[0].Payouts != 0 {
+		t.Fatalf("unexpected periods %+v", e.Periods)
+	}
+
+	learner.expect(learner.get(fmt.Sprintf("/api/v1/earnings?instructor_id=%d", course.InstructorID)), http.StatusForbidden)
+	admin.expect(admin.get("/api/v1/earnings?period=year"), http.StatusBadRequest)
+
+	// payouts up to the balance
+	payout := func(c *testClient, amount uint) *http.Response {
+		return c.postJSON("/api/v1/payouts/create", map[string]any{
+			"InstructorID": course.InstructorID, "Amount": amount, "Currency": "UAH", "Reference": "PAY-1",
+		})
+	}
+	learner.expect(payout(learner, 10000), http.StatusForbidden)
+	admin.expect(payout(admin, 100000), http.StatusBadRequest)
+	admin.expect(payout(admin, 0), http.StatusCreated)
+	admin.expect(payout(admin, 0), http.StatusBadRequest)
+
+	if e := earnings(); e.Balances[0].Amount != 0 || e.Periods[0].Payouts != 41930 {
+		t.Fatalf("expected the balance to be paid out, got %+v", e)
+	}
+
+	// every transaction is balanced
+	resp = admin.get("/api/v1/ledger")
+	admin.expect(resp, http.StatusOK)
+
+	var transactions []models.LedgerTransaction
+	admin.decode(resp, &transactions)
+
+	var kinds []string
+	for _, tr := range transactions {
+		kinds = append(kinds, tr.Kind)
+		if !services.Balanced(tr.Entries) {
+			t.Errorf("unbalanced transaction %+v", tr)
+		}
+	}
+	if !slices.Equal(kinds, []string{services.LedgerSale, services.LedgerSale, services.LedgerRefund, services.LedgerPayout}) {
+		t.Fatalf("unexpected transactions %v", kinds)
+	}
+
+	// monthly statements
+	resp = admin.get("/api/v1/earnings/statement?format=csv")
+	admin.expect(resp, http.StatusOK)
+
+	body, _ := io.ReadAll(resp.Body)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if resp.Header.Get("Content-Type") != "text/csv; charset=utf-8" || len(lines) != 6 ||
+		!strings.HasSuffix(lines[5], ",closing,,,UAH,,,0.00") {
+		t.Fatalf("unexpected CSV statement %q", body)
+	}
+
+	resp = admin.get(fmt.Sprintf("/api/v1/earnings/statement?format=pdf&month=%s", time.Now().Format("2006-01")))
+	admin.expect(resp, http.StatusOK)
+
+	body, _ = io.ReadAll(resp.Body)
+	if resp.Header.Get("Content-Type") != "application/pdf" || !bytes.HasPrefix(body, []byte("%PDF-")) {
+		t.Fatalf("unexpected PDF statement %q", resp.Header)
+	}
+
+	learner.expect(learner.get(fmt.Sprintf("/api/v1/earnings/statement?instructor_id=%d", course.InstructorID)), http.StatusForbidden)
+	admin.expect(admin.get("/api/v1/earnings/statement?month=March"), http.StatusBadRequest)
+}
+
+func TestSubscriptions(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+	admin := a.login("mail@plaja.io", "plaja-dev-password")
+
+	var course models.Course
+	a.app.DB.First(&course, "title = ?", "Використання мікросервісів у Go")
+
+	admin.expect(admin.postJSON("/api/v1/course-exercises/create-update", map[string]any{
+		"CourseID":  course.ID,
+		"Exercises": []map[string]any{{"Title": "Вступ", "Content": "Сервіси та їх межі"}},
+	}), http.StatusCreated)
+
+	resp := admin.postJSON("/api/v1/subscription-plans/create", map[string]any{
+		"Code": "Pro-Monthly", "Name": "Plaja Pro", "Interval": "month", "Price": 29900,
+	})
+	admin.expect(resp, http.StatusCreated)
+
+	var plan models.SubscriptionPlan
+	admin.decode(resp, &plan)
+	if plan.Code != "pro-monthly" || plan.Currency != "UAH" || !plan.Active {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+
+	learner := a.signUp("learner@plaja.test")
+	learner.expect(learner.postJSON("/api/v1/subscription-plans/create", map[string]any{
+		"Code": "cheap", "Name": "Cheap", "Interval": "month", "Price": 100,
+	}), http.StatusForbidden)
+	learner.expect(learner.postJSON("/api/v1/subscription-plans/create", map[string]any{}), http.StatusBadRequest)
+
+	content := func() string {
+		resp := learner.get(fmt.Sprintf("/api/v1/course-exercises?course_id=%d", course.ID))
+		learner.expect(resp, http.StatusOK)
+
+		var exercises []models.CourseExercise
+		learner.decode(resp, &exercises)
+		if len(exercises) != 1 {
+			t.Fatalf("expected 1 exercise, got %+v", exercises)
+		}
+		return exercises[0].Content
+	}
+
+	// without a subscription the paid course is neither enrollable nor readable
+	learner.expect(learner.postJSON("/api/v1/enrollments/create", map[string]any{"CourseID": course.ID}), http.StatusForbidden)
+	if c := content(); c != "" {
+		t.Errorf("expected the content to be hidden, got %q", c)
+	}
+
+	resp = learner.postJSON("/api/v1/subscriptions/create", map[string]any{"PlanID": plan.ID})
+	learner.expect(resp, http.StatusCreated)
+
+	var checkout services.SubscriptionCheckout
+	learner.decode(resp, &checkout)
+
+	subscription := checkout.Subscription
+	if subscription.Status != services.SubscriptionStatusPending || subscription.Amount != 29900 || checkout.URL == "" {
+		t.Fatalf("unexpected subscription checkout %+v", checkout)
+	}
+
+	webhook := payments.FakeWebhook{
+		EventID:  "subscription-paid-1",
+		OrderID:  subscription.ID,
+		Kind:     payments.KindSubscription,
+		Status:   payments.StatusSucceeded,
+		Amount:   subscription.Amount,
+		Currency: subscription.Currency,
+	}
+	learner.expect(learner.webhook(webhook, testPaymentSecret), http.StatusOK)
+	learner.expect(learner.webhook(webhook, testPaymentSecret), http.StatusOK)
+
+	subscriptions := func() []models.Subscription {
+		resp := learner.get("/api/v1/subscriptions")
+		learner.expect(resp, http.StatusOK)
+
+		var subscriptions []models.Subscription
+		learner.decode(resp, &subscriptions)
+		return subscriptions
+	}
+
+	list := subscriptions()
+	if len(list) != 1 || list[0].Status != services.SubscriptionStatusActive || list[0].CurrentPeriodEnd == nil ||
+		list[0].Plan.Code != "pro-monthly" {
+		t.Fatalf("expected an active subscription, got %+v", list)
+	}
+	end := *list[0].CurrentPeriodEnd
+
+	var paid int64
+	a.app.DB.Model(&models.SubscriptionPayment{}).Where("subscription_id = ?", subscription.ID).Count(&paid)
+	if paid != 1 {
+		t.Errorf("expected 1 subscription payment, got %d", paid)
+	}
+
+	learner.expect(learner.postJSON("/api/v1/subscriptions/create", map[string]any{"PlanID": plan.ID}), http.StatusConflict)
+
+	// the subscription enrolls into the paid courses and opens their content
+	learner.expect(learner.postJSON("/api/v1/enrollments/create", map[string]any{"CourseID": course.ID}), http.StatusCreated)
+	if c := content(); c != "Сервіси та їх межі" {
+		t.Errorf("expected the content to be visible, got %q", c)
+	}
+
+	a.app.DB.Model(&models.Enrollment{}).Where("course_id = ?", course.ID).Update("progress", 40)
+
+	// a renewal extends the period
+	renewal := webhook
+	renewal.EventID = "subscription-paid-2"
+	learner.expect(learner.webhook(renewal, testPaymentSecret), http.StatusOK)
+
+	if list := subscriptions(); !list[0].CurrentPeriodEnd.Equal(end.AddDate(0, 1, 0)) {
+		t.Errorf("expected the period to end at %v, got %v", end.AddDate(0, 1, 0), list[0].CurrentPeriodEnd)
+	}
+
+	// the lapsed subscription hides the content but keeps the enrollment and its progress
+	a.app.DB.Model(&models.Subscription{}).Where("id = ?", subscription.ID).
+		Update("current_period_end", time.Now().Add(-4*24*time.Hour))
+
+	if list := subscriptions(); list[0].Status != services.SubscriptionStatusExpired {
+		t.Errorf("expected the subscription to expire, got %s", list[0].Status)
+	}
+	if c := content(); c != "" {
+		t.Errorf("expected the content to be hidden, got %q", c)
+	}
+
+	var enrollment models.Enrollment
+	a.app.DB.First(&enrollment, "course_id = ?", course.ID)
+	if enrollment.Progress != 40 || enrollment.SubscriptionID == nil {
+		t.Errorf("expected the enrollment to keep its progress, got %+v", enrollment)
+	}
+
+	// within the grace period the access is kept while the renewal is due
+	a.app.DB.Model(&models.Subscription{}).Where("id = ?", subscription.ID).
+		Update("current_period_end", time.Now().Add(-24*time.Hour))
+
+	if list := subscriptions(); list[0].Status != services.SubscriptionStatusPastDue {
+		t.Errorf("expected the subscription to be past due, got %s", list[0].Status)
+	}
+	if c := content(); c == "" {
+		t.Error("expected the content to be visible during the grace period")
+	}
+
+	// the course bought on top of the subscription stays open for good
+	learner.buy(course.ID)
+	a.app.DB.First(&enrollment, "course_id = ?", course.ID)
+	if enrollment.SubscriptionID != nil || enrollment.Progress != 40 {
+		t.Errorf("expected the bought enrollment to keep its progress, got %+v", enrollment)
+	}
+
+	// canceled subscriptions are not renewed and cannot be canceled again
+	cancel := map[string]any{"SubscriptionID": subscription.ID}
+	learner.expect(learner.postJSON("/api/v1/subscriptions/cancel", cancel), http.StatusOK)
+	learner.expect(learner.postJSON("/api/v1/subscriptions/cancel", cancel), http.StatusBadRequest)
+
+	if list := subscriptions(); list[0].Status != services.SubscriptionStatusExpired {
+		t.Errorf("expected the canceled subscription past its period to expire, got %s", list[0].Status)
+	}
+
+	// the inactive plans are hidden from the learners
+	admin.expect(admin.postJSON("/api/v1/subscription-plans/update", map[string]any{
+		"PlanID": plan.ID, "Name": "Plaja Pro", "Price": 34900, "Active": false,
+	}), http.StatusOK)
+
+	resp = learner.get("/api/v1/subscription-plans")
+	learner.expect(resp, http.StatusOK)
+
+	var plans []models.SubscriptionPlan
+	learner.decode(resp, &plans)
+	if len(plans) != 0 {
+		t.Errorf("expected no active plans, got %+v", plans)
+	}
+
+	learner.expect(learner.postJSON("/api/v1/subscriptions/create", map[string]any{"PlanID": plan.ID}), http.StatusNotFound)
+}
+
+func TestInvoices(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+	admin := a.login("mail@plaja.io", "plaja-dev-password")
+
+	var course models.Course
+	a.app.DB.First(&course, "title = ?", "Використання мікросервісів у Go")
+
+	learner := a.signUp("learner@plaja.test")
+	business := a.signUp("business@plaja.test")
+
+	// the businesses abroad are reverse charged
+	business.expect(business.postJSON("/api/v1/billing-profile/update", map[string]any{"TaxID": "PL1234567890"}), http.StatusBadRequest)
+	business.expect(business.postJSON("/api/v1/billing-profile/update", map[string]any{
+		"Company": "Kurs Sp. z o.o.", "TaxID": "pl 1234567890", "Address": "ul. Długa 1, Kraków", "Country": "pl",
+	}), http.StatusOK)
+
+	resp := business.get("/api/v1/billing-profile")
+	business.expect(resp, http.StatusOK)
+
+	var profile models.BillingProfile
+	business.decode(resp, &profile)
+	if profile.TaxID != "PL1234567890" || profile.Country != "PL" {
+		t.Fatalf("unexpected billing profile %+v", profile)
+	}
+
+	learner.buy(course.ID)
+	business.buy(course.ID)
+
+	invoices := func(c *testClient, query string) []models.Invoice {
+		resp := c.get("/api/v1/invoices" + query)
+		c.expect(resp, http.StatusOK)
+
+		var invoices []models.Invoice
+		c.decode(resp, &invoices)
+		return invoices
+	}
+
+	year := time.Now().Year()
+
+	list := invoices(learner, "")
+	if len(list) != 1 || list[0].Number != fmt.Sprintf("INV-%d-000001", year) || list[0].Kind != services.InvoiceKindInvoice ||
+		list[0].Total != 59900 || list[0].Net != 49917 || list[0].VAT != 9983 || list[0].VATRate != 2000 || list[0].ReverseCharge {
+		t.Fatalf("unexpected learner invoices %+v", list)
+	}
+	invoice := list[0]
+
+	list = invoices(business, "")
+	if len(list) != 1 || list[0].Number != fmt.Sprintf("INV-%d-000002", year) || !list[0].ReverseCharge ||
+		list[0].Net != 59900 || list[0].VAT != 0 || list[0].BuyerName != "Kurs Sp. z o.o." || list[0].BuyerCountry != "PL" {
+		t.Fatalf("unexpected business invoices %+v", list)
+	}
+
+	business.expect(business.get(fmt.Sprintf("/api/v1/invoices?user_id=%d", invoice.UserID)), http.StatusForbidden)
+	if list := invoices(admin, fmt.Sprintf("?user_id=%d", invoice.UserID)); len(list) != 1 {
+		t.Fatalf("expected the admin to see the learner invoice, got %+v", list)
+	}
+
+	// the documents are downloadable by the buyer only, not through the public storage
+	document := fmt.Sprintf("/api/v1/invoices/document?id=%d", invoice.ID)
+	for i := 0; i < 2; i++ {
+		resp = learner.get(document)
+		learner.expect(resp, http.StatusOK)
+
+		body, _ := io.ReadAll(resp.Body)
+		if resp.Header.Get("Content-Type") != "application/pdf" || !bytes.HasPrefix(body, []byte("%PDF-")) ||
+			!strings.Contains(resp.Header.Get("Content-Disposition"), invoice.Number+".pdf") {
+			t.Fatalf("unexpected invoice document %q", resp.Header)
+		}
+	}
+
+	business.expect(business.get(document), http.StatusForbidden)
+	admin.expect(admin.get(document), http.StatusOK)
+	learner.expect(learner.get(fmt.Sprintf("/api/v1/storage/private/invoices/%s.pdf", invoice.Number)), http.StatusNotFound)
+
+	// the refund issues a credit note of the invoice
+	resp = learner.postJSON("/api/v1/refunds/create", map[string]any{"OrderID": *invoice.OrderID})
+	learner.expect(resp, http.StatusCreated)
+
+	var refund models.Refund
+	learner.decode(resp, &refund)
+	admin.expect(admin.postJSON("/api/v1/refunds/decide", map[string]any{"RefundID": refund.ID, "Approve": true}), http.StatusOK)
+
+	list = invoices(learner, "?kind=credit_note")
+	if len(list) != 1 || list[0].Number != fmt.Sprintf("CN-%d-000001", year) || list[0].CreditedInvoiceID == nil ||
+		*list[0].CreditedInvoiceID != invoice.ID || list[0].Total != 59900 || list[0].VAT != 9983 {
+		t.Fatalf("unexpected credit notes %+v", list)
+	}
+
+	learner.expect(learner.get(fmt.Sprintf("/api/v1/invoices/document?id=%d", list[0].ID)), http.StatusOK)
+}
+
+func TestMultiCurrency(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+	admin := a.login("mail@plaja.io", "plaja-dev-password")
+
+	var course, svelte models.Course
+	a.app.DB.First(&course, "title = ?", "Використання мікросервісів у Go")
+	a.app.DB.First(&svelte, "title = ?", "Svelte та SvelteKit: повний курс")
+
+	learner := a.signUp("learner@plaja.test")
+
+	// the admins import the rates against the payment currency
+	rates := []byte("currency,rate\nUSD,0.025\neur,0.023\n")
+	learner.expect(learner.postFile("/api/v1/exchange-rates/import", nil, "File", rates), http.StatusForbidden)
+	admin.expect(admin.postFile("/api/v1/exchange-rates/import", nil, "File", []byte("EUR,0.023\nUSD,x\n")), http.StatusBadRequest)
+	admin.expect(admin.postFile("/api/v1/exchange-rates/import", map[string]string{"Base": "XYZ"}, "File", rates), http.StatusBadRequest)
+	admin.expect(admin.postFile("/api/v1/exchange-rates/import", nil, "File", rates), http.StatusOK)
+
+	resp := a.client().get("/api/v1/exchange-rates")
+	learner.expect(resp, http.StatusOK)
+
+	var imported []models.ExchangeRate
+	learner.decode(resp, &imported)
+	if len(imported) != 2 || imported[0].Currency != "EUR" || imported[0].Base != "UAH" || imported[1].Rate != 0.025 {
+		t.Fatalf("unexpected rates %+v", imported)
+	}
+
+	// the instructors set the prices in the other currencies
+	prices := func(c *testClient, prices ...services.PriceInput) *http.Response {
+		return c.postJSON("/api/v1/courses/update-prices", map[string]any{"CourseID": course.ID, "Prices": prices})
+	}
+	learner.expect(prices(learner, services.PriceInput{Currency: "USD", Amount: 1499}), http.StatusForbidden)
+	admin.expect(prices(admin, services.PriceInput{Currency: "XYZ", Amount: 1499}), http.StatusBadRequest)
+	admin.expect(prices(admin, services.PriceInput{Currency: "UAH", Amount: 1499}), http.StatusBadRequest)
+	admin.expect(prices(admin, services.PriceInput{Currency: "usd", Amount: 1499}), http.StatusOK)
+
+	local := func(c *testClient, path string) map[uint]string {
+		resp := c.get(path)
+		c.expect(resp, http.StatusOK)
+
+		var body struct{ Courses []models.Course }
+		c.decode(resp, &body)
+
+		local := make(map[uint]string)
+		for _, c := range body.Courses {
+			local[c.ID] = fmt.Sprintf("%d %s", *c.LocalPrice, c.LocalCurrency)
+		}
+		return local
+	}
+
+	if p := local(a.client(), "/api/v1/catalogue?currency=EUR"); p[course.ID] != "1378 EUR" || p[svelte.ID] != "458 EUR" {
+		t.Fatalf("unexpected converted prices %v", p)
+	}
+	if p := local(a.client(), "/api/v1/catalogue"); p[course.ID] != "59900 UAH" {
+		t.Fatalf("unexpected course currency prices %v", p)
+	}
+	learner.expect(learner.get("/api/v1/catalogue?currency=XYZ"), http.StatusBadRequest)
+
+	// the preferred currency of the learner
+	learner.expect(learner.postForm("/api/v1/users/update-general", map[string]string{
+		"FirstName": "Тарас", "LastName": "Шевченко", "Currency": "XYZ",
+	}), http.StatusBadRequest)
+	learner.expect(learner.postForm("/api/v1/users/update-general", map[string]string{
+		"FirstName": "Тарас", "LastName": "Шевченко", "Currency": "usd",
+	}), http.StatusOK)
+
+	if p := local(learner, "/api/v1/catalogue"); p[course.ID] != "1499 USD" || p[svelte.ID] != "498 USD" {
+		t.Fatalf("unexpected preferred currency prices %v", p)
+	}
+
+	// the orders are paid in the preferred or the chosen currency
+	order := learner.checkout(course.ID)
+	if order.Amount != 1499 || order.Currency != "USD" {
+		t.Fatalf("unexpected order %+v", order)
+	}
+
+	resp = learner.postJSON("/api/v1/orders/checkout", map[string]any{"CourseID": course.ID, "Currency": "EUR"})
+	learner.expect(resp, http.StatusCreated)
+
+	var session services.CheckoutSession
+	learner.decode(resp, &session)
+	if session.Order.ID == order.ID || session.Order.Amount != 1378 || session.Order.Currency != "EUR" {
+		t.Fatalf("unexpected order %+v", session.Order)
+	}
+
+	learner.expect(learner.postJSON("/api/v1/orders/checkout", map[string]any{"CourseID": course.ID, "Currency": "XYZ"}), http.StatusBadRequest)
+}
+
+func TestOrganizations(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+
+	var course models.Course
+	a.app.DB.First(&course, "title = ?", "Використання мікросервісів у Go")
+
+	owner := a.signUp("owner@plaja.test")
+	manager := a.signUp("manager@plaja.test")
+	learner := a.signUp("learner@plaja.test")
+	outsider := a.signUp("outsider@plaja.test")
+
+	userID := func(c *testClient) uint {
+		resp := c.get("/api/v1/users/getme")
+		c.expect(resp, http.StatusOK)
+
+		var user models.User
+		c.decode(resp, &user)
+		return user.ID
+	}
+	managerID, learnerID, outsiderID := userID(manager), userID(learner), userID(outsider)
+
+	owner.expect(owner.postJSON("/api/v1/organizations/create", map[string]any{"Name": " "}), http.StatusBadRequest)
+	resp := owner.postJSON("/api/v1/organizations/create", map[string]any{"Name": "Acme"})
+	owner.expect(resp, http.StatusCreated)
+
+	var organization models.Organization
+	owner.decode(resp, &organization)
+
+	// the owners add the managers, the managers the learners
+	add := func(c *testClient, email, role string) *http.Response {
+		return c.postJSON("/api/v1/organization-members/add", map[string]any{"OrganizationID": organization.ID, "Email": email, "Role": role})
+	}
+	owner.expect(add(owner, "manager@plaja.test", services.RoleManager), http.StatusCreated)
+	manager.expect(add(manager, "outsider@plaja.test", services.RoleManager), http.StatusForbidden)
+	manager.expect(add(manager, "learner@plaja.test", "boss"), http.StatusBadRequest)
+	manager.expect(add(manager, "nobody@plaja.test", services.RoleLearner), http.StatusNotFound)
+	manager.expect(add(manager, "learner@plaja.test", services.RoleLearner), http.StatusCreated)
+	manager.expect(add(manager, "learner@plaja.test", services.RoleLearner), http.StatusConflict)
+	outsider.expect(add(outsider, "outsider@plaja.test", services.RoleLearner), http.StatusForbidden)
+
+	resp = learner.get(fmt.Sprintf("/api/v1/organization-members?organization_id=%d", organization.ID))
+	learner.expect(resp, http.StatusOK)
+
+	var members []models.OrganizationMember
+	learner.decode(resp, &members)
+	if len(members) != 3 || members[0].Role != services.RoleOwner || members[2].User.Email != "learner@plaja.test" {
+		t.Fatalf("unexpected members %+v", members)
+	}
+	outsider.expect(outsider.get(fmt.Sprintf("/api/v1/organization-members?organization_id=%d", organization.ID)), http.StatusForbidden)
+
+	// the managers buy the seats of the paid courses
+	buy := func(c *testClient, seats uint) *http.Response {
+		return c.postJSON("/api/v1/organizations/buy-seats", map[string]any{"OrganizationID": organization.ID, "CourseID": course.ID, "Seats": seats})
+	}
+	learner.expect(buy(learner, 2), http.StatusForbidden)
+	manager.expect(buy(manager, 0), http.StatusBadRequest)
+
+	resp = buy(manager, 2)
+	manager.expect(resp, http.StatusCreated)
+
+	var session services.CheckoutSession
+	manager.decode(resp, &session)
+	if session.Order.Amount != 2*course.Price || session.Order.Seats != 2 || session.Order.OrganizationID == nil {
+		t.Fatalf("unexpected seat order %+v", session.Order)
+	}
+
+	assign := func(c *testClient, userIDs ...uint) *http.Response {
+		due := time.Now().Add(7 * 24 * time.Hour)
+		return c.postJSON("/api/v1/course-assignments/create", map[string]any{
+			"OrganizationID": organization.ID, "CourseID": course.ID, "UserIDs": userIDs, "DueAt": due,
+		})
+	}
+	manager.expect(assign(manager, learnerID), http.StatusConflict)
+
+	manager.expect(manager.webhook(payments.FakeWebhook{
+		EventID:  "seats-1",
+		OrderID:  session.Order.ID,
+		Status:   payments.StatusSucceeded,
+		Amount:   session.Order.Amount,
+		Currency: session.Order.Currency,
+	}, testPaymentSecret), http.StatusOK)
+
+	// the assignments take the seats and enroll the members
+	learner.expect(assign(learner, learnerID), http.StatusForbidden)
+	manager.expect(assign(manager, learnerID, outsiderID), http.StatusBadRequest)
+	manager.expect(assign(manager, learnerID), http.StatusCreated)
+	manager.expect(assign(manager, learnerID), http.StatusCreated)
+
+	access := func(c *testClient) bool {
+		resp := c.get(fmt.Sprintf("/api/v1/enrollments/access?course_id=%d", course.ID))
+		c.expect(resp, http.StatusOK)
+
+		var body struct{ Access bool }
+		c.decode(resp, &body)
+		return body.Access
+	}
+	if !access(learner) || access(outsider) {
+		t.Fatal("expected the assigned member to get access to the course")
+	}
+
+	resp = learner.get("/api/v1/course-assignments")
+	learner.expect(resp, http.StatusOK)
+
+	var assignments []models.CourseAssignment
+	learner.decode(resp, &assignments)
+	if len(assignments) != 1 || assignments[0].CourseID != course.ID || assignments[0].DueAt == nil {
+		t.Fatalf("unexpected assignments %+v", assignments)
+	}
+
+	a.app.DB.Model(&models.Enrollment{}).Where("user_id = ? AND course_id = ?", learnerID, course.ID).Update("progress", 100)
+	manager.expect(assign(manager, learnerID, managerID, userID(owner)), http.StatusConflict)
+
+	dashboard := func() services.OrganizationDashboard {
+		resp := manager.get(fmt.Sprintf("/api/v1/organizations/dashboard?organization_id=%d", organization.ID))
+		manager.expect(resp, http.StatusOK)
+
+		var dashboard services.OrganizationDashboard
+		manager.decode(resp, &dashboard)
+		return dashboard
+	}
+
+	d := dashboard()
+	if len(d.Licenses) != 1 || d.Licenses[0].Seats != 2 || d.Licenses[0].Used != 1 || len(d.Members) != 3 {
+		t.Fatalf("unexpected dashboard %+v", d)
+	}
+	if c := d.Members[2].Courses; len(c) != 1 || c[0].Progress != 100 || !c[0].Completed || c[0].Overdue {
+		t.Fatalf("unexpected member progress %+v", d.Members[2])
+	}
+	learner.expect(learner.get(fmt.Sprintf("/api/v1/organizations/dashboard?organization_id=%d", organization.ID)), http.StatusForbidden)
+
+	// only the owners change the roles, and the organization keeps an owner
+	role := func(c *testClient, userID uint, role string) *http.Response {
+		return c.postJSON("/api/v1/organization-members/update", map[string]any{"OrganizationID": organization.ID, "UserID": userID, "Role": role})
+	}
+	manager.expect(role(manager, learnerID, services.RoleLearner), http.StatusForbidden)
+	owner.expect(role(owner, userID(owner), services.RoleManager), http.StatusBadRequest)
+	owner.expect(role(owner, managerID, services.RoleOwner), http.StatusOK)
+	owner.expect(role(owner, userID(owner), services.RoleManager), http.StatusOK)
+
+	// the seat orders are not refundable
+	manager.expect(manager.postJSON("/api/v1/refunds/create", map[string]any{"OrderID": session.Order.ID}), http.StatusBadRequest)
+
+	// removing a member frees the seats
+	remove := func(c *testClient, userID uint) *http.Response {
+		return c.postJSON("/api/v1/organization-members/remove", map[string]any{"OrganizationID": organization.ID, "UserID": userID})
+	}
+	learner.expect(remove(learner, managerID), http.StatusForbidden)
+	manager.expect(remove(manager, learnerID), http.StatusOK)
+
+	if access(learner) {
+		t.Error("expected the removed member to lose the access to the course")
+	}
+	if d := dashboard(); d.Licenses[0].Used != 0 || len(d.Members) != 2 {
+		t.Fatalf("unexpected dashboard %+v", d)
+	}
+}
+
+func TestUserImports(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+
+	var course, free, draft models.Course
+	a.app.DB.First(&course, "title = ?", "Використання мікросервісів у Go")
+	a.app.DB.First(&free, "title = ?", "Створення курсів на Plaja")
+	a.app.DB.First(&draft, "title = ?", "Чернетка")
+
+	admin := a.login("mail@plaja.io", "plaja-dev-password")
+	owner := a.signUp("owner@plaja.test")
+	outsider := a.signUp("existing@plaja.test")
+
+	resp := owner.postJSON("/api/v1/organizations/create", map[string]any{"Name": "Acme"})
+	owner.expect(resp, http.StatusCreated)
+
+	var organization models.Organization
+	owner.decode(resp, &organization)
+
+	resp = owner.postJSON("/api/v1/organizations/buy-seats", map[string]any{"OrganizationID": organization.ID, "CourseID": course.ID, "Seats": 3})
+	owner.expect(resp, http.StatusCreated)
+
+	var session services.CheckoutSession
+	owner.decode(resp, &session)
+	owner.expect(owner.webhook(payments.FakeWebhook{
+		EventID:  "import-seats-1",
+		OrderID:  session.Order.ID,
+		Status:   payments.StatusSucceeded,
+		Amount:   session.Order.Amount,
+		Currency: session.Order.Currency,
+	}, testPaymentSecret), http.StatusOK)
+
+	csv := "email,first_name,last_name,department\n" +
+		"new1@plaja.test,Олена,Коваль,Продажі\n" +
+		"Existing@plaja.test,Іван,Петренко,\n" +
+		"not-an-email,Ігор,Бондар,\n" +
+		"new1@plaja.test,Олена,Коваль,\n" +
+		"new2@plaja.test,Марія,,\n" +
+		"new3@plaja.test,Андрій,Шевчук,\n" +
+		"owner@plaja.test,Ольга,Ткач,\n"
+
+	start := func(c *testClient, organizationID uint, courseIDs string, content string) *http.Response {
+		values := map[string]string{"CourseIDs": courseIDs}
+		if organizationID != 0 {
+			values["OrganizationID"] = fmt.Sprint(organizationID)
+		}
+		return c.postFile("/api/v1/user-imports/create", values, "File", []byte(content))
+	}
+	courses := fmt.Sprintf("%d,%d", course.ID, free.ID)
+
+	outsider.expect(start(outsider, organization.ID, courses, csv), http.StatusForbidden)
+	owner.expect(start(owner, organization.ID, courses, "email,name\na@plaja.test,A\n"), http.StatusBadRequest)
+	owner.expect(start(owner, organization.ID, courses, "email,first_name,last_name\n"), http.StatusBadRequest)
+	owner.expect(start(owner, organization.ID, fmt.Sprint(draft.ID), csv), http.StatusBadRequest)
+	owner.expect(start(owner, 0, courses, csv), http.StatusForbidden)
+
+	resp = start(owner, organization.ID, courses, csv)
+	owner.expect(resp, http.StatusAccepted)
+
+	var userImport models.UserImport
+	owner.decode(resp, &userImport)
+	if userImport.Total != 7 {
+		t.Fatalf("unexpected import %+v", userImport)
+	}
+
+	// the rows are imported in the background
+	poll := func(c *testClient, id uint) models.UserImport {
+		for i := 0; i < 500; i++ {
+			resp := c.get(fmt.Sprintf("/api/v1/user-imports?id=%d", id))
+			c.expect(resp, http.StatusOK)
+
+			var userImport models.UserImport
+			c.decode(resp, &userImport)
+			if userImport.Status == services.ImportStatusCompleted || userImport.Status == services.ImportStatusFailed {
+				return userImport
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("import %d did not finish", id)
+		return models.UserImport{}
+	}
+
+	userImport = poll(owner, userImport.ID)
+	if userImport.Status != services.ImportStatusCompleted || userImport.Processed != 7 || userImport.Created != 2 ||
+		userImport.Matched != 1 || userImport.Pending != 1 || userImport.Failed != 3 || len(userImport.Rows) != 7 {
+		t.Fatalf("unexpected import %+v", userImport)
+	}
+
+	results := []string{
+		services.ImportRowCreated, services.ImportRowPending, services.ImportRowFailed,
+		services.ImportRowFailed, services.ImportRowFailed, services.ImportRowCreated, services.ImportRowMatched,
+	}
+	for i, row := range userImport.Rows {
+		if row.Line != uint(i+2) || row.Result != results[i] {
+			t.Fatalf("unexpected row %+v", row)
+		}
+	}
+	if r := userImport.Rows; !r[0].Invited || !r[1].Invited || r[1].Email != "existing@plaja.test" || r[6].Invited {
+		t.Fatalf("unexpected rows %+v", r)
+	}
+	outsider.expect(outsider.get(fmt.Sprintf("/api/v1/user-imports?id=%d", userImport.ID)), http.StatusNotFound)
+	admin.expect(admin.get(fmt.Sprintf("/api/v1/user-imports?id=%d", userImport.ID)), http.StatusOK)
+
+	// the invited users set their password through the link
+	a.app.DB.Model(&models.Invitation{}).Where("user_id = ?", *userImport.Rows[0].UserID).
+		Update("token_hash", services.HashInvitationToken("test-token"))
+
+	accept := func(token, password string) *http.Response {
+		return a.client().postJSON("/api/v1/users/set-password", map[string]string{"token": token, "password": password})
+	}
+	owner.expect(accept("test-token", "short"), http.StatusBadRequest)
+	owner.expect(accept("wrong-token", "password123"), http.StatusNotFound)
+	owner.expect(accept("test-token", "password123"), http.StatusOK)
+	owner.expect(accept("test-token", "password123"), http.StatusNotFound)
+
+	access := func(c *testClient, courseID uint) bool {
+		resp := c.get(fmt.Sprintf("/api/v1/enrollments/access?course_id=%d", courseID))
+		c.expect(resp, http.StatusOK)
+
+		var body struct{ Access bool }
+		c.decode(resp, &body)
+		return body.Access
+	}
+
+	imported := a.login("new1@plaja.test", "password123")
+	if !access(imported, course.ID) || !access(owner, course.ID) {
+		t.Fatal("expected the imported members to get access to the assigned course")
+	}
+
+	// the existing users join the organization only if they accept the invitation
+	outsiderID := *userImport.Rows[1].UserID
+	count := func(model any) int64 {
+		var n int64
+		a.app.DB.Model(model).Where("user_id = ?", outsiderID).Count(&n)
+		return n
+	}
+	if count(&models.OrganizationMember{}) != 0 || count(&models.Enrollment{}) != 0 {
+		t.Fatal("expected the existing user not to be added before accepting")
+	}
+
+	a.app.DB.Model(&models.Invitation{}).Where("user_id = ? AND organization_id IS NOT NULL", outsiderID).
+		Update("token_hash", services.HashInvitationToken("join-token"))
+
+	join := func(c *testClient, token string) *http.Response {
+		return c.postJSON("/api/v1/organizations/join", map[string]string{"token": token})
+	}
+	owner.expect(accept("join-token", "password123"), http.StatusNotFound)
+	imported.expect(join(imported, "join-token"), http.StatusNotFound)
+	outsider.expect(join(outsider, "wrong-token"), http.StatusNotFound)
+
+	resp = join(outsider, "join-token")
+	outsider.expect(resp, http.StatusOK)
+	outsider.expect(join(outsider, "join-token"), http.StatusNotFound)
+
+	var member models.OrganizationMember
+	outsider.decode(resp, &member)
+	if member.OrganizationID != organization.ID || member.Role != services.RoleLearner {
+		t.Fatalf("unexpected member %+v", member)
+	}
+
+	// the free course is assigned, the paid one has no seats left
+	if count(&models.Enrollment{}) != 1 || access(outsider, course.ID) {
+		t.Fatal("expected only the free course to be assigned to the joined user")
+	}
+
+	// the admins import the users into the platform
+	resp = start(admin, 0, fmt.Sprint(free.ID), "first_name,last_name,email\nНадія,Лисенко,new4@plaja.test\n")
+	admin.expect(resp, http.StatusAccepted)
+	admin.decode(resp, &userImport)
+
+	if userImport = poll(admin, userImport.ID); userImport.Created != 1 || userImport.Rows[0].Line != 2 {
+		t.Fatalf("unexpected import %+v", userImport)
+	}
+}
+
+func TestLearningPaths(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+
+	var web, micro, free, draft models.Course
+	a.app.DB.First(&web, "title = ?", "Розробка сучасних веб-застосунків із Go")
+	a.app.DB.First(&micro, "title = ?", "Використання мікросервісів у Go")
+	a.app.DB.First(&free, "title = ?", "Створення курсів на Plaja")
+	a.app.DB.First(&draft, "title = ?", "Чернетка")
+
+	admin := a.login("mail@plaja.io", "plaja-dev-password")
+	learner := a.signUp("learner@plaja.test")
+
+	// the seeded path bundles the Go courses in order
+	resp := a.client().get("/api/v1/learning-paths")
+	learner.expect(resp, http.StatusOK)
+
+	var paths []models.LearningPath
+	learner.decode(resp, &paths)
+	if len(paths) != 1 || len(paths[0].Courses) != 2 || paths[0].Price != 79900 ||
+		paths[0].Courses[0].CourseID != web.ID || paths[0].Courses[1].Course.Title != micro.Title {
+		t.Fatalf("unexpected learning paths %+v", paths)
+	}
+	path := paths[0]
+
+	// only the admins create the paths of published courses
+	create := func(c *testClient, courseIDs ...uint) *http.Response {
+		return c.postJSON("/api/v1/learning-paths/create", map[string]any{"Title": "Plaja", "CourseIDs": courseIDs})
+	}
+	learner.expect(create(learner, free.ID, web.ID), http.StatusForbidden)
+	admin.expect(create(admin, free.ID), http.StatusBadRequest)
+	admin.expect(create(admin, free.ID, free.ID), http.StatusBadRequest)
+	admin.expect(create(admin, free.ID, draft.ID), http.StatusBadRequest)
+
+	resp = create(admin, free.ID, web.ID)
+	admin.expect(resp, http.StatusCreated)
+
+	var created models.LearningPath
+	admin.decode(resp, &created)
+	if created.Price != 0 || created.Currency != "UAH" || created.Courses[0].CourseID != free.ID {
+		t.Fatalf("unexpected learning path %+v", created)
+	}
+
+	resp = admin.postJSON("/api/v1/learning-paths/update", map[string]any{"ID": created.ID, "Title": "Plaja", "CourseIDs": []uint{web.ID, free.ID}})
+	admin.expect(resp, http.StatusOK)
+	admin.decode(resp, &created)
+	if created.Courses[0].CourseID != web.ID || created.Courses[1].CourseID != free.ID {
+		t.Fatalf("expected the courses to be reordered, got %+v", created.Courses)
+	}
+
+	// enrolling into a path enrolls into its free courses only
+	learner.expect(learner.postJSON("/api/v1/learning-paths/enroll", map[string]any{"PathID": created.ID}), http.StatusOK)
+
+	progress := func(c *testClient, pathID uint) services.PathProgress {
+		resp := c.get(fmt.Sprintf("/api/v1/learning-paths/progress?path_id=%d", pathID))
+		c.expect(resp, http.StatusOK)
+
+		var p services.PathProgress
+		c.decode(resp, &p)
+		return p
+	}
+	if p := progress(learner, created.ID); !p.Enrolled || p.Completed || p.Courses[0].Enrolled || !p.Courses[1].Enrolled {
+		t.Fatalf("unexpected path progress %+v", p)
+	}
+	learner.expect(learner.postJSON("/api/v1/learning-paths/checkout", map[string]any{"PathID": created.ID}), http.StatusBadRequest)
+
+	// the bundle enrolls into all the courses of the path
+	resp = learner.postJSON("/api/v1/learning-paths/checkout", map[string]any{"PathID": path.ID})
+	learner.expect(resp, http.StatusCreated)
+
+	var session services.CheckoutSession
+	learner.decode(resp, &session)
+	if session.Order.Amount != 79900 || session.Order.PathID == nil || *session.Order.PathID != path.ID {
+		t.Fatalf("unexpected bundle order %+v", session.Order)
+	}
+
+	learner.expect(learner.webhook(payments.FakeWebhook{
+		EventID:  "path-1",
+		OrderID:  session.Order.ID,
+		Status:   payments.StatusSucceeded,
+		Amount:   session.Order.Amount,
+		Currency: session.Order.Currency,
+	}, testPaymentSecret), http.StatusOK)
+
+	p := progress(learner, path.ID)
+	if !p.Enrolled || p.Progress != 0 || !p.Courses[0].Enrolled || !p.Courses[1].Enrolled {
+		t.Fatalf("unexpected path progress %+v", p)
+	}
+	learner.expect(learner.postJSON("/api/v1/learning-paths/checkout", map[string]any{"PathID": path.ID}), http.StatusConflict)
+
+	// the bundle amount is split between the courses by their list prices
+	var sales []models.LedgerTransaction
+	a.app.DB.Preload("Entries").Where("order_id = ?", session.Order.ID).Order("id").Find(&sales)
+	if len(sales) != 2 || !strings.HasPrefix(sales[0].Description, web.Title) {
+		t.Fatalf("unexpected bundle sales %+v", sales)
+	}
+	var total uint
+	for _, sale := range sales {
+		for _, e := range sale.Entries {
+			if e.Account == services.AccountCash {
+				total += e.Debit
+			}
+		}
+	}
+	if total != 79900 {
+		t.Fatalf("expected the sales to add up to the bundle price, got %d", total)
+	}
+	learner.expect(learner.postJSON("/api/v1/refunds/create", map[string]any{"OrderID": session.Order.ID, "Reason": "Не підійшло"}), http.StatusBadRequest)
+
+	// the path is completed once all its courses are
+	var me models.User
+	learner.decode(learner.get("/api/v1/users/getme"), &me)
+	a.app.DB.Model(&models.Enrollment{}).Where("user_id = ? AND course_id = ?", me.ID, web.ID).Update("progress", 100)
+
+	if p := progress(learner, path.ID); p.Progress != 50 || p.Completed {
+		t.Fatalf("unexpected path progress %+v", p)
+	}
+	learner.expect(learner.postJSON("/api/v1/learning-paths/certificate", map[string]any{"PathID": path.ID}), http.StatusBadRequest)
+
+	a.app.DB.Model(&models.Enrollment{}).Where("user_id = ? AND course_id = ?", me.ID, micro.ID).Update("progress", 100)
+
+	learner.expect(learner.postJSON("/api/v1/learning-paths/certificate", map[string]any{"PathID": path.ID}), http.StatusCreated)
+	learner.expect(learner.postJSON("/api/v1/learning-paths/certificate", map[string]any{"PathID": path.ID}), http.StatusConflict)
+
+	if p := progress(learner, path.ID); p.Progress != 100 || !p.Completed || p.CertificateID == nil {
+		t.Fatalf("unexpected path progress %+v", p)
+	}
+}
+
+func TestCoursePrerequisites(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+
+	var web, micro, free, draft models.Course
+	a.app.DB.First(&web, "title = ?", "Розробка сучасних веб-застосунків із Go")
+	a.app.DB.First(&micro, "title = ?", "Використання мікросервісів у Go")
+	a.app.DB.First(&free, "title = ?", "Створення курсів на Plaja")
+	a.app.DB.First(&draft, "title = ?", "Чернетка")
+
+	admin := a.login("mail@plaja.io", "plaja-dev-password")
+	learner := a.signUp("learner@plaja.test")
+
+	update := func(c *testClient, courseID uint, enforce bool, prerequisiteIDs ...uint) *http.Response {
+		return c.postJSON("/api/v1/courses/update-prerequisites", map[string]any{
+			"CourseID": courseID, "PrerequisiteIDs": prerequisiteIDs, "Enforce": enforce,
+		})
+	}
+
+	// only the instructor declares the published prerequisites without cycles
+	learner.expect(update(learner, micro.ID, false, web.ID), http.StatusForbidden)
+	admin.expect(update(admin, micro.ID, false, micro.ID), http.StatusBadRequest)
+	admin.expect(update(admin, micro.ID, false, draft.ID), http.StatusBadRequest)
+	admin.expect(update(admin, micro.ID, false, web.ID, web.ID), http.StatusOK)
+	admin.expect(update(admin, free.ID, true, micro.ID), http.StatusOK)
+
+	resp := update(admin, web.ID, false, free.ID)
+	admin.expect(resp, http.StatusBadRequest)
+	if body, _ := io.ReadAll(resp.Body); !strings.Contains(string(body), "cycle") {
+		t.Fatalf("expected the cycle to be reported, got %q", body)
+	}
+
+	graph := func(courseID uint) services.PrerequisiteGraph {
+		resp := a.client().get(fmt.Sprintf("/api/v1/course-prerequisites?course_id=%d", courseID))
+		learner.expect(resp, http.StatusOK)
+
+		var g services.PrerequisiteGraph
+		learner.decode(resp, &g)
+		return g
+	}
+	if g := graph(0); len(g.Courses) != 3 || len(g.Edges) != 2 || !slices.Equal(g.Order, []uint{web.ID, micro.ID, free.ID}) {
+		t.Fatalf("unexpected prerequisite graph %+v", g)
+	}
+	if g := graph(micro.ID); len(g.Courses) != 2 || !slices.Equal(g.Order, []uint{web.ID, micro.ID}) {
+		t.Fatalf("unexpected prerequisite graph %+v", g)
+	}
+
+	// the enforced prerequisites block the enrollment
+	learner.expect(learner.postJSON("/api/v1/enrollments/create", map[string]any{"CourseID": free.ID}), http.StatusForbidden)
+
+	resp = learner.get(fmt.Sprintf("/api/v1/enrollments/prerequisites?course_id=%d", free.ID))
+	learner.expect(resp, http.StatusOK)
+
+	var check services.PrerequisiteCheck
+	learner.decode(resp, &check)
+	if !check.Enforced || len(check.Missing) != 1 || check.Missing[0].ID != micro.ID {
+		t.Fatalf("unexpected prerequisite check %+v", check)
+	}
+
+	admin.expect(update(admin, micro.ID, true, web.ID), http.StatusOK)
+	learner.expect(learner.postJSON("/api/v1/orders/checkout", map[string]any{"CourseID": micro.ID}), http.StatusForbidden)
+
+	// the others only warn about the incomplete prerequisites
+	admin.expect(update(admin, free.ID, false, micro.ID), http.StatusOK)
+
+	resp = learner.postJSON("/api/v1/enrollments/create", map[string]any{"CourseID": free.ID})
+	learner.expect(resp, http.StatusCreated)
+	learner.decode(resp, &check)
+	if check.Enforced || len(check.Missing) != 1 {
+		t.Fatalf("expected the incomplete prerequisites to be warned about, got %+v", check)
+	}
+
+	admin.expect(update(admin, free.ID, false), http.StatusOK)
+	if g := graph(0); len(g.Edges) != 1 {
+		t.Fatalf("expected the prerequisites to be removed, got %+v", g)
+	}
+}
+
+func TestRecommendations(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+
+	var web, micro, svelte, patterns models.Course
+	a.app.DB.First(&web, "title = ?", "Розробка сучасних веб-застосунків із Go")
+	a.app.DB.First(&micro, "title = ?", "Використання мікросервісів у Go")
+	a.app.DB.First(&svelte, "title = ?", "Svelte та SvelteKit: повний курс")
+	a.app.DB.First(&patterns, "title = ?", "Шаблони проєктування в C++/C#")
+
+	clients := make([]*testClient, 4)
+	learners := make([]models.User, 4)
+	for i := range learners {
+		clients[i] = a.signUp(fmt.Sprintf("learner%d@plaja.test", i))
+		clients[i].decode(clients[i].get("/api/v1/users/getme"), &learners[i])
+	}
+
+	// the web course is taken with the Svelte one more often than with the microservices
+	for _, e := range []struct {
+		user   int
+		course uint
+	}{
+		{0, web.ID}, {0, svelte.ID},
+		{1, web.ID}, {1, svelte.ID},
+		{2, web.ID}, {2, micro.ID},
+		{3, patterns.ID},
+	} {
+		enrollment := services.NewEnrollment(learners[e.user].ID, e.course)
+		if err := a.app.DB.Create(&enrollment).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type recommended struct {
+		Course models.Course
+		Score  float64
+		Reason string
+	}
+
+	recommendations := func(c *testClient, path string) []recommended {
+		resp := c.get(path)
+		c.expect(resp, http.StatusOK)
+
+		var r []recommended
+		c.decode(resp, &r)
+		return r
+	}
+
+	also := recommendations(a.client(), fmt.Sprintf("/api/v1/courses/also-took?course_id=%d", web.ID))
+	if len(also) != 2 || also[0].Course.ID != svelte.ID || also[1].Course.ID != micro.ID || also[0].Reason != services.ReasonAlsoTook {
+		t.Fatalf("unexpected also-took courses %+v", also)
+	}
+	if also[0].Course.FinalPrice == nil {
+		t.Fatalf("expected the final prices to be set, got %+v", also[0].Course)
+	}
+
+	a.client().expect(a.client().get(fmt.Sprintf("/api/v1/courses/also-took?course_id=%d&limit=-1", web.ID)), http.StatusBadRequest)
+
+	// only the signed in users get the personal recommendations
+	a.client().expect(a.client().get("/api/v1/recommendations"), http.StatusUnauthorized)
+
+	personal := recommendations(clients[2], "/api/v1/recommendations")
+	if len(personal) == 0 || personal[0].Course.ID != svelte.ID || personal[0].Reason != services.ReasonAlsoTook {
+		t.Fatalf("unexpected recommendations %+v", personal)
+	}
+	for _, r := range personal {
+		if r.Course.ID == web.ID || r.Course.ID == micro.ID {
+			t.Fatalf("expected the taken courses to be excluded, got %+v", r)
+		}
+	}
+
+	// the new users get the popular courses
+	newcomer := a.signUp("newcomer@plaja.test")
+	popular := recommendations(newcomer, "/api/v1/recommendations?limit=1")
+	if len(popular) != 1 || popular[0].Course.ID != web.ID || popular[0].Reason != services.ReasonPopular {
+		t.Fatalf("unexpected popular courses %+v", popular)
+	}
+}
+
+func TestDiscussions(t *testing.T) {
+	a := newTestApp(t, seed.DatasetDev)
+
+	var free, web models.Course
+	a.app.DB.First(&free, "title = ?", "Створення курсів на Plaja")
+	a.app.DB.First(&web, "title = ?", "Розробка сучасних веб-застосунків із Go")
+
+	instructor := a.login("mail@plaja.io", "plaja-dev-password")
+	for _, course := range []models.Course{free, web} {
+		instructor.expect(instructor.postJSON("/api/v1/course-exercises/create-update", map[string]any{
+			"CourseID":  course.ID,
+			"Exercises": []map[string]any{{"Title": "Вступ", "Content": "Текст"}},
+		}), http.StatusCreated)
+	}
+
+	var exercise, otherExercise models.CourseExercise
+	a.app.DB.First(&exercise, "course_id = ?", free.ID)
+	a.app.DB.First(&otherExercise, "course_id = ?", web.ID)
+
+	author := a.signUp("author@plaja.test")
+	learner := a.signUp("learner@plaja.test")
+	outsider := a.signUp("outsider@plaja.test")
+	for _, c := range []*testClient{author, learner} {
+		c.expect(c.postJSON("/api/v1/enrollments/create", map[string]any{"CourseID": free.ID}), http.StatusCreated)
+	}
+
+	create := func(c *testClient, exerciseID uint, content string) *http.Response {
+		return c.postJSON("/api/v1/discussions/create", map[string]any{
+			"CourseID": free.ID, "ExerciseID": exerciseID, "Title": "Як опублікувати курс?", "Content": content,
+		})
+	}
+
+	// only the enrolled learners and the instructor take part in the discussions
+	outsider.expect(create(outsider, 0, "Питання"), http.StatusForbidden)
+	author.expect(create(author, otherExercise.ID, "Питання"), http.StatusNotFound)
+
+	content := "Чому <script>alert(1)</script> не **працює**? [Докладніше]( javascript:alert(1))"
+	resp := create(author, exercise.ID, content)
+	author.expect(resp, http.StatusCreated)
+	var discussion models.Discussion
+	author.decode(resp, &discussion)
+	if want := "<p>Чому alert(1) не <strong>працює</strong>? Докладніше</p>\n"; discussion.Content != content || discussion.ContentHTML != want {
+		t.Fatalf("expected the content %q rendered to %q, got %q and %q", content, want, discussion.Content, discussion.ContentHTML)
+	}
+
+	list := func(c *testClient, query string) []models.Discussion {
+		resp := c.get("/api/v1/discussions?" + query)
+		c.expect(resp, http.StatusOK)
+
+		var discussions []models.Discussion
+		c.decode(resp, &discussions)
+		return discussions
+	}
+
+	if discussions := list(instructor, fmt.Sprintf("course_id=%d&exercise_id=%d", free.ID, exercise.ID)); len(discussions) != 1 || discussions[0].ID != discussion.ID {
+		t.Fatalf("unexpected discussions %+v", discussions)
+	}
+	outsider.expect(outsider.get(fmt.Sprintf("/api/v1/discussions?course_id=%d", free.ID)), http.StatusForbidden)
+	author.expect(author.get("/api/v1/discussions"), http.StatusBadRequest)
+
+	post := func(c *testClient, parentID uint, content string) models.DiscussionPost {
+		resp := c.postJSON("/api/v1/discussion-posts/create", map[string]any{
+			"DiscussionID": discussion.ID, "ParentID": parentID, "Content": content,
+		})
+		c.expect(resp, http.StatusCreated)
+
+		var p models.DiscussionPost
+		c.decode(resp, &p)
+		return p
+	}
+
+	guess := post(learner, 0, "Спробуйте **перезапустити**")
+	answer := post(instructor, 0, "Натисніть `Опублікувати` у налаштуваннях")
+	reply := post(author, answer.ID, "Дякую!")
+	if !answer.ByInstructor || guess.ByInstructor || reply.ParentID == nil || *reply.ParentID != answer.ID {
+		t.Fatalf("unexpected posts %+v %+v %+v", guess, answer, reply)
+	}
+	outsider.expect(outsider.postJSON("/api/v1/discussion-posts/create", map[string]any{"DiscussionID": discussion.ID, "Content": "Спам"}), http.StatusForbidden)
+	learner.expect(learner.postJSON("/api/v1/discussion-posts/create", map[string]any{"DiscussionID": discussion.ID, "Content": " "}), http.StatusBadRequest)
+
+	vote := func(c *testClient, postID uint, up bool) *http.Response {
+		return c.postJSON("/api/v1/discussions/vote", map[string]any{"DiscussionID": discussion.ID, "PostID": postID, "Up": up})
+	}
+	author.expect(vote(author, 0, true), http.StatusForbidden)
+	learner.expect(vote(learner, 0, true), http.StatusOK)
+	learner.expect(vote(learner, 0, true), http.StatusOK)
+	learner.expect(vote(learner, answer.ID, true), http.StatusOK)
+	author.expect(vote(author, answer.ID, true), http.StatusOK)
+	author.expect(vote(author, answer.ID, false), http.StatusOK)
+
+	// the author and the instructor accept the answers, not the replies
+	accept := func(c *testClient, postID uint) *http.Response {
+		return c.postJSON("/api/v1/discussion-posts/accept", map[string]any{"PostID": postID, "Accepted": true})
+	}
+	learner.expect(accept(learner, answer.ID), http.StatusForbidden)
+	author.expect(accept(author, reply.ID), http.StatusBadRequest)
+	author.expect(accept(author, answer.ID), http.StatusOK)
+
+	resp = learner.get(fmt.Sprintf("/api/v1/discussions/thread?id=%d", discussion.ID))
+	learner.expect(resp, http.StatusOK)
+	learner.decode(resp, &discussion)
+	if discussion.Votes != 1 || discussion.PostCount != 3 || !discussion.InstructorAnswered ||
+		discussion.AcceptedPostID == nil || *discussion.AcceptedPostID != answer.ID {
+		t.Fatalf("unexpected discussion %+v", discussion)
+	}
+	if len(discussion.Posts) != 3 || discussion.Posts[1].ID != answer.ID || discussion.Posts[1].Votes != 1 {
+		t.Fatalf("unexpected posts %+v", discussion.Posts)
+	}
+	outsider.expect(outsider.get(fmt.Sprintf("/api/v1/discussions/thread?id=%d", discussion.ID)), http.StatusForbidden)
+
+	if unanswered := list(author, fmt.Sprintf("course_id=%d&answered=false", free.ID)); len(unanswered) != 0 {
+		t.Fatalf("expected no unanswered discussions, got %+v", unanswered)
+	}
+	if accepted := list(author, fmt.Sprintf("course_id=%d&accepted=true&sort=-votes", free.ID)); len(accepted) != 1 {
+		t.Fatalf("expected the accepted discussion, got %+v", accepted)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/plaja-app/back-end/config"
+	"github.com/plaja-app/back-end/database"
+	"github.com/plaja-app/back-end/database/migrations"
+	"github.com/plaja-app/back-end/database/seed"
+	"io/fs"
+	"math"
+	"os"
+)
+
+// runSeedCommand runs the seed subcommand with the given arguments.
+// The reference dataset is always loaded; the dev dataset only in the development environment.
+// Resetting the database and seeding the dev dataset require APP_ENV=development.
+func runSeedCommand(args []string) error {
+	env, err := config.LoadEnvVariables()
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	appEnv := flags.String("env", env.AppEnv, "environment to seed: development or production")
+	dir := flags.String("dir", "", "directory with the fixture datasets (defaults to the embedded fixtures)")
+	reset := flags.Bool("reset", false, "revert and re-apply all migrations before seeding (development only)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	isDevelopment := *appEnv == "development"
+
+	// -env only selects the datasets, so the guards check APP_ENV of the database instead
+	if !env.IsDevelopment() {
+		if *reset {
+			return errors.New("refusing to reset a non-development database")
+		}
+		if isDevelopment {
+			return errors.New("refusing to seed the development dataset into a non-development database")
+		}
+	}
+
+	db, err := database.Open(env)
+	if err != nil {
+		return err
+	}
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+
+	if *reset {
+		if _, err := migrator.Down(math.MaxInt); err != nil {
+			return err
+		}
+		if _, err := migrator.Up(); err != nil {
+			return err
+		}
+		fmt.Println("database reset")
+	}
+
+	err = migrator.Check()
+	if err != nil {
+		return err
+	}
+
+	var fixtures fs.FS = seed.Fixtures()
+	if *dir != "" {
+		fixtures = os.DirFS(*dir)
+	}
+
+	datasets := []string{seed.DatasetReference}
+	if isDevelopment {
+		datasets = append(datasets, seed.DatasetDev)
+	}
+
+	results, err := seed.New(db, fixtures).Seed(datasets...)
+	for _, r := range results {
+		fmt.Printf("%s/%s: %d created, %d skipped\n", r.Dataset, r.File, r.Created, r.Skipped)
+	}
+
+	return err
+}
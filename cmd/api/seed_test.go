@@ -0,0 +1,97 @@
+package main
+
+import (
+	"github.com/plaja-app/back-end/config"
+	"github.com/plaja-app/back-end/database"
+	"github.com/plaja-app/back-end/database/migrations"
+	"github.com/plaja-app/back-end/database/seed"
+	"github.com/plaja-app/back-end/models"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+)
+
+// setCommandEnv points the commands to a fresh SQLite database in the given environment.
+func setCommandEnv(t *testing.T, appEnv string) {
+	t.Helper()
+
+	t.Setenv("APP_ENV", appEnv)
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("SQLITE_PATH", filepath.Join(t.TempDir(), "plaja.db"))
+	t.Setenv("PAYMENT_PROVIDER", "")
+	t.Setenv("PLAJA_CONFIG_FILE", "")
+}
+
+func TestSeedCommandGuards(t *testing.T) {
+	setCommandEnv(t, "production")
+
+	if err := runMigrateCommand([]string{"up"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the -env flag does not lift the guards of a production database
+	for _, args := range [][]string{
+		{"-reset"},
+		{"-env", "development", "-reset"},
+		{"-env", "development"},
+	} {
+		if err := runSeedCommand(args); err == nil {
+			t.Errorf("seed %q: expected the command to be refused", args)
+		}
+	}
+
+	if err := runSeedCommand(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	db := openCommandDB(t)
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Check(); err != nil {
+		t.Fatalf("expected the schema to be kept, got %v", err)
+	}
+
+	var users int64
+	db.Model(&models.User{}).Count(&users)
+	if users != 0 {
+		t.Fatalf("expected no dev users in production, got %d", users)
+	}
+}
+
+func TestSeedCommandReset(t *testing.T) {
+	setCommandEnv(t, "development")
+
+	if err := runMigrateCommand([]string{"up"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := runSeedCommand([]string{"-reset"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var courses int64
+	openCommandDB(t).Model(&models.Course{}).Count(&courses)
+	if courses == 0 {
+		t.Fatalf("expected the %s dataset to be seeded", seed.DatasetDev)
+	}
+}
+
+// openCommandDB opens the database the commands ran against.
+func openCommandDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	env, err := config.LoadEnvVariables()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := database.Open(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
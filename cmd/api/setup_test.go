@@ -0,0 +1,35 @@
+package main
+
+import (
+	"github.com/plaja-app/back-end/config"
+	"strings"
+	"testing"
+)
+
+func TestSetupRequiresSMTP(t *testing.T) {
+	setCommandEnv(t, "production")
+	t.Setenv("SMTP_ADDR", "")
+
+	// the invitation links are not written to the log outside development
+	var app config.AppConfig
+	if err := setup(&app); err == nil || !strings.Contains(err.Error(), "SMTP_ADDR") {
+		t.Fatalf("expected the missing SMTP server to be refused, got %v", err)
+	}
+
+	t.Setenv("SMTP_ADDR", "smtp.plaja.test:587")
+	if err := runMigrateCommand([]string{"up"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := setup(&app); err != nil {
+		t.Fatal(err)
+	}
+
+	setCommandEnv(t, "development")
+	t.Setenv("SMTP_ADDR", "")
+	if err := runMigrateCommand([]string{"up"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := setup(&app); err != nil {
+		t.Fatal(err)
+	}
+}
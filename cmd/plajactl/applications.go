@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+	"gorm.io/gorm"
+	"io"
+	"time"
+)
+
+// applicationResult is the output of the teaching application commands.
+type applicationResult struct {
+	UserID     uint
+	Email      string
+	Experience string
+	Motivation string
+	CreatedAt  time.Time
+	ApprovedAt *time.Time
+}
+
+// listApplications lists the teaching applications.
+func (c *ctl) listApplications(args []string) error {
+	flags := flag.NewFlagSet("application list", flag.ContinueOnError)
+	pending := flags.Bool("pending", false, "only list applications that are not approved")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	query := c.db.Preload("User").Order("created_at")
+	if *pending {
+		query = query.Where("approved_at IS NULL")
+	}
+
+	var applications []models.TeachingApplication
+	if err := query.Find(&applications).Error; err != nil {
+		return err
+	}
+
+	results := make([]applicationResult, 0, len(applications))
+	for _, a := range applications {
+		results = append(results, applicationResult{
+			UserID:     a.UserID,
+			Email:      a.User.Email,
+			Experience: a.Experience,
+			Motivation: a.Motivation,
+			CreatedAt:  a.CreatedAt,
+			ApprovedAt: a.ApprovedAt,
+		})
+	}
+
+	return c.out.table(results, "USER ID\tEMAIL\tCREATED AT\tAPPROVED", func(w io.Writer) {
+		for _, r := range results {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%t\n", r.UserID, r.Email, r.CreatedAt.Format("2006-01-02"), r.ApprovedAt != nil)
+		}
+	})
+}
+
+// approveApplication approves the teaching application and promotes the user to Educator.
+func (c *ctl) approveApplication(args []string) error {
+	flags := flag.NewFlagSet("application approve", flag.ContinueOnError)
+	userID := flags.Uint("user-id", 0, "id of the applicant")
+	if err := parseFlags(flags, args, "user-id"); err != nil {
+		return err
+	}
+
+	var application models.TeachingApplication
+	if err := c.db.Preload("User").First(&application, "user_id = ?", *userID).Error; err != nil {
+		return fmt.Errorf("teaching application of user %d: %v", *userID, err)
+	}
+
+	educator, err := c.userType("Educator")
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	application.ApprovedAt = &now
+
+	err = c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&application).Update("approved_at", now).Error; err != nil {
+			return err
+		}
+
+		// never demote admins
+		return tx.Model(&models.User{}).
+			Where("id = ? AND user_type_id < ?", application.UserID, educator.ID).
+			Update("user_type_id", educator.ID).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.out.result(
+		applicationResult{
+			UserID:     application.UserID,
+			Email:      application.User.Email,
+			Experience: application.Experience,
+			Motivation: application.Motivation,
+			CreatedAt:  application.CreatedAt,
+			ApprovedAt: application.ApprovedAt,
+		},
+		fmt.Sprintf("application of %s approved", application.User.Email),
+	)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/plaja-app/back-end/certificates"
+	"github.com/plaja-app/back-end/config"
+)
+
+// certificateResult is the output of the certificate commands.
+type certificateResult struct {
+	ID   uint
+	Path string
+	URL  string
+}
+
+// regenerateCertificate renders the certificate image again from the current data.
+func (c *ctl) regenerateCertificate(args []string) error {
+	flags := flag.NewFlagSet("certificate regenerate", flag.ContinueOnError)
+	id := flags.Uint("id", 0, "id of the certificate")
+	if err := parseFlags(flags, args, "id"); err != nil {
+		return err
+	}
+
+	data, err := certificates.LoadData(c.db, *id)
+	if err != nil {
+		return fmt.Errorf("certificate %d: %v", *id, err)
+	}
+
+	path, err := certificates.NewGenerator(c.env.StorageRoot).Generate(data)
+	if err != nil {
+		return err
+	}
+
+	app := config.AppConfig{Env: c.env}
+
+	return c.out.result(
+		certificateResult{ID: data.ID, Path: path, URL: app.StorageURL(path)},
+		fmt.Sprintf("certificate %d regenerated: %s", data.ID, path),
+	)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+	"io"
+)
+
+// courseResult is the output of the course commands.
+type courseResult struct {
+	ID         uint
+	Title      string
+	Status     string
+	Instructor string
+}
+
+// listCourses lists the courses, optionally filtered by status.
+func (c *ctl) listCourses(args []string) error {
+	flags := flag.NewFlagSet("course list", flag.ContinueOnError)
+	statusTitle := flags.String("status", "", `only list courses with this status, e.g. "being validated"`)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	query := c.db.Preload("Status").Preload("Instructor").Order("id")
+	if *statusTitle != "" {
+		status, err := c.courseStatus(*statusTitle)
+		if err != nil {
+			return err
+		}
+		query = query.Where("status_id = ?", status.ID)
+	}
+
+	var courses []models.Course
+	if err := query.Find(&courses).Error; err != nil {
+		return err
+	}
+
+	results := make([]courseResult, 0, len(courses))
+	for _, course := range courses {
+		results = append(results, courseResult{
+			ID:         course.ID,
+			Title:      course.Title,
+			Status:     course.Status.Title,
+			Instructor: course.Instructor.Email,
+		})
+	}
+
+	return c.out.table(results, "ID\tTITLE\tSTATUS\tINSTRUCTOR", func(w io.Writer) {
+		for _, r := range results {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", r.ID, r.Title, r.Status, r.Instructor)
+		}
+	})
+}
+
+// approveCourse publishes the course being validated.
+func (c *ctl) approveCourse(args []string) error {
+	flags := flag.NewFlagSet("course approve", flag.ContinueOnError)
+	id := flags.Uint("id", 0, "id of the course")
+	if err := parseFlags(flags, args, "id"); err != nil {
+		return err
+	}
+
+	var course models.Course
+	if err := c.db.Preload("Instructor").Preload("Status").First(&course, "id = ?", *id).Error; err != nil {
+		return fmt.Errorf("course %d: %v", *id, err)
+	}
+
+	validating, err := c.courseStatus("being validated")
+	if err != nil {
+		return err
+	}
+
+	published, err := c.courseStatus("published")
+	if err != nil {
+		return err
+	}
+
+	// the status is switched atomically, so only the courses being validated are published
+	result := c.db.Model(&models.Course{}).Where("id = ? AND status_id = ?", course.ID, validating.ID).Update("status_id", published.ID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("course %d is %s, only the courses being validated can be approved", course.ID, course.Status.Title)
+	}
+
+	return c.out.result(
+		courseResult{ID: course.ID, Title: course.Title, Status: published.Title, Instructor: course.Instructor.Email},
+		fmt.Sprintf("course %d (%s) is published", course.ID, course.Title),
+	)
+}
+
+// courseStatus finds the course status with the given title.
+func (c *ctl) courseStatus(title string) (models.CourseStatus, error) {
+	var status models.CourseStatus
+	if err := c.db.First(&status, "title = ?", title).Error; err != nil {
+		return status, fmt.Errorf("course status %q: %v", title, err)
+	}
+	return status, nil
+}
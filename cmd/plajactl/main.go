@@ -0,0 +1,124 @@
+// Command plajactl performs operational tasks on the Plaja database: managing users,
+// approving courses and teaching applications, regenerating certificates and running migrations.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/plaja-app/back-end/config"
+	"github.com/plaja-app/back-end/database"
+	"github.com/plaja-app/back-end/database/migrations"
+	"gorm.io/gorm"
+	"os"
+)
+
+const usage = `usage: plajactl [-json] <command> <subcommand> [flags]
+
+commands:
+  user create-admin -email E -first-name F -last-name L [-password P]
+  user set-type -email E -type Learner|Educator|Admin
+  user reset-password -email E [-password P]
+  user list [-type T]
+  course list [-status S]
+  course approve -id N
+  application list [-pending]
+  application approve -user-id N
+  certificate regenerate -id N
+  migrate up | down [steps] | status`
+
+// ctl holds the state shared by the commands.
+type ctl struct {
+	env *config.EnvVariables
+	db  *gorm.DB
+	out *printer
+}
+
+func main() {
+	flags := flag.NewFlagSet("plajactl", flag.ExitOnError)
+	jsonOutput := flags.Bool("json", false, "print the output as JSON")
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	flags.Parse(os.Args[1:])
+
+	if err := run(flags.Args(), &printer{json: *jsonOutput}); err != nil {
+		fmt.Fprintln(os.Stderr, "plajactl:", err)
+		os.Exit(1)
+	}
+}
+
+// run dispatches the command.
+func run(args []string, out *printer) error {
+	if len(args) < 2 {
+		return errors.New(usage)
+	}
+
+	env, err := config.LoadEnvVariables()
+	if err != nil {
+		return err
+	}
+
+	db, err := database.Open(env)
+	if err != nil {
+		return err
+	}
+
+	c := &ctl{env: env, db: db, out: out}
+
+	if args[0] == "migrate" {
+		return c.migrate(args[1:])
+	}
+
+	// every other command requires an up-to-date schema
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+
+	if err := migrator.Check(); err != nil {
+		return err
+	}
+
+	command, sub, rest := args[0], args[1], args[2:]
+
+	switch command + " " + sub {
+	case "user create-admin":
+		return c.createAdmin(rest)
+	case "user set-type":
+		return c.setUserType(rest)
+	case "user reset-password":
+		return c.resetPassword(rest)
+	case "user list":
+		return c.listUsers(rest)
+	case "course list":
+		return c.listCourses(rest)
+	case "course approve":
+		return c.approveCourse(rest)
+	case "application list":
+		return c.listApplications(rest)
+	case "application approve":
+		return c.approveApplication(rest)
+	case "certificate regenerate":
+		return c.regenerateCertificate(rest)
+	}
+
+	return errors.New(usage)
+}
+
+// parseFlags parses the subcommand flags and checks that the required ones are set.
+func parseFlags(flags *flag.FlagSet, args []string, required ...string) error {
+	flags.SetOutput(os.Stderr)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	for _, name := range required {
+		if !set[name] {
+			return fmt.Errorf("flag -%s is required", name)
+		}
+	}
+
+	return nil
+}
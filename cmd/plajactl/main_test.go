@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/plaja-app/back-end/config"
+	"github.com/plaja-app/back-end/database"
+	"github.com/plaja-app/back-end/database/migrations"
+	"github.com/plaja-app/back-end/database/seed"
+	"github.com/plaja-app/back-end/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setTestEnv points the commands to a fresh SQLite database.
+func setTestEnv(t *testing.T) {
+	t.Helper()
+
+	t.Setenv("APP_ENV", "development")
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("SQLITE_PATH", filepath.Join(t.TempDir(), "plaja.db"))
+	t.Setenv("STORAGE_ROOT", t.TempDir())
+	t.Setenv("PAYMENT_PROVIDER", "")
+	t.Setenv("PLAJA_CONFIG_FILE", "")
+}
+
+// runCtl runs the command and returns its text output.
+func runCtl(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	err := run(args, &printer{w: &buf})
+	return buf.String(), err
+}
+
+// runCtlJSON runs the command with the JSON output and decodes it into v.
+func runCtlJSON(t *testing.T, v any, args ...string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := run(args, &printer{json: true, w: &buf}); err != nil {
+		t.Fatalf("plajactl %s: %v", strings.Join(args, " "), err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), v); err != nil {
+		t.Fatalf("plajactl %s: invalid JSON output %q: %v", strings.Join(args, " "), buf.String(), err)
+	}
+}
+
+// openTestDB opens the database the commands run against.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	env, err := config.LoadEnvVariables()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := database.Open(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// seedTestDB migrates the database and seeds the datasets.
+func seedTestDB(t *testing.T, datasets ...string) *gorm.DB {
+	t.Helper()
+
+	if _, err := runCtl(t, "migrate", "up"); err != nil {
+		t.Fatal(err)
+	}
+
+	db := openTestDB(t)
+	if _, err := seed.New(db, seed.Fixtures()).Seed(datasets...); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestMigrate(t *testing.T) {
+	setTestEnv(t)
+
+	// the commands refuse a pending schema
+	if _, err := runCtl(t, "user", "list"); err == nil {
+		t.Fatal("expected the pending schema to be refused")
+	}
+
+	var statuses []migrations.Status
+	runCtlJSON(t, &statuses, "migrate", "status")
+	if len(statuses) == 0 || statuses[0].State != migrations.StatePending {
+		t.Fatalf("expected pending migrations, got %+v", statuses)
+	}
+
+	out, err := runCtl(t, "migrate", "up")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "applied 0001_") || strings.Count(out, "\n") != len(statuses) {
+		t.Fatalf("expected every migration to be applied, got %q", out)
+	}
+
+	out, err = runCtl(t, "migrate", "up")
+	if err != nil || out != "database schema is up to date\n" {
+		t.Fatalf("expected an up-to-date schema, got %q and %v", out, err)
+	}
+
+	var reverted []migrations.Result
+	runCtlJSON(t, &reverted, "migrate", "down", "2")
+	last := statuses[len(statuses)-1]
+	if len(reverted) != 2 || reverted[0].Version != last.Version || reverted[0].Action != "reverted" {
+		t.Fatalf("expected the last two migrations to be reverted, got %+v", reverted)
+	}
+
+	for _, args := range [][]string{{"migrate", "down", "0"}, {"migrate", "down", "x"}, {"migrate", "sideways"}} {
+		if _, err := runCtl(t, args...); err == nil || !strings.HasPrefix(err.Error(), "usage:") {
+			t.Errorf("%q: expected the usage, got %v", args, err)
+		}
+	}
+
+	out, err = runCtl(t, "migrate", "status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "VERSION") || strings.Count(out, string(migrations.StatePending)) != 2 {
+		t.Fatalf("expected two pending migrations, got %q", out)
+	}
+}
+
+func TestUserCommands(t *testing.T) {
+	setTestEnv(t)
+	db := seedTestDB(t, seed.DatasetReference)
+
+	if _, err := runCtl(t, "user", "create-admin", "-email", "ops@plaja.test"); err == nil {
+		t.Error("expected the required flags to be checked")
+	}
+	if _, err := runCtl(t, "user", "create-admin", "-email", "ops", "-first-name", "Ops", "-last-name", "Team"); err == nil {
+		t.Error("expected the invalid email to be refused")
+	}
+
+	// the generated password is printed once, the bare address is stored lower-cased
+	var admin userResult
+	runCtlJSON(t, &admin, "user", "create-admin", "-email", " Ops Team <Ops@Plaja.test> ", "-first-name", "Ops", "-last-name", "Team")
+	if admin.UserType != "Admin" || admin.Password == "" || admin.Email != "ops@plaja.test" {
+		t.Fatalf("unexpected admin %+v", admin)
+	}
+
+	var user models.User
+	db.First(&user, admin.ID)
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(admin.Password)) != nil {
+		t.Fatal("expected the generated password to be stored hashed")
+	}
+
+	out, err := runCtl(t, "user", "reset-password", "-email", "OPS@plaja.test", "-password", "new-password")
+	if err != nil || strings.Contains(out, "new-password") {
+		t.Fatalf("unexpected reset output %q and %v", out, err)
+	}
+
+	db.First(&user, admin.ID)
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("new-password")) != nil {
+		t.Fatal("expected the password to be reset")
+	}
+
+	if _, err := runCtl(t, "user", "set-type", "-email", "ops@plaja.test", "-type", "Owner"); err == nil {
+		t.Error("expected the unknown type to be refused")
+	}
+	if _, err := runCtl(t, "user", "set-type", "-email", "ops@plaja.test", "-type", "Educator"); err != nil {
+		t.Fatal(err)
+	}
+
+	var users []userResult
+	runCtlJSON(t, &users, "user", "list", "-type", "Educator")
+	if len(users) != 1 || users[0].Email != "ops@plaja.test" || users[0].Password != "" {
+		t.Fatalf("unexpected educators %+v", users)
+	}
+
+	runCtlJSON(t, &users, "user", "list", "-type", "Admin")
+	if len(users) != 0 {
+		t.Fatalf("expected no admins, got %+v", users)
+	}
+}
+
+func TestCourseAndApplicationCommands(t *testing.T) {
+	setTestEnv(t)
+	db := seedTestDB(t, seed.DatasetReference, seed.DatasetDev)
+
+	var course models.Course
+	var validating models.CourseStatus
+	db.First(&course)
+	db.First(&validating, "title = ?", "being validated")
+	db.Model(&course).Update("status_id", validating.ID)
+
+	var pending []courseResult
+	runCtlJSON(t, &pending, "course", "list", "-status", "being validated")
+	if len(pending) != 1 || pending[0].ID != course.ID || pending[0].Instructor == "" {
+		t.Fatalf("unexpected courses being validated %+v", pending)
+	}
+
+	out, err := runCtl(t, "course", "approve", "-id", fmt.Sprint(course.ID))
+	if err != nil || !strings.HasSuffix(out, "is published\n") {
+		t.Fatalf("unexpected approve output %q and %v", out, err)
+	}
+
+	runCtlJSON(t, &pending, "course", "list", "-status", "being validated")
+	if len(pending) != 0 {
+		t.Fatalf("expected the course to be published, got %+v", pending)
+	}
+
+	// only the courses being validated are approved
+	var draft models.Course
+	var drafted models.CourseStatus
+	db.First(&draft, "id <> ?", course.ID)
+	db.First(&drafted, "title = ?", "draft")
+	db.Model(&draft).Update("status_id", drafted.ID)
+	for _, id := range []uint{course.ID, draft.ID} {
+		if _, err := runCtl(t, "course", "approve", "-id", fmt.Sprint(id)); err == nil || !strings.Contains(err.Error(), "being validated") {
+			t.Errorf("course %d: expected the approval to be refused, got %v", id, err)
+		}
+	}
+
+	db.First(&draft, draft.ID)
+	if draft.StatusID != drafted.ID {
+		t.Fatalf("expected the draft to stay unpublished, got status %d", draft.StatusID)
+	}
+
+	// the applicant is promoted to educator
+	var learner models.UserType
+	db.First(&learner, "title = ?", "Learner")
+	applicant := models.User{FirstName: "Олена", LastName: "Коваль", Email: "applicant@plaja.test", UserTypeID: learner.ID}
+	db.Create(&applicant)
+	db.Create(&models.TeachingApplication{UserID: applicant.ID, Experience: "5 років", Motivation: "Ділитися знаннями"})
+
+	var applications []applicationResult
+	runCtlJSON(t, &applications, "application", "list", "-pending")
+	if len(applications) != 1 || applications[0].UserID != applicant.ID {
+		t.Fatalf("unexpected pending applications %+v", applications)
+	}
+
+	out, err = runCtl(t, "application", "approve", "-user-id", fmt.Sprint(applicant.ID))
+	if err != nil || out != "application of applicant@plaja.test approved\n" {
+		t.Fatalf("unexpected approve output %q and %v", out, err)
+	}
+
+	db.Preload("UserType").First(&applicant, applicant.ID)
+	if applicant.UserType.Title != "Educator" {
+		t.Errorf("expected the applicant to be an educator, got %s", applicant.UserType.Title)
+	}
+
+	runCtlJSON(t, &applications, "application", "list", "-pending")
+	if len(applications) != 0 {
+		t.Fatalf("expected no pending applications, got %+v", applications)
+	}
+
+	if _, err := runCtl(t, "certificate", "regenerate", "-id", "999"); err == nil {
+		t.Error("expected the unknown certificate to be refused")
+	}
+	if _, err := runCtl(t, "course", "delete", "-id", "1"); err == nil || !strings.HasPrefix(err.Error(), "usage:") {
+		t.Errorf("expected the usage, got %v", err)
+	}
+}
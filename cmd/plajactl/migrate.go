@@ -0,0 +1,11 @@
+package main
+
+import (
+	"github.com/plaja-app/back-end/database/migrations"
+)
+
+// migrate runs the migrate subcommands: up, down [steps] and status.
+func (c *ctl) migrate(args []string) error {
+	cmd := migrations.Command{Out: c.out.writer(), JSON: c.out.json, Usage: usage}
+	return cmd.Run(c.db, args)
+}
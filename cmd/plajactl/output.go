@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+)
+
+// printer prints the command results either as JSON or as human-readable text.
+type printer struct {
+	json bool
+	w    io.Writer
+}
+
+// writer returns the output writer.
+func (p *printer) writer() io.Writer {
+	if p.w == nil {
+		return os.Stdout
+	}
+	return p.w
+}
+
+// result prints a single result. The text form is the given message.
+func (p *printer) result(v any, message string) error {
+	if p.json {
+		return p.encode(v)
+	}
+
+	_, err := fmt.Fprintln(p.writer(), message)
+	return err
+}
+
+// table prints a list of results. The text form is a table with the given header and rows.
+func (p *printer) table(v any, header string, rows func(w io.Writer)) error {
+	if p.json {
+		return p.encode(v)
+	}
+
+	tw := tabwriter.NewWriter(p.writer(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, header)
+	rows(tw)
+	return tw.Flush()
+}
+
+// encode writes v as indented JSON.
+func (p *printer) encode(v any) error {
+	enc := json.NewEncoder(p.writer())
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"net/mail"
+	"strings"
+)
+
+// userResult is the output of the user commands.
+type userResult struct {
+	ID       uint
+	Email    string
+	UserType string
+	Password string `json:",omitempty"`
+}
+
+// createAdmin creates a new admin user.
+func (c *ctl) createAdmin(args []string) error {
+	flags := flag.NewFlagSet("user create-admin", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the admin")
+	firstName := flags.String("first-name", "", "first name")
+	lastName := flags.String("last-name", "", "last name")
+	password := flags.String("password", "", "password (generated if empty)")
+	if err := parseFlags(flags, args, "email", "first-name", "last-name"); err != nil {
+		return err
+	}
+
+	addr, err := mail.ParseAddress(*email)
+	if err != nil {
+		return fmt.Errorf("invalid email %q", *email)
+	}
+
+	userType, err := c.userType("Admin")
+	if err != nil {
+		return err
+	}
+
+	plain, hashed, err := preparePassword(*password)
+	if err != nil {
+		return err
+	}
+
+	user := models.User{
+		FirstName:  *firstName,
+		LastName:   *lastName,
+		Email:      strings.ToLower(strings.TrimSpace(addr.Address)),
+		Password:   hashed,
+		UserTypeID: userType.ID,
+	}
+
+	if err := c.db.Create(&user).Error; err != nil {
+		return fmt.Errorf("error creating user: %v", err)
+	}
+
+	result := userResult{ID: user.ID, Email: user.Email, UserType: userType.Title}
+	message := fmt.Sprintf("created admin %s (id %d)", user.Email, user.ID)
+	if *password == "" {
+		result.Password = plain
+		message += fmt.Sprintf(", password: %s", plain)
+	}
+
+	return c.out.result(result, message)
+}
+
+// setUserType changes the type of the user.
+func (c *ctl) setUserType(args []string) error {
+	flags := flag.NewFlagSet("user set-type", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the user")
+	typeTitle := flags.String("type", "", "new user type: Learner, Educator or Admin")
+	if err := parseFlags(flags, args, "email", "type"); err != nil {
+		return err
+	}
+
+	user, err := c.userByEmail(*email)
+	if err != nil {
+		return err
+	}
+
+	userType, err := c.userType(*typeTitle)
+	if err != nil {
+		return err
+	}
+
+	// the preloaded type would be saved back over the new one
+	if err := c.db.Model(&models.User{}).Where("id = ?", user.ID).Update("user_type_id", userType.ID).Error; err != nil {
+		return err
+	}
+
+	return c.out.result(
+		userResult{ID: user.ID, Email: user.Email, UserType: userType.Title},
+		fmt.Sprintf("%s is now %s", user.Email, userType.Title),
+	)
+}
+
+// resetPassword sets a new password for the user.
+func (c *ctl) resetPassword(args []string) error {
+	flags := flag.NewFlagSet("user reset-password", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the user")
+	password := flags.String("password", "", "new password (generated if empty)")
+	if err := parseFlags(flags, args, "email"); err != nil {
+		return err
+	}
+
+	user, err := c.userByEmail(*email)
+	if err != nil {
+		return err
+	}
+
+	plain, hashed, err := preparePassword(*password)
+	if err != nil {
+		return err
+	}
+
+	if err := c.db.Model(&user).Update("password", hashed).Error; err != nil {
+		return err
+	}
+
+	result := userResult{ID: user.ID, Email: user.Email, UserType: user.UserType.Title}
+	message := fmt.Sprintf("password of %s has been reset", user.Email)
+	if *password == "" {
+		result.Password = plain
+		message += fmt.Sprintf(", new password: %s", plain)
+	}
+
+	return c.out.result(result, message)
+}
+
+// listUsers lists the users, optionally filtered by type.
+func (c *ctl) listUsers(args []string) error {
+	flags := flag.NewFlagSet("user list", flag.ContinueOnError)
+	typeTitle := flags.String("type", "", "only list users of this type")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	query := c.db.Preload("UserType").Order("id")
+	if *typeTitle != "" {
+		userType, err := c.userType(*typeTitle)
+		if err != nil {
+			return err
+		}
+		query = query.Where("user_type_id = ?", userType.ID)
+	}
+
+	var users []models.User
+	if err := query.Find(&users).Error; err != nil {
+		return err
+	}
+
+	results := make([]userResult, 0, len(users))
+	for _, u := range users {
+		results = append(results, userResult{ID: u.ID, Email: u.Email, UserType: u.UserType.Title})
+	}
+
+	return c.out.table(results, "ID\tEMAIL\tTYPE", func(w io.Writer) {
+		for _, u := range results {
+			fmt.Fprintf(w, "%d\t%s\t%s\n", u.ID, u.Email, u.UserType)
+		}
+	})
+}
+
+// userByEmail finds the user with the given email, ignoring the case.
+func (c *ctl) userByEmail(email string) (models.User, error) {
+	var user models.User
+	if err := c.db.Preload("UserType").First(&user, "LOWER(email) = ?", strings.ToLower(strings.TrimSpace(email))).Error; err != nil {
+		return user, fmt.Errorf("user %s: %v", email, err)
+	}
+	return user, nil
+}
+
+// userType finds the user type with the given title.
+func (c *ctl) userType(title string) (models.UserType, error) {
+	var userType models.UserType
+	if err := c.db.First(&userType, "title = ?", title).Error; err != nil {
+		return userType, fmt.Errorf("user type %q: %v", title, err)
+	}
+	return userType, nil
+}
+
+// preparePassword returns the plain password (generating one if empty) and its bcrypt hash.
+func preparePassword(password string) (string, string, error) {
+	if password == "" {
+		buf := make([]byte, 12)
+		if _, err := rand.Read(buf); err != nil {
+			return "", "", err
+		}
+		password = base64.RawURLEncoding.EncodeToString(buf)
+	}
+
+	if len(password) < 8 {
+		return "", "", fmt.Errorf("password must be at least 8 characters long")
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), 10)
+	if err != nil {
+		return "", "", err
+	}
+
+	return password, string(hashed), nil
+}
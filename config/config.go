@@ -1,6 +1,10 @@
 package config
 
-import "gorm.io/gorm"
+import (
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
 
 // AppConfig holds the application config.
 type AppConfig struct {
@@ -10,9 +14,102 @@ type AppConfig struct {
 
 // EnvVariables holds environment variables used in the application.
 type EnvVariables struct {
+	// DBDriver is the database driver: "postgres" (default) or "sqlite".
+	DBDriver string
+	// SQLitePath is the SQLite database file, or ":memory:".
+	SQLitePath string
+
 	PostgresHost   string
 	PostgresUser   string
 	PostgresPass   string
 	PostgresDBName string
 	JWTSecret      string
+
+	// AppEnv is the deployment environment, e.g. "development" or "production".
+	AppEnv string
+	// ServerAddr is the address the HTTP server listens on, e.g. ":8080".
+	ServerAddr string
+	// PublicBaseURL is the externally reachable URL of the API, without a trailing slash.
+	PublicBaseURL string
+	// StorageRoot is the directory holding the uploaded and service files.
+	StorageRoot string
+	// CORSOrigins lists the origins allowed to make credentialed cross-origin requests. The
+	// wildcard is not accepted.
+	CORSOrigins []string
+	// CookieDomain is the domain attribute of the session cookie.
+	CookieDomain string
+	// CookieSecure marks the session cookie as HTTPS-only.
+	CookieSecure bool
+
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+
+	// AutocompleteRefresh is the interval of the periodic autocomplete index rebuild.
+	AutocompleteRefresh time.Duration
+	// RecommendationsRefresh is the interval of the periodic recomputation of the
+	// recommendation model.
+	RecommendationsRefresh time.Duration
+
+	// SMTPAddr is the "host:port" of the SMTP server sending the emails. If empty, the
+	// emails are written to the log, which the API only allows in development.
+	SMTPAddr     string
+	SMTPUsername string
+	SMTPPassword string
+	// MailFrom is the sender address of the emails.
+	MailFrom string
+	// InvitationURL is the page the users invited by the imports set their password on;
+	// the invitation token is added as the token query parameter.
+	InvitationURL string
+	// JoinURL is the page the existing users invited by the imports accept joining the
+	// organization on; the invitation token is added as the token query parameter.
+	JoinURL string
+
+	// PaymentProvider is the payment provider: "liqpay", "fake" (development only) or
+	// empty to disable the purchases of the paid courses.
+	PaymentProvider string
+	// PaymentCurrency is the default currency of the course prices and the base of the
+	// exchange rates, e.g. "UAH".
+	PaymentCurrency string
+	// PaymentResultURL is the page the learners return to after paying.
+	PaymentResultURL string
+	// PaymentWebhookSecret signs the webhooks of the fake provider.
+	PaymentWebhookSecret string
+	LiqPayPublicKey      string
+	LiqPayPrivateKey     string
+	// LiqPaySandbox accepts the LiqPay test payments.
+	LiqPaySandbox bool
+
+	// RefundWindowDays is the number of days after the payment the learners may request a
+	// refund in, as long as their progress is below RefundMaxProgress percent.
+	RefundWindowDays  uint
+	RefundMaxProgress uint
+	// RevenueShare is the percentage of the course sales paid to the instructors.
+	RevenueShare uint
+	// SubscriptionGraceDays is the number of days the subscriptions keep the access to the
+	// courses after the end of the period while the renewal payment is due.
+	SubscriptionGraceDays uint
+
+	// SellerName, SellerAddress, SellerTaxID and SellerCountry (ISO 3166 code) are the
+	// details of the seller printed on the invoices. The VAT of the buyers without billing
+	// details is charged at the rate of SellerCountry.
+	SellerName    string
+	SellerAddress string
+	SellerTaxID   string
+	SellerCountry string
+}
+
+// IsDevelopment reports whether the application runs in the development environment.
+func (e *EnvVariables) IsDevelopment() bool {
+	return e.AppEnv == "development"
+}
+
+// StorageURL returns the public URL of the file stored at the given storage-relative path.
+// Absolute URLs (e.g. external thumbnails) are returned unchanged.
+func (a *AppConfig) StorageURL(path string) string {
+	if path == "" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+
+	return a.Env.PublicBaseURL + "/api/v1/storage/" + strings.TrimPrefix(path, "/")
 }
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/joho/godotenv"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadEnvVariables loads the application settings from the environment.
+// Values are also read from the optional .env file and from the file referenced by
+// PLAJA_CONFIG_FILE (same KEY=value format); variables already set in the
+// environment take precedence over both files.
+func LoadEnvVariables() (*EnvVariables, error) {
+	err := godotenv.Load()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("error getting environment variables: %v", err)
+	}
+
+	if configFile := os.Getenv("PLAJA_CONFIG_FILE"); configFile != "" {
+		if err := godotenv.Load(configFile); err != nil {
+			return nil, fmt.Errorf("error reading config file %s: %v", configFile, err)
+		}
+	}
+
+	env := &EnvVariables{
+		DBDriver:       getString("DB_DRIVER", "postgres"),
+		SQLitePath:     getString("SQLITE_PATH", "plaja.db"),
+		PostgresHost:   os.Getenv("POSTGRES_HOST"),
+		PostgresUser:   os.Getenv("POSTGRES_USER"),
+		PostgresPass:   os.Getenv("POSTGRES_PASS"),
+		PostgresDBName: os.Getenv("POSTGRES_DBNAME"),
+		JWTSecret:      os.Getenv("JWT_SECRET"),
+		AppEnv:         getString("APP_ENV", "production"),
+		ServerAddr:     getString("SERVER_ADDR", ":8080"),
+		PublicBaseURL:  strings.TrimRight(getString("PUBLIC_BASE_URL", "http://localhost:8080"), "/"),
+		StorageRoot:    getString("STORAGE_ROOT", "storage"),
+		CORSOrigins:    getList("CORS_ORIGINS"),
+		CookieDomain:   os.Getenv("COOKIE_DOMAIN"),
+		SMTPAddr:       os.Getenv("SMTP_ADDR"),
+		SMTPUsername:   os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:   os.Getenv("SMTP_PASSWORD"),
+		MailFrom:       getString("MAIL_FROM", "Plaja <no-reply@plaja.io>"),
+
+		PaymentProvider:      os.Getenv("PAYMENT_PROVIDER"),
+		PaymentCurrency:      getString("PAYMENT_CURRENCY", "UAH"),
+		PaymentWebhookSecret: os.Getenv("PAYMENT_WEBHOOK_SECRET"),
+		LiqPayPublicKey:      os.Getenv("LIQPAY_PUBLIC_KEY"),
+		LiqPayPrivateKey:     os.Getenv("LIQPAY_PRIVATE_KEY"),
+
+		SellerName:    getString("SELLER_NAME", "Plaja"),
+		SellerAddress: os.Getenv("SELLER_ADDRESS"),
+		SellerTaxID:   os.Getenv("SELLER_TAX_ID"),
+		SellerCountry: strings.ToUpper(getString("SELLER_COUNTRY", "UA")),
+	}
+
+	env.PaymentResultURL = getString("PAYMENT_RESULT_URL", env.PublicBaseURL)
+	env.InvitationURL = getString("INVITATION_URL", env.PublicBaseURL+"/set-password")
+	env.JoinURL = getString("JOIN_URL", env.PublicBaseURL+"/join")
+
+	if env.CookieSecure, err = getBool("COOKIE_SECURE", false); err != nil {
+		return nil, err
+	}
+
+	if env.ReadTimeout, err = getDuration("SERVER_READ_TIMEOUT", 15*time.Second); err != nil {
+		return nil, err
+	}
+
+	if env.WriteTimeout, err = getDuration("SERVER_WRITE_TIMEOUT", 30*time.Second); err != nil {
+		return nil, err
+	}
+
+	if env.IdleTimeout, err = getDuration("SERVER_IDLE_TIMEOUT", 60*time.Second); err != nil {
+		return nil, err
+	}
+
+	if env.AutocompleteRefresh, err = getDuration("AUTOCOMPLETE_REFRESH_INTERVAL", 5*time.Minute); err != nil {
+		return nil, err
+	}
+
+	if env.RecommendationsRefresh, err = getDuration("RECOMMENDATIONS_REFRESH_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+
+	if env.LiqPaySandbox, err = getBool("LIQPAY_SANDBOX", false); err != nil {
+		return nil, err
+	}
+
+	if env.RefundWindowDays, err = getUint("REFUND_WINDOW_DAYS", 14); err != nil {
+		return nil, err
+	}
+
+	if env.RefundMaxProgress, err = getUint("REFUND_MAX_PROGRESS", 30); err != nil {
+		return nil, err
+	}
+
+	if env.RefundMaxProgress > 100 {
+		return nil, errors.New("REFUND_MAX_PROGRESS must be a percentage between 0 and 100")
+	}
+
+	if env.RevenueShare, err = getUint("REVENUE_SHARE", 70); err != nil {
+		return nil, err
+	}
+
+	if env.RevenueShare > 100 {
+		return nil, errors.New("REVENUE_SHARE must be a percentage between 0 and 100")
+	}
+
+	if env.SubscriptionGraceDays, err = getUint("SUBSCRIPTION_GRACE_DAYS", 3); err != nil {
+		return nil, err
+	}
+
+	if len(env.SellerCountry) != 2 || strings.Trim(env.SellerCountry, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return nil, fmt.Errorf("invalid SELLER_COUNTRY %q, expected a two-letter country code", env.SellerCountry)
+	}
+
+	for _, origin := range env.CORSOrigins {
+		// the CORS responses allow credentials, so a wildcard would let any site act as the user
+		if strings.Contains(origin, "*") {
+			return nil, fmt.Errorf("invalid CORS_ORIGINS origin %q, expected the exact origins of the front-end", origin)
+		}
+	}
+
+	if err := env.validatePayments(); err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// validatePayments checks the payment provider settings. The fake provider confirms the
+// payments without collecting money, so it is refused outside development.
+func (e *EnvVariables) validatePayments() error {
+	switch e.PaymentProvider {
+	case "":
+		return nil
+	case "fake":
+		if !e.IsDevelopment() {
+			return errors.New("PAYMENT_PROVIDER=fake is only allowed with APP_ENV=development")
+		}
+		if e.PaymentWebhookSecret == "" {
+			return errors.New("PAYMENT_WEBHOOK_SECRET is required by the fake payment provider")
+		}
+	case "liqpay":
+		if e.LiqPayPublicKey == "" || e.LiqPayPrivateKey == "" {
+			return errors.New("LIQPAY_PUBLIC_KEY and LIQPAY_PRIVATE_KEY are required by the liqpay payment provider")
+		}
+	default:
+		return fmt.Errorf("unknown PAYMENT_PROVIDER %q, expected liqpay or fake", e.PaymentProvider)
+	}
+	return nil
+}
+
+// getString returns the value of the environment variable or the fallback if it is unset.
+func getString(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getList returns the comma-separated values of the environment variable.
+func getList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getBool parses the environment variable as a boolean.
+func getBool(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for %s: %v", key, err)
+	}
+	return b, nil
+}
+
+// getUint parses the environment variable as an unsigned integer.
+func getUint(key string, fallback uint) (uint, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %v", key, err)
+	}
+	return uint(n), nil
+}
+
+// getDuration parses the environment variable as a time.Duration (e.g. "15s").
+func getDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %v", key, err)
+	}
+	return d, nil
+}
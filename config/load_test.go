@@ -0,0 +1,26 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestLoadCORSOrigins(t *testing.T) {
+	t.Setenv("PLAJA_CONFIG_FILE", "")
+	t.Setenv("PAYMENT_PROVIDER", "")
+
+	t.Setenv("CORS_ORIGINS", "http://localhost:5173, https://plaja.io")
+	env, err := LoadEnvVariables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(env.CORSOrigins) != 2 || env.CORSOrigins[1] != "https://plaja.io" {
+		t.Fatalf("unexpected CORS origins %q", env.CORSOrigins)
+	}
+
+	for _, origins := range []string{"*", "https://plaja.io,*", "https://*.plaja.io"} {
+		t.Setenv("CORS_ORIGINS", origins)
+		if _, err := LoadEnvVariables(); err == nil {
+			t.Errorf("CORS_ORIGINS=%s: expected an error", origins)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"github.com/plaja-app/back-end/services"
+	"net/http"
+)
+
+// GetAuditLog returns the queried page of the models.AuditEntry to the admins.
+func (c *BaseController) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	filter := services.AuditFilter{
+		Entity:   query.String("entity"),
+		EntityID: query.String("entity_id"),
+		ActorID:  query.ID("actor_id"),
+		Actions:  query.List("action"),
+	}
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	entries, info, err := c.Audit.List(r.Context(), user.ID, filter, page)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, entries, info, query.Fields())
+}
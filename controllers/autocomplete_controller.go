@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"github.com/plaja-app/back-end/services"
+	"net/http"
+)
+
+// GetSuggestions returns the courses, instructors and categories matching the query q as
+// the user types it, e.g. "rozrobka" or "розр" for "Розробка".
+func (c *BaseController) GetSuggestions(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	limit := query.Int("limit")
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	switch {
+	case limit < 0:
+		writeError(w, services.Errorf(services.ErrInvalidInput, "invalid format for limit"))
+		return
+	case limit == 0:
+		limit = services.DefaultSuggestions
+	case limit > services.MaxSuggestions:
+		limit = services.MaxSuggestions
+	}
+
+	suggestions, err := c.Autocomplete.Suggest(r.Context(), query.String("q"), limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if suggestions == nil {
+		suggestions = []services.Suggestion{}
+	}
+
+	writeJSON(w, http.StatusOK, suggestions)
+}
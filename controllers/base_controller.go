@@ -1,23 +1,60 @@
 package controllers
 
-import "github.com/plaja-app/back-end/config"
-
-// Controller the repository used by the controllers.
-var Controller *BaseController
+import (
+	"github.com/plaja-app/back-end/config"
+	"github.com/plaja-app/back-end/services"
+)
 
 // BaseController holds the base information needed for all the controllers.
 type BaseController struct {
-	App *config.AppConfig
+	App             *config.AppConfig
+	Users           services.UserService
+	Courses         services.CourseService
+	Enrollments     services.EnrollmentService
+	Certificates    services.CertificateService
+	Reviews         services.ReviewService
+	Discussions     services.DiscussionService
+	Wishlist        services.WishlistService
+	Notifications   services.NotificationService
+	Orders          services.OrderService
+	Coupons         services.CouponService
+	Refunds         services.RefundService
+	Audit           services.AuditService
+	Ledger          services.LedgerService
+	Subscriptions   services.SubscriptionService
+	Invoices        services.InvoiceService
+	Currencies      services.CurrencyService
+	Organizations   services.OrganizationService
+	UserImports     services.UserImportService
+	Paths           services.LearningPathService
+	Autocomplete    services.AutocompleteService
+	Recommendations services.RecommendationService
 }
 
-// NewBaseController creates a new BaseController.
-func NewBaseController(app *config.AppConfig) *BaseController {
+// NewBaseController creates a new BaseController using the given services.
+func NewBaseController(app *config.AppConfig, svc *services.Services) *BaseController {
 	return &BaseController{
-		App: app,
+		App:             app,
+		Users:           svc.Users,
+		Courses:         svc.Courses,
+		Enrollments:     svc.Enrollments,
+		Certificates:    svc.Certificates,
+		Reviews:         svc.Reviews,
+		Discussions:     svc.Discussions,
+		Wishlist:        svc.Wishlist,
+		Notifications:   svc.Notifications,
+		Orders:          svc.Orders,
+		Coupons:         svc.Coupons,
+		Refunds:         svc.Refunds,
+		Audit:           svc.Audit,
+		Ledger:          svc.Ledger,
+		Subscriptions:   svc.Subscriptions,
+		Invoices:        svc.Invoices,
+		Currencies:      svc.Currencies,
+		Organizations:   svc.Organizations,
+		UserImports:     svc.UserImports,
+		Paths:           svc.Paths,
+		Autocomplete:    svc.Autocomplete,
+		Recommendations: svc.Recommendations,
 	}
 }
-
-// NewControllers sets the repository for the controllers.
-func NewControllers(c *BaseController) {
-	Controller = c
-}
@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"net/http"
+	"time"
+)
+
+// couponBody is the coupon creation request body structure.
+type couponBody struct {
+	Code           string
+	CourseID       *uint
+	Kind           string
+	Value          uint
+	Currency       string
+	MaxRedemptions uint
+	ExpiresAt      *time.Time
+}
+
+// couponUpdateBody is the coupon update request body structure.
+type couponUpdateBody struct {
+	CouponID       uint
+	MaxRedemptions uint
+	ExpiresAt      *time.Time
+	Disabled       bool
+}
+
+// couponFilter parses the coupon filter query parameters.
+func couponFilter(query *queryParser) services.CouponFilter {
+	return services.CouponFilter{
+		IDs:      query.IDs("id"),
+		CourseID: query.ID("course_id"),
+	}
+}
+
+// setFinalPrices sets the LocalPrice and the FinalPrice of the courses with the active
+// sales and the coupon with the code, if it is not empty, applied. The prices are in the
+// currency query parameter, or else the preferred currency of the user, and stay in the
+// course currencies the exchange rates cannot convert.
+func (c *BaseController) setFinalPrices(r *http.Request, code string, courses ...*models.Course) error {
+	ctx := r.Context()
+
+	var coupon *models.Coupon
+	if code != "" {
+		redeemable, err := c.Coupons.Redeemable(ctx, code)
+		if err != nil {
+			return err
+		}
+		coupon = &redeemable
+	}
+
+	currency := services.NormalizeCurrency(r.URL.Query().Get("currency"))
+	if currency == "" {
+		user, _ := ctx.Value("user").(models.User)
+		currency = user.Currency
+	}
+	if currency != "" && !services.ValidCurrency(currency) {
+		return services.Errorf(services.ErrInvalidInput, "unsupported currency %q", currency)
+	}
+
+	list, err := c.Currencies.Rates(ctx)
+	if err != nil {
+		return err
+	}
+	rates := services.NewRates(list)
+
+	now := time.Now()
+	for _, course := range courses {
+		price, err := services.CalculatePrice(*course, coupon, currency, rates, now)
+		if err != nil {
+			if price, err = services.CalculatePrice(*course, coupon, "", rates, now); err != nil {
+				return err
+			}
+		}
+		course.LocalPrice, course.FinalPrice, course.LocalCurrency = &price.List, &price.Amount, price.Currency
+	}
+
+	return nil
+}
+
+// GetCoupons returns the queried page of the models.Coupon created by the current user, or
+// of all of them for the admins.
+func (c *BaseController) GetCoupons(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	filter := couponFilter(query)
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	coupons, info, err := c.Coupons.List(r.Context(), user.ID, filter, page)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, coupons, info, query.Fields())
+}
+
+// GetCouponReport returns the redemption summaries of the models.Coupon created by the
+// current user, or of all of them for the admins.
+func (c *BaseController) GetCouponReport(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	filter := couponFilter(query)
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	reports, err := c.Coupons.Report(r.Context(), user.ID, filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reports)
+}
+
+// CreateCoupon creates a models.Coupon of a course of the current user, or a site-wide one
+// if the current user is an admin.
+func (c *BaseController) CreateCoupon(w http.ResponseWriter, r *http.Request) {
+	var body couponBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	coupon, err := c.Coupons.Create(r.Context(), user.ID, services.CouponInput{
+		Code:           body.Code,
+		CourseID:       body.CourseID,
+		Kind:           body.Kind,
+		Value:          body.Value,
+		Currency:       body.Currency,
+		MaxRedemptions: body.MaxRedemptions,
+		ExpiresAt:      body.ExpiresAt,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, coupon)
+}
+
+// UpdateCoupon updates the usage limits of a models.Coupon created by the current user.
+func (c *BaseController) UpdateCoupon(w http.ResponseWriter, r *http.Request) {
+	var body couponUpdateBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	err = c.Coupons.Update(r.Context(), user.ID, services.CouponUpdate{
+		CouponID:       body.CouponID,
+		MaxRedemptions: body.MaxRedemptions,
+		ExpiresAt:      body.ExpiresAt,
+		Disabled:       body.Disabled,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
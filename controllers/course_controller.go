@@ -2,16 +2,11 @@ package controllers
 
 import (
 	"encoding/json"
-	"errors"
-	"fmt"
 	"github.com/plaja-app/back-end/models"
-	"gorm.io/gorm"
-	"io"
+	"github.com/plaja-app/back-end/services"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
-	"strings"
+	"time"
 )
 
 // courseCategory is the models.CourseCategory DTO.
@@ -20,117 +15,183 @@ type courseCategory struct {
 	Title string
 }
 
+// catalogue is the catalogue response structure.
+type catalogue struct {
+	Courses any
+	Facets  services.CourseFacets
+}
+
 // courseCreationBody is the course creation request body structure.
 type courseCreationBody struct {
 	Title          string
 	Categories     []courseCategory
 	LevelID        uint
 	HasCertificate bool
-	// InstructorID   uint
 }
 
-// courseUpdateGeneralBody is the course general information update request body structure.
-type courseUpdateGeneralBody struct {
-	Title            string
-	ShortDescription string
-	Description      string
-	Price            uint
-	// InstructorID     uint
+// courseSaleBody is the course sale request body structure.
+type courseSaleBody struct {
+	CourseID  uint
+	SalePrice *uint
+	StartsAt  *time.Time
+	EndsAt    *time.Time
+}
+
+// coursePricesBody is the course prices request body structure.
+type coursePricesBody struct {
 	CourseID uint
+	Prices   []services.PriceInput
+}
+
+// coursePrerequisitesBody is the course prerequisites request body structure.
+type coursePrerequisitesBody struct {
+	CourseID        uint
+	PrerequisiteIDs []uint
+	Enforce         bool
+}
+
+// courseFilter parses the course filter query parameters.
+func courseFilter(query *queryParser) services.CourseFilter {
+	return services.CourseFilter{
+		IDs:            query.IDs("id"),
+		UserID:         query.ID("user_id"),
+		StatusID:       query.ID("status_id"),
+		InstructorID:   query.ID("instructor_id"),
+		HasCertificate: query.Bool("has_certificate"),
+		CategoryIDs:    query.IDs("category_id"),
+		LevelIDs:       query.IDs("level_id"),
+		PriceBuckets:   query.List("price"),
+		PriceMin:       query.OptionalUint("price_min"),
+		PriceMax:       query.OptionalUint("price_max"),
+		PriceCurrency:  services.NormalizeCurrency(query.String("price_currency")),
+		Free:           query.Bool("free"),
+		LengthBuckets:  query.List("length"),
+		LengthMin:      query.OptionalUint("length_min"),
+		LengthMax:      query.OptionalUint("length_max"),
+	}
 }
 
-// GetCourses returns the queried list of models.Course.
+// GetCourses returns the queried page of models.Course.
 func (c *BaseController) GetCourses(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query()
-
-	id := query.Get("id")
-	userID := query.Get("user_id")
-	statusID := query.Get("status_id")
-	instructorID := query.Get("instructor_id")
-	levelID := query.Get("level_id")
-	hasCertificate := query.Get("has_certificate")
-	sort := query.Get("sort")
-
-	w.Header().Set("Content-Type", "application/json")
-
-	var courses []models.Course
-	dbQuery := c.App.DB
-
-	if id != "" {
-		if id != "all" {
-			ids := strings.Split(id, ",")
-			var intIds []int
-			for _, idStr := range ids {
-				intId, err := strconv.Atoi(idStr)
-				if err != nil {
-					http.Error(w, "Invalid ID format", http.StatusBadRequest)
-					return
-				}
-				intIds = append(intIds, intId)
-			}
-			dbQuery = dbQuery.Where("id IN ?", intIds)
-		}
+	query := newQueryParser(r)
+
+	filter := courseFilter(query)
+	coupon := query.String("coupon")
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
 	}
 
-	if statusID != "" {
-		dbQuery = dbQuery.Where("status_id = ?", statusID)
+	courses, info, err := c.Courses.List(r.Context(), filter, page)
+	if err != nil {
+		writeError(w, err)
+		return
 	}
 
-	if instructorID != "" {
-		dbQuery = dbQuery.Where("instructor_id = ?", instructorID)
+	if err := c.markWishlisted(r, courseRefs(courses)...); err != nil {
+		writeError(w, err)
+		return
 	}
 
-	if levelID != "" {
-		dbQuery = dbQuery.Where("level_id = ?", levelID)
+	if err := c.setFinalPrices(r, coupon, courseRefs(courses)...); err != nil {
+		writeError(w, err)
+		return
 	}
 
-	if userID != "" {
-		dbQuery = dbQuery.Joins("JOIN enrollments ON enrollments.course_id = courses.id").Where("enrollments.user_id = ?", userID)
+	c.resolveCourseURLs(courses)
+
+	writeList(w, courses, info, query.Fields())
+}
+
+// GetCatalogue returns the queried page of the published courses together with the
+// facet counts for building the catalogue filters.
+func (c *BaseController) GetCatalogue(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	filter := courseFilter(query)
+	filter.StatusID = services.CourseStatusPublished
+	coupon := query.String("coupon")
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
 	}
 
-	if hasCertificate != "" {
-		hasCertBool, err := strconv.ParseBool(hasCertificate)
-		if err != nil {
-			http.Error(w, "Invalid format for has_certificate", http.StatusBadRequest)
-			return
-		}
-		dbQuery = dbQuery.Where("has_certificate = ?", hasCertBool)
+	courses, info, err := c.Courses.List(r.Context(), filter, page)
+	if err != nil {
+		writeError(w, err)
+		return
 	}
 
-	if sort != "" {
-		direction := "ASC"
-		if sort[0] == '-' {
-			direction = "DESC"
-			sort = sort[1:]
-		}
-		allowedSortFields := map[string]bool{
-			"id":              true,
-			"name":            true,
-			"status_id":       true,
-			"instructor_id":   true,
-			"level_id":        true,
-			"has_certificate": true,
-			"updated_at":      true,
-			"created_at":      true,
-		}
-		if _, ok := allowedSortFields[sort]; ok {
-			dbQuery = dbQuery.Order(sort + " " + direction)
-		} else {
-			http.Error(w, "Invalid sort field", http.StatusBadRequest)
+	facets, err := c.Courses.Facets(r.Context(), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := c.markWishlisted(r, courseRefs(courses)...); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := c.setFinalPrices(r, coupon, courseRefs(courses)...); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	c.resolveCourseURLs(courses)
+
+	var list any = courses
+	if fields := query.Fields(); len(fields) > 0 {
+		if list, err = selectFields(courses, fields); err != nil {
+			writeError(w, err)
 			return
 		}
 	}
 
-	dbQuery = dbQuery.Preload("Instructor").Preload("Level").Preload("Categories")
+	writePageHeaders(w, info)
+	writeJSON(w, http.StatusOK, catalogue{Courses: list, Facets: facets})
+}
 
-	if err := dbQuery.Find(&courses).Error; err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+// SearchCourses returns the queried page of the published courses matching the search
+// query q, the most relevant first, with highlighted snippets.
+func (c *BaseController) SearchCourses(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(courses)
+	results, info, err := c.Courses.Search(r.Context(), query.String("q"), page)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	refs := make([]*models.Course, len(results))
+	for i := range results {
+		refs[i] = &results[i].Course
+	}
+
+	if err := c.markWishlisted(r, refs...); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := c.setFinalPrices(r, query.String("coupon"), refs...); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	for i := range results {
+		results[i].Thumbnail = c.App.StorageURL(results[i].Thumbnail)
+		c.resolveUserURLs(&results[i].Instructor)
+	}
+
+	writeList(w, results, info, query.Fields())
 }
 
 // CreateCourse creates a new models.Course.
@@ -144,42 +205,22 @@ func (c *BaseController) CreateCourse(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var courseCategories []models.CourseCategory
-	for _, c := range body.Categories {
-		category := models.CourseCategory{
-			ID:    c.ID,
-			Title: c.Title,
-		}
-
-		courseCategories = append(courseCategories, category)
-	}
-
-	userCtx := r.Context().Value("user")
-	if userCtx == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
-	user, ok := userCtx.(models.User)
+	user, ok := currentUser(w, r)
 	if !ok {
-		http.Error(w, "Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	var course models.Course
-	course = models.Course{
+	input := services.CourseInput{
 		Title:          body.Title,
-		Thumbnail:      "http://localhost:8080/api/v1/storage/service/courses/no-thumbnail.png",
-		Categories:     courseCategories,
 		LevelID:        body.LevelID,
-		StatusID:       1, // draft
 		HasCertificate: body.HasCertificate,
-		InstructorID:   user.ID,
+	}
+	for _, category := range body.Categories {
+		input.CategoryIDs = append(input.CategoryIDs, category.ID)
 	}
 
-	result := c.App.DB.Create(&course)
-	if result.Error != nil {
-		http.Error(w, "Error creating course", http.StatusBadRequest)
+	if _, err := c.Courses.Create(r.Context(), user.ID, input); err != nil {
+		writeError(w, err)
 		return
 	}
 
@@ -194,88 +235,156 @@ func (c *BaseController) UpdateGeneralCourse(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	var body courseUpdateGeneralBody
-	body.Title = r.FormValue("Title")
-	body.ShortDescription = r.FormValue("ShortDescription")
-	body.Description = r.FormValue("Description")
+	input := services.CourseGeneralUpdate{
+		Title:            r.FormValue("Title"),
+		ShortDescription: r.FormValue("ShortDescription"),
+		Description:      r.FormValue("Description"),
+		Currency:         r.FormValue("Currency"),
+	}
 
 	price, err := strconv.ParseUint(r.FormValue("Price"), 10, 32)
 	if err != nil {
 		http.Error(w, "Invalid price", http.StatusBadRequest)
 		return
 	}
-	body.Price = uint(price)
+	input.Price = uint(price)
 
 	courseID, err := strconv.ParseUint(r.FormValue("CourseID"), 10, 32)
 	if err != nil {
 		http.Error(w, "Invalid course id", http.StatusBadRequest)
 		return
 	}
-	body.CourseID = uint(courseID)
+	input.CourseID = uint(courseID)
 
-	//instructorID, err := strconv.ParseUint(r.FormValue("InstructorID"), 10, 32)
-	//if err != nil {
-	//	http.Error(w, "Invalid instructor id", http.StatusBadRequest)
-	//	return
-	//}
-	//body.InstructorID = uint(instructorID)
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
 
-	userCtx := r.Context().Value("user")
-	if userCtx == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	file, _, err := r.FormFile("Thumbnail")
+	if err == nil && file != nil {
+		defer file.Close()
+		input.Thumbnail = file
+	}
+
+	if err := c.Courses.UpdateGeneral(r.Context(), user.ID, input); err != nil {
+		writeError(w, err)
 		return
 	}
 
-	user, ok := userCtx.(models.User)
+	w.WriteHeader(http.StatusOK)
+}
+
+// UpdateCourseSale sets or ends the sale of a models.Course of the current user.
+func (c *BaseController) UpdateCourseSale(w http.ResponseWriter, r *http.Request) {
+	var body courseSaleBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
 	if !ok {
-		http.Error(w, "Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	var fileURL string
+	err = c.Courses.UpdateSale(r.Context(), user.ID, services.SaleUpdate{
+		CourseID:  body.CourseID,
+		SalePrice: body.SalePrice,
+		StartsAt:  body.StartsAt,
+		EndsAt:    body.EndsAt,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
 
-	file, _, err := r.FormFile("Thumbnail")
-	if err == nil && file != nil {
-		defer file.Close()
+	w.WriteHeader(http.StatusOK)
+}
 
-		storagePath := "storage/courses/thumbnails"
-		os.MkdirAll(storagePath, os.ModePerm)
+// UpdateCoursePrices replaces the prices in the other currencies of a models.Course of the
+// current user.
+func (c *BaseController) UpdateCoursePrices(w http.ResponseWriter, r *http.Request) {
+	var body coursePricesBody
 
-		filePath := filepath.Join(storagePath, fmt.Sprintf("%d-%s", body.CourseID, "thumbnail.png"))
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
 
-		dst, err := os.Create(filePath)
-		if err != nil {
-			http.Error(w, "Failed to save the file", http.StatusInternalServerError)
-			return
-		}
-		defer dst.Close()
-		_, err = io.Copy(dst, file)
-		if err != nil {
-			http.Error(w, "Failed to write the file", http.StatusInternalServerError)
-			return
-		}
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
 
-		fileURL = fmt.Sprintf("http://localhost:8080/api/v1/%s", filePath)
+	prices, err := c.Courses.UpdatePrices(r.Context(), user.ID, body.CourseID, body.Prices)
+	if err != nil {
+		writeError(w, err)
+		return
 	}
 
-	updateData := map[string]interface{}{
-		"ShortDescription": body.ShortDescription,
-		"Title":            body.Title,
-		"Description":      body.Description,
-		"Price":            body.Price,
-		"InstructorID":     user.ID,
+	if prices == nil {
+		prices = make([]models.CoursePrice, 0)
 	}
 
-	if fileURL != "" {
-		updateData["Thumbnail"] = fileURL
+	writeJSON(w, http.StatusOK, prices)
+}
+
+// UpdateCoursePrerequisites replaces the prerequisites of a models.Course of the current
+// user and sets whether they block the enrollments.
+func (c *BaseController) UpdateCoursePrerequisites(w http.ResponseWriter, r *http.Request) {
+	var body coursePrerequisitesBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
 	}
 
-	var course models.Course
-	result := c.App.DB.Model(&course).Where("id = ?", body.CourseID).Updates(updateData)
-	if result.Error != nil {
-		http.Error(w, "Error updating course", http.StatusInternalServerError)
+	user, ok := currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	prerequisites, err := c.Courses.UpdatePrerequisites(r.Context(), user.ID, services.PrerequisiteInput{
+		CourseID:        body.CourseID,
+		PrerequisiteIDs: body.PrerequisiteIDs,
+		Enforce:         body.Enforce,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if prerequisites == nil {
+		prerequisites = make([]models.CoursePrerequisite, 0)
+	}
+
+	writeJSON(w, http.StatusOK, prerequisites)
+}
+
+// GetCoursePrerequisites returns the prerequisite graph of a course, or of all the
+// published courses, with the recommended order of taking them.
+func (c *BaseController) GetCoursePrerequisites(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	courseID := query.ID("course_id")
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	graph, err := c.Courses.Prerequisites(r.Context(), courseID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, graph)
 }
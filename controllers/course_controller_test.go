@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/plaja-app/back-end/config"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services/memsvc"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestController creates a controller backed by the in-memory services.
+func newTestController(store *memsvc.Store) *BaseController {
+	app := &config.AppConfig{Env: &config.EnvVariables{PublicBaseURL: "http://plaja.test"}}
+	return NewBaseController(app, memsvc.New(store))
+}
+
+// asUser returns the request authenticated as the user, as done by the RequireAuth middleware.
+func asUser(r *http.Request, user models.User) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), "user", user))
+}
+
+func TestGetCoursesResolvesThumbnailURLs(t *testing.T) {
+	store := memsvc.NewStore()
+	store.Courses[1] = &models.Course{ID: 1, Title: "Go", Thumbnail: "courses/thumbnails/1-thumbnail.png"}
+	store.Courses[2] = &models.Course{ID: 2, Title: "Rust", Thumbnail: "https://cdn.plaja.test/rust.png"}
+
+	c := newTestController(store)
+
+	w := httptest.NewRecorder()
+	c.GetCourses(w, httptest.NewRequest(http.MethodGet, "/api/v1/courses?id=1,2&sort=id", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body)
+	}
+
+	var courses []models.Course
+	json.NewDecoder(w.Body).Decode(&courses)
+
+	want := []string{
+		"http://plaja.test/api/v1/storage/courses/thumbnails/1-thumbnail.png",
+		"https://cdn.plaja.test/rust.png",
+	}
+	if len(courses) != len(want) {
+		t.Fatalf("expected %d courses, got %d", len(want), len(courses))
+	}
+	for i, course := range courses {
+		if course.Thumbnail != want[i] {
+			t.Errorf("course %d: expected thumbnail %q, got %q", course.ID, want[i], course.Thumbnail)
+		}
+	}
+}
+
+func TestGetCoursesInvalidID(t *testing.T) {
+	c := newTestController(memsvc.NewStore())
+
+	w := httptest.NewRecorder()
+	c.GetCourses(w, httptest.NewRequest(http.MethodGet, "/api/v1/courses?id=abc", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestSaveExercisesOfForeignCourse(t *testing.T) {
+	store := memsvc.NewStore()
+	store.Users[1] = &models.User{ID: 1, UserTypeID: 2}
+	store.Users[2] = &models.User{ID: 2, UserTypeID: 2}
+	store.Courses[1] = &models.Course{ID: 1, Title: "Go", InstructorID: 1}
+
+	c := newTestController(store)
+
+	body, _ := json.Marshal(CourseExerciseInput{
+		CourseID:  1,
+		Exercises: []ExerciseInput{{Title: "Вступ", Content: "Привіт"}},
+	})
+
+	tests := []struct {
+		name   string
+		user   models.User
+		status int
+	}{
+		{"other instructor", *store.Users[2], http.StatusForbidden},
+		{"course instructor", *store.Users[1], http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/course-exercises/create-update", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+
+			c.CreateOrUpdateCourseExercises(w, asUser(r, tt.user))
+
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, w.Code, w.Body)
+			}
+		})
+	}
+
+	if len(store.Exercises) != 1 {
+		t.Fatalf("expected one exercise to be saved, got %d", len(store.Exercises))
+	}
+}
+
+func TestGetCoursesConvertsPrices(t *testing.T) {
+	store := memsvc.NewStore()
+	store.Courses[1] = &models.Course{ID: 1, Title: "Go", Price: 40000, Currency: "UAH"}
+	store.Rates = []models.ExchangeRate{{Currency: "USD", Base: "UAH", Rate: 0.025}}
+
+	c := newTestController(store)
+
+	tests := []struct {
+		query    string
+		status   int
+		price    uint
+		currency string
+	}{
+		{"", http.StatusOK, 40000, "UAH"},
+		{"&currency=usd", http.StatusOK, 1000, "USD"},
+		{"&currency=XXX", http.StatusBadRequest, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c.GetCourses(w, httptest.NewRequest(http.MethodGet, "/api/v1/courses?id=1"+tt.query, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, w.Code, w.Body)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var courses []models.Course
+			json.NewDecoder(w.Body).Decode(&courses)
+			if len(courses) != 1 || courses[0].FinalPrice == nil || *courses[0].FinalPrice != tt.price || courses[0].LocalCurrency != tt.currency {
+				t.Fatalf("expected the final price %d %s, got %+v", tt.price, tt.currency, courses)
+			}
+		})
+	}
+}
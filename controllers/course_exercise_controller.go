@@ -3,16 +3,13 @@ package controllers
 import (
 	"encoding/json"
 	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
 	"net/http"
-	"strconv"
-	"strings"
-	"time"
 )
 
 // CourseExerciseInput is the exercises input request body structure.
 type CourseExerciseInput struct {
 	Exercises         []ExerciseInput
-	InstructorID      uint
 	CourseID          uint
 	ExercisesToDelete []uint
 }
@@ -33,108 +30,66 @@ func (c *BaseController) CreateOrUpdateCourseExercises(w http.ResponseWriter, r
 		return
 	}
 
-	var course models.Course
-	if err := c.App.DB.First(&course, body.CourseID).Error; err != nil {
-		http.Error(w, "Course not found", http.StatusNotFound)
+	user, ok := currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	if course.InstructorID != body.InstructorID {
-		http.Error(w, "Instructor not authorized to add or update exercises in this course", http.StatusUnauthorized)
-		return
+	input := services.ExercisesUpdate{
+		CourseID:  body.CourseID,
+		DeleteIDs: body.ExercisesToDelete,
 	}
-
 	for _, ex := range body.Exercises {
-		if ex.ID != 0 {
-			var existingExercise models.CourseExercise
-			if err := c.App.DB.First(&existingExercise, "id = ?", ex.ID).Error; err != nil {
-				http.Error(w, "Exercise not found", http.StatusNotFound)
-				return
-			}
-
-			existingExercise.Title = ex.Title
-			existingExercise.Content = ex.Content
-			existingExercise.Length = calculateExerciseLength(ex.Content)
-			existingExercise.UpdatedAt = time.Now()
-
-			if err := c.App.DB.Save(&existingExercise).Error; err != nil {
-				http.Error(w, "Failed to update exercise", http.StatusInternalServerError)
-				return
-			}
-		} else {
-			newExercise := models.CourseExercise{
-				CourseID:  body.CourseID,
-				TypeID:    1, // Assuming TypeID is a fixed value as per your original logic
-				Length:    calculateExerciseLength(ex.Content),
-				Title:     ex.Title,
-				Content:   ex.Content,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
-			}
-
-			if err := c.App.DB.Create(&newExercise).Error; err != nil {
-				http.Error(w, "Failed to add exercise", http.StatusInternalServerError)
-				return
-			}
-		}
-	}
-
-	if len(body.ExercisesToDelete) > 0 {
-		c.App.DB.Where("id IN ?", body.ExercisesToDelete).Delete(&models.CourseExercise{})
+		input.Exercises = append(input.Exercises, services.ExerciseInput{ID: ex.ID, Title: ex.Title, Content: ex.Content})
 	}
 
-	var totalCourseLength uint
-	if err := c.App.DB.Model(&models.CourseExercise{}).Where("course_id = ?", course.ID).Select("sum(length)").Row().Scan(&totalCourseLength); err != nil {
-		http.Error(w, "Failed to calculate total course length", http.StatusInternalServerError)
-		return
-	}
-
-	course.Length = totalCourseLength
-	if err := c.App.DB.Save(&course).Error; err != nil {
-		http.Error(w, "Failed to update course length", http.StatusInternalServerError)
+	if err := c.Courses.SaveExercises(r.Context(), user.ID, input); err != nil {
+		writeError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
 }
 
-// GetCourseExercises returns the queried list of models.CourseExercise.
+// GetCourseExercises returns the queried list of models.CourseExercise. The content is
+// left out unless the current user has access to the course.
 func (c *BaseController) GetCourseExercises(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query()
-	exerciseID := query.Get("exercise_id")
-	courseID := query.Get("course_id")
+	query := newQueryParser(r)
 
-	w.Header().Set("Content-Type", "application/json")
+	courseID := query.ID("course_id")
+	ids := query.IDs("exercise_id")
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
 
-	// Convert courseID to int
-	courseIDInt, err := strconv.Atoi(courseID)
-	if err != nil {
+	if courseID == 0 {
 		http.Error(w, "Invalid course ID format", http.StatusBadRequest)
 		return
 	}
 
-	var data []models.CourseExercise
-
-	if exerciseID == "all" {
-		c.App.DB.Order("id").Where("course_id = ?", courseIDInt).Find(&data)
-	} else {
-		ids := strings.Split(exerciseID, ",")
-		var intIDs []int
-		for _, idStr := range ids {
-			id, err := strconv.Atoi(idStr)
-			if err != nil {
-				http.Error(w, "Invalid exercise ID format", http.StatusBadRequest)
-				return
-			}
-			intIDs = append(intIDs, id)
+	data, err := c.Courses.Exercises(r.Context(), courseID, ids)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, _ := r.Context().Value("user").(models.User)
+	access, err := c.Enrollments.Access(r.Context(), user.ID, courseID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if !access {
+		for i := range data {
+			data[i].Content = ""
 		}
-		c.App.DB.Order("id").Where("id IN ? AND course_id = ?", intIDs, courseIDInt).Find(&data)
 	}
 
-	if len(data) == 0 {
+	if data == nil {
 		data = make([]models.CourseExercise, 0)
-		w.WriteHeader(http.StatusOK)
 	}
 
-	json.NewEncoder(w).Encode(data)
+	writeJSON(w, http.StatusOK, data)
 }
@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"net/http"
+)
+
+// courseReviewBody is the course review request body structure.
+type courseReviewBody struct {
+	CourseID uint
+	Rating   uint
+	Text     string
+}
+
+// courseReviewReplyBody is the instructor reply request body structure.
+type courseReviewReplyBody struct {
+	ReviewID uint
+	Reply    string
+}
+
+// courseReviewVoteBody is the helpful vote request body structure.
+type courseReviewVoteBody struct {
+	ReviewID uint
+	Helpful  bool
+}
+
+// courseReviewModerationBody is the review moderation request body structure.
+type courseReviewModerationBody struct {
+	ReviewID uint
+	Flagged  bool
+	Hidden   bool
+}
+
+// reviewFilter parses the review filter query parameters.
+func reviewFilter(query *queryParser) services.ReviewFilter {
+	return services.ReviewFilter{
+		IDs:      query.IDs("id"),
+		CourseID: query.ID("course_id"),
+		UserID:   query.ID("user_id"),
+		Ratings:  query.IDs("rating"),
+		Flagged:  query.Bool("flagged"),
+		Hidden:   query.Bool("hidden"),
+	}
+}
+
+// resolveReviewURLs replaces the storage-relative paths of the reviews' users with public URLs.
+func (c *BaseController) resolveReviewURLs(reviews []models.CourseReview) {
+	for i := range reviews {
+		c.resolveUserURLs(&reviews[i].User)
+	}
+}
+
+// GetCourseReviews returns the queried page of the visible models.CourseReview.
+func (c *BaseController) GetCourseReviews(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	filter := reviewFilter(query)
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	reviews, info, err := c.Reviews.List(r.Context(), filter, page)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	c.resolveReviewURLs(reviews)
+	writeList(w, reviews, info, query.Fields())
+}
+
+// GetModeratedCourseReviews returns the queried page of models.CourseReview, hidden ones
+// included, to the admins.
+func (c *BaseController) GetModeratedCourseReviews(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	filter := reviewFilter(query)
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	reviews, info, err := c.Reviews.Moderation(r.Context(), user.ID, filter, page)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	c.resolveReviewURLs(reviews)
+	writeList(w, reviews, info, query.Fields())
+}
+
+// CreateCourseReview creates the models.CourseReview of the current user.
+func (c *BaseController) CreateCourseReview(w http.ResponseWriter, r *http.Request) {
+	var body courseReviewBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	review, err := c.Reviews.Create(r.Context(), user.ID, services.ReviewInput{
+		CourseID: body.CourseID,
+		Rating:   body.Rating,
+		Text:     body.Text,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, review)
+}
+
+// UpdateCourseReview updates the models.CourseReview of the current user.
+func (c *BaseController) UpdateCourseReview(w http.ResponseWriter, r *http.Request) {
+	var body courseReviewBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	err = c.Reviews.Update(r.Context(), user.ID, services.ReviewInput{
+		CourseID: body.CourseID,
+		Rating:   body.Rating,
+		Text:     body.Text,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ReplyCourseReview sets the reply of the current user, the course instructor, to a models.CourseReview.
+func (c *BaseController) ReplyCourseReview(w http.ResponseWriter, r *http.Request) {
+	var body courseReviewReplyBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.Reviews.Reply(r.Context(), user.ID, body.ReviewID, body.Reply); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// VoteCourseReview adds or removes the helpful vote of the current user for a models.CourseReview.
+func (c *BaseController) VoteCourseReview(w http.ResponseWriter, r *http.Request) {
+	var body courseReviewVoteBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.Reviews.Vote(r.Context(), user.ID, body.ReviewID, body.Helpful); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ModerateCourseReview flags or hides a models.CourseReview. Only admins may moderate the reviews.
+func (c *BaseController) ModerateCourseReview(w http.ResponseWriter, r *http.Request) {
+	var body courseReviewModerationBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	err = c.Reviews.Moderate(r.Context(), user.ID, services.ReviewModeration{
+		ReviewID: body.ReviewID,
+		Flagged:  body.Flagged,
+		Hidden:   body.Hidden,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"net/http"
+)
+
+// GetExchangeRates returns the current models.ExchangeRate.
+func (c *BaseController) GetExchangeRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := c.Currencies.Rates(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if rates == nil {
+		rates = make([]models.ExchangeRate, 0)
+	}
+
+	writeJSON(w, http.StatusOK, rates)
+}
+
+// ImportExchangeRates replaces the exchange rates with the uploaded CSV rate table of the
+// currencies against the Base currency, the payment currency by default. Only the admins
+// may import the rates.
+func (c *BaseController) ImportExchangeRates(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseMultipartForm(1 << 20) // 1 MB
+	if err != nil {
+		http.Error(w, "Failed to parse multipart form", http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("File")
+	if err != nil {
+		http.Error(w, "Missing rate table", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	base := r.FormValue("Base")
+	if base == "" {
+		base = c.App.Env.PaymentCurrency
+	}
+
+	input, err := services.ParseRates(file)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	rates, err := c.Currencies.ImportRates(r.Context(), user.ID, base, input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rates)
+}
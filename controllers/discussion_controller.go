@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"net/http"
+)
+
+// discussionBody is the new discussion request body structure.
+type discussionBody struct {
+	CourseID   uint
+	ExerciseID uint
+	Title      string
+	Content    string
+}
+
+// discussionPostBody is the new discussion post request body structure.
+type discussionPostBody struct {
+	DiscussionID uint
+	ParentID     uint
+	Content      string
+}
+
+// discussionVoteBody is the discussion or post upvote request body structure.
+type discussionVoteBody struct {
+	DiscussionID uint
+	PostID       uint
+	Up           bool
+}
+
+// discussionAcceptBody is the accepted answer request body structure.
+type discussionAcceptBody struct {
+	PostID   uint
+	Accepted bool
+}
+
+// GetDiscussions returns the queried page of the models.Discussion of a course.
+func (c *BaseController) GetDiscussions(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	filter := services.DiscussionFilter{
+		CourseID:   query.ID("course_id"),
+		ExerciseID: query.ID("exercise_id"),
+		UserID:     query.ID("user_id"),
+		Answered:   query.Bool("answered"),
+		Accepted:   query.Bool("accepted"),
+	}
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	discussions, info, err := c.Discussions.List(r.Context(), user.ID, filter, page)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	for i := range discussions {
+		c.resolveUserURLs(&discussions[i].User)
+	}
+	writeList(w, discussions, info, query.Fields())
+}
+
+// GetDiscussion returns the queried models.Discussion with its posts.
+func (c *BaseController) GetDiscussion(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	id := query.ID("id")
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	discussion, err := c.Discussions.Get(r.Context(), user.ID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	c.resolveDiscussionURLs(&discussion)
+	writeJSON(w, http.StatusOK, discussion)
+}
+
+// CreateDiscussion creates the models.Discussion of the current user.
+func (c *BaseController) CreateDiscussion(w http.ResponseWriter, r *http.Request) {
+	var body discussionBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	discussion, err := c.Discussions.Create(r.Context(), user.ID, services.DiscussionInput{
+		CourseID:   body.CourseID,
+		ExerciseID: body.ExerciseID,
+		Title:      body.Title,
+		Content:    body.Content,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, discussion)
+}
+
+// CreateDiscussionPost creates the models.DiscussionPost of the current user.
+func (c *BaseController) CreateDiscussionPost(w http.ResponseWriter, r *http.Request) {
+	var body discussionPostBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	post, err := c.Discussions.Post(r.Context(), user.ID, services.PostInput{
+		DiscussionID: body.DiscussionID,
+		ParentID:     body.ParentID,
+		Content:      body.Content,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, post)
+}
+
+// VoteDiscussion adds or removes the upvote of the current user for a models.Discussion or
+// one of its posts.
+func (c *BaseController) VoteDiscussion(w http.ResponseWriter, r *http.Request) {
+	var body discussionVoteBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	err = c.Discussions.Vote(r.Context(), user.ID, services.VoteInput{
+		DiscussionID: body.DiscussionID,
+		PostID:       body.PostID,
+		Up:           body.Up,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// AcceptDiscussionPost marks or unmarks the models.DiscussionPost as the accepted answer of
+// its discussion.
+func (c *BaseController) AcceptDiscussionPost(w http.ResponseWriter, r *http.Request) {
+	var body discussionAcceptBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.Discussions.Accept(r.Context(), user.ID, body.PostID, body.Accepted); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// resolveDiscussionURLs replaces the storage-relative paths of the users of the discussion
+// and its posts with public URLs.
+func (c *BaseController) resolveDiscussionURLs(discussion *models.Discussion) {
+	c.resolveUserURLs(&discussion.User)
+	for i := range discussion.Posts {
+		c.resolveUserURLs(&discussion.Posts[i].User)
+	}
+}
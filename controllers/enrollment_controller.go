@@ -2,12 +2,8 @@ package controllers
 
 import (
 	"encoding/json"
-	"errors"
-	"github.com/plaja-app/back-end/models"
-	"gorm.io/gorm"
+	"github.com/plaja-app/back-end/services"
 	"net/http"
-	"strconv"
-	"strings"
 )
 
 // enrollmentBody is the course enrollment request body structure.
@@ -15,61 +11,32 @@ type enrollmentBody struct {
 	CourseID uint
 }
 
-// GetEnrollments returns the queried list of models.Enrollment.
+// GetEnrollments returns the queried page of models.Enrollment.
 func (c *BaseController) GetEnrollments(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query()
-	userID := query.Get("user_id")
-	courseID := query.Get("course_id")
-	statusID := query.Get("status_id")
-
-	w.Header().Set("Content-Type", "application/json")
-
-	var enrollments []models.Enrollment
-	dbQuery := c.App.DB
-
-	if userID != "" {
-		ids := strings.Split(userID, ",")
-		var intIds []int
-		for _, idStr := range ids {
-			intId, err := strconv.Atoi(idStr)
-			if err != nil {
-				http.Error(w, "Invalid ID format", http.StatusBadRequest)
-				return
-			}
-			intIds = append(intIds, intId)
-		}
-		dbQuery = dbQuery.Where("user_id IN ?", intIds)
-	}
+	query := newQueryParser(r)
 
-	if statusID != "" {
-		ids := strings.Split(statusID, ",")
-		var intIds []int
-		for _, idStr := range ids {
-			intId, err := strconv.Atoi(idStr)
-			if err != nil {
-				http.Error(w, "Invalid ID format", http.StatusBadRequest)
-				return
-			}
-			intIds = append(intIds, intId)
-		}
-		dbQuery = dbQuery.Where("status_id IN ?", intIds)
+	filter := services.EnrollmentFilter{
+		UserIDs:   query.IDs("user_id"),
+		StatusIDs: query.IDs("status_id"),
+		CourseID:  query.ID("course_id"),
 	}
-
-	if courseID != "" {
-		dbQuery = dbQuery.Where("course_id = ?", courseID)
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
 	}
 
-	if err := dbQuery.Find(&enrollments).Error; err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+	enrollments, info, err := c.Enrollments.List(r.Context(), filter, page)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(enrollments)
+	writeList(w, enrollments, info, query.Fields())
 }
 
-// CreateEnrollment creates a new models.Enrollment.
+// CreateEnrollment creates a new models.Enrollment and returns the incomplete prerequisites
+// of the course.
 func (c *BaseController) CreateEnrollment(w http.ResponseWriter, r *http.Request) {
 	var body enrollmentBody
 
@@ -80,32 +47,71 @@ func (c *BaseController) CreateEnrollment(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	userCtx := r.Context().Value("user")
-	if userCtx == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := c.Enrollments.Enroll(r.Context(), user.ID, body.CourseID); err != nil {
+		writeError(w, err)
 		return
 	}
 
-	user, ok := userCtx.(models.User)
+	// the incomplete prerequisites that do not block the enrollment are warned about
+	check, err := c.Enrollments.CheckPrerequisites(r.Context(), user.ID, body.CourseID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, check)
+}
+
+// GetEnrollmentPrerequisites returns the prerequisites of a course the current user has
+// not completed yet.
+func (c *BaseController) GetEnrollmentPrerequisites(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	courseID := query.ID("course_id")
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
 	if !ok {
-		http.Error(w, "Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	var enrollment models.Enrollment
-	enrollment = models.Enrollment{
-		UserID:         user.ID,
-		CourseID:       body.CourseID,
-		StatusID:       1, // enrolled
-		Progress:       0,
-		LastExerciseID: 1,
+	check, err := c.Enrollments.CheckPrerequisites(r.Context(), user.ID, courseID)
+	if err != nil {
+		writeError(w, err)
+		return
 	}
 
-	result := c.App.DB.Create(&enrollment)
-	if result.Error != nil {
-		http.Error(w, "Error creating enrollment", http.StatusBadRequest)
+	writeJSON(w, http.StatusOK, check)
+}
+
+// GetCourseAccess returns whether the current user has access to the content of a course.
+func (c *BaseController) GetCourseAccess(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	courseID := query.ID("course_id")
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	access, err := c.Enrollments.Access(r.Context(), user.ID, courseID)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, http.StatusOK, map[string]any{"CourseID": courseID, "Access": access})
 }
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"github.com/plaja-app/back-end/services/memsvc"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCreateEnrollment(t *testing.T) {
+	store := memsvc.NewStore()
+	store.Users[1] = &models.User{ID: 1, UserTypeID: services.UserTypeLearner}
+	store.Courses[1] = &models.Course{ID: 1, Title: "Go", StatusID: services.CourseStatusPublished}
+	store.Courses[2] = &models.Course{ID: 2, Title: "Rust", StatusID: services.CourseStatusPublished, Price: 39900}
+	store.Courses[3] = &models.Course{ID: 3, Title: "Go Pro", StatusID: services.CourseStatusPublished}
+	store.Courses[4] = &models.Course{ID: 4, Title: "Go Expert", StatusID: services.CourseStatusPublished, EnforcePrerequisites: true}
+	store.Prerequisites = []models.CoursePrerequisite{{CourseID: 3, PrerequisiteID: 1}, {CourseID: 4, PrerequisiteID: 3}}
+
+	c := newTestController(store)
+
+	tests := []struct {
+		name     string
+		courseID uint
+		status   int
+		missing  int
+	}{
+		{"free course", 1, http.StatusCreated, 0},
+		{"enrolled twice", 1, http.StatusBadRequest, 0},
+		{"paid course", 2, http.StatusForbidden, 0},
+		{"unknown course", 9, http.StatusNotFound, 0},
+		{"warned prerequisites", 3, http.StatusCreated, 1},
+		{"enforced prerequisites", 4, http.StatusForbidden, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(enrollmentBody{CourseID: tt.courseID})
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/enrollments/create", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+
+			c.CreateEnrollment(w, asUser(r, *store.Users[1]))
+
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, w.Code, w.Body)
+			}
+			if w.Code != http.StatusCreated {
+				return
+			}
+
+			var check services.PrerequisiteCheck
+			json.NewDecoder(w.Body).Decode(&check)
+			if len(check.Missing) != tt.missing {
+				t.Errorf("expected %d missing prerequisites, got %+v", tt.missing, check.Missing)
+			}
+		})
+	}
+
+	if len(store.Enrollments) != 2 {
+		t.Fatalf("expected two enrollments, got %d", len(store.Enrollments))
+	}
+}
+
+func TestGetCourseAccess(t *testing.T) {
+	store := memsvc.NewStore()
+	store.Users[1] = &models.User{ID: 1, UserTypeID: services.UserTypeLearner}
+	store.Users[2] = &models.User{ID: 2, UserTypeID: services.UserTypeLearner}
+	store.Users[3] = &models.User{ID: 3, UserTypeID: services.UserTypeAdmin}
+	store.Courses[1] = &models.Course{ID: 1, Title: "Rust", StatusID: services.CourseStatusPublished, Price: 39900}
+
+	end := time.Now().Add(24 * time.Hour)
+	store.Subscriptions = []*models.Subscription{{ID: 1, UserID: 2, Status: services.SubscriptionStatusActive, CurrentPeriodEnd: &end}}
+
+	c := newTestController(store)
+
+	access := func(userID uint) bool {
+		t.Helper()
+
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/enrollments/access?course_id=1", nil)
+		w := httptest.NewRecorder()
+		c.GetCourseAccess(w, asUser(r, *store.Users[userID]))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body)
+		}
+
+		var body struct{ Access bool }
+		json.NewDecoder(w.Body).Decode(&body)
+		return body.Access
+	}
+
+	if access(1) || access(2) || !access(3) {
+		t.Fatal("expected only the admin to have access before enrolling")
+	}
+
+	// the subscriber enrolls into the paid course through the subscription
+	body, _ := json.Marshal(enrollmentBody{CourseID: 1})
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/enrollments/create", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	c.CreateEnrollment(w, asUser(r, *store.Users[2]))
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body)
+	}
+	if len(store.Enrollments) != 1 || store.Enrollments[0].SubscriptionID == nil {
+		t.Fatalf("expected the enrollment through the subscription, got %+v", store.Enrollments)
+	}
+
+	if !access(2) {
+		t.Fatal("expected the subscriber to have access")
+	}
+
+	// the access ends with the subscription
+	expired := time.Now().Add(-30 * 24 * time.Hour)
+	store.Subscriptions[0].CurrentPeriodEnd = &expired
+	if access(2) {
+		t.Fatal("expected the access to end with the subscription")
+	}
+
+	w = httptest.NewRecorder()
+	c.GetEnrollments(w, httptest.NewRequest(http.MethodGet, "/api/v1/enrollments?user_id=2", nil))
+
+	var enrollments []models.Enrollment
+	json.NewDecoder(w.Body).Decode(&enrollments)
+	if w.Code != http.StatusOK || len(enrollments) != 1 || enrollments[0].CourseID != 1 {
+		t.Fatalf("unexpected enrollments %d %+v", w.Code, enrollments)
+	}
+}
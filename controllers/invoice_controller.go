@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/plaja-app/back-end/services"
+	"io"
+	"net/http"
+)
+
+// billingBody is the billing profile update request body structure.
+type billingBody struct {
+	Company string
+	TaxID   string
+	Address string
+	Country string
+}
+
+// GetInvoices returns the queried page of models.Invoice of the current user, or of any
+// user for the admins.
+func (c *BaseController) GetInvoices(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	filter := services.InvoiceFilter{
+		UserID:  query.ID("user_id"),
+		OrderID: query.ID("order_id"),
+		Kinds:   query.List("kind"),
+	}
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	invoices, info, err := c.Invoices.List(r.Context(), user.ID, filter, page)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, invoices, info, query.Fields())
+}
+
+// GetInvoiceDocument downloads the PDF document of the models.Invoice of the current user,
+// or of any user for the admins.
+func (c *BaseController) GetInvoiceDocument(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	invoiceID := query.ID("id")
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	invoice, document, err := c.Invoices.Document(r.Context(), user.ID, invoiceID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer document.Close()
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", invoice.Number+".pdf"))
+	io.Copy(w, document)
+}
+
+// GetBillingProfile returns the models.BillingProfile of the current user.
+func (c *BaseController) GetBillingProfile(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := c.Invoices.BillingProfile(r.Context(), user.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, profile)
+}
+
+// UpdateBillingProfile sets the models.BillingProfile of the current user printed on the
+// next invoices.
+func (c *BaseController) UpdateBillingProfile(w http.ResponseWriter, r *http.Request) {
+	var body billingBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := c.Invoices.UpdateBillingProfile(r.Context(), user.ID, services.BillingInput{
+		Company: body.Company,
+		TaxID:   body.TaxID,
+		Address: body.Address,
+		Country: body.Country,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, profile)
+}
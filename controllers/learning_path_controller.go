@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"net/http"
+)
+
+// learningPathBody is the learning path creation and update request body structure.
+type learningPathBody struct {
+	ID          uint
+	Title       string
+	Description string
+	CourseIDs   []uint
+	Price       uint
+	Currency    string
+}
+
+// pathBody is the learning path enrollment, checkout and certificate request body structure.
+type pathBody struct {
+	PathID   uint
+	Currency string
+}
+
+// GetLearningPaths returns the queried page of models.LearningPath with their courses.
+func (c *BaseController) GetLearningPaths(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	ids := query.IDs("id")
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	paths, info, err := c.Paths.List(r.Context(), ids, page)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	for i := range paths {
+		c.resolvePathURLs(&paths[i])
+	}
+
+	writeList(w, paths, info, query.Fields())
+}
+
+// CreateLearningPath creates a new models.LearningPath of the published courses.
+func (c *BaseController) CreateLearningPath(w http.ResponseWriter, r *http.Request) {
+	var body learningPathBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	path, err := c.Paths.Create(r.Context(), user.ID, body.input())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	c.resolvePathURLs(&path)
+	writeJSON(w, http.StatusCreated, path)
+}
+
+// UpdateLearningPath replaces the details and the courses of a models.LearningPath.
+func (c *BaseController) UpdateLearningPath(w http.ResponseWriter, r *http.Request) {
+	var body learningPathBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	path, err := c.Paths.Update(r.Context(), user.ID, body.ID, body.input())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	c.resolvePathURLs(&path)
+	writeJSON(w, http.StatusOK, path)
+}
+
+// EnrollLearningPath enrolls the current user into a learning path and its free courses.
+func (c *BaseController) EnrollLearningPath(w http.ResponseWriter, r *http.Request) {
+	var body pathBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.Paths.Enroll(r.Context(), user.ID, body.PathID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// CheckoutLearningPath creates the models.Order of the bundle of a learning path and
+// returns the checkout page of the payment provider to redirect to.
+func (c *BaseController) CheckoutLearningPath(w http.ResponseWriter, r *http.Request) {
+	var body pathBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := c.Paths.Checkout(r.Context(), user.ID, body.PathID, body.Currency)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, session)
+}
+
+// GetLearningPathProgress returns the progress of the current user in a learning path.
+func (c *BaseController) GetLearningPathProgress(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	pathID := query.ID("path_id")
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	progress, err := c.Paths.Progress(r.Context(), user.ID, pathID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, progress)
+}
+
+// CreateLearningPathCertificate creates a new models.PathCertificate for the completed
+// learning path of the current user and renders the certificate image.
+func (c *BaseController) CreateLearningPathCertificate(w http.ResponseWriter, r *http.Request) {
+	var body pathBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	certificate, err := c.Paths.IssueCertificate(r.Context(), user.ID, body.PathID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, certificate)
+}
+
+// input returns the learning path input of the request body.
+func (b learningPathBody) input() services.PathInput {
+	return services.PathInput{
+		Title:       b.Title,
+		Description: b.Description,
+		CourseIDs:   b.CourseIDs,
+		Price:       b.Price,
+		Currency:    b.Currency,
+	}
+}
+
+// resolvePathURLs replaces the storage-relative paths of the courses of the learning path with public URLs.
+func (c *BaseController) resolvePathURLs(path *models.LearningPath) {
+	for i := range path.Courses {
+		path.Courses[i].Course.Thumbnail = c.App.StorageURL(path.Courses[i].Course.Thumbnail)
+	}
+}
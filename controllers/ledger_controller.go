@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/plaja-app/back-end/services"
+	"github.com/plaja-app/back-end/statements"
+	"net/http"
+	"time"
+)
+
+// payoutBody is the payout request body structure.
+type payoutBody struct {
+	InstructorID uint
+	Amount       uint
+	Currency     string
+	Reference    string
+}
+
+// GetLedger returns the queried page of the models.LedgerTransaction of the current
+// instructor, or of any instructor for the admins.
+func (c *BaseController) GetLedger(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	filter := services.LedgerFilter{
+		InstructorID: query.ID("instructor_id"),
+		Kinds:        query.List("kind"),
+		From:         query.Time("from", time.DateOnly),
+		To:           query.Time("to", time.DateOnly),
+	}
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	transactions, info, err := c.Ledger.Transactions(r.Context(), user.ID, filter, page)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, transactions, info, query.Fields())
+}
+
+// GetEarnings returns the balance and the period breakdown of the earnings of the current
+// instructor, or of any instructor for the admins. The range defaults to the last 12
+// months, the period to a month.
+func (c *BaseController) GetEarnings(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	filter := services.EarningsFilter{
+		InstructorID: query.ID("instructor_id"),
+		Period:       query.String("period"),
+	}
+	from := query.Time("from", time.DateOnly)
+	to := query.Time("to", time.DateOnly)
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	filter.To = time.Now()
+	if to != nil {
+		filter.To = *to
+	}
+
+	filter.From = services.PeriodStart(filter.To, services.PeriodMonth).AddDate(0, -11, 0)
+	if from != nil {
+		filter.From = *from
+	}
+
+	if filter.Period == "" {
+		filter.Period = services.PeriodMonth
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	earnings, err := c.Ledger.Earnings(r.Context(), user.ID, filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, earnings)
+}
+
+// GetStatement returns the payout statement of the current instructor, or of any
+// instructor for the admins, for the month (e.g. "2024-03", the current one by default)
+// as JSON, CSV or PDF.
+func (c *BaseController) GetStatement(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	instructorID := query.ID("instructor_id")
+	month := query.Time("month", "2006-01")
+	format := query.String("format")
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if month == nil {
+		now := time.Now()
+		month = &now
+	}
+
+	switch format {
+	case "", "json", "csv", "pdf":
+	default:
+		writeError(w, services.Errorf(services.ErrInvalidInput, "unknown statement format %q", format))
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if instructorID == 0 {
+		instructorID = user.ID
+	}
+
+	statement, err := c.Ledger.Statement(r.Context(), user.ID, instructorID, *month)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if format == "" || format == "json" {
+		writeJSON(w, http.StatusOK, statement)
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := "text/csv; charset=utf-8"
+	if format == "csv" {
+		err = statements.WriteCSV(&buf, statement)
+	} else {
+		contentType = "application/pdf"
+		err = statements.NewRenderer(c.App.Env.StorageRoot).WritePDF(&buf, statement)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%d-%s.%s", instructorID, statement.Month.Format("2006-01"), format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	buf.WriteTo(w)
+}
+
+// CreatePayout records the payout of the earnings of an instructor. Only the admins may
+// pay out.
+func (c *BaseController) CreatePayout(w http.ResponseWriter, r *http.Request) {
+	var body payoutBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	transaction, err := c.Ledger.Payout(r.Context(), user.ID, services.PayoutInput{
+		InstructorID: body.InstructorID,
+		Amount:       body.Amount,
+		Currency:     body.Currency,
+		Reference:    body.Reference,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, transaction)
+}
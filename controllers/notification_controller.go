@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/plaja-app/back-end/services"
+	"net/http"
+)
+
+// notificationReadBody is the request body structure marking the notifications as read.
+type notificationReadBody struct {
+	IDs []uint
+}
+
+// GetNotifications returns the queried page of the models.Notification of the current user.
+func (c *BaseController) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	var filter services.NotificationFilter
+	if unread := query.Bool("unread"); unread != nil {
+		filter.Unread = *unread
+	}
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	notifications, info, err := c.Notifications.List(r.Context(), user.ID, filter, page)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, notifications, info, query.Fields())
+}
+
+// MarkNotificationsRead marks the listed models.Notification of the current user, or all
+// of them if none are listed, as read.
+func (c *BaseController) MarkNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	var body notificationReadBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.Notifications.MarkRead(r.Context(), user.ID, body.IDs); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/plaja-app/back-end/services"
+	"io"
+	"net/http"
+)
+
+// maxWebhookSize is the maximum size of the payment webhook bodies.
+const maxWebhookSize = 1 << 20
+
+// checkoutBody is the checkout request body structure.
+type checkoutBody struct {
+	CourseID   uint
+	CouponCode string
+	Currency   string
+}
+
+// GetOrders returns the queried page of the models.Order of the current user.
+func (c *BaseController) GetOrders(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	filter := services.OrderFilter{
+		CourseID: query.ID("course_id"),
+		Statuses: query.List("status"),
+	}
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	filter.UserID = user.ID
+
+	orders, info, err := c.Orders.List(r.Context(), filter, page)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, orders, info, query.Fields())
+}
+
+// Checkout creates the models.Order of a paid course for the current user and returns the
+// checkout page of the payment provider to redirect to.
+func (c *BaseController) Checkout(w http.ResponseWriter, r *http.Request) {
+	var body checkoutBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := c.Orders.Checkout(r.Context(), user.ID, body.CourseID, body.CouponCode, body.Currency)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, session)
+}
+
+// PaymentWebhook applies the payment webhook of the payment provider.
+func (c *BaseController) PaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookSize))
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.Orders.HandleWebhook(r.Context(), r.Header, body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,357 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"net/http"
+	"time"
+)
+
+// organizationBody is the organization creation request body structure.
+type organizationBody struct {
+	Name string
+}
+
+// memberBody is the organization member request body structure.
+type memberBody struct {
+	OrganizationID uint
+	UserID         uint
+	Email          string
+	Role           string
+}
+
+// seatsBody is the seat purchase request body structure.
+type seatsBody struct {
+	OrganizationID uint
+	CourseID       uint
+	Seats          uint
+	Currency       string
+}
+
+// assignmentBody is the course assignment request body structure.
+type assignmentBody struct {
+	OrganizationID uint
+	CourseID       uint
+	UserIDs        []uint
+	UserID         uint
+	DueAt          *time.Time
+}
+
+// GetOrganizations returns the models.Organization the current user is a member of.
+func (c *BaseController) GetOrganizations(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	organizations, err := c.Organizations.List(r.Context(), user.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if organizations == nil {
+		organizations = make([]models.Organization, 0)
+	}
+
+	writeJSON(w, http.StatusOK, organizations)
+}
+
+// CreateOrganization creates a new models.Organization owned by the current user.
+func (c *BaseController) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	var body organizationBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	organization, err := c.Organizations.Create(r.Context(), user.ID, services.OrganizationInput{Name: body.Name})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, organization)
+}
+
+// GetOrganizationMembers returns the queried page of the models.OrganizationMember of an
+// organization of the current user.
+func (c *BaseController) GetOrganizationMembers(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	organizationID := query.ID("organization_id")
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	members, info, err := c.Organizations.Members(r.Context(), user.ID, organizationID, page)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	for i := range members {
+		c.resolveUserURLs(&members[i].User)
+	}
+
+	writeList(w, members, info, query.Fields())
+}
+
+// AddOrganizationMember adds the registered user with the email to an organization.
+func (c *BaseController) AddOrganizationMember(w http.ResponseWriter, r *http.Request) {
+	var body memberBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	member, err := c.Organizations.AddMember(r.Context(), user.ID, services.MemberInput{
+		OrganizationID: body.OrganizationID,
+		Email:          body.Email,
+		Role:           body.Role,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	c.resolveUserURLs(&member.User)
+	writeJSON(w, http.StatusCreated, member)
+}
+
+// UpdateOrganizationMember changes the role of a member of an organization.
+func (c *BaseController) UpdateOrganizationMember(w http.ResponseWriter, r *http.Request) {
+	var body memberBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	err = c.Organizations.UpdateMember(r.Context(), user.ID, services.MemberUpdate{
+		OrganizationID: body.OrganizationID,
+		UserID:         body.UserID,
+		Role:           body.Role,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// RemoveOrganizationMember removes a member from an organization.
+func (c *BaseController) RemoveOrganizationMember(w http.ResponseWriter, r *http.Request) {
+	var body memberBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.Organizations.RemoveMember(r.Context(), user.ID, body.OrganizationID, body.UserID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// BuySeats creates the models.Order of the seats of a course for an organization and
+// returns the checkout page of the payment provider to redirect to.
+func (c *BaseController) BuySeats(w http.ResponseWriter, r *http.Request) {
+	var body seatsBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := c.Organizations.BuySeats(r.Context(), user.ID, services.SeatPurchase{
+		OrganizationID: body.OrganizationID,
+		CourseID:       body.CourseID,
+		Seats:          body.Seats,
+		Currency:       body.Currency,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, session)
+}
+
+// JoinOrganization adds the current user to the organization they were invited to by an
+// import with the token of the join link.
+func (c *BaseController) JoinOrganization(w http.ResponseWriter, r *http.Request) {
+	var body invitationBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	member, err := c.Organizations.Join(r.Context(), user.ID, body.Token)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, member)
+}
+
+// GetOrganizationDashboard returns the seats of an organization and the progress of its
+// members in the assigned courses.
+func (c *BaseController) GetOrganizationDashboard(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	organizationID := query.ID("organization_id")
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	dashboard, err := c.Organizations.Dashboard(r.Context(), user.ID, organizationID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if dashboard.Licenses == nil {
+		dashboard.Licenses = make([]models.SeatLicense, 0)
+	}
+
+	writeJSON(w, http.StatusOK, dashboard)
+}
+
+// GetCourseAssignments returns the models.CourseAssignment of the current user.
+func (c *BaseController) GetCourseAssignments(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	assignments, err := c.Organizations.Assignments(r.Context(), user.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if assignments == nil {
+		assignments = make([]models.CourseAssignment, 0)
+	}
+
+	writeJSON(w, http.StatusOK, assignments)
+}
+
+// AssignCourse assigns a course to the members of an organization.
+func (c *BaseController) AssignCourse(w http.ResponseWriter, r *http.Request) {
+	var body assignmentBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	assignments, err := c.Organizations.Assign(r.Context(), user.ID, services.AssignmentInput{
+		OrganizationID: body.OrganizationID,
+		CourseID:       body.CourseID,
+		UserIDs:        body.UserIDs,
+		DueAt:          body.DueAt,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, assignments)
+}
+
+// UnassignCourse ends the assignment of a course to a member of an organization.
+func (c *BaseController) UnassignCourse(w http.ResponseWriter, r *http.Request) {
+	var body assignmentBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.Organizations.Unassign(r.Context(), user.ID, body.OrganizationID, body.CourseID, body.UserID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"github.com/plaja-app/back-end/services"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// queryParser parses the URL query parameters of a request. The first parsing error is
+// kept and returned by Err, so the parameters can be read without checking each one.
+type queryParser struct {
+	values url.Values
+	err    error
+}
+
+// newQueryParser creates a new queryParser for the request.
+func newQueryParser(r *http.Request) *queryParser {
+	return &queryParser{values: r.URL.Query()}
+}
+
+// newFormParser creates a new queryParser for the form values of the request, which must
+// be parsed already.
+func newFormParser(r *http.Request) *queryParser {
+	return &queryParser{values: r.Form}
+}
+
+// Err returns the first parsing error as a services.ErrInvalidInput error.
+func (p *queryParser) Err() error {
+	return p.err
+}
+
+// fail records the parsing error of the parameter.
+func (p *queryParser) fail(name string) {
+	if p.err == nil {
+		p.err = services.Errorf(services.ErrInvalidInput, "invalid format for %s", name)
+	}
+}
+
+// String returns the parameter value.
+func (p *queryParser) String(name string) string {
+	return p.values.Get(name)
+}
+
+// List returns the comma-separated parameter values.
+func (p *queryParser) List(name string) []string {
+	param := p.values.Get(name)
+	if param == "" {
+		return nil
+	}
+
+	var list []string
+	for _, v := range strings.Split(param, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// IDs parses a comma-separated list of IDs. "all" and "" yield no IDs (no filtering).
+func (p *queryParser) IDs(name string) []uint {
+	if p.values.Get(name) == "all" {
+		return nil
+	}
+
+	var ids []uint
+	for _, v := range p.List(name) {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			p.fail(name)
+			return nil
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids
+}
+
+// ID parses a single ID. An empty parameter yields zero (no filtering).
+func (p *queryParser) ID(name string) uint {
+	param := p.values.Get(name)
+	if param == "" {
+		return 0
+	}
+
+	id, err := strconv.ParseUint(param, 10, 32)
+	if err != nil {
+		p.fail(name)
+		return 0
+	}
+	return uint(id)
+}
+
+// OptionalUint parses an unsigned integer. An empty parameter yields nil (no filtering).
+func (p *queryParser) OptionalUint(name string) *uint {
+	param := p.values.Get(name)
+	if param == "" {
+		return nil
+	}
+
+	n, err := strconv.ParseUint(param, 10, 32)
+	if err != nil {
+		p.fail(name)
+		return nil
+	}
+
+	v := uint(n)
+	return &v
+}
+
+// Int parses an integer. An empty parameter yields zero.
+func (p *queryParser) Int(name string) int {
+	param := p.values.Get(name)
+	if param == "" {
+		return 0
+	}
+
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		p.fail(name)
+		return 0
+	}
+	return n
+}
+
+// Bool parses a boolean. An empty parameter yields nil (no filtering).
+func (p *queryParser) Bool(name string) *bool {
+	param := p.values.Get(name)
+	if param == "" {
+		return nil
+	}
+
+	b, err := strconv.ParseBool(param)
+	if err != nil {
+		p.fail(name)
+		return nil
+	}
+	return &b
+}
+
+// Time parses a time in the given layout, in UTC. An empty parameter yields nil.
+func (p *queryParser) Time(name, layout string) *time.Time {
+	param := p.values.Get(name)
+	if param == "" {
+		return nil
+	}
+
+	t, err := time.Parse(layout, param)
+	if err != nil {
+		p.fail(name)
+		return nil
+	}
+	return &t
+}
+
+// Page parses the sort, limit, cursor and count parameters.
+func (p *queryParser) Page() services.Page {
+	page := services.Page{
+		Sort:   p.String("sort"),
+		Limit:  p.Int("limit"),
+		Cursor: p.String("cursor"),
+	}
+
+	if count := p.Bool("count"); count != nil {
+		page.Count = *count
+	}
+
+	if page.Limit < 0 {
+		p.fail("limit")
+	}
+
+	return page
+}
+
+// Fields parses the fields parameter listing the response fields to keep.
+func (p *queryParser) Fields() []string {
+	return p.List("fields")
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"net/http"
+)
+
+// recommendedCourse is a recommended course with its score and the main reason of the
+// recommendation.
+type recommendedCourse struct {
+	Course models.Course
+	Score  float64
+	Reason string
+}
+
+// recommendationLimit returns the validated limit query parameter of the recommendations.
+func recommendationLimit(query *queryParser) (int, error) {
+	limit := query.Int("limit")
+	if err := query.Err(); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case limit < 0:
+		return 0, services.Errorf(services.ErrInvalidInput, "invalid format for limit")
+	case limit == 0:
+		return services.DefaultRecommendations, nil
+	case limit > services.MaxRecommendations:
+		return services.MaxRecommendations, nil
+	}
+	return limit, nil
+}
+
+// GetRecommendations returns the courses recommended to the current user.
+func (c *BaseController) GetRecommendations(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	query := newQueryParser(r)
+	limit, err := recommendationLimit(query)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	recommendations, err := c.Recommendations.ForUser(r.Context(), user.ID, limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	c.writeRecommendations(w, r, query.String("coupon"), recommendations)
+}
+
+// GetAlsoTook returns the courses most often taken by the learners of the course course_id.
+func (c *BaseController) GetAlsoTook(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+	courseID := query.ID("course_id")
+	limit, err := recommendationLimit(query)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	recommendations, err := c.Recommendations.AlsoTook(r.Context(), courseID, limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	c.writeRecommendations(w, r, query.String("coupon"), recommendations)
+}
+
+// writeRecommendations writes the recommended courses in the order of the recommendations,
+// skipping the courses unpublished since the model was computed.
+func (c *BaseController) writeRecommendations(w http.ResponseWriter, r *http.Request, coupon string, recommendations []services.Recommendation) {
+	recommended := []recommendedCourse{}
+	if len(recommendations) == 0 {
+		writeJSON(w, http.StatusOK, recommended)
+		return
+	}
+
+	ids := make([]uint, len(recommendations))
+	for i, rec := range recommendations {
+		ids[i] = rec.CourseID
+	}
+
+	filter := services.CourseFilter{IDs: ids, StatusID: services.CourseStatusPublished}
+	courses, _, err := c.Courses.List(r.Context(), filter, services.Page{Limit: len(ids)})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := c.markWishlisted(r, courseRefs(courses)...); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := c.setFinalPrices(r, coupon, courseRefs(courses)...); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	c.resolveCourseURLs(courses)
+
+	byID := make(map[uint]models.Course, len(courses))
+	for _, course := range courses {
+		byID[course.ID] = course
+	}
+	for _, rec := range recommendations {
+		if course, ok := byID[rec.CourseID]; ok {
+			recommended = append(recommended, recommendedCourse{Course: course, Score: rec.Score, Reason: rec.Reason})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, recommended)
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/plaja-app/back-end/services"
+	"net/http"
+)
+
+// refundBody is the refund request body structure.
+type refundBody struct {
+	OrderID uint
+	Reason  string
+}
+
+// refundDecisionBody is the refund decision request body structure.
+type refundDecisionBody struct {
+	RefundID uint
+	Approve  bool
+	Note     string
+}
+
+// refundFilter parses the refund filter query parameters.
+func refundFilter(query *queryParser) services.RefundFilter {
+	return services.RefundFilter{
+		OrderID:  query.ID("order_id"),
+		Statuses: query.List("status"),
+	}
+}
+
+// GetRefunds returns the queried page of the models.Refund requested by the current user.
+func (c *BaseController) GetRefunds(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	filter := refundFilter(query)
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	filter.UserID = user.ID
+
+	refunds, info, err := c.Refunds.List(r.Context(), filter, page)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, refunds, info, query.Fields())
+}
+
+// GetRefundRequests returns the queried page of the models.Refund of all the users to the admins.
+func (c *BaseController) GetRefundRequests(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	filter := refundFilter(query)
+	filter.UserID = query.ID("user_id")
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	refunds, info, err := c.Refunds.Requests(r.Context(), user.ID, filter, page)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, refunds, info, query.Fields())
+}
+
+// RequestRefund requests the refund of a models.Order paid by the current user.
+func (c *BaseController) RequestRefund(w http.ResponseWriter, r *http.Request) {
+	var body refundBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	refund, err := c.Refunds.Request(r.Context(), user.ID, body.OrderID, body.Reason)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, refund)
+}
+
+// DecideRefund approves or rejects a requested models.Refund. Only the admins may decide.
+func (c *BaseController) DecideRefund(w http.ResponseWriter, r *http.Request) {
+	var body refundDecisionBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	err = c.Refunds.Decide(r.Context(), user.ID, services.RefundDecision{
+		RefundID: body.RefundID,
+		Approve:  body.Approve,
+		Note:     body.Note,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
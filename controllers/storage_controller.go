@@ -2,15 +2,16 @@ package controllers
 
 import (
 	"github.com/go-chi/chi/v5"
+	"github.com/plaja-app/back-end/storage"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
-// GetImage returns the image from the application storage.
+// GetImage returns the image from the application storage. The private files are not served.
 func (c *BaseController) GetImage(w http.ResponseWriter, r *http.Request) {
-	basePath := "storage"
+	basePath := c.App.Env.StorageRoot
 
 	filePath := chi.URLParam(r, "*")
 
@@ -21,6 +22,11 @@ func (c *BaseController) GetImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if storage.IsPrivate(filePath) {
+		http.NotFound(w, r)
+		return
+	}
+
 	if _, err := os.Stat(fullPath); err == nil {
 		// Add Cache-Control headers
 		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate") // HTTP 1.1.
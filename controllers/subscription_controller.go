@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"net/http"
+)
+
+// planBody is the subscription plan creation request body structure.
+type planBody struct {
+	Code     string
+	Name     string
+	Interval string
+	Price    uint
+}
+
+// planUpdateBody is the subscription plan update request body structure.
+type planUpdateBody struct {
+	PlanID uint
+	Name   string
+	Price  uint
+	Active bool
+}
+
+// subscriptionBody is the subscription request body structure.
+type subscriptionBody struct {
+	PlanID uint
+}
+
+// subscriptionCancelBody is the subscription cancellation request body structure.
+type subscriptionCancelBody struct {
+	SubscriptionID uint
+}
+
+// GetSubscriptionPlans returns the active models.SubscriptionPlan, and the inactive ones
+// to the admins.
+func (c *BaseController) GetSubscriptionPlans(w http.ResponseWriter, r *http.Request) {
+	user, _ := r.Context().Value("user").(models.User)
+
+	plans, err := c.Subscriptions.Plans(r.Context(), user.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if plans == nil {
+		plans = make([]models.SubscriptionPlan, 0)
+	}
+
+	writeJSON(w, http.StatusOK, plans)
+}
+
+// CreateSubscriptionPlan creates a new models.SubscriptionPlan.
+func (c *BaseController) CreateSubscriptionPlan(w http.ResponseWriter, r *http.Request) {
+	var body planBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	plan, err := c.Subscriptions.CreatePlan(r.Context(), user.ID, services.PlanInput{
+		Code:     body.Code,
+		Name:     body.Name,
+		Interval: body.Interval,
+		Price:    body.Price,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, plan)
+}
+
+// UpdateSubscriptionPlan updates the name, the price and the availability of a
+// models.SubscriptionPlan.
+func (c *BaseController) UpdateSubscriptionPlan(w http.ResponseWriter, r *http.Request) {
+	var body planUpdateBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	err = c.Subscriptions.UpdatePlan(r.Context(), user.ID, services.PlanUpdate{
+		PlanID: body.PlanID,
+		Name:   body.Name,
+		Price:  body.Price,
+		Active: body.Active,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetSubscriptions returns the queried page of the models.Subscription of the current user.
+func (c *BaseController) GetSubscriptions(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	subscriptions, info, err := c.Subscriptions.List(r.Context(), user.ID, page)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, subscriptions, info, query.Fields())
+}
+
+// Subscribe creates the models.Subscription of the current user to a plan and returns the
+// checkout page of the payment provider to redirect to.
+func (c *BaseController) Subscribe(w http.ResponseWriter, r *http.Request) {
+	var body subscriptionBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	checkout, err := c.Subscriptions.Subscribe(r.Context(), user.ID, body.PlanID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, checkout)
+}
+
+// CancelSubscription stops the renewals of a models.Subscription of the current user.
+func (c *BaseController) CancelSubscription(w http.ResponseWriter, r *http.Request) {
+	var body subscriptionCancelBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.Subscriptions.Cancel(r.Context(), user.ID, body.SubscriptionID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -2,19 +2,18 @@ package controllers
 
 import (
 	"encoding/json"
-	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
 	"net/http"
 )
 
 // teachingApplicationBody is the teaching application request body structure.
 type teachingApplicationBody struct {
-	UserID         uint
 	Experience     string
 	Motivation     string
 	PlatformChoice string
 }
 
-// CreateTeachingApplication creates a new models.TeachingApplication.
+// CreateTeachingApplication creates a new models.TeachingApplication for the current user.
 func (c *BaseController) CreateTeachingApplication(w http.ResponseWriter, r *http.Request) {
 	var body teachingApplicationBody
 
@@ -25,23 +24,18 @@ func (c *BaseController) CreateTeachingApplication(w http.ResponseWriter, r *htt
 		return
 	}
 
-	var application models.TeachingApplication
-	application = models.TeachingApplication{
-		UserID:         body.UserID,
-		Experience:     body.Experience,
-		Motivation:     body.Motivation,
-		PlatformChoice: body.PlatformChoice,
-	}
-
-	result := c.App.DB.Create(&application)
-	if result.Error != nil {
-		http.Error(w, "Error creating teaching application", http.StatusBadRequest)
+	user, ok := currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	err = c.changeUserType(application.UserID, 2)
+	err = c.Users.ApplyToTeach(r.Context(), user.ID, services.TeachingApplicationInput{
+		Experience:     body.Experience,
+		Motivation:     body.Motivation,
+		PlatformChoice: body.PlatformChoice,
+	})
 	if err != nil {
-		http.Error(w, "Error promoting user", http.StatusBadRequest)
+		writeError(w, err)
 		return
 	}
 
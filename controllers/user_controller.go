@@ -2,16 +2,9 @@ package controllers
 
 import (
 	"encoding/json"
-	"fmt"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/plaja-app/back-end/models"
-	"golang.org/x/crypto/bcrypt"
-	"io"
+	"github.com/plaja-app/back-end/services"
 	"net/http"
-	"os"
-	"path/filepath"
-	"strconv"
-	"strings"
 	"time"
 )
 
@@ -29,30 +22,22 @@ type loginBody struct {
 	Password string `json:"password"`
 }
 
-// userUpdateGeneralBody is the user general information update request body structure.
-type userUpdateGeneralBody struct {
-	FirstName string
-	LastName  string
+// invitationBody is the invitation acceptance request body structure.
+type invitationBody struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
 }
 
 // GetMe returns the model of the current models.User.
 func (c *BaseController) GetMe(w http.ResponseWriter, r *http.Request) {
-	userCtx := r.Context().Value("user")
-	if userCtx == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
-	user, ok := userCtx.(models.User)
+	user, ok := currentUser(w, r)
 	if !ok {
-		http.Error(w, "Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(user); err != nil {
-		http.Error(w, "Error encoding response", http.StatusInternalServerError)
-	}
+	c.resolveUserURLs(&user)
+
+	writeJSON(w, http.StatusOK, user)
 }
 
 // SignUp handles the signup request.
@@ -67,34 +52,14 @@ func (c *BaseController) SignUp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if body.FirstName == "" ||
-		body.LastName == "" ||
-		!validateEmail(body.Email) ||
-		len(body.Password) < 8 {
-		http.Error(w, "Bad credentials provided", http.StatusBadRequest)
-		return
-	}
-
-	// hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(body.Password), 10)
+	_, err = c.Users.SignUp(r.Context(), services.SignUpInput{
+		FirstName: body.FirstName,
+		LastName:  body.LastName,
+		Email:     body.Email,
+		Password:  body.Password,
+	})
 	if err != nil {
-		http.Error(w, "Error hashing password", http.StatusBadRequest)
-		return
-	}
-
-	// create a new user model
-	user := models.User{
-		FirstName:  body.FirstName,
-		LastName:   body.LastName,
-		Email:      body.Email,
-		Password:   string(hashedPassword),
-		UserTypeID: 1,
-	}
-
-	// add user to the database
-	result := c.App.DB.Create(&user)
-	if result.Error != nil {
-		http.Error(w, "Error creating user", http.StatusConflict)
+		writeError(w, err)
 		return
 	}
 
@@ -113,20 +78,9 @@ func (c *BaseController) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// look up the requested user
-	var user models.User
-
-	c.App.DB.First(&user, "email = ?", body.Email)
-
-	if user.ID == 0 {
-		http.Error(w, "Invalid email", http.StatusUnauthorized)
-		return
-	}
-
-	// compare sent in password with saved user password hash
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.Password))
+	user, err := c.Users.Authenticate(r.Context(), body.Email, body.Password)
 	if err != nil {
-		http.Error(w, "Invalid password", http.StatusUnauthorized)
+		writeError(w, err)
 		return
 	}
 
@@ -144,72 +98,64 @@ func (c *BaseController) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// create and set a cookie
-	cookie := http.Cookie{
-		Name:     "pja_user_jwt",
-		Path:     "/",
-		Value:    tokenString,
-		MaxAge:   3600 * 24 * 30,
-		Secure:   false,
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-	}
-	http.SetCookie(w, &cookie)
+	http.SetCookie(w, c.sessionCookie(tokenString, 3600*24*30))
 
 	w.WriteHeader(http.StatusOK)
 }
 
 // Logout handles the logout request by invalidating the user's session cookie.
 func (c *BaseController) Logout(w http.ResponseWriter, r *http.Request) {
-	cookie := http.Cookie{
-		Name:     "pja_user_jwt",
-		Path:     "/",
-		Value:    "",
-		MaxAge:   -1,
-		Secure:   false,
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
+	http.SetCookie(w, c.sessionCookie("", -1))
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// AcceptInvitation sets the password of the user invited with the token of the set-password link.
+func (c *BaseController) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var body invitationBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.Users.AcceptInvitation(r.Context(), body.Token, body.Password); err != nil {
+		writeError(w, err)
+		return
 	}
-	http.SetCookie(w, &cookie)
 
 	w.WriteHeader(http.StatusOK)
 }
 
-// GetUsers returns the queried list of models.User.
+// GetUsers returns the queried page of models.User.
 func (c *BaseController) GetUsers(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query()
-	idParam := query.Get("id")
-
-	w.Header().Set("Content-Type", "application/json")
-
-	var data []models.User
-
-	if idParam == "all" {
-		err := c.App.DB.Model(&models.User{}).Preload("UserType").Find(&data).Error
-		if err != nil {
-			return
-		}
-	} else {
-		ids := strings.Split(idParam, ",")
-		var intIds []int
-		for _, idStr := range ids {
-			id, err := strconv.Atoi(idStr)
-			if err != nil {
-				http.Error(w, "Invalid ID format", http.StatusBadRequest)
-				return
-			}
-			intIds = append(intIds, id)
-		}
-		err := c.App.DB.Where("id IN ?", intIds).Preload("UserType").Find(&data).Error
-		if err != nil {
-			return
-		}
+	query := newQueryParser(r)
+
+	ids := query.IDs("id")
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	data, info, err := c.Users.List(r.Context(), ids, page)
+	if err != nil {
+		writeError(w, err)
+		return
 	}
 
 	if len(data) == 0 {
 		http.NotFound(w, r)
-	} else {
-		json.NewEncoder(w).Encode(data)
+		return
 	}
+
+	for i := range data {
+		c.resolveUserURLs(&data[i])
+	}
+
+	writeList(w, data, info, query.Fields())
 }
 
 // UpdateUser handles the update request of the user's information.
@@ -220,62 +166,41 @@ func (c *BaseController) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var body userUpdateGeneralBody
-	body.FirstName = r.FormValue("FirstName")
-	body.LastName = r.FormValue("LastName")
-
-	userCtx := r.Context().Value("user")
-	if userCtx == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
-	user, ok := userCtx.(models.User)
+	user, ok := currentUser(w, r)
 	if !ok {
-		http.Error(w, "Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	var fileURL string
+	input := services.ProfileUpdate{
+		FirstName: r.FormValue("FirstName"),
+		LastName:  r.FormValue("LastName"),
+		Currency:  r.FormValue("Currency"),
+	}
 
 	file, _, err := r.FormFile("ProfilePic")
 	if err == nil && file != nil {
 		defer file.Close()
-
-		storagePath := "storage/users/profile-pictures"
-		os.MkdirAll(storagePath, os.ModePerm)
-
-		filePath := filepath.Join(storagePath, fmt.Sprintf("%d-%s", user.ID, "pp.png"))
-
-		dst, err := os.Create(filePath)
-		if err != nil {
-			http.Error(w, "Failed to save the file", http.StatusInternalServerError)
-			return
-		}
-		defer dst.Close()
-		_, err = io.Copy(dst, file)
-		if err != nil {
-			http.Error(w, "Failed to write the file", http.StatusInternalServerError)
-			return
-		}
-
-		fileURL = fmt.Sprintf("http://localhost:8080/api/v1/%s", filePath)
-	}
-
-	updateData := map[string]interface{}{
-		"FirstName": body.FirstName,
-		"LastName":  body.LastName,
+		input.ProfilePic = file
 	}
 
-	if fileURL != "" {
-		updateData["ProfilePic"] = fileURL
-	}
-
-	result := c.App.DB.Model(&user).Where("id = ?", user.ID).Updates(updateData)
-	if result.Error != nil {
-		http.Error(w, "Error updating user", http.StatusInternalServerError)
+	if err := c.Users.UpdateProfile(r.Context(), user.ID, input); err != nil {
+		writeError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// sessionCookie returns the session cookie holding the JWT with the configured domain and security flags.
+func (c *BaseController) sessionCookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     "pja_user_jwt",
+		Path:     "/",
+		Domain:   c.App.Env.CookieDomain,
+		Value:    value,
+		MaxAge:   maxAge,
+		Secure:   c.App.Env.CookieSecure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
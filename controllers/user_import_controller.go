@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"github.com/plaja-app/back-end/services"
+	"net/http"
+)
+
+// StartUserImport starts the import of the users of the uploaded CSV File into the
+// OrganizationID, or the platform if none, enrolling them into the comma-separated
+// CourseIDs. The rows are processed in the background; their results are polled with
+// GetUserImport.
+func (c *BaseController) StartUserImport(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseMultipartForm(10 << 20) // 10 MB
+	if err != nil {
+		http.Error(w, "Failed to parse multipart form", http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("File")
+	if err != nil {
+		http.Error(w, "Missing import file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	form := newFormParser(r)
+
+	organizationID := form.ID("OrganizationID")
+	courseIDs := form.IDs("CourseIDs")
+	if err := form.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := services.ParseImport(file)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	userImport, err := c.UserImports.Start(r.Context(), user.ID, services.UserImportInput{
+		OrganizationID: organizationID,
+		CourseIDs:      courseIDs,
+		Rows:           rows,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, userImport)
+}
+
+// GetUserImport returns the progress of the queried models.UserImport with the results of
+// the processed rows.
+func (c *BaseController) GetUserImport(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	id := query.ID("id")
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	userImport, err := c.UserImports.Get(r.Context(), user.ID, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, userImport)
+}
@@ -2,83 +2,154 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"log"
 	"net/http"
-	"net/mail"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 )
 
-// changeUserType changes the type of the specified user to the new one provided.
-// Note: 1 ("Learner") < 2 ("Educator") < 3 ("Admin").
-func (c *BaseController) changeUserType(userID uint, newType uint) error {
-	var user models.User
-	return c.App.DB.Model(&user).Where("id = ?", userID).Update("user_type_id", newType).Error
-}
+// currentUser returns the authenticated user set by the RequireAuth middleware.
+func currentUser(w http.ResponseWriter, r *http.Request) (models.User, bool) {
+	userCtx := r.Context().Value("user")
+	if userCtx == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return models.User{}, false
+	}
 
-// calculateExerciseLength calculates ant returns the approximate length of the exercise (in minutes).
-func calculateExerciseLength(content string) uint {
-	words := strings.Fields(content)
+	user, ok := userCtx.(models.User)
+	if !ok {
+		http.Error(w, "Server Error", http.StatusInternalServerError)
+		return models.User{}, false
+	}
 
-	wordCount := len(words)
+	return user, true
+}
 
-	length := uint(wordCount) / 200 // approximate reading speed in Ukrainian
+// writeError writes the HTTP error corresponding to the service error.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidInput):
+		status = http.StatusBadRequest
+	case errors.Is(err, services.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, services.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, services.ErrConflict):
+		status = http.StatusConflict
+	default:
+		log.Println(err)
+		http.Error(w, "Server Error", status)
+		return
+	}
 
-	return length
+	http.Error(w, err.Error(), status)
 }
 
-// GetCourseCategoriesStats returns a category and the number of courses associated with it.
-func (c *BaseController) GetCourseCategoriesStats(w http.ResponseWriter, r *http.Request) {
-	type CategoryStat struct {
-		Title        string
-		CoursesCount int
-	}
+// writeJSON writes v as the JSON response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
 
-	var stats []CategoryStat
+// writeList writes the page of records as the JSON response with the pagination headers.
+// If fields are given, only those fields of the records are written.
+func writeList(w http.ResponseWriter, list any, info services.PageInfo, fields []string) {
+	writePageHeaders(w, info)
 
-	err := c.App.DB.Table("course_categories").Select("course_categories.title, COUNT(course_categories_junction.course_id) as courses_count").
-		Joins("LEFT JOIN course_categories_junction ON course_categories.id = course_categories_junction.course_category_id").
-		Joins("LEFT JOIN courses ON course_categories_junction.course_id = courses.id").
-		Group("course_categories.title").
-		Scan(&stats).Error
+	if v := reflect.ValueOf(list); v.Kind() == reflect.Slice && v.IsNil() {
+		list = []any{}
+	}
 
+	if len(fields) == 0 {
+		writeJSON(w, http.StatusOK, list)
+		return
+	}
+
+	trimmed, err := selectFields(list, fields)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(stats)
+	writeJSON(w, http.StatusOK, trimmed)
 }
 
-// GetCourseCategoriesAndLevelsStats returns a course level for a category and the number of courses associated with it.
-func (c *BaseController) GetCourseCategoriesAndLevelsStats(w http.ResponseWriter, r *http.Request) {
-	type CategoryLevelStat struct {
-		CategoryTitle string
-		LevelTitle    string
-		CoursesCount  int
+// writePageHeaders writes the pagination headers of the page.
+func writePageHeaders(w http.ResponseWriter, info services.PageInfo) {
+	if info.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", info.NextCursor)
+	}
+
+	if info.Total != nil {
+		w.Header().Set("X-Total-Count", strconv.FormatInt(*info.Total, 10))
+	}
+}
+
+// selectFields returns the JSON objects of the records with only the given fields,
+// matched case-insensitively.
+func selectFields(list any, fields []string) ([]map[string]json.RawMessage, error) {
+	raw, err := json.Marshal(list)
+	if err != nil {
+		return nil, err
 	}
 
-	var stats []CategoryLevelStat
+	var records []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &records); err != nil {
+		return nil, err
+	}
 
-	err := c.App.DB.Table("course_categories").
-		Select("course_categories.title as category_title, course_levels.title as level_title, COUNT(courses.id) as courses_count").
-		Joins("LEFT JOIN course_categories_junction ON course_categories.id = course_categories_junction.course_category_id").
-		Joins("LEFT JOIN courses ON course_categories_junction.course_id = courses.id").
-		Joins("LEFT JOIN course_levels ON courses.level_id = course_levels.id"). // Assuming courses have a level_id column
-		Group("course_categories.title, course_levels.title").
-		Scan(&stats).Error
+	for _, record := range records {
+		for key := range record {
+			if !slices.ContainsFunc(fields, func(f string) bool { return strings.EqualFold(f, key) }) {
+				delete(record, key)
+			}
+		}
+	}
 
+	return records, nil
+}
+
+// resolveUserURLs replaces the storage-relative paths of the user with public URLs.
+func (c *BaseController) resolveUserURLs(user *models.User) {
+	user.ProfilePic = c.App.StorageURL(user.ProfilePic)
+}
+
+// resolveCourseURLs replaces the storage-relative paths of the courses and their instructors with public URLs.
+func (c *BaseController) resolveCourseURLs(courses []models.Course) {
+	for i := range courses {
+		courses[i].Thumbnail = c.App.StorageURL(courses[i].Thumbnail)
+		c.resolveUserURLs(&courses[i].Instructor)
+	}
+}
+
+// GetCourseCategoriesStats returns a category and the number of courses associated with it.
+func (c *BaseController) GetCourseCategoriesStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := c.Courses.CategoryStats(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(stats)
+	writeJSON(w, http.StatusOK, stats)
 }
 
-// validateEmail validates the email address.
-func validateEmail(email string) bool {
-	_, err := mail.ParseAddress(email)
-	return err == nil
+// GetCourseCategoriesAndLevelsStats returns a course level for a category and the number of courses associated with it.
+func (c *BaseController) GetCourseCategoriesAndLevelsStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := c.Courses.CategoryLevelStats(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
 }
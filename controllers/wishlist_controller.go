@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/plaja-app/back-end/models"
+	"net/http"
+)
+
+// wishlistBody is the wishlist request body structure.
+type wishlistBody struct {
+	CourseID uint
+}
+
+// courseRefs returns pointers to the courses.
+func courseRefs(courses []models.Course) []*models.Course {
+	refs := make([]*models.Course, len(courses))
+	for i := range courses {
+		refs[i] = &courses[i]
+	}
+	return refs
+}
+
+// markWishlisted sets the InWishlist flag of the courses if the request is authenticated.
+func (c *BaseController) markWishlisted(r *http.Request, courses ...*models.Course) error {
+	user, ok := r.Context().Value("user").(models.User)
+	if !ok || len(courses) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(courses))
+	for i, course := range courses {
+		ids[i] = course.ID
+	}
+
+	wishlisted, err := c.Wishlist.Contains(r.Context(), user.ID, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, course := range courses {
+		in := wishlisted[course.ID]
+		course.InWishlist = &in
+	}
+
+	return nil
+}
+
+// GetWishlist returns the queried page of the models.Course in the wishlist of the current user.
+func (c *BaseController) GetWishlist(w http.ResponseWriter, r *http.Request) {
+	query := newQueryParser(r)
+
+	page := query.Page()
+	if err := query.Err(); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	courses, info, err := c.Wishlist.List(r.Context(), user.ID, page)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	for i := range courses {
+		in := true
+		courses[i].InWishlist = &in
+	}
+
+	if err := c.setFinalPrices(r, query.String("coupon"), courseRefs(courses)...); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	c.resolveCourseURLs(courses)
+
+	writeList(w, courses, info, query.Fields())
+}
+
+// AddToWishlist adds a published models.Course to the wishlist of the current user.
+func (c *BaseController) AddToWishlist(w http.ResponseWriter, r *http.Request) {
+	var body wishlistBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.Wishlist.Add(r.Context(), user.ID, body.CourseID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// RemoveFromWishlist removes a models.Course from the wishlist of the current user.
+func (c *BaseController) RemoveFromWishlist(w http.ResponseWriter, r *http.Request) {
+	var body wishlistBody
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	defer r.Body.Close()
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.Wishlist.Remove(r.Context(), user.ID, body.CourseID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"fmt"
+	"github.com/glebarez/sqlite"
+	"github.com/plaja-app/back-end/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// Open initializes a db session using the configured driver (PostgreSQL or SQLite).
+func Open(env *config.EnvVariables) (*gorm.DB, error) {
+	switch env.DBDriver {
+	case "", "postgres":
+		return openPostgres(env)
+	case "sqlite":
+		return openSQLite(env.SQLitePath)
+	}
+
+	return nil, fmt.Errorf("unsupported database driver %q", env.DBDriver)
+}
+
+// openPostgres initializes a PostgreSQL db session.
+func openPostgres(env *config.EnvVariables) (*gorm.DB, error) {
+	dsn := fmt.Sprintf("host=%s user=%s dbname=%s password=%s sslmode=disable",
+		env.PostgresHost, env.PostgresUser, env.PostgresDBName, env.PostgresPass)
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("could not connect: %v", err)
+	}
+
+	return db, nil
+}
+
+// openSQLite initializes a SQLite db session for the database file at path (":memory:" for an in-memory database).
+// Foreign keys are enforced and a single connection is used, as SQLite serializes writes anyway
+// and every connection to ":memory:" would otherwise see its own empty database.
+func openSQLite(path string) (*gorm.DB, error) {
+	dsn := path
+	if strings.Contains(dsn, "?") {
+		dsn += "&_pragma=foreign_keys(1)"
+	} else {
+		dsn += "?_pragma=foreign_keys(1)"
+	}
+
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("could not open %s: %v", path, err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	return db, nil
+}
@@ -0,0 +1,121 @@
+package migrations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// Command runs the migrate subcommands of the command line tools: up, down [steps] and
+// status. The results are printed to Out as text, or as JSON for scripting.
+type Command struct {
+	Out  io.Writer
+	JSON bool
+	// Usage is returned as the error of invalid arguments.
+	Usage string
+}
+
+// Result is an applied or reverted migration.
+type Result struct {
+	Version int
+	Name    string
+	Action  string
+}
+
+// Run runs the subcommand in args against the database.
+func (c Command) Run(db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New(c.Usage)
+	}
+
+	migrator, err := New(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		if printErr := c.printResults("applied", applied); printErr != nil {
+			return printErr
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return errors.New(c.Usage)
+			}
+		}
+
+		reverted, err := migrator.Down(steps)
+		if printErr := c.printResults("reverted", reverted); printErr != nil {
+			return printErr
+		}
+		return err
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		return c.printStatuses(statuses)
+	}
+
+	return errors.New(c.Usage)
+}
+
+// printResults prints the applied or reverted migrations.
+func (c Command) printResults(action string, done []Migration) error {
+	results := make([]Result, 0, len(done))
+	for _, m := range done {
+		results = append(results, Result{Version: m.Version, Name: m.Name, Action: action})
+	}
+
+	if c.JSON {
+		return c.encode(results)
+	}
+
+	if len(results) == 0 && action == "applied" {
+		_, err := fmt.Fprintln(c.Out, "database schema is up to date")
+		return err
+	}
+
+	for _, r := range results {
+		if _, err := fmt.Fprintf(c.Out, "%s %04d_%s\n", r.Action, r.Version, r.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printStatuses prints the migrations and their states.
+func (c Command) printStatuses(statuses []Status) error {
+	if c.JSON {
+		return c.encode(statuses)
+	}
+
+	tw := tabwriter.NewWriter(c.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+	for _, s := range statuses {
+		appliedAt := "-"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, s.State, appliedAt)
+	}
+	return tw.Flush()
+}
+
+// encode writes v as indented JSON.
+func (c Command) encode(v any) error {
+	enc := json.NewEncoder(c.Out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
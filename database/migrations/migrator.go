@@ -0,0 +1,228 @@
+package migrations
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"sort"
+	"strings"
+	"time"
+)
+
+// State is the state of a migration in the database.
+type State string
+
+const (
+	StateApplied State = "applied"
+	StatePending State = "pending"
+	// StateDrifted means the migration file changed after it was applied.
+	StateDrifted State = "drifted"
+	// StateUnknown means the migration was applied but its file no longer exists.
+	StateUnknown State = "unknown"
+)
+
+// SchemaMigration is a row of the schema_migrations table.
+type SchemaMigration struct {
+	Version   int    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"size:255"`
+	Checksum  string `gorm:"size:64"`
+	AppliedAt time.Time
+}
+
+// Status describes a migration and its state.
+type Status struct {
+	Version   int
+	Name      string
+	State     State
+	AppliedAt *time.Time `json:",omitempty"`
+}
+
+// Migrator applies and reverts the migrations.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New creates a new Migrator for the embedded migrations of the database dialect.
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := Load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// ensureTable creates the schema_migrations table if it does not exist.
+func (m *Migrator) ensureTable() error {
+	return m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    bigint PRIMARY KEY,
+		name       varchar(255) NOT NULL,
+		checksum   varchar(64) NOT NULL,
+		applied_at timestamp NOT NULL
+	)`).Error
+}
+
+// applied returns the applied migrations keyed by version.
+func (m *Migrator) applied() (map[int]SchemaMigration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, fmt.Errorf("error creating schema_migrations table: %v", err)
+	}
+
+	var rows []SchemaMigration
+	if err := m.db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]SchemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
+
+// Status returns the state of every known or applied migration ordered by version.
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name, State: StatePending}
+
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+			status.State = StateApplied
+			if row.Checksum != migration.Checksum {
+				status.State = StateDrifted
+			}
+			delete(applied, migration.Version)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	for _, row := range applied {
+		appliedAt := row.AppliedAt
+		statuses = append(statuses, Status{Version: row.Version, Name: row.Name, State: StateUnknown, AppliedAt: &appliedAt})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// Check returns an error if the database schema is not up to date with the embedded migrations.
+func (m *Migrator) Check() error {
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for _, s := range statuses {
+		if s.State != StateApplied {
+			problems = append(problems, fmt.Sprintf("%04d_%s is %s", s.Version, s.Name, s.State))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("database schema is not up to date (%s); run the migrate command", strings.Join(problems, ", "))
+	}
+
+	return nil
+}
+
+// Up applies all pending migrations in order. Each migration runs in its own transaction.
+// Returns the applied migrations.
+func (m *Migrator) Up() ([]Migration, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range statuses {
+		if s.State == StateDrifted || s.State == StateUnknown {
+			return nil, fmt.Errorf("migration %04d_%s is %s, refusing to migrate", s.Version, s.Name, s.State)
+		}
+	}
+
+	pending := make(map[int]bool)
+	for _, s := range statuses {
+		pending[s.Version] = s.State == StatePending
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if !pending[migration.Version] {
+			continue
+		}
+
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+
+			return tx.Create(&SchemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Checksum:  migration.Checksum,
+				AppliedAt: time.Now().UTC(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("error applying migration %04d_%s: %v", migration.Version, migration.Name, err)
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Down reverts the given number of the most recently applied migrations.
+// Returns the reverted migrations.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+
+		row, ok := applied[migration.Version]
+		if !ok {
+			continue
+		}
+
+		if row.Checksum != migration.Checksum {
+			return done, fmt.Errorf("migration %04d_%s is drifted, refusing to revert", migration.Version, migration.Name)
+		}
+
+		if migration.Down == "" {
+			return done, fmt.Errorf("migration %04d_%s has no down file", migration.Version, migration.Name)
+		}
+
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+
+			return tx.Delete(&SchemaMigration{}, "version = ?", migration.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("error reverting migration %04d_%s: %v", migration.Version, migration.Name, err)
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
@@ -0,0 +1,32 @@
+//go:build postgres
+
+package migrations
+
+import (
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"os"
+	"testing"
+)
+
+// TestPostgresUpDown runs the PostgreSQL migrations against the scratch database of
+// PLAJA_TEST_POSTGRES_DSN, e.g.
+//
+//	PLAJA_TEST_POSTGRES_DSN="host=localhost user=plaja dbname=plaja_test sslmode=disable" \
+//		go test -tags postgres ./database/migrations
+//
+// The database is expected to be empty; the user needs to be able to create pg_trgm or
+// have it installed beforehand.
+func TestPostgresUpDown(t *testing.T) {
+	dsn := os.Getenv("PLAJA_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("PLAJA_TEST_POSTGRES_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testUpDown(t, db)
+}
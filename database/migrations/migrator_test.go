@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+)
+
+// testUpDown applies all the migrations, reverts them and applies them again, checking
+// that every down file removes what its up file created.
+func testUpDown(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	migrator, err := New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for round := 1; round <= 2; round++ {
+		applied, err := migrator.Up()
+		if err != nil {
+			t.Fatalf("round %d: %v", round, err)
+		}
+		if len(applied) != len(migrator.migrations) {
+			t.Fatalf("round %d: expected %d migrations to be applied, got %d", round, len(migrator.migrations), len(applied))
+		}
+		if err := migrator.Check(); err != nil {
+			t.Fatalf("round %d: %v", round, err)
+		}
+
+		reverted, err := migrator.Down(len(applied))
+		if err != nil {
+			t.Fatalf("round %d: %v", round, err)
+		}
+		if len(reverted) != len(applied) {
+			t.Fatalf("round %d: expected %d migrations to be reverted, got %d", round, len(applied), len(reverted))
+		}
+
+		all, err := db.Migrator().GetTables()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the internal tables of SQLite stay
+		var tables []string
+		for _, table := range all {
+			if !strings.HasPrefix(table, "sqlite_") {
+				tables = append(tables, table)
+			}
+		}
+		if len(tables) != 1 || tables[0] != "schema_migrations" {
+			t.Fatalf("round %d: expected only the schema_migrations table to be left, got %v", round, tables)
+		}
+	}
+}
+
+func TestSQLiteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:?_pragma=foreign_keys(1)"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	testUpDown(t, db)
+}
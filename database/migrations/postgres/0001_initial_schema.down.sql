@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS teaching_applications;
+DROP TABLE IF EXISTS enrollments;
+DROP TABLE IF EXISTS course_exercises;
+DROP TABLE IF EXISTS course_exercise_types;
+DROP TABLE IF EXISTS enrollment_statuses;
+DROP TABLE IF EXISTS course_certificates;
+DROP TABLE IF EXISTS course_categories_junction;
+DROP TABLE IF EXISTS courses;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS user_types;
+DROP TABLE IF EXISTS course_levels;
+DROP TABLE IF EXISTS course_categories;
+DROP TABLE IF EXISTS course_statuses;
@@ -0,0 +1,117 @@
+-- Baseline schema. Tables are created only if missing so databases previously
+-- managed by GORM AutoMigrate are adopted as they are.
+
+CREATE TABLE IF NOT EXISTS course_statuses (
+    id         bigserial PRIMARY KEY,
+    title      varchar(255),
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS course_categories (
+    id         bigserial PRIMARY KEY,
+    title      varchar(255),
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS course_levels (
+    id         bigserial PRIMARY KEY,
+    title      varchar(255),
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS user_types (
+    id         bigserial PRIMARY KEY,
+    title      varchar(255),
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id           serial PRIMARY KEY,
+    profile_pic  text,
+    first_name   varchar(255),
+    last_name    varchar(255),
+    email        varchar(255) UNIQUE,
+    password     varchar(255),
+    user_type_id bigint NOT NULL REFERENCES user_types (id),
+    created_at   timestamptz,
+    updated_at   timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS courses (
+    id                bigserial PRIMARY KEY,
+    thumbnail         text,
+    title             varchar(255),
+    short_description varchar(255),
+    description       varchar(65000),
+    level_id          bigint NOT NULL REFERENCES course_levels (id),
+    status_id         bigint NOT NULL REFERENCES course_statuses (id),
+    instructor_id     bigint NOT NULL REFERENCES users (id),
+    length            bigint,
+    price             bigint,
+    has_certificate   boolean,
+    created_at        timestamptz,
+    updated_at        timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS course_categories_junction (
+    course_id          bigint REFERENCES courses (id),
+    course_category_id bigint REFERENCES course_categories (id),
+    PRIMARY KEY (course_id, course_category_id)
+);
+
+CREATE TABLE IF NOT EXISTS course_certificates (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL REFERENCES users (id),
+    course_id  bigint NOT NULL REFERENCES courses (id),
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS enrollment_statuses (
+    id         bigserial PRIMARY KEY,
+    title      varchar(255),
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS course_exercise_types (
+    id         bigserial PRIMARY KEY,
+    title      varchar(255),
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS course_exercises (
+    id         bigserial PRIMARY KEY,
+    title      varchar(255),
+    content    varchar(65000),
+    length     bigint,
+    course_id  bigint NOT NULL REFERENCES courses (id),
+    type_id    bigint NOT NULL REFERENCES course_exercise_types (id),
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS enrollments (
+    user_id          bigint NOT NULL REFERENCES users (id),
+    course_id        bigint NOT NULL REFERENCES courses (id),
+    progress         bigint,
+    status_id        bigint NOT NULL REFERENCES enrollment_statuses (id),
+    last_exercise_id bigint NOT NULL,
+    created_at       timestamptz,
+    updated_at       timestamptz,
+    PRIMARY KEY (user_id, course_id)
+);
+
+CREATE TABLE IF NOT EXISTS teaching_applications (
+    user_id         bigint PRIMARY KEY REFERENCES users (id),
+    experience      varchar(255),
+    motivation      varchar(255),
+    platform_choice varchar(255),
+    created_at      timestamptz,
+    updated_at      timestamptz
+);
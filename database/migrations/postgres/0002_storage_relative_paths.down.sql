@@ -0,0 +1,7 @@
+UPDATE courses
+SET thumbnail = 'http://localhost:8080/api/v1/storage/' || thumbnail
+WHERE thumbnail <> '' AND thumbnail NOT LIKE 'http://%' AND thumbnail NOT LIKE 'https://%';
+
+UPDATE users
+SET profile_pic = 'http://localhost:8080/api/v1/storage/' || profile_pic
+WHERE profile_pic <> '' AND profile_pic NOT LIKE 'http://%' AND profile_pic NOT LIKE 'https://%';
@@ -0,0 +1,10 @@
+-- Storage files are referenced by their storage-relative path; the public URL is
+-- computed at response time from PUBLIC_BASE_URL.
+
+UPDATE courses
+SET thumbnail = substr(thumbnail, length('http://localhost:8080/api/v1/storage/') + 1)
+WHERE thumbnail LIKE 'http://localhost:8080/api/v1/storage/%';
+
+UPDATE users
+SET profile_pic = substr(profile_pic, length('http://localhost:8080/api/v1/storage/') + 1)
+WHERE profile_pic LIKE 'http://localhost:8080/api/v1/storage/%';
@@ -0,0 +1 @@
+ALTER TABLE teaching_applications DROP COLUMN approved_at;
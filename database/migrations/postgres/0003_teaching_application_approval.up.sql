@@ -0,0 +1,7 @@
+ALTER TABLE teaching_applications ADD COLUMN approved_at timestamptz;
+
+-- Applications of users that were already promoted count as approved.
+UPDATE teaching_applications
+SET approved_at = teaching_applications.created_at
+FROM users
+WHERE users.id = teaching_applications.user_id AND users.user_type_id >= 2;
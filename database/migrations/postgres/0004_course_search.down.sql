@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS courses_title_trgm_idx;
+DROP INDEX IF EXISTS courses_search_vector_idx;
+
+DROP TRIGGER IF EXISTS course_exercises_search_vector_update ON course_exercises;
+DROP FUNCTION IF EXISTS course_exercises_search_vector_update();
+DROP TRIGGER IF EXISTS courses_search_vector_update ON courses;
+DROP FUNCTION IF EXISTS courses_search_vector_update();
+DROP FUNCTION IF EXISTS course_search_vector(courses);
+
+ALTER TABLE courses DROP COLUMN search_vector;
+
+DROP TEXT SEARCH CONFIGURATION IF EXISTS plaja_uk;
+
+-- pg_trgm is left installed, other database objects may depend on it.
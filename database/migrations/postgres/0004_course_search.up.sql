@@ -0,0 +1,60 @@
+-- Full-text search over the courses. The plaja_uk configuration starts as a copy of the
+-- language-neutral "simple" one; where a Ukrainian hunspell dictionary is installed it
+-- can be switched with ALTER TEXT SEARCH CONFIGURATION without touching the queries.
+--
+-- The title typos are matched with pg_trgm. Creating the extension needs the superuser or,
+-- since PostgreSQL 13, the CREATE privilege on the database; where the migrating user has
+-- neither, a superuser installs it once beforehand with CREATE EXTENSION pg_trgm.
+DO $$
+BEGIN
+    CREATE EXTENSION IF NOT EXISTS pg_trgm;
+EXCEPTION WHEN insufficient_privilege THEN
+    RAISE EXCEPTION 'the course search requires the pg_trgm extension, which the user % may not create: run CREATE EXTENSION pg_trgm as a superuser and migrate again', current_user;
+END
+$$;
+
+CREATE TEXT SEARCH CONFIGURATION plaja_uk (COPY = pg_catalog.simple);
+
+ALTER TABLE courses ADD COLUMN search_vector tsvector NOT NULL DEFAULT ''::tsvector;
+
+-- The title weighs most, followed by the short description, the exercise titles and the description.
+CREATE FUNCTION course_search_vector(c courses) RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('plaja_uk', coalesce(c.title, '')), 'A') ||
+           setweight(to_tsvector('plaja_uk', coalesce(c.short_description, '')), 'B') ||
+           setweight(to_tsvector('plaja_uk', coalesce(string_agg(e.title, ' '), '')), 'C') ||
+           setweight(to_tsvector('plaja_uk', coalesce(c.description, '')), 'D')
+    FROM course_exercises e
+    WHERE e.course_id = c.id
+$$ LANGUAGE sql STABLE;
+
+CREATE FUNCTION courses_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := course_search_vector(NEW);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER courses_search_vector_update
+    BEFORE INSERT OR UPDATE OF title, short_description, description ON courses
+    FOR EACH ROW EXECUTE FUNCTION courses_search_vector_update();
+
+CREATE FUNCTION course_exercises_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE courses SET search_vector = course_search_vector(courses) WHERE id = OLD.course_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE courses SET search_vector = course_search_vector(courses) WHERE id = NEW.course_id;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER course_exercises_search_vector_update
+    AFTER INSERT OR DELETE OR UPDATE OF title, course_id ON course_exercises
+    FOR EACH ROW EXECUTE FUNCTION course_exercises_search_vector_update();
+
+UPDATE courses SET search_vector = course_search_vector(courses);
+
+CREATE INDEX courses_search_vector_idx ON courses USING gin (search_vector);
+CREATE INDEX courses_title_trgm_idx ON courses USING gin (title gin_trgm_ops);
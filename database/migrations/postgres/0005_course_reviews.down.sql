@@ -0,0 +1,6 @@
+ALTER TABLE courses DROP COLUMN rating_distribution;
+ALTER TABLE courses DROP COLUMN rating_count;
+ALTER TABLE courses DROP COLUMN rating_average;
+
+DROP TABLE IF EXISTS course_review_votes;
+DROP TABLE IF EXISTS course_reviews;
@@ -0,0 +1,31 @@
+-- Reviews of the enrolled learners, one per enrollment, and their helpful votes.
+CREATE TABLE IF NOT EXISTS course_reviews (
+    id            bigserial PRIMARY KEY,
+    user_id       bigint NOT NULL REFERENCES users (id),
+    course_id     bigint NOT NULL REFERENCES courses (id),
+    rating        bigint NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    text          varchar(65000),
+    reply         varchar(65000),
+    replied_at    timestamptz,
+    helpful_count bigint NOT NULL DEFAULT 0,
+    flagged       boolean NOT NULL DEFAULT false,
+    hidden        boolean NOT NULL DEFAULT false,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    UNIQUE (user_id, course_id),
+    FOREIGN KEY (user_id, course_id) REFERENCES enrollments (user_id, course_id) ON DELETE CASCADE
+);
+
+CREATE INDEX course_reviews_course_id ON course_reviews (course_id);
+
+CREATE TABLE IF NOT EXISTS course_review_votes (
+    review_id  bigint NOT NULL REFERENCES course_reviews (id) ON DELETE CASCADE,
+    user_id    bigint NOT NULL REFERENCES users (id),
+    created_at timestamptz,
+    PRIMARY KEY (review_id, user_id)
+);
+
+-- Ratings of the visible reviews cached on the courses.
+ALTER TABLE courses ADD COLUMN rating_average double precision NOT NULL DEFAULT 0;
+ALTER TABLE courses ADD COLUMN rating_count bigint NOT NULL DEFAULT 0;
+ALTER TABLE courses ADD COLUMN rating_distribution text NOT NULL DEFAULT '[0,0,0,0,0]';
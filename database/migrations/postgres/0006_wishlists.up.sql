@@ -0,0 +1,23 @@
+-- Courses saved by the users for later.
+CREATE TABLE IF NOT EXISTS wishlist_items (
+    user_id    bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    course_id  bigint NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+    created_at timestamptz,
+    PRIMARY KEY (user_id, course_id)
+);
+
+CREATE INDEX wishlist_items_course_id ON wishlist_items (course_id);
+
+-- In-app notifications, e.g. of the price drops of the wishlisted courses.
+CREATE TABLE IF NOT EXISTS notifications (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind       varchar(64),
+    course_id  bigint REFERENCES courses (id) ON DELETE SET NULL,
+    title      varchar(255),
+    body       varchar(65000),
+    read_at    timestamptz,
+    created_at timestamptz
+);
+
+CREATE INDEX notifications_user_id ON notifications (user_id);
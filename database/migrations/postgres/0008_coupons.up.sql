@@ -0,0 +1,39 @@
+-- Discount codes of the instructors (per course) and the admins (site-wide).
+CREATE TABLE IF NOT EXISTS coupons (
+    id               bigserial PRIMARY KEY,
+    code             varchar(64) NOT NULL UNIQUE,
+    creator_id       bigint NOT NULL REFERENCES users (id),
+    course_id        bigint REFERENCES courses (id) ON DELETE CASCADE,
+    kind             varchar(16) NOT NULL,
+    value            bigint NOT NULL,
+    max_redemptions  bigint NOT NULL DEFAULT 0,
+    redemption_count bigint NOT NULL DEFAULT 0,
+    expires_at       timestamptz,
+    disabled         boolean NOT NULL DEFAULT false,
+    created_at       timestamptz,
+    updated_at       timestamptz
+);
+
+CREATE INDEX coupons_creator_id ON coupons (creator_id);
+
+-- Coupons used by the paid orders.
+CREATE TABLE IF NOT EXISTS coupon_redemptions (
+    id         bigserial PRIMARY KEY,
+    coupon_id  bigint NOT NULL REFERENCES coupons (id) ON DELETE CASCADE,
+    order_id   bigint NOT NULL UNIQUE REFERENCES orders (id),
+    user_id    bigint NOT NULL REFERENCES users (id),
+    course_id  bigint NOT NULL REFERENCES courses (id),
+    discount   bigint NOT NULL,
+    amount     bigint NOT NULL,
+    created_at timestamptz
+);
+
+CREATE INDEX coupon_redemptions_coupon_id ON coupon_redemptions (coupon_id);
+
+ALTER TABLE orders ADD COLUMN coupon_id bigint REFERENCES coupons (id) ON DELETE SET NULL;
+ALTER TABLE orders ADD COLUMN discount bigint NOT NULL DEFAULT 0;
+
+-- Time-limited sale prices of the courses.
+ALTER TABLE courses ADD COLUMN sale_price bigint;
+ALTER TABLE courses ADD COLUMN sale_starts_at timestamptz;
+ALTER TABLE courses ADD COLUMN sale_ends_at timestamptz;
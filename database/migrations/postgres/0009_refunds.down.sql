@@ -0,0 +1,4 @@
+ALTER TABLE course_certificates DROP COLUMN revoked_at;
+
+DROP TABLE IF EXISTS audit_entries;
+DROP TABLE IF EXISTS refunds;
@@ -0,0 +1,30 @@
+-- Refund requests of the paid orders.
+CREATE TABLE IF NOT EXISTS refunds (
+    id          bigserial PRIMARY KEY,
+    order_id    bigint NOT NULL REFERENCES orders (id),
+    user_id     bigint NOT NULL REFERENCES users (id),
+    reason      varchar(65000),
+    status      varchar(32) NOT NULL,
+    reviewer_id bigint REFERENCES users (id),
+    note        varchar(65000),
+    decided_at  timestamptz,
+    created_at  timestamptz,
+    updated_at  timestamptz
+);
+
+CREATE INDEX refunds_order_id ON refunds (order_id);
+
+-- Actions performed on the records, e.g. the refund decisions and the revocations.
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id         bigserial PRIMARY KEY,
+    actor_id   bigint REFERENCES users (id),
+    action     varchar(64) NOT NULL,
+    entity     varchar(64) NOT NULL,
+    entity_id  varchar(64) NOT NULL,
+    details    text,
+    created_at timestamptz
+);
+
+CREATE INDEX audit_entries_entity ON audit_entries (entity, entity_id);
+
+ALTER TABLE course_certificates ADD COLUMN revoked_at timestamptz;
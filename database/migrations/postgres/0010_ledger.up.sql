@@ -0,0 +1,25 @@
+-- Double-entry ledger of the instructor earnings: sales, refunds and payouts.
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+    id            bigserial PRIMARY KEY,
+    kind          varchar(32) NOT NULL,
+    instructor_id bigint NOT NULL REFERENCES users (id),
+    order_id      bigint REFERENCES orders (id),
+    currency      varchar(3) NOT NULL,
+    share         bigint,
+    description   varchar(65000),
+    created_by_id bigint REFERENCES users (id),
+    created_at    timestamptz
+);
+
+CREATE INDEX ledger_transactions_instructor_id ON ledger_transactions (instructor_id, created_at);
+CREATE INDEX ledger_transactions_order_id ON ledger_transactions (order_id);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id             bigserial PRIMARY KEY,
+    transaction_id bigint NOT NULL REFERENCES ledger_transactions (id) ON DELETE CASCADE,
+    account        varchar(32) NOT NULL,
+    debit          bigint NOT NULL DEFAULT 0,
+    credit         bigint NOT NULL DEFAULT 0
+);
+
+CREATE INDEX ledger_entries_transaction_id ON ledger_entries (transaction_id);
@@ -0,0 +1,50 @@
+-- Plans of the subscriptions granting access to all the paid published courses.
+CREATE TABLE IF NOT EXISTS subscription_plans (
+    id         bigserial PRIMARY KEY,
+    code       varchar(64) NOT NULL UNIQUE,
+    name       varchar(255) NOT NULL,
+    interval   varchar(16) NOT NULL,
+    price      bigint NOT NULL,
+    currency   varchar(3) NOT NULL,
+    active     boolean NOT NULL DEFAULT true,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+-- Subscriptions of the users, renewed by the recurring payments of the provider.
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id                   bigserial PRIMARY KEY,
+    user_id              bigint NOT NULL REFERENCES users (id),
+    plan_id              bigint NOT NULL REFERENCES subscription_plans (id),
+    status               varchar(32) NOT NULL,
+    amount               bigint NOT NULL,
+    currency             varchar(3) NOT NULL,
+    provider             varchar(32),
+    reference            varchar(255),
+    current_period_start timestamptz,
+    current_period_end   timestamptz,
+    canceled_at          timestamptz,
+    created_at           timestamptz,
+    updated_at           timestamptz
+);
+
+CREATE INDEX subscriptions_user_id ON subscriptions (user_id);
+
+-- Payments of the subscription periods.
+CREATE TABLE IF NOT EXISTS subscription_payments (
+    id              bigserial PRIMARY KEY,
+    subscription_id bigint NOT NULL REFERENCES subscriptions (id),
+    amount          bigint NOT NULL,
+    currency        varchar(3) NOT NULL,
+    period_start    timestamptz NOT NULL,
+    period_end      timestamptz NOT NULL,
+    created_at      timestamptz
+);
+
+CREATE INDEX subscription_payments_subscription_id ON subscription_payments (subscription_id);
+
+-- Enrollments through a subscription, which lose the content access when it lapses.
+ALTER TABLE enrollments ADD COLUMN subscription_id bigint REFERENCES subscriptions (id);
+
+-- Webhooks of the subscription payments.
+ALTER TABLE payment_events ADD COLUMN subscription_id bigint REFERENCES subscriptions (id);
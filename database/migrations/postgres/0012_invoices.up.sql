@@ -0,0 +1,49 @@
+-- Billing details of the users printed on the invoices.
+CREATE TABLE IF NOT EXISTS billing_profiles (
+    user_id    bigint PRIMARY KEY REFERENCES users (id),
+    company    varchar(255),
+    tax_id     varchar(32),
+    address    varchar(255),
+    country    varchar(2),
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+-- Last issued numbers of the invoice numbering series, e.g. INV-2026.
+CREATE TABLE IF NOT EXISTS invoice_counters (
+    series varchar(16) PRIMARY KEY,
+    last   bigint NOT NULL DEFAULT 0
+);
+
+-- Invoices of the purchases and credit notes of the refunds.
+CREATE TABLE IF NOT EXISTS invoices (
+    id                      bigserial PRIMARY KEY,
+    number                  varchar(32) NOT NULL UNIQUE,
+    kind                    varchar(16) NOT NULL,
+    user_id                 bigint NOT NULL REFERENCES users (id),
+    order_id                bigint REFERENCES orders (id),
+    subscription_payment_id bigint REFERENCES subscription_payments (id),
+    credited_invoice_id     bigint REFERENCES invoices (id),
+    description             varchar(255),
+    currency                varchar(3) NOT NULL,
+    net                     bigint NOT NULL,
+    vat                     bigint NOT NULL,
+    total                   bigint NOT NULL,
+    vat_rate                bigint NOT NULL,
+    reverse_charge          boolean NOT NULL DEFAULT false,
+    seller_name             varchar(255),
+    seller_address          varchar(255),
+    seller_tax_id           varchar(32),
+    seller_country          varchar(2),
+    buyer_name              varchar(255),
+    buyer_email             varchar(255),
+    buyer_address           varchar(255),
+    buyer_tax_id            varchar(32),
+    buyer_country           varchar(2),
+    storage_path            varchar(255),
+    issued_at               timestamptz NOT NULL,
+    created_at              timestamptz
+);
+
+CREATE INDEX invoices_user_id ON invoices (user_id);
+CREATE INDEX invoices_order_id ON invoices (order_id);
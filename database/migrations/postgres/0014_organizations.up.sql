@@ -0,0 +1,52 @@
+-- Organizations training their members in the courses they buy seats of.
+CREATE TABLE IF NOT EXISTS organizations (
+    id         bigserial PRIMARY KEY,
+    name       varchar(255) NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+-- Members of the organizations with their roles: owner, manager or learner.
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id bigint NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    user_id         bigint NOT NULL REFERENCES users (id),
+    role            varchar(16) NOT NULL,
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX organization_members_user_id ON organization_members (user_id);
+
+-- Seats of the paid courses bought by the organizations.
+CREATE TABLE IF NOT EXISTS seat_licenses (
+    id              bigserial PRIMARY KEY,
+    organization_id bigint NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    course_id       bigint NOT NULL REFERENCES courses (id),
+    seats           bigint NOT NULL,
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    UNIQUE (organization_id, course_id)
+);
+
+-- Courses assigned by the managers to the members, each taking a seat of a paid course.
+CREATE TABLE IF NOT EXISTS course_assignments (
+    id              bigserial PRIMARY KEY,
+    organization_id bigint NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    user_id         bigint NOT NULL REFERENCES users (id),
+    course_id       bigint NOT NULL REFERENCES courses (id),
+    assigned_by_id  bigint REFERENCES users (id),
+    due_at          timestamptz,
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    UNIQUE (organization_id, user_id, course_id)
+);
+
+CREATE INDEX course_assignments_user_id ON course_assignments (user_id);
+
+-- Orders of the seats of an organization.
+ALTER TABLE orders ADD COLUMN organization_id bigint REFERENCES organizations (id);
+ALTER TABLE orders ADD COLUMN seats bigint NOT NULL DEFAULT 0;
+
+-- Enrollments through the seats, which end with the assignment.
+ALTER TABLE enrollments ADD COLUMN organization_id bigint REFERENCES organizations (id);
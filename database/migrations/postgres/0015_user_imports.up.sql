@@ -0,0 +1,42 @@
+-- Bulk imports of the users from the CSV files, processed in the background.
+CREATE TABLE IF NOT EXISTS user_imports (
+    id              bigserial PRIMARY KEY,
+    user_id         bigint NOT NULL REFERENCES users (id),
+    organization_id bigint REFERENCES organizations (id) ON DELETE CASCADE,
+    status          varchar(16) NOT NULL,
+    total           bigint NOT NULL DEFAULT 0,
+    processed       bigint NOT NULL DEFAULT 0,
+    created         bigint NOT NULL DEFAULT 0,
+    matched         bigint NOT NULL DEFAULT 0,
+    failed          bigint NOT NULL DEFAULT 0,
+    error           text NOT NULL DEFAULT '',
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    finished_at     timestamptz
+);
+
+-- Results of the imported rows.
+CREATE TABLE IF NOT EXISTS user_import_rows (
+    id        bigserial PRIMARY KEY,
+    import_id bigint NOT NULL REFERENCES user_imports (id) ON DELETE CASCADE,
+    line      bigint NOT NULL,
+    email     varchar(255) NOT NULL,
+    user_id   bigint REFERENCES users (id),
+    result    varchar(16) NOT NULL,
+    error     text NOT NULL DEFAULT '',
+    invited   boolean NOT NULL DEFAULT false
+);
+
+CREATE INDEX user_import_rows_import_id ON user_import_rows (import_id);
+
+-- Invitations to set the password of the imported users; only the token hashes are stored.
+CREATE TABLE IF NOT EXISTS invitations (
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash  varchar(64) NOT NULL UNIQUE,
+    expires_at  timestamptz NOT NULL,
+    accepted_at timestamptz,
+    created_at  timestamptz
+);
+
+CREATE INDEX invitations_user_id ON invitations (user_id);
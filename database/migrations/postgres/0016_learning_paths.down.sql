@@ -0,0 +1,6 @@
+ALTER TABLE orders DROP COLUMN path_id;
+
+DROP TABLE IF EXISTS path_certificates;
+DROP TABLE IF EXISTS path_enrollments;
+DROP TABLE IF EXISTS learning_path_courses;
+DROP TABLE IF EXISTS learning_paths;
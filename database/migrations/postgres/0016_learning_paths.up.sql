@@ -0,0 +1,40 @@
+-- Learning paths: curated sequences of courses, optionally sold as a bundle.
+CREATE TABLE IF NOT EXISTS learning_paths (
+    id          bigserial PRIMARY KEY,
+    title       varchar(255) NOT NULL,
+    description text NOT NULL DEFAULT '',
+    price       bigint NOT NULL DEFAULT 0,
+    currency    varchar(3) NOT NULL,
+    created_at  timestamptz,
+    updated_at  timestamptz
+);
+
+-- Courses of the learning paths in order.
+CREATE TABLE IF NOT EXISTS learning_path_courses (
+    path_id   bigint NOT NULL REFERENCES learning_paths (id) ON DELETE CASCADE,
+    course_id bigint NOT NULL REFERENCES courses (id),
+    position  bigint NOT NULL,
+    PRIMARY KEY (path_id, course_id)
+);
+
+-- Enrollments of the users into the learning paths.
+CREATE TABLE IF NOT EXISTS path_enrollments (
+    path_id    bigint NOT NULL REFERENCES learning_paths (id) ON DELETE CASCADE,
+    user_id    bigint NOT NULL REFERENCES users (id),
+    created_at timestamptz,
+    PRIMARY KEY (path_id, user_id)
+);
+
+CREATE INDEX path_enrollments_user_id ON path_enrollments (user_id);
+
+-- Certificates of the completed learning paths.
+CREATE TABLE IF NOT EXISTS path_certificates (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL REFERENCES users (id),
+    path_id    bigint NOT NULL REFERENCES learning_paths (id),
+    created_at timestamptz,
+    UNIQUE (user_id, path_id)
+);
+
+-- Bundle orders of the learning paths.
+ALTER TABLE orders ADD COLUMN path_id bigint REFERENCES learning_paths (id);
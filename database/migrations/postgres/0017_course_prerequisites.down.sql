@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS course_prerequisites;
+
+ALTER TABLE courses DROP COLUMN enforce_prerequisites;
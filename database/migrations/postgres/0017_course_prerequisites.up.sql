@@ -0,0 +1,12 @@
+-- Whether the enrollments are blocked rather than warned while the prerequisites are incomplete.
+ALTER TABLE courses ADD COLUMN enforce_prerequisites boolean NOT NULL DEFAULT false;
+
+-- Courses to complete before the course, forming a DAG.
+CREATE TABLE IF NOT EXISTS course_prerequisites (
+    course_id       bigint NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+    prerequisite_id bigint NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+    created_at      timestamptz,
+    PRIMARY KEY (course_id, prerequisite_id)
+);
+
+CREATE INDEX course_prerequisites_prerequisite_id ON course_prerequisites (prerequisite_id);
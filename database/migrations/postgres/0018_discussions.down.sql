@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS discussion_post_votes;
+DROP TABLE IF EXISTS discussion_votes;
+ALTER TABLE discussions DROP COLUMN accepted_post_id;
+DROP TABLE IF EXISTS discussion_posts;
+DROP TABLE IF EXISTS discussions;
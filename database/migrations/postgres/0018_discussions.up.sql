@@ -0,0 +1,51 @@
+-- Discussions of the courses and their exercises with the posts, the replies and the upvotes.
+-- The Markdown content is stored as written together with its sanitized HTML rendering.
+CREATE TABLE IF NOT EXISTS discussions (
+    id                  bigserial PRIMARY KEY,
+    course_id           bigint NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+    exercise_id         bigint REFERENCES course_exercises (id) ON DELETE SET NULL,
+    user_id             bigint NOT NULL REFERENCES users (id),
+    title               varchar(255) NOT NULL,
+    content             varchar(65000),
+    content_html        text,
+    votes               bigint NOT NULL DEFAULT 0,
+    post_count          bigint NOT NULL DEFAULT 0,
+    instructor_answered boolean NOT NULL DEFAULT false,
+    accepted_post_id    bigint,
+    created_at          timestamptz,
+    updated_at          timestamptz
+);
+
+CREATE INDEX discussions_course_id ON discussions (course_id);
+CREATE INDEX discussions_exercise_id ON discussions (exercise_id);
+
+CREATE TABLE IF NOT EXISTS discussion_posts (
+    id            bigserial PRIMARY KEY,
+    discussion_id bigint NOT NULL REFERENCES discussions (id) ON DELETE CASCADE,
+    parent_id     bigint REFERENCES discussion_posts (id) ON DELETE CASCADE,
+    user_id       bigint NOT NULL REFERENCES users (id),
+    content       varchar(65000) NOT NULL,
+    content_html  text NOT NULL,
+    votes         bigint NOT NULL DEFAULT 0,
+    by_instructor boolean NOT NULL DEFAULT false,
+    created_at    timestamptz,
+    updated_at    timestamptz
+);
+
+CREATE INDEX discussion_posts_discussion_id ON discussion_posts (discussion_id);
+
+ALTER TABLE discussions ADD FOREIGN KEY (accepted_post_id) REFERENCES discussion_posts (id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS discussion_votes (
+    discussion_id bigint NOT NULL REFERENCES discussions (id) ON DELETE CASCADE,
+    user_id       bigint NOT NULL REFERENCES users (id),
+    created_at    timestamptz,
+    PRIMARY KEY (discussion_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS discussion_post_votes (
+    post_id    bigint NOT NULL REFERENCES discussion_posts (id) ON DELETE CASCADE,
+    user_id    bigint NOT NULL REFERENCES users (id),
+    created_at timestamptz,
+    PRIMARY KEY (post_id, user_id)
+);
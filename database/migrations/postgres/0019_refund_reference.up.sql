@@ -0,0 +1,2 @@
+-- The provider reference of the returned payment, recorded before the refund is completed.
+ALTER TABLE refunds ADD COLUMN reference varchar(255);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS user_import_courses;
+
+ALTER TABLE user_imports DROP COLUMN pending;
+ALTER TABLE invitations DROP COLUMN import_id;
+ALTER TABLE invitations DROP COLUMN organization_id;
@@ -0,0 +1,11 @@
+-- Invitations of the existing users to join the organizations of the imports, and the
+-- courses the imports assign once the invitations are accepted.
+ALTER TABLE invitations ADD COLUMN organization_id bigint REFERENCES organizations (id) ON DELETE CASCADE;
+ALTER TABLE invitations ADD COLUMN import_id bigint REFERENCES user_imports (id) ON DELETE CASCADE;
+ALTER TABLE user_imports ADD COLUMN pending bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS user_import_courses (
+    import_id bigint NOT NULL REFERENCES user_imports (id) ON DELETE CASCADE,
+    course_id bigint NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+    PRIMARY KEY (import_id, course_id)
+);
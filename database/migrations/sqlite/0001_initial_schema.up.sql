@@ -0,0 +1,114 @@
+CREATE TABLE IF NOT EXISTS course_statuses (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    title      varchar(255),
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS course_categories (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    title      varchar(255),
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS course_levels (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    title      varchar(255),
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS user_types (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    title      varchar(255),
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id           integer PRIMARY KEY AUTOINCREMENT,
+    profile_pic  text,
+    first_name   varchar(255),
+    last_name    varchar(255),
+    email        varchar(255) UNIQUE,
+    password     varchar(255),
+    user_type_id integer NOT NULL REFERENCES user_types (id),
+    created_at   datetime,
+    updated_at   datetime
+);
+
+CREATE TABLE IF NOT EXISTS courses (
+    id                integer PRIMARY KEY AUTOINCREMENT,
+    thumbnail         text,
+    title             varchar(255),
+    short_description varchar(255),
+    description       text,
+    level_id          integer NOT NULL REFERENCES course_levels (id),
+    status_id         integer NOT NULL REFERENCES course_statuses (id),
+    instructor_id     integer NOT NULL REFERENCES users (id),
+    length            integer,
+    price             integer,
+    has_certificate   numeric,
+    created_at        datetime,
+    updated_at        datetime
+);
+
+CREATE TABLE IF NOT EXISTS course_categories_junction (
+    course_id          integer REFERENCES courses (id),
+    course_category_id integer REFERENCES course_categories (id),
+    PRIMARY KEY (course_id, course_category_id)
+);
+
+CREATE TABLE IF NOT EXISTS course_certificates (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    user_id    integer NOT NULL REFERENCES users (id),
+    course_id  integer NOT NULL REFERENCES courses (id),
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS enrollment_statuses (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    title      varchar(255),
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS course_exercise_types (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    title      varchar(255),
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS course_exercises (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    title      varchar(255),
+    content    text,
+    length     integer,
+    course_id  integer NOT NULL REFERENCES courses (id),
+    type_id    integer NOT NULL REFERENCES course_exercise_types (id),
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS enrollments (
+    user_id          integer NOT NULL REFERENCES users (id),
+    course_id        integer NOT NULL REFERENCES courses (id),
+    progress         integer,
+    status_id        integer NOT NULL REFERENCES enrollment_statuses (id),
+    last_exercise_id integer NOT NULL,
+    created_at       datetime,
+    updated_at       datetime,
+    PRIMARY KEY (user_id, course_id)
+);
+
+CREATE TABLE IF NOT EXISTS teaching_applications (
+    user_id         integer PRIMARY KEY REFERENCES users (id),
+    experience      varchar(255),
+    motivation      varchar(255),
+    platform_choice varchar(255),
+    created_at      datetime,
+    updated_at      datetime
+);
@@ -0,0 +1,6 @@
+ALTER TABLE teaching_applications ADD COLUMN approved_at datetime;
+
+-- Applications of users that were already promoted count as approved.
+UPDATE teaching_applications
+SET approved_at = created_at
+WHERE user_id IN (SELECT id FROM users WHERE user_type_id >= 2);
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS course_exercises_search_delete;
+DROP TRIGGER IF EXISTS course_exercises_search_update;
+DROP TRIGGER IF EXISTS course_exercises_search_insert;
+DROP TRIGGER IF EXISTS courses_search_delete;
+DROP TRIGGER IF EXISTS courses_search_update;
+DROP TRIGGER IF EXISTS courses_search_insert;
+
+DROP TABLE IF EXISTS course_search;
@@ -0,0 +1,60 @@
+-- Full-text search over the courses, kept in an FTS5 table whose rowid is the course id.
+CREATE VIRTUAL TABLE course_search USING fts5(
+    title,
+    short_description,
+    exercises,
+    description,
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER courses_search_insert AFTER INSERT ON courses
+BEGIN
+    INSERT INTO course_search (rowid, title, short_description, exercises, description)
+    VALUES (NEW.id, coalesce(NEW.title, ''), coalesce(NEW.short_description, ''), '', coalesce(NEW.description, ''));
+END;
+
+CREATE TRIGGER courses_search_update AFTER UPDATE OF title, short_description, description ON courses
+BEGIN
+    UPDATE course_search
+    SET title = coalesce(NEW.title, ''),
+        short_description = coalesce(NEW.short_description, ''),
+        description = coalesce(NEW.description, '')
+    WHERE rowid = NEW.id;
+END;
+
+CREATE TRIGGER courses_search_delete AFTER DELETE ON courses
+BEGIN
+    DELETE FROM course_search WHERE rowid = OLD.id;
+END;
+
+CREATE TRIGGER course_exercises_search_insert AFTER INSERT ON course_exercises
+BEGIN
+    UPDATE course_search
+    SET exercises = (SELECT coalesce(group_concat(title, ' '), '') FROM course_exercises WHERE course_id = NEW.course_id)
+    WHERE rowid = NEW.course_id;
+END;
+
+CREATE TRIGGER course_exercises_search_update AFTER UPDATE OF title, course_id ON course_exercises
+BEGIN
+    UPDATE course_search
+    SET exercises = (SELECT coalesce(group_concat(title, ' '), '') FROM course_exercises WHERE course_id = OLD.course_id)
+    WHERE rowid = OLD.course_id;
+    UPDATE course_search
+    SET exercises = (SELECT coalesce(group_concat(title, ' '), '') FROM course_exercises WHERE course_id = NEW.course_id)
+    WHERE rowid = NEW.course_id;
+END;
+
+CREATE TRIGGER course_exercises_search_delete AFTER DELETE ON course_exercises
+BEGIN
+    UPDATE course_search
+    SET exercises = (SELECT coalesce(group_concat(title, ' '), '') FROM course_exercises WHERE course_id = OLD.course_id)
+    WHERE rowid = OLD.course_id;
+END;
+
+INSERT INTO course_search (rowid, title, short_description, exercises, description)
+SELECT id,
+       coalesce(title, ''),
+       coalesce(short_description, ''),
+       (SELECT coalesce(group_concat(e.title, ' '), '') FROM course_exercises e WHERE e.course_id = courses.id),
+       coalesce(description, '')
+FROM courses;
@@ -0,0 +1,31 @@
+-- Reviews of the enrolled learners, one per enrollment, and their helpful votes.
+CREATE TABLE IF NOT EXISTS course_reviews (
+    id            integer PRIMARY KEY AUTOINCREMENT,
+    user_id       integer NOT NULL REFERENCES users (id),
+    course_id     integer NOT NULL REFERENCES courses (id),
+    rating        integer NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    text          text,
+    reply         text,
+    replied_at    datetime,
+    helpful_count integer NOT NULL DEFAULT 0,
+    flagged       numeric NOT NULL DEFAULT false,
+    hidden        numeric NOT NULL DEFAULT false,
+    created_at    datetime,
+    updated_at    datetime,
+    UNIQUE (user_id, course_id),
+    FOREIGN KEY (user_id, course_id) REFERENCES enrollments (user_id, course_id) ON DELETE CASCADE
+);
+
+CREATE INDEX course_reviews_course_id ON course_reviews (course_id);
+
+CREATE TABLE IF NOT EXISTS course_review_votes (
+    review_id  integer NOT NULL REFERENCES course_reviews (id) ON DELETE CASCADE,
+    user_id    integer NOT NULL REFERENCES users (id),
+    created_at datetime,
+    PRIMARY KEY (review_id, user_id)
+);
+
+-- Ratings of the visible reviews cached on the courses.
+ALTER TABLE courses ADD COLUMN rating_average real NOT NULL DEFAULT 0;
+ALTER TABLE courses ADD COLUMN rating_count integer NOT NULL DEFAULT 0;
+ALTER TABLE courses ADD COLUMN rating_distribution text NOT NULL DEFAULT '[0,0,0,0,0]';
@@ -0,0 +1,23 @@
+-- Courses saved by the users for later.
+CREATE TABLE IF NOT EXISTS wishlist_items (
+    user_id    integer NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    course_id  integer NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+    created_at datetime,
+    PRIMARY KEY (user_id, course_id)
+);
+
+CREATE INDEX wishlist_items_course_id ON wishlist_items (course_id);
+
+-- In-app notifications, e.g. of the price drops of the wishlisted courses.
+CREATE TABLE IF NOT EXISTS notifications (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    user_id    integer NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind       varchar(64),
+    course_id  integer REFERENCES courses (id) ON DELETE SET NULL,
+    title      varchar(255),
+    body       text,
+    read_at    datetime,
+    created_at datetime
+);
+
+CREATE INDEX notifications_user_id ON notifications (user_id);
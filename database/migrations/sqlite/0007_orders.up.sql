@@ -0,0 +1,26 @@
+-- Course purchases, enrolling the users once paid.
+CREATE TABLE IF NOT EXISTS orders (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    user_id    integer NOT NULL REFERENCES users (id),
+    course_id  integer NOT NULL REFERENCES courses (id),
+    amount     integer NOT NULL,
+    currency   varchar(3) NOT NULL,
+    status     varchar(32) NOT NULL,
+    provider   varchar(32) NOT NULL,
+    reference  varchar(255),
+    paid_at    datetime,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE INDEX orders_user_id ON orders (user_id);
+
+-- Processed payment webhooks, so the duplicate deliveries are ignored.
+CREATE TABLE IF NOT EXISTS payment_events (
+    provider   varchar(32) NOT NULL,
+    event_id   varchar(255) NOT NULL,
+    order_id   integer REFERENCES orders (id),
+    status     varchar(32),
+    created_at datetime,
+    PRIMARY KEY (provider, event_id)
+);
@@ -0,0 +1,9 @@
+ALTER TABLE courses DROP COLUMN sale_ends_at;
+ALTER TABLE courses DROP COLUMN sale_starts_at;
+ALTER TABLE courses DROP COLUMN sale_price;
+
+ALTER TABLE orders DROP COLUMN discount;
+ALTER TABLE orders DROP COLUMN coupon_id;
+
+DROP TABLE IF EXISTS coupon_redemptions;
+DROP TABLE IF EXISTS coupons;
@@ -0,0 +1,40 @@
+-- Discount codes of the instructors (per course) and the admins (site-wide).
+CREATE TABLE IF NOT EXISTS coupons (
+    id               integer PRIMARY KEY AUTOINCREMENT,
+    code             varchar(64) NOT NULL UNIQUE,
+    creator_id       integer NOT NULL REFERENCES users (id),
+    course_id        integer REFERENCES courses (id) ON DELETE CASCADE,
+    kind             varchar(16) NOT NULL,
+    value            integer NOT NULL,
+    max_redemptions  integer NOT NULL DEFAULT 0,
+    redemption_count integer NOT NULL DEFAULT 0,
+    expires_at       datetime,
+    disabled         numeric NOT NULL DEFAULT false,
+    created_at       datetime,
+    updated_at       datetime
+);
+
+CREATE INDEX coupons_creator_id ON coupons (creator_id);
+
+-- Coupons used by the paid orders.
+CREATE TABLE IF NOT EXISTS coupon_redemptions (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    coupon_id  integer NOT NULL REFERENCES coupons (id) ON DELETE CASCADE,
+    order_id   integer NOT NULL UNIQUE REFERENCES orders (id),
+    user_id    integer NOT NULL REFERENCES users (id),
+    course_id  integer NOT NULL REFERENCES courses (id),
+    discount   integer NOT NULL,
+    amount     integer NOT NULL,
+    created_at datetime
+);
+
+CREATE INDEX coupon_redemptions_coupon_id ON coupon_redemptions (coupon_id);
+
+-- SQLite cannot drop the columns used in foreign keys, so the coupon is not referenced.
+ALTER TABLE orders ADD COLUMN coupon_id integer;
+ALTER TABLE orders ADD COLUMN discount integer NOT NULL DEFAULT 0;
+
+-- Time-limited sale prices of the courses.
+ALTER TABLE courses ADD COLUMN sale_price integer;
+ALTER TABLE courses ADD COLUMN sale_starts_at datetime;
+ALTER TABLE courses ADD COLUMN sale_ends_at datetime;
@@ -0,0 +1,30 @@
+-- Refund requests of the paid orders.
+CREATE TABLE IF NOT EXISTS refunds (
+    id          integer PRIMARY KEY AUTOINCREMENT,
+    order_id    integer NOT NULL REFERENCES orders (id),
+    user_id     integer NOT NULL REFERENCES users (id),
+    reason      text,
+    status      varchar(32) NOT NULL,
+    reviewer_id integer REFERENCES users (id),
+    note        text,
+    decided_at  datetime,
+    created_at  datetime,
+    updated_at  datetime
+);
+
+CREATE INDEX refunds_order_id ON refunds (order_id);
+
+-- Actions performed on the records, e.g. the refund decisions and the revocations.
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    actor_id   integer REFERENCES users (id),
+    action     varchar(64) NOT NULL,
+    entity     varchar(64) NOT NULL,
+    entity_id  varchar(64) NOT NULL,
+    details    text,
+    created_at datetime
+);
+
+CREATE INDEX audit_entries_entity ON audit_entries (entity, entity_id);
+
+ALTER TABLE course_certificates ADD COLUMN revoked_at datetime;
@@ -0,0 +1,25 @@
+-- Double-entry ledger of the instructor earnings: sales, refunds and payouts.
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+    id            integer PRIMARY KEY AUTOINCREMENT,
+    kind          varchar(32) NOT NULL,
+    instructor_id integer NOT NULL REFERENCES users (id),
+    order_id      integer REFERENCES orders (id),
+    currency      varchar(3) NOT NULL,
+    share         integer,
+    description   text,
+    created_by_id integer REFERENCES users (id),
+    created_at    datetime
+);
+
+CREATE INDEX ledger_transactions_instructor_id ON ledger_transactions (instructor_id, created_at);
+CREATE INDEX ledger_transactions_order_id ON ledger_transactions (order_id);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id             integer PRIMARY KEY AUTOINCREMENT,
+    transaction_id integer NOT NULL REFERENCES ledger_transactions (id) ON DELETE CASCADE,
+    account        varchar(32) NOT NULL,
+    debit          integer NOT NULL DEFAULT 0,
+    credit         integer NOT NULL DEFAULT 0
+);
+
+CREATE INDEX ledger_entries_transaction_id ON ledger_entries (transaction_id);
@@ -0,0 +1,6 @@
+ALTER TABLE payment_events DROP COLUMN subscription_id;
+ALTER TABLE enrollments DROP COLUMN subscription_id;
+
+DROP TABLE IF EXISTS subscription_payments;
+DROP TABLE IF EXISTS subscriptions;
+DROP TABLE IF EXISTS subscription_plans;
@@ -0,0 +1,50 @@
+-- Plans of the subscriptions granting access to all the paid published courses.
+CREATE TABLE IF NOT EXISTS subscription_plans (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    code       varchar(64) NOT NULL UNIQUE,
+    name       varchar(255) NOT NULL,
+    interval   varchar(16) NOT NULL,
+    price      integer NOT NULL,
+    currency   varchar(3) NOT NULL,
+    active     boolean NOT NULL DEFAULT true,
+    created_at datetime,
+    updated_at datetime
+);
+
+-- Subscriptions of the users, renewed by the recurring payments of the provider.
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id                   integer PRIMARY KEY AUTOINCREMENT,
+    user_id              integer NOT NULL REFERENCES users (id),
+    plan_id              integer NOT NULL REFERENCES subscription_plans (id),
+    status               varchar(32) NOT NULL,
+    amount               integer NOT NULL,
+    currency             varchar(3) NOT NULL,
+    provider             varchar(32),
+    reference            varchar(255),
+    current_period_start datetime,
+    current_period_end   datetime,
+    canceled_at          datetime,
+    created_at           datetime,
+    updated_at           datetime
+);
+
+CREATE INDEX subscriptions_user_id ON subscriptions (user_id);
+
+-- Payments of the subscription periods.
+CREATE TABLE IF NOT EXISTS subscription_payments (
+    id              integer PRIMARY KEY AUTOINCREMENT,
+    subscription_id integer NOT NULL REFERENCES subscriptions (id),
+    amount          integer NOT NULL,
+    currency        varchar(3) NOT NULL,
+    period_start    datetime NOT NULL,
+    period_end      datetime NOT NULL,
+    created_at      datetime
+);
+
+CREATE INDEX subscription_payments_subscription_id ON subscription_payments (subscription_id);
+
+-- Enrollments through a subscription, which lose the content access when it lapses.
+ALTER TABLE enrollments ADD COLUMN subscription_id integer;
+
+-- Webhooks of the subscription payments.
+ALTER TABLE payment_events ADD COLUMN subscription_id integer;
@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS exchange_rates;
+DROP TABLE IF EXISTS course_prices;
+
+ALTER TABLE users DROP COLUMN currency;
+ALTER TABLE coupons DROP COLUMN currency;
+ALTER TABLE courses DROP COLUMN currency;
+
+UPDATE invoices SET net = net / 100, vat = vat / 100, total = total / 100;
+UPDATE subscription_payments SET amount = amount / 100;
+UPDATE subscriptions SET amount = amount / 100;
+UPDATE subscription_plans SET price = price / 100;
+UPDATE ledger_entries SET debit = debit / 100, credit = credit / 100;
+UPDATE coupon_redemptions SET discount = discount / 100, amount = amount / 100;
+UPDATE coupons SET value = value / 100 WHERE kind = 'fixed';
+UPDATE orders SET amount = amount / 100, discount = discount / 100;
+UPDATE courses SET price = price / 100, sale_price = sale_price / 100;
@@ -0,0 +1,36 @@
+-- The amounts move from the main currency units to the minor ones, e.g. kopiyky.
+UPDATE courses SET price = price * 100, sale_price = sale_price * 100;
+UPDATE orders SET amount = amount * 100, discount = discount * 100;
+UPDATE coupons SET value = value * 100 WHERE kind = 'fixed';
+UPDATE coupon_redemptions SET discount = discount * 100, amount = amount * 100;
+UPDATE ledger_entries SET debit = debit * 100, credit = credit * 100;
+UPDATE subscription_plans SET price = price * 100;
+UPDATE subscriptions SET amount = amount * 100;
+UPDATE subscription_payments SET amount = amount * 100;
+UPDATE invoices SET net = net * 100, vat = vat * 100, total = total * 100;
+
+-- The currencies of the course prices and the fixed discounts.
+ALTER TABLE courses ADD COLUMN currency varchar(3) NOT NULL DEFAULT 'UAH';
+ALTER TABLE coupons ADD COLUMN currency varchar(3) NOT NULL DEFAULT 'UAH';
+
+-- Preferred currencies of the catalogue prices.
+ALTER TABLE users ADD COLUMN currency varchar(3);
+
+-- Course prices set by the instructors in the other currencies.
+CREATE TABLE IF NOT EXISTS course_prices (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    course_id  integer NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+    currency   varchar(3) NOT NULL,
+    amount     integer NOT NULL,
+    created_at datetime,
+    updated_at datetime,
+    UNIQUE (course_id, currency)
+);
+
+-- Exchange rates converting the other course prices, replaced by every import.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency   varchar(3) PRIMARY KEY,
+    base       varchar(3) NOT NULL,
+    rate       real NOT NULL,
+    updated_at datetime
+);
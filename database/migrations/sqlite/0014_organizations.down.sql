@@ -0,0 +1,8 @@
+ALTER TABLE enrollments DROP COLUMN organization_id;
+ALTER TABLE orders DROP COLUMN seats;
+ALTER TABLE orders DROP COLUMN organization_id;
+
+DROP TABLE IF EXISTS course_assignments;
+DROP TABLE IF EXISTS seat_licenses;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,52 @@
+-- Organizations training their members in the courses they buy seats of.
+CREATE TABLE IF NOT EXISTS organizations (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    name       varchar(255) NOT NULL,
+    created_at datetime,
+    updated_at datetime
+);
+
+-- Members of the organizations with their roles: owner, manager or learner.
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id integer NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    user_id         integer NOT NULL REFERENCES users (id),
+    role            varchar(16) NOT NULL,
+    created_at      datetime,
+    updated_at      datetime,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX organization_members_user_id ON organization_members (user_id);
+
+-- Seats of the paid courses bought by the organizations.
+CREATE TABLE IF NOT EXISTS seat_licenses (
+    id              integer PRIMARY KEY AUTOINCREMENT,
+    organization_id integer NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    course_id       integer NOT NULL REFERENCES courses (id),
+    seats           integer NOT NULL,
+    created_at      datetime,
+    updated_at      datetime,
+    UNIQUE (organization_id, course_id)
+);
+
+-- Courses assigned by the managers to the members, each taking a seat of a paid course.
+CREATE TABLE IF NOT EXISTS course_assignments (
+    id              integer PRIMARY KEY AUTOINCREMENT,
+    organization_id integer NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    user_id         integer NOT NULL REFERENCES users (id),
+    course_id       integer NOT NULL REFERENCES courses (id),
+    assigned_by_id  integer REFERENCES users (id),
+    due_at          datetime,
+    created_at      datetime,
+    updated_at      datetime,
+    UNIQUE (organization_id, user_id, course_id)
+);
+
+CREATE INDEX course_assignments_user_id ON course_assignments (user_id);
+
+-- Orders of the seats of an organization.
+ALTER TABLE orders ADD COLUMN organization_id integer;
+ALTER TABLE orders ADD COLUMN seats integer NOT NULL DEFAULT 0;
+
+-- Enrollments through the seats, which end with the assignment.
+ALTER TABLE enrollments ADD COLUMN organization_id integer;
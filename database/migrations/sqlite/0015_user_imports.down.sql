@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS invitations;
+DROP TABLE IF EXISTS user_import_rows;
+DROP TABLE IF EXISTS user_imports;
@@ -0,0 +1,42 @@
+-- Bulk imports of the users from the CSV files, processed in the background.
+CREATE TABLE IF NOT EXISTS user_imports (
+    id              integer PRIMARY KEY AUTOINCREMENT,
+    user_id         integer NOT NULL REFERENCES users (id),
+    organization_id integer REFERENCES organizations (id) ON DELETE CASCADE,
+    status          varchar(16) NOT NULL,
+    total           integer NOT NULL DEFAULT 0,
+    processed       integer NOT NULL DEFAULT 0,
+    created         integer NOT NULL DEFAULT 0,
+    matched         integer NOT NULL DEFAULT 0,
+    failed          integer NOT NULL DEFAULT 0,
+    error           text NOT NULL DEFAULT '',
+    created_at      datetime,
+    updated_at      datetime,
+    finished_at     datetime
+);
+
+-- Results of the imported rows.
+CREATE TABLE IF NOT EXISTS user_import_rows (
+    id        integer PRIMARY KEY AUTOINCREMENT,
+    import_id integer NOT NULL REFERENCES user_imports (id) ON DELETE CASCADE,
+    line      integer NOT NULL,
+    email     varchar(255) NOT NULL,
+    user_id   integer REFERENCES users (id),
+    result    varchar(16) NOT NULL,
+    error     text NOT NULL DEFAULT '',
+    invited   boolean NOT NULL DEFAULT false
+);
+
+CREATE INDEX user_import_rows_import_id ON user_import_rows (import_id);
+
+-- Invitations to set the password of the imported users; only the token hashes are stored.
+CREATE TABLE IF NOT EXISTS invitations (
+    id          integer PRIMARY KEY AUTOINCREMENT,
+    user_id     integer NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash  varchar(64) NOT NULL UNIQUE,
+    expires_at  datetime NOT NULL,
+    accepted_at datetime,
+    created_at  datetime
+);
+
+CREATE INDEX invitations_user_id ON invitations (user_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS discussion_post_votes;
+DROP TABLE IF EXISTS discussion_votes;
+DROP TABLE IF EXISTS discussion_posts;
+DROP TABLE IF EXISTS discussions;
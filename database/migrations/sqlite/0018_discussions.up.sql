@@ -0,0 +1,49 @@
+-- Discussions of the courses and their exercises with the posts, the replies and the upvotes.
+-- The Markdown content is stored as written together with its sanitized HTML rendering.
+CREATE TABLE IF NOT EXISTS discussions (
+    id                  integer PRIMARY KEY AUTOINCREMENT,
+    course_id           integer NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+    exercise_id         integer REFERENCES course_exercises (id) ON DELETE SET NULL,
+    user_id             integer NOT NULL REFERENCES users (id),
+    title               text NOT NULL,
+    content             text,
+    content_html        text,
+    votes               integer NOT NULL DEFAULT 0,
+    post_count          integer NOT NULL DEFAULT 0,
+    instructor_answered numeric NOT NULL DEFAULT false,
+    accepted_post_id    integer REFERENCES discussion_posts (id) ON DELETE SET NULL,
+    created_at          datetime,
+    updated_at          datetime
+);
+
+CREATE INDEX discussions_course_id ON discussions (course_id);
+CREATE INDEX discussions_exercise_id ON discussions (exercise_id);
+
+CREATE TABLE IF NOT EXISTS discussion_posts (
+    id            integer PRIMARY KEY AUTOINCREMENT,
+    discussion_id integer NOT NULL REFERENCES discussions (id) ON DELETE CASCADE,
+    parent_id     integer REFERENCES discussion_posts (id) ON DELETE CASCADE,
+    user_id       integer NOT NULL REFERENCES users (id),
+    content       text NOT NULL,
+    content_html  text NOT NULL,
+    votes         integer NOT NULL DEFAULT 0,
+    by_instructor numeric NOT NULL DEFAULT false,
+    created_at    datetime,
+    updated_at    datetime
+);
+
+CREATE INDEX discussion_posts_discussion_id ON discussion_posts (discussion_id);
+
+CREATE TABLE IF NOT EXISTS discussion_votes (
+    discussion_id integer NOT NULL REFERENCES discussions (id) ON DELETE CASCADE,
+    user_id       integer NOT NULL REFERENCES users (id),
+    created_at    datetime,
+    PRIMARY KEY (discussion_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS discussion_post_votes (
+    post_id    integer NOT NULL REFERENCES discussion_posts (id) ON DELETE CASCADE,
+    user_id    integer NOT NULL REFERENCES users (id),
+    created_at datetime,
+    PRIMARY KEY (post_id, user_id)
+);
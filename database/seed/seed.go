@@ -0,0 +1,375 @@
+package seed
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+const (
+	// DatasetReference holds the reference data (user types, statuses, levels, ...) required in every environment.
+	DatasetReference = "reference"
+	// DatasetDev holds the demo users and courses used in development.
+	DatasetDev = "dev"
+)
+
+// Fixtures returns the embedded fixture datasets.
+func Fixtures() fs.FS {
+	sub, _ := fs.Sub(fixtures, "fixtures")
+	return sub
+}
+
+// referenceFixture is a row of a reference table identified by its title.
+type referenceFixture struct {
+	ID    uint
+	Title string
+}
+
+// userFixture is a user identified by the email.
+type userFixture struct {
+	FirstName string
+	LastName  string
+	Email     string
+	Password  string
+	UserType  string
+}
+
+// courseFixture is a course identified by the title. The related records are referenced by their natural keys.
+type courseFixture struct {
+	Title            string
+	Thumbnail        string
+	ShortDescription string
+	Description      string
+	Instructor       string
+	Level            string
+	Status           string
+	Categories       []string
+	Price            uint
+	HasCertificate   bool
+}
+
+// learningPathFixture is a learning path identified by the title. Its courses are
+// referenced by their titles in order.
+type learningPathFixture struct {
+	Title       string
+	Description string
+	Courses     []string
+	Price       uint
+	Currency    string
+}
+
+// Result is the outcome of seeding a single fixture file.
+type Result struct {
+	Dataset string
+	File    string
+	Created int
+	Skipped int
+}
+
+// Seeder loads the fixture datasets into the database. Records are matched by their
+// natural keys, so running the seeder repeatedly only creates the missing ones.
+type Seeder struct {
+	db   *gorm.DB
+	fsys fs.FS
+}
+
+// New creates a new Seeder reading the datasets from fsys.
+func New(db *gorm.DB, fsys fs.FS) *Seeder {
+	return &Seeder{db: db, fsys: fsys}
+}
+
+// Seed loads the given datasets in order.
+func (s *Seeder) Seed(datasets ...string) ([]Result, error) {
+	var results []Result
+
+	for _, dataset := range datasets {
+		files, err := s.datasetFiles(dataset)
+		if err != nil {
+			return results, err
+		}
+
+		for _, file := range files {
+			var result Result
+			err := s.db.Transaction(func(tx *gorm.DB) error {
+				var err error
+				result, err = s.seedFile(tx, dataset, file)
+				return err
+			})
+			if err != nil {
+				return results, fmt.Errorf("error seeding %s/%s: %v", dataset, file, err)
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// datasetFiles returns the fixture files of the dataset. Users are seeded before
+// courses and courses before learning paths, all after the reference tables they depend on.
+func (s *Seeder) datasetFiles(dataset string) ([]string, error) {
+	entries, err := fs.ReadDir(s.fsys, dataset)
+	if err != nil {
+		return nil, fmt.Errorf("unknown dataset %s: %v", dataset, err)
+	}
+
+	rank := map[string]int{"users.json": 1, "courses.json": 2, "learning_paths.json": 3}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			files = append(files, entry.Name())
+		}
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		if rank[files[i]] != rank[files[j]] {
+			return rank[files[i]] < rank[files[j]]
+		}
+		return files[i] < files[j]
+	})
+
+	return files, nil
+}
+
+// seedFile seeds a single fixture file.
+func (s *Seeder) seedFile(tx *gorm.DB, dataset, file string) (Result, error) {
+	result := Result{Dataset: dataset, File: file}
+
+	content, err := fs.ReadFile(s.fsys, path.Join(dataset, file))
+	if err != nil {
+		return result, err
+	}
+
+	switch file {
+	case "users.json":
+		var rows []userFixture
+		if err := json.Unmarshal(content, &rows); err != nil {
+			return result, err
+		}
+		for _, row := range rows {
+			created, err := seedUser(tx, row)
+			if err != nil {
+				return result, err
+			}
+			result.count(created)
+		}
+
+	case "courses.json":
+		var rows []courseFixture
+		if err := json.Unmarshal(content, &rows); err != nil {
+			return result, err
+		}
+		for _, row := range rows {
+			created, err := seedCourse(tx, row)
+			if err != nil {
+				return result, err
+			}
+			result.count(created)
+		}
+
+	case "learning_paths.json":
+		var rows []learningPathFixture
+		if err := json.Unmarshal(content, &rows); err != nil {
+			return result, err
+		}
+		for _, row := range rows {
+			created, err := seedLearningPath(tx, row)
+			if err != nil {
+				return result, err
+			}
+			result.count(created)
+		}
+
+	default:
+		var rows []referenceFixture
+		if err := json.Unmarshal(content, &rows); err != nil {
+			return result, err
+		}
+		table := strings.TrimSuffix(file, ".json")
+		for _, row := range rows {
+			created, err := seedReference(tx, table, row)
+			if err != nil {
+				return result, err
+			}
+			result.count(created)
+		}
+		if result.Created > 0 {
+			if err := resetSequence(tx, table); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// count records whether a row was created or skipped.
+func (r *Result) count(created bool) {
+	if created {
+		r.Created++
+	} else {
+		r.Skipped++
+	}
+}
+
+// seedReference creates the reference row unless a row with the same title exists.
+func seedReference(tx *gorm.DB, table string, row referenceFixture) (bool, error) {
+	var count int64
+	if err := tx.Table(table).Where("title = ?", row.Title).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	if count > 0 {
+		return false, nil
+	}
+
+	values := map[string]interface{}{
+		"title":      row.Title,
+		"created_at": time.Now(),
+		"updated_at": time.Now(),
+	}
+	if row.ID != 0 {
+		values["id"] = row.ID
+	}
+
+	return true, tx.Table(table).Create(values).Error
+}
+
+// resetSequence moves the id sequence of the table past the explicitly inserted ids (PostgreSQL only).
+func resetSequence(tx *gorm.DB, table string) error {
+	if tx.Dialector.Name() != "postgres" {
+		return nil
+	}
+
+	return tx.Exec(fmt.Sprintf(
+		"SELECT setval(pg_get_serial_sequence('%s', 'id'), (SELECT MAX(id) FROM %s))", table, table,
+	)).Error
+}
+
+// seedUser creates the user unless a user with the same email exists.
+func seedUser(tx *gorm.DB, row userFixture) (bool, error) {
+	var count int64
+	if err := tx.Model(&models.User{}).Where("email = ?", row.Email).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	if count > 0 {
+		return false, nil
+	}
+
+	var userType models.UserType
+	if err := tx.First(&userType, "title = ?", row.UserType).Error; err != nil {
+		return false, fmt.Errorf("user type %q: %v", row.UserType, err)
+	}
+
+	user := models.User{
+		FirstName:  row.FirstName,
+		LastName:   row.LastName,
+		Email:      row.Email,
+		UserTypeID: userType.ID,
+	}
+
+	if row.Password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(row.Password), 10)
+		if err != nil {
+			return false, err
+		}
+		user.Password = string(hashedPassword)
+	}
+
+	return true, tx.Create(&user).Error
+}
+
+// seedCourse creates the course unless a course with the same title exists.
+func seedCourse(tx *gorm.DB, row courseFixture) (bool, error) {
+	var count int64
+	if err := tx.Model(&models.Course{}).Where("title = ?", row.Title).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	if count > 0 {
+		return false, nil
+	}
+
+	var instructor models.User
+	if err := tx.First(&instructor, "email = ?", row.Instructor).Error; err != nil {
+		return false, fmt.Errorf("instructor %q: %v", row.Instructor, err)
+	}
+
+	var level models.CourseLevel
+	if err := tx.First(&level, "title = ?", row.Level).Error; err != nil {
+		return false, fmt.Errorf("course level %q: %v", row.Level, err)
+	}
+
+	var status models.CourseStatus
+	if err := tx.First(&status, "title = ?", row.Status).Error; err != nil {
+		return false, fmt.Errorf("course status %q: %v", row.Status, err)
+	}
+
+	var categories []models.CourseCategory
+	if len(row.Categories) > 0 {
+		if err := tx.Where("title IN ?", row.Categories).Find(&categories).Error; err != nil {
+			return false, err
+		}
+		if len(categories) != len(row.Categories) {
+			return false, fmt.Errorf("unknown course categories in %v", row.Categories)
+		}
+	}
+
+	course := models.Course{
+		Title:            row.Title,
+		Thumbnail:        row.Thumbnail,
+		ShortDescription: row.ShortDescription,
+		Description:      row.Description,
+		Categories:       categories,
+		InstructorID:     instructor.ID,
+		LevelID:          level.ID,
+		StatusID:         status.ID,
+		Price:            row.Price,
+		HasCertificate:   row.HasCertificate,
+	}
+
+	return true, tx.Create(&course).Error
+}
+
+// seedLearningPath creates the learning path unless a path with the same title exists.
+func seedLearningPath(tx *gorm.DB, row learningPathFixture) (bool, error) {
+	var count int64
+	if err := tx.Model(&models.LearningPath{}).Where("title = ?", row.Title).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	if count > 0 {
+		return false, nil
+	}
+
+	path := models.LearningPath{
+		Title:       row.Title,
+		Description: row.Description,
+		Price:       row.Price,
+		Currency:    row.Currency,
+	}
+
+	for i, title := range row.Courses {
+		var course models.Course
+		if err := tx.First(&course, "title = ?", title).Error; err != nil {
+			return false, fmt.Errorf("course %q: %v", title, err)
+		}
+		path.Courses = append(path.Courses, models.LearningPathCourse{CourseID: course.ID, Position: uint(i + 1)})
+	}
+
+	return true, tx.Omit("Courses.Course").Create(&path).Error
+}
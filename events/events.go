@@ -0,0 +1,71 @@
+// Package events implements the in-process publishing of the domain events, letting the
+// parts of the app react to the changes without the services knowing about them.
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Topics of the events.
+const (
+	CourseCreated      = "course.created"
+	CourseUpdated      = "course.updated"
+	CoursePriceDropped = "course.price_dropped"
+	UserCreated        = "user.created"
+	UserUpdated        = "user.updated"
+)
+
+// Event is a change of the record with the given ID.
+type Event struct {
+	Topic string
+	ID    uint
+	// Payload holds the details of the change specific to the topic, e.g. a PriceChange.
+	Payload any
+}
+
+// PriceChange is the payload of the CoursePriceDropped events.
+type PriceChange struct {
+	Old uint
+	New uint
+}
+
+// Handler handles the published events. The handlers run synchronously in the publishing
+// goroutine, so they must hand off any long work.
+type Handler func(ctx context.Context, e Event)
+
+// Bus dispatches the published events to the subscribed handlers.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// NewBus creates a new Bus.
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe subscribes the handler to the topics.
+func (b *Bus) Subscribe(handler Handler, topics ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, topic := range topics {
+		b.handlers[topic] = append(b.handlers[topic], handler)
+	}
+}
+
+// Publish calls the handlers subscribed to the topic of the event. Publishing on a nil Bus does nothing.
+func (b *Bus) Publish(ctx context.Context, e Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	handlers := b.handlers[e.Topic]
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(ctx, e)
+	}
+}
@@ -0,0 +1,171 @@
+// Package invoices renders the invoices and the credit notes as PDF.
+package invoices
+
+import (
+	"fmt"
+	"github.com/fogleman/gg"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/pdf"
+	"github.com/plaja-app/back-end/services"
+	"github.com/plaja-app/back-end/storage"
+	"image"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Page layout: A4 rendered at 150 DPI.
+const (
+	pageWidth  = 1240
+	pageHeight = 1754
+	pageMargin = 90
+	lineHeight = 34
+)
+
+// columns are the right edges of the amount columns of the invoice table.
+var columns = [...]float64{820, 960, pageWidth - pageMargin}
+
+// Renderer renders the invoices as PDF using the fonts of the storage.
+type Renderer struct {
+	StorageRoot string
+}
+
+// NewRenderer creates a new Renderer using the given storage root.
+func NewRenderer(storageRoot string) *Renderer {
+	return &Renderer{StorageRoot: storageRoot}
+}
+
+// Path returns the private storage-relative path of the document of the invoice.
+func Path(invoice models.Invoice) string {
+	return path.Join(storage.Private, "invoices", invoice.Number+".pdf")
+}
+
+// WritePDF renders the invoice and writes it as a single page PDF.
+func (r *Renderer) WritePDF(w io.Writer, invoice models.Invoice) error {
+	regular, err := gg.LoadFontFace(r.fontPath("Onest-Regular"), 22)
+	if err != nil {
+		return fmt.Errorf("error loading font Onest-Regular: %v", err)
+	}
+
+	medium, err := gg.LoadFontFace(r.fontPath("Onest-Medium"), 22)
+	if err != nil {
+		return fmt.Errorf("error loading font Onest-Medium: %v", err)
+	}
+
+	title, err := gg.LoadFontFace(r.fontPath("Onest-Medium"), 40)
+	if err != nil {
+		return fmt.Errorf("error loading font Onest-Medium: %v", err)
+	}
+
+	dc := gg.NewContext(pageWidth, pageHeight)
+	dc.SetRGB(1, 1, 1)
+	dc.Clear()
+	dc.SetRGB(0, 0, 0)
+	y := float64(pageMargin)
+
+	line := func(text string) {
+		if text != "" {
+			dc.DrawString(text, pageMargin, y)
+			y += lineHeight
+		}
+	}
+
+	dc.SetFontFace(title)
+	heading := "Рахунок-фактура № " + invoice.Number
+	if invoice.Kind == services.InvoiceKindCreditNote {
+		heading = "Кредитна нота № " + invoice.Number
+	}
+	dc.DrawString(heading, pageMargin, y)
+	y += lineHeight * 2
+
+	dc.SetFontFace(regular)
+	line("дата: " + invoice.IssuedAt.UTC().Format("02.01.2006"))
+	if credited := invoice.CreditedInvoice; credited != nil {
+		line("до рахунку-фактури № " + credited.Number + " від " + credited.IssuedAt.UTC().Format("02.01.2006"))
+	}
+	y += lineHeight
+
+	party := func(label, name, address, taxID, country, email string) {
+		dc.SetFontFace(medium)
+		line(label)
+		dc.SetFontFace(regular)
+		line(name)
+		line(address)
+		line(country)
+		line(email)
+		if taxID != "" {
+			line("податковий номер: " + taxID)
+		}
+		y += lineHeight
+	}
+	party("Продавець", invoice.SellerName, invoice.SellerAddress, invoice.SellerTaxID, invoice.SellerCountry, "")
+	party("Покупець", invoice.BuyerName, invoice.BuyerAddress, invoice.BuyerTaxID, invoice.BuyerCountry, invoice.BuyerEmail)
+
+	dc.SetFontFace(medium)
+	dc.DrawString("опис", pageMargin, y)
+	for i, title := range [...]string{"без ПДВ", "ПДВ " + formatRate(invoice.VATRate), "сума"} {
+		dc.DrawStringAnchored(title, columns[i], y, 1, 0)
+	}
+	y += lineHeight / 2
+	dc.DrawLine(pageMargin, y, pageWidth-pageMargin, y)
+	dc.Stroke()
+	y += lineHeight
+
+	dc.SetFontFace(regular)
+	dc.DrawString(truncate(dc, invoice.Description, columns[0]-pageMargin-160), pageMargin, y)
+	for i, amount := range [...]uint{invoice.Net, invoice.VAT, invoice.Total} {
+		dc.DrawStringAnchored(formatMoney(amount, invoice.Currency), columns[i], y, 1, 0)
+	}
+	y += lineHeight * 2
+
+	dc.SetFontFace(medium)
+	total := "разом до сплати: "
+	if invoice.Kind == services.InvoiceKindCreditNote {
+		total = "разом до повернення: "
+	}
+	dc.DrawStringAnchored(total+formatMoney(invoice.Total, invoice.Currency), columns[2], y, 1, 0)
+	y += lineHeight
+	dc.SetFontFace(regular)
+	dc.DrawStringAnchored("у тому числі ПДВ: "+formatMoney(invoice.VAT, invoice.Currency), columns[2], y, 1, 0)
+	y += lineHeight * 2
+
+	if invoice.ReverseCharge {
+		line("Зворотне оподаткування: ПДВ сплачує покупець.")
+	}
+
+	return pdf.Write(w, []image.Image{dc.Image()})
+}
+
+// fontPath returns the filesystem path of the service font.
+func (r *Renderer) fontPath(font string) string {
+	return filepath.Join(r.StorageRoot, "service", "fonts", font+".ttf")
+}
+
+// truncate shortens the text to fit the width, ending it with an ellipsis.
+func truncate(dc *gg.Context, text string, width float64) string {
+	if w, _ := dc.MeasureString(text); w <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if w, _ := dc.MeasureString(string(runes) + "…"); w <= width {
+			break
+		}
+	}
+	return string(runes) + "…"
+}
+
+// formatMoney formats the amount in the minor units with the currency, e.g. "599,00 UAH".
+func formatMoney(amount uint, currency string) string {
+	return strings.Replace(services.FormatMoney(int64(amount), currency), ".", ",", 1)
+}
+
+// formatRate formats the VAT rate in hundredths of a percent, e.g. "20%" or "25,5%".
+func formatRate(rate uint) string {
+	s := strconv.FormatFloat(float64(rate)/100, 'f', -1, 64)
+	return strings.Replace(s, ".", ",", 1) + "%"
+}
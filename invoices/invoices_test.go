@@ -0,0 +1,40 @@
+package invoices
+
+import (
+	"bytes"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWritePDF(t *testing.T) {
+	r := NewRenderer(filepath.Join("..", "storage"))
+
+	invoice := services.NewInvoice(services.DefaultSeller, models.User{FirstName: "Тарас", LastName: "Шевченко", Email: "taras@plaja.test"},
+		models.BillingProfile{Company: "Kurs Sp. z o.o.", TaxID: "PL1234567890", Country: "PL"},
+		"Курс «Використання мікросервісів у Go»", "UAH", 599, time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC))
+	invoice.Number = "INV-2024-000001"
+
+	note := services.NewCreditNote(invoice, time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC))
+	note.Number = "CN-2024-000001"
+	note.CreditedInvoice = &invoice
+
+	for _, i := range []models.Invoice{invoice, note} {
+		var buf bytes.Buffer
+		if err := r.WritePDF(&buf, i); err != nil {
+			t.Fatal(err)
+		}
+
+		pdf := buf.String()
+		if !strings.HasPrefix(pdf, "%PDF-1.4") || !strings.HasSuffix(pdf, "%%EOF\n") || !strings.Contains(pdf, "/Count 1") {
+			t.Fatalf("%s: unexpected PDF structure", i.Number)
+		}
+	}
+
+	if p := Path(invoice); p != "private/invoices/INV-2024-000001.pdf" {
+		t.Errorf("unexpected path %q", p)
+	}
+}
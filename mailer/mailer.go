@@ -0,0 +1,73 @@
+// Package mailer sends the emails of the app.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// Message is an email message with a plain text body.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends the emails.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Log is a Mailer writing the emails to the log instead of sending them, for development.
+type Log struct{}
+
+// Send writes the message to the log.
+func (Log) Send(ctx context.Context, msg Message) error {
+	log.Printf("email to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// headerValue removes the line breaks from the header values.
+var headerValue = strings.NewReplacer("\r", " ", "\n", " ")
+
+// SMTP is a Mailer sending the emails through an SMTP server.
+type SMTP struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTP creates a new SMTP mailer sending from the given address through the server at
+// addr ("host:port"). The server is authenticated with PLAIN if the username is set.
+func NewSMTP(addr, username, password, from string) *SMTP {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTP{addr: addr, auth: auth, from: from}
+}
+
+// Send sends the message.
+func (m *SMTP) Send(ctx context.Context, msg Message) error {
+	if strings.ContainsAny(msg.To, "\r\n") {
+		return fmt.Errorf("invalid recipient %q", msg.To)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", m.from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerValue.Replace(msg.Subject)))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, []byte(b.String()))
+}
@@ -0,0 +1,37 @@
+// Package markdown renders the Markdown written by the users to HTML that the clients can
+// embed into the pages as it is.
+package markdown
+
+import (
+	"bytes"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"regexp"
+)
+
+var (
+	// renderer parses CommonMark with the GitHub tables, strikethrough and autolinks. The raw
+	// HTML is left out of the output.
+	renderer = goldmark.New(goldmark.WithExtensions(extension.Table, extension.Strikethrough, extension.Linkify))
+
+	// policy allows the elements and the attributes of the user content and only the http,
+	// https, mailto and relative URLs.
+	policy = newPolicy()
+)
+
+// newPolicy returns the sanitizer policy of the rendered HTML.
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	return p
+}
+
+// Render returns the Markdown rendered to HTML sanitized against the allowlist of the user
+// content, e.g. without scripts, event handlers or "javascript:" links.
+func Render(src string) string {
+	var buf bytes.Buffer
+	// writing to a bytes.Buffer does not fail
+	_ = renderer.Convert([]byte(src), &buf)
+	return policy.Sanitize(buf.String())
+}
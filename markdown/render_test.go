@@ -0,0 +1,47 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"**Як** запустити `go test`?", "<p><strong>Як</strong> запустити <code>go test</code>?</p>\n"},
+		{"```go\nfmt.Println(\"<b>\")\n```", "<pre><code class=\"language-go\">fmt.Println(&#34;&lt;b&gt;&#34;)\n</code></pre>\n"},
+		{"a < b", "<p>a &lt; b</p>\n"},
+		{"[документація](https://go.dev/doc)", "<p><a href=\"https://go.dev/doc\" rel=\"nofollow\">документація</a></p>\n"},
+		{"<script>alert(1)</script>", "\n"},
+		{"<img src=x onerror=alert(1)>", "\n"},
+	}
+
+	for _, tt := range tests {
+		if got := Render(tt.src); got != tt.want {
+			t.Errorf("Render(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestRenderUnsafeLinks(t *testing.T) {
+	for _, src := range []string{
+		"[x](javascript:alert(1))",
+		"[x]( javascript:alert(1))",
+		"[x](\njavascript:alert(1))",
+		"[x](<java script:alert(1)>)",
+		"[x](&#106;avascript:alert(1))",
+		"![x](JaVaScRiPt:alert(1))",
+		"[a]:\n  javascript:alert(1)\n\n[a]",
+		"[a]: data:text/html;base64,PHNjcmlwdD4=\n\n[a]",
+		"<javascript:alert(1)>",
+		"<a href=\"javascript:alert(1)\">x</a>",
+		"- item\n  ```\n<script>alert(1)</script>\n  ```",
+	} {
+		got := strings.ToLower(Render(src))
+		if strings.Contains(got, "=\"javascript") || strings.Contains(got, "=\"data:") || strings.Contains(got, "<script") {
+			t.Errorf("Render(%q) = %q", src, got)
+		}
+	}
+}
@@ -1,23 +1,20 @@
 package middleware
 
-import "github.com/plaja-app/back-end/config"
-
-// Middleware the repository used by the middlware.
-var Middleware *BaseMiddleware
+import (
+	"github.com/plaja-app/back-end/config"
+	"github.com/plaja-app/back-end/services"
+)
 
 // BaseMiddleware holds the base information needed for middleware.
 type BaseMiddleware struct {
-	App *config.AppConfig
+	App   *config.AppConfig
+	Users services.UserService
 }
 
 // NewBaseMiddleware creates a new BaseMiddleware.
-func NewBaseMiddleware(app *config.AppConfig) *BaseMiddleware {
+func NewBaseMiddleware(app *config.AppConfig, users services.UserService) *BaseMiddleware {
 	return &BaseMiddleware{
-		App: app,
+		App:   app,
+		Users: users,
 	}
 }
-
-// NewMiddleware sets the repository for the middleware.
-func NewMiddleware(m *BaseMiddleware) {
-	Middleware = m
-}
@@ -12,45 +12,66 @@ import (
 // RequireAuth is a middleware that checks for the presence and validity of a JWT in the request cookie.
 func (m *BaseMiddleware) RequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// get the cookie of request
-		tokenCookie, err := r.Cookie("pja_user_jwt")
-		if err != nil {
+		user, ok := m.authenticate(r)
+		if !ok {
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 
-		// decode/validate it
-		token, err := jwt.Parse(tokenCookie.Value, func(token *jwt.Token) (any, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
+		ctx := context.WithValue(r.Context(), "user", user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// OptionalAuth is a middleware that sets the user of a valid JWT in the request cookie,
+// as RequireAuth does, but lets the anonymous requests through.
+func (m *BaseMiddleware) OptionalAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, ok := m.authenticate(r); ok {
+			r = r.WithContext(context.WithValue(r.Context(), "user", user))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
 
-			return []byte(m.App.Env.JWTSecret), nil
-		})
+// authenticate returns the user of the JWT in the request cookie.
+func (m *BaseMiddleware) authenticate(r *http.Request) (models.User, bool) {
+	// get the cookie of request
+	tokenCookie, err := r.Cookie("pja_user_jwt")
+	if err != nil {
+		return models.User{}, false
+	}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			// check the exp
-			if float64(time.Now().Unix()) > claims["exp"].(float64) {
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
-				return
-			}
+	// decode/validate it
+	token, err := jwt.Parse(tokenCookie.Value, func(token *jwt.Token) (any, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
 
-			// find the user with token sub
-			var user models.User
+		return []byte(m.App.Env.JWTSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return models.User{}, false
+	}
 
-			m.App.DB.Preload("UserType").First(&user, "id = ?", claims["sub"])
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return models.User{}, false
+	}
 
-			if user.ID == 0 {
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
-				return
-			}
+	// check the exp
+	exp, _ := claims["exp"].(float64)
+	if float64(time.Now().Unix()) > exp {
+		return models.User{}, false
+	}
 
-			ctx := context.WithValue(r.Context(), "user", user)
+	// find the user with token sub
+	sub, _ := claims["sub"].(float64)
+	user, err := m.Users.Get(r.Context(), uint(sub))
+	if err != nil {
+		return models.User{}, false
+	}
 
-			next.ServeHTTP(w, r.WithContext(ctx))
-		} else {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
-	})
+	return user, true
 }
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// AuditEntry records an action performed on a record, e.g. the approval of a refund.
+type AuditEntry struct {
+	ID uint
+	// ActorID is the user performing the action; nil for the actions of the system.
+	ActorID *uint
+	Actor   *User  `json:"-"`
+	Action  string `gorm:"size:64"`
+	// Entity and EntityID identify the record, e.g. "enrollments" and "3:7" for the
+	// composite keys.
+	Entity   string `gorm:"size:64"`
+	EntityID string `gorm:"size:64"`
+	// Details is the JSON of the action details.
+	Details   string
+	CreatedAt time.Time
+}
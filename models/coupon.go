@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Coupon is a discount code. The coupons of a course are created by its instructor,
+// the site-wide ones (without a course) by the admins.
+type Coupon struct {
+	ID        uint
+	Code      string `gorm:"size:64;uniqueIndex"`
+	CreatorID uint   `gorm:"not null"`
+	Creator   User   `json:"-"`
+	CourseID  *uint
+	Course    *Course `json:"-"`
+	// Kind is "percentage" or "fixed"; Value is the percentage or the amount in the minor
+	// units of Currency taken off the price.
+	Kind     string `gorm:"size:16"`
+	Value    uint
+	Currency string `gorm:"size:3"`
+	// MaxRedemptions limits the paid orders using the coupon; zero is unlimited.
+	MaxRedemptions  uint
+	RedemptionCount uint
+	ExpiresAt       *time.Time
+	Disabled        bool
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// CouponRedemption is the use of a coupon by a paid order.
+type CouponRedemption struct {
+	ID        uint
+	CouponID  uint   `gorm:"not null"`
+	Coupon    Coupon `json:"-"`
+	OrderID   uint   `gorm:"not null;uniqueIndex"`
+	Order     Order  `json:"-"`
+	UserID    uint   `gorm:"not null"`
+	CourseID  uint   `gorm:"not null"`
+	Discount  uint
+	Amount    uint
+	CreatedAt time.Time
+}
@@ -18,8 +18,41 @@ type Course struct {
 	Instructor       User
 	Exercises        []CourseExercise `gorm:"foreignkey:CourseID"`
 	Length           uint
-	Price            uint
-	HasCertificate   bool
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
+	// Price is in the minor units of Currency. Prices holds the prices the instructor set
+	// in the other currencies.
+	Price          uint
+	Currency       string        `gorm:"size:3;default:UAH"`
+	Prices         []CoursePrice `gorm:"foreignkey:CourseID"`
+	HasCertificate bool
+	// EnforcePrerequisites blocks the enrollments of the users who have not completed the
+	// prerequisites of the course; otherwise they are only warned.
+	EnforcePrerequisites bool
+	// SalePrice replaces the price from SaleStartsAt, or right away if it is nil, until SaleEndsAt.
+	SalePrice    *uint
+	SaleStartsAt *time.Time
+	SaleEndsAt   *time.Time
+	// FinalPrice is the price to pay in LocalCurrency, the preferred currency of the user,
+	// with the active sale and the requested coupon applied, and LocalPrice the price in
+	// LocalCurrency without them. They are only set for the course listings.
+	FinalPrice    *uint  `gorm:"-" json:",omitempty"`
+	LocalPrice    *uint  `gorm:"-" json:",omitempty"`
+	LocalCurrency string `gorm:"-" json:",omitempty"`
+	// RatingAverage, RatingCount and RatingDistribution cache the ratings of the visible reviews.
+	RatingAverage      float64
+	RatingCount        uint
+	RatingDistribution RatingDistribution `gorm:"type:text"`
+	// InWishlist tells whether the course is in the wishlist of the current user. It is
+	// only set for the authenticated requests.
+	InWishlist *bool `gorm:"-" json:",omitempty"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// CoursePrerequisite is a course to complete before the course. The prerequisites of the
+// courses form a directed acyclic graph.
+type CoursePrerequisite struct {
+	CourseID       uint   `gorm:"primaryKey;autoIncrement:false"`
+	PrerequisiteID uint   `gorm:"primaryKey;autoIncrement:false"`
+	Prerequisite   Course `json:"-"`
+	CreatedAt      time.Time
 }
@@ -2,13 +2,15 @@ package models
 
 import "time"
 
-// CourseCertificate is the course certificate model.
+// CourseCertificate is the course certificate model. The certificates of the refunded
+// enrollments are revoked rather than deleted, so they can still be verified.
 type CourseCertificate struct {
 	ID        uint
 	UserID    uint   `gorm:"not null"`
 	User      User   `json:"-"`
 	CourseID  uint   `gorm:"not null"`
 	Course    Course `json:"-"`
+	RevokedAt *time.Time
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// CourseReview is the course review model. A review belongs to the enrollment of its
+// user into its course, so there is at most one review per learner and course.
+type CourseReview struct {
+	ID           uint
+	UserID       uint `gorm:"not null"`
+	User         User
+	CourseID     uint   `gorm:"not null"`
+	Course       Course `json:"-"`
+	Rating       uint   `gorm:"not null"`
+	Text         string `gorm:"size:65000"`
+	Reply        string `gorm:"size:65000"`
+	RepliedAt    *time.Time
+	HelpfulCount uint
+	Flagged      bool
+	Hidden       bool
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// CourseReviewVote is the helpful vote of a user for a course review.
+type CourseReviewVote struct {
+	ReviewID  uint         `gorm:"primaryKey;autoIncrement:false;not null"`
+	Review    CourseReview `json:"-"`
+	UserID    uint         `gorm:"primaryKey;autoIncrement:false;not null"`
+	User      User         `json:"-"`
+	CreatedAt time.Time
+}
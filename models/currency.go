@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// CoursePrice is the price of a course in a currency other than its own set by its
+// instructor, in the minor units of the currency.
+type CoursePrice struct {
+	ID        uint
+	CourseID  uint   `gorm:"not null"`
+	Course    Course `json:"-"`
+	Currency  string `gorm:"size:3"`
+	Amount    uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ExchangeRate is the rate of a currency against the base currency of the imported rate
+// table: one unit of Base is worth Rate units of Currency.
+type ExchangeRate struct {
+	Currency  string `gorm:"primaryKey;size:3"`
+	Base      string `gorm:"size:3"`
+	Rate      float64
+	UpdatedAt time.Time
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Discussion is the discussion thread model. A discussion belongs to a course and
+// optionally to one of its exercises; only the enrolled learners and the instructor of the
+// course take part in it. Content is the Markdown of the opening post as written, and
+// ContentHTML its rendering sanitized for embedding into the pages.
+type Discussion struct {
+	ID          uint
+	CourseID    uint   `gorm:"not null"`
+	Course      Course `json:"-"`
+	ExerciseID  *uint
+	Exercise    *CourseExercise `json:"-"`
+	UserID      uint            `gorm:"not null"`
+	User        User
+	Title       string `gorm:"size:255;not null"`
+	Content     string `gorm:"size:65000"`
+	ContentHTML string
+	Votes       uint
+	PostCount   uint
+	// InstructorAnswered is set once the instructor of the course posts in the discussion.
+	InstructorAnswered bool
+	AcceptedPostID     *uint
+	Posts              []DiscussionPost `gorm:"foreignKey:DiscussionID" json:",omitempty"`
+	CreatedAt          time.Time
+	// UpdatedAt is also the time of the last post.
+	UpdatedAt time.Time
+}
+
+// DiscussionPost is the discussion post model. The posts with a ParentID are the replies
+// to that post, the ones without it answer the discussion. Content is the Markdown of the
+// post as written, and ContentHTML its rendering sanitized for embedding into the pages.
+type DiscussionPost struct {
+	ID           uint
+	DiscussionID uint       `gorm:"not null"`
+	Discussion   Discussion `json:"-"`
+	ParentID     *uint
+	UserID       uint `gorm:"not null"`
+	User         User
+	Content      string `gorm:"size:65000;not null"`
+	ContentHTML  string `gorm:"not null"`
+	Votes        uint
+	ByInstructor bool
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// DiscussionVote is the upvote of a user for a discussion.
+type DiscussionVote struct {
+	DiscussionID uint       `gorm:"primaryKey;autoIncrement:false;not null"`
+	Discussion   Discussion `json:"-"`
+	UserID       uint       `gorm:"primaryKey;autoIncrement:false;not null"`
+	User         User       `json:"-"`
+	CreatedAt    time.Time
+}
+
+// DiscussionPostVote is the upvote of a user for a discussion post.
+type DiscussionPostVote struct {
+	PostID    uint           `gorm:"primaryKey;autoIncrement:false;not null"`
+	Post      DiscussionPost `json:"-"`
+	UserID    uint           `gorm:"primaryKey;autoIncrement:false;not null"`
+	User      User           `json:"-"`
+	CreatedAt time.Time
+}
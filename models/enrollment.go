@@ -2,7 +2,10 @@ package models
 
 import "time"
 
-// Enrollment is the enrollment model.
+// Enrollment is the enrollment model. The enrollments through a subscription have a
+// SubscriptionID and give access to the course content only while the subscription lasts.
+// The enrollments through a seat of an organization have an OrganizationID and end with
+// the assignment of the course.
 type Enrollment struct {
 	UserID         uint   `gorm:"primaryKey;autoIncrement:false;not null"`
 	User           User   `json:"-"`
@@ -13,6 +16,8 @@ type Enrollment struct {
 	Status         EnrollmentStatus `json:"-"`
 	LastExerciseID uint             `gorm:"not null"`
 	LastExercise   CourseExercise   `json:"-"`
+	SubscriptionID *uint
+	OrganizationID *uint
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 }
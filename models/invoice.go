@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Invoice is the invoice of a purchase, or the credit note of a refund crediting the
+// invoice with CreditedInvoiceID. The seller and the buyer details are copied at issue,
+// VATRate is in hundredths of a percent and the amounts include the VAT.
+type Invoice struct {
+	ID                    uint
+	Number                string `gorm:"size:32;uniqueIndex"`
+	Kind                  string `gorm:"size:16"`
+	UserID                uint   `gorm:"not null"`
+	User                  User   `json:"-"`
+	OrderID               *uint
+	SubscriptionPaymentID *uint
+	CreditedInvoiceID     *uint
+	CreditedInvoice       *Invoice `json:"-"`
+	Description           string   `gorm:"size:255"`
+	Currency              string   `gorm:"size:3"`
+	Net                   uint
+	VAT                   uint
+	Total                 uint
+	VATRate               uint
+	ReverseCharge         bool
+	SellerName            string `gorm:"size:255"`
+	SellerAddress         string `gorm:"size:255"`
+	SellerTaxID           string `gorm:"size:32"`
+	SellerCountry         string `gorm:"size:2"`
+	BuyerName             string `gorm:"size:255"`
+	BuyerEmail            string `gorm:"size:255"`
+	BuyerAddress          string `gorm:"size:255"`
+	BuyerTaxID            string `gorm:"size:32"`
+	BuyerCountry          string `gorm:"size:2"`
+	StoragePath           string `gorm:"size:255" json:"-"`
+	IssuedAt              time.Time
+	CreatedAt             time.Time
+}
+
+// InvoiceCounter is the last number issued in an invoice numbering series.
+type InvoiceCounter struct {
+	Series string `gorm:"primaryKey;size:16"`
+	Last   uint
+}
+
+// BillingProfile holds the details of a user printed on the invoices, e.g. of the company
+// buying the courses.
+type BillingProfile struct {
+	UserID    uint   `gorm:"primaryKey;autoIncrement:false"`
+	User      User   `json:"-"`
+	Company   string `gorm:"size:255"`
+	TaxID     string `gorm:"size:32"`
+	Address   string `gorm:"size:255"`
+	Country   string `gorm:"size:2"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// LearningPath is a curated sequence of courses, e.g. "Go backend developer". Price is the
+// optional price of the bundle of all its courses in the minor units of Currency, zero if
+// the courses are only sold separately.
+type LearningPath struct {
+	ID          uint
+	Title       string `gorm:"size:255"`
+	Description string
+	Price       uint
+	Currency    string               `gorm:"size:3"`
+	Courses     []LearningPathCourse `gorm:"foreignKey:PathID"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// LearningPathCourse is a course of a learning path at the Position in its sequence.
+type LearningPathCourse struct {
+	PathID   uint `gorm:"primaryKey;autoIncrement:false" json:"-"`
+	CourseID uint `gorm:"primaryKey;autoIncrement:false"`
+	Course   Course
+	Position uint
+}
+
+// PathEnrollment is the enrollment of a user into a learning path.
+type PathEnrollment struct {
+	PathID    uint `gorm:"primaryKey;autoIncrement:false"`
+	UserID    uint `gorm:"primaryKey;autoIncrement:false"`
+	CreatedAt time.Time
+}
+
+// PathCertificate certifies that the user completed all the courses of the learning path.
+type PathCertificate struct {
+	ID        uint
+	UserID    uint         `gorm:"not null"`
+	User      User         `json:"-"`
+	PathID    uint         `gorm:"not null"`
+	Path      LearningPath `json:"-"`
+	CreatedAt time.Time
+}
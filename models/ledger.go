@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// LedgerTransaction is a balanced set of ledger entries moving money between the accounts
+// of the platform and an instructor, e.g. a course sale split into the instructor earnings
+// and the platform fee.
+type LedgerTransaction struct {
+	ID           uint
+	Kind         string `gorm:"size:32"`
+	InstructorID uint   `gorm:"not null"`
+	Instructor   User   `json:"-"`
+	OrderID      *uint
+	Order        *Order `json:"-"`
+	Currency     string `gorm:"size:3"`
+	// Share is the revenue share of the instructor in percent at the time of the sale.
+	Share       uint
+	Description string
+	CreatedByID *uint
+	CreatedBy   *User         `json:"-"`
+	Entries     []LedgerEntry `gorm:"foreignKey:TransactionID"`
+	CreatedAt   time.Time
+}
+
+// LedgerEntry is a debit or a credit of a ledger account. The debits and the credits of a
+// transaction are equal.
+type LedgerEntry struct {
+	ID            uint
+	TransactionID uint   `gorm:"not null"`
+	Account       string `gorm:"size:32"`
+	Debit         uint
+	Credit        uint
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Notification is an in-app notification of a user.
+type Notification struct {
+	ID        uint
+	UserID    uint   `gorm:"not null"`
+	User      User   `json:"-"`
+	Kind      string `gorm:"size:64"`
+	CourseID  *uint
+	Title     string `gorm:"size:255"`
+	Body      string `gorm:"size:65000"`
+	ReadAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Order is the purchase of a course by a user. The enrollment is created once the order is
+// paid. Amount is the price to pay, after the Discount of the coupon if any. The orders of
+// an organization buy the Seats of the course for its members instead. The bundle orders of
+// a learning path buy all its courses and hold its first course.
+type Order struct {
+	ID             uint
+	UserID         uint   `gorm:"not null"`
+	User           User   `json:"-"`
+	CourseID       uint   `gorm:"not null"`
+	Course         Course `json:"-"`
+	OrganizationID *uint
+	Seats          uint
+	PathID         *uint
+	Amount         uint
+	Discount       uint
+	CouponID       *uint
+	Currency       string `gorm:"size:3"`
+	Status         string `gorm:"size:32"`
+	Provider       string `gorm:"size:32"`
+	Reference      string `gorm:"size:255"`
+	PaidAt         *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// PaymentEvent is a processed payment webhook, kept to ignore the duplicates. It is the
+// payment of either an order or a subscription.
+type PaymentEvent struct {
+	Provider       string `gorm:"primaryKey;size:32"`
+	EventID        string `gorm:"primaryKey;size:255"`
+	OrderID        *uint
+	SubscriptionID *uint
+	Status         string `gorm:"size:32"`
+	CreatedAt      time.Time
+}
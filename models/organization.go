@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Organization is a company training its employees, the members, in the courses it buys
+// seats of.
+type Organization struct {
+	ID        uint
+	Name      string `gorm:"size:255"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// OrganizationMember is the membership of a user in an organization with the Role "owner",
+// "manager" or "learner".
+type OrganizationMember struct {
+	OrganizationID uint `gorm:"primaryKey;autoIncrement:false"`
+	UserID         uint `gorm:"primaryKey;autoIncrement:false"`
+	User           User
+	Role           string `gorm:"size:16"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// SeatLicense is the number of seats of a paid course bought by an organization. Every
+// assignment of the course to a member takes a seat; Used counts them.
+type SeatLicense struct {
+	ID             uint
+	OrganizationID uint   `gorm:"not null"`
+	CourseID       uint   `gorm:"not null"`
+	Course         Course `json:"-"`
+	Seats          uint
+	Used           uint `gorm:"-"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// CourseAssignment is a course assigned by a manager to a member of the organization, to be
+// completed by DueAt if set.
+type CourseAssignment struct {
+	ID             uint
+	OrganizationID uint   `gorm:"not null"`
+	UserID         uint   `gorm:"not null"`
+	User           User   `json:"-"`
+	CourseID       uint   `gorm:"not null"`
+	Course         Course `json:"-"`
+	AssignedByID   uint
+	DueAt          *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
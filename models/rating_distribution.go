@@ -0,0 +1,31 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// RatingDistribution holds the number of ratings of each star, from 1 to 5 stars.
+// It is stored as a JSON array.
+type RatingDistribution [5]uint
+
+// Scan implements sql.Scanner.
+func (d *RatingDistribution) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		*d = RatingDistribution{}
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	case []byte:
+		return json.Unmarshal(v, d)
+	}
+	return fmt.Errorf("cannot scan %T into RatingDistribution", value)
+}
+
+// Value implements driver.Valuer.
+func (d RatingDistribution) Value() (driver.Value, error) {
+	b, err := json.Marshal(d)
+	return string(b), err
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Refund is the request of a learner to refund a paid order, decided by an admin.
+type Refund struct {
+	ID         uint
+	OrderID    uint  `gorm:"not null"`
+	Order      Order `json:"-"`
+	UserID     uint  `gorm:"not null"`
+	User       User  `json:"-"`
+	Reason     string
+	Status     string `gorm:"size:32"`
+	ReviewerID *uint
+	Reviewer   *User `json:"-"`
+	Note       string
+	DecidedAt  *time.Time
+	Reference  string `gorm:"size:255"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
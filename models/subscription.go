@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// SubscriptionPlan is a plan of the subscription granting access to all the paid published
+// courses, charged every Interval ("month" or "year").
+type SubscriptionPlan struct {
+	ID        uint
+	Code      string `gorm:"size:64;uniqueIndex"`
+	Name      string `gorm:"size:255"`
+	Interval  string `gorm:"size:16"`
+	Price     uint
+	Currency  string `gorm:"size:3"`
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Subscription is the subscription of a user to a plan. The user has access to the courses
+// until the end of the current period, extended by every renewal payment. Amount is the
+// price the subscription is renewed at.
+type Subscription struct {
+	ID                 uint
+	UserID             uint `gorm:"not null"`
+	User               User `json:"-"`
+	PlanID             uint `gorm:"not null"`
+	Plan               SubscriptionPlan
+	Status             string `gorm:"size:32"`
+	Amount             uint
+	Currency           string `gorm:"size:3"`
+	Provider           string `gorm:"size:32"`
+	Reference          string `gorm:"size:255"`
+	CurrentPeriodStart *time.Time
+	CurrentPeriodEnd   *time.Time
+	CanceledAt         *time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// SubscriptionPayment is a payment of a subscription period.
+type SubscriptionPayment struct {
+	ID             uint
+	SubscriptionID uint `gorm:"not null"`
+	Amount         uint
+	Currency       string `gorm:"size:3"`
+	PeriodStart    time.Time
+	PeriodEnd      time.Time
+	CreatedAt      time.Time
+}
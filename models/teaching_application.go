@@ -9,6 +9,7 @@ type TeachingApplication struct {
 	Experience     string `gorm:"size:255;"`
 	Motivation     string `gorm:"size:255;"`
 	PlatformChoice string `gorm:"size:255;"`
+	ApprovedAt     *time.Time
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 }
@@ -2,7 +2,8 @@ package models
 
 import "time"
 
-// User is the user model.
+// User is the user model. Currency is the preferred currency of the catalogue prices,
+// empty to show the prices in the course currencies.
 type User struct {
 	ID         uint `gorm:"type:int;"`
 	ProfilePic string
@@ -12,6 +13,7 @@ type User struct {
 	Password   string `gorm:"size:255" json:"-"`
 	UserTypeID uint   `gorm:"not null"`
 	UserType   UserType
+	Currency   string `gorm:"size:3"`
 	CreatedAt  time.Time
 	UpdatedAt  time.Time `json:"-"`
 }
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// UserImport is a bulk import of the users from a CSV file into an organization, or the
+// platform if OrganizationID is nil, processed in the background. Created, Matched, Pending
+// and Failed count the results of the Processed rows.
+type UserImport struct {
+	ID             uint
+	UserID         uint `gorm:"not null"`
+	OrganizationID *uint
+	Status         string `gorm:"size:16"`
+	Total          uint
+	Processed      uint
+	Created        uint
+	Matched        uint
+	Pending        uint
+	Failed         uint
+	Error          string
+	Rows           []UserImportRow `gorm:"foreignKey:ImportID"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	FinishedAt     *time.Time
+}
+
+// UserImportCourse is a course the import assigns to its users.
+type UserImportCourse struct {
+	ImportID uint `gorm:"primaryKey;autoIncrement:false"`
+	CourseID uint `gorm:"primaryKey;autoIncrement:false"`
+}
+
+// UserImportRow is the result of the import of the user on the Line of the file: "created",
+// "matched", "pending" or "failed" with the Error. Invited tells whether the set-password
+// or the join link was emailed to the user.
+type UserImportRow struct {
+	ID       uint
+	ImportID uint `gorm:"not null"`
+	Line     uint
+	Email    string `gorm:"size:255"`
+	UserID   *uint
+	Result   string `gorm:"size:16"`
+	Error    string
+	Invited  bool
+}
+
+// Invitation lets the user set the password through the emailed link holding the token,
+// of which only the hash is stored, until ExpiresAt. The invitations with an OrganizationID
+// let the existing user join the organization of the import instead.
+type Invitation struct {
+	ID             uint
+	UserID         uint `gorm:"not null"`
+	OrganizationID *uint
+	ImportID       *uint
+	TokenHash      string `gorm:"size:64;unique" json:"-"`
+	ExpiresAt      time.Time
+	AcceptedAt     *time.Time
+	CreatedAt      time.Time
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// WishlistItem is a course saved by a user for later.
+type WishlistItem struct {
+	UserID    uint   `gorm:"primaryKey;autoIncrement:false;not null"`
+	User      User   `json:"-"`
+	CourseID  uint   `gorm:"primaryKey;autoIncrement:false;not null"`
+	Course    Course `json:"-"`
+	CreatedAt time.Time
+}
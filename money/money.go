@@ -0,0 +1,8 @@
+// Package money holds the currency table shared by the services and the payment providers.
+package money
+
+// Digits are the supported ISO 4217 currencies with the number of digits of their minor
+// units. All the amounts are stored in the minor units, e.g. kopiyky.
+var Digits = map[string]uint{
+	"CHF": 2, "CZK": 2, "EUR": 2, "GBP": 2, "JPY": 0, "PLN": 2, "UAH": 2, "USD": 2,
+}
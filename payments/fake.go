@@ -0,0 +1,97 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// FakeSignatureHeader is the header holding the signature of the fake webhooks.
+const FakeSignatureHeader = "X-Fake-Signature"
+
+// Fake is a provider for local development and tests. No money is collected: the payments
+// are confirmed by posting a FakeWebhook signed with the secret.
+type Fake struct {
+	secret []byte
+}
+
+// FakeWebhook is the payload of the fake webhooks.
+type FakeWebhook struct {
+	EventID  string `json:"event_id"`
+	OrderID  uint   `json:"order_id"`
+	Kind     string `json:"kind,omitempty"`
+	Status   string `json:"status"`
+	Amount   uint   `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// NewFake creates a new Fake provider signing the webhooks with the secret.
+func NewFake(secret string) *Fake {
+	return &Fake{secret: []byte(secret)}
+}
+
+// Name returns "fake".
+func (p *Fake) Name() string {
+	return "fake"
+}
+
+// CreateCheckout returns the result URL of the checkout with the order ID as the checkout page.
+func (p *Fake) CreateCheckout(ctx context.Context, checkout Checkout) (Session, error) {
+	ref := fmt.Sprintf("fake-%d", checkout.OrderID)
+	if checkout.Kind != "" {
+		ref = fmt.Sprintf("fake-%s-%d", checkout.Kind, checkout.OrderID)
+	}
+
+	u, err := url.Parse(checkout.ResultURL)
+	if err != nil {
+		return Session{}, err
+	}
+
+	q := u.Query()
+	q.Set("order_id", fmt.Sprint(checkout.OrderID))
+	u.RawQuery = q.Encode()
+
+	return Session{Reference: ref, URL: u.String()}, nil
+}
+
+// ParseWebhook verifies the signature header of the webhook and returns its notification.
+func (p *Fake) ParseWebhook(header http.Header, body []byte) (Notification, error) {
+	signature, err := hex.DecodeString(header.Get(FakeSignatureHeader))
+	if err != nil || !hmac.Equal(signature, p.sign(body)) {
+		return Notification{}, ErrInvalidSignature
+	}
+
+	var webhook FakeWebhook
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		return Notification{}, fmt.Errorf("invalid webhook payload: %v", err)
+	}
+
+	return Notification(webhook), nil
+}
+
+// Refund accepts the refund without contacting anyone.
+func (p *Fake) Refund(ctx context.Context, refund Refund) (string, error) {
+	return "refund-" + refund.Reference, nil
+}
+
+// CancelRecurring accepts the cancellation without contacting anyone.
+func (p *Fake) CancelRecurring(ctx context.Context, reference string) error {
+	return nil
+}
+
+// Sign returns the signature header value of the webhook body.
+func (p *Fake) Sign(body []byte) string {
+	return hex.EncodeToString(p.sign(body))
+}
+
+// sign returns the HMAC-SHA256 of the body.
+func (p *Fake) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
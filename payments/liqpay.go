@@ -0,0 +1,280 @@
+package payments
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/plaja-app/back-end/money"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// liqPayCheckoutURL is the checkout page of LiqPay.
+	liqPayCheckoutURL = "https://www.liqpay.ua/api/3/checkout"
+	// liqPayAPIURL is the server-to-server API of LiqPay.
+	liqPayAPIURL = "https://www.liqpay.ua/api/request"
+)
+
+// LiqPay is the provider of the LiqPay payment service. The checkout parameters and the
+// webhooks are base64-encoded JSON signed with the private key.
+type LiqPay struct {
+	publicKey  string
+	privateKey string
+	sandbox    bool
+	apiURL     string
+	client     *http.Client
+}
+
+// liqPayCallback is the payload of the LiqPay webhooks.
+type liqPayCallback struct {
+	PaymentID int64   `json:"payment_id"`
+	Status    string  `json:"status"`
+	OrderID   string  `json:"order_id"`
+	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency"`
+}
+
+// NewLiqPay creates a new LiqPay provider. In the sandbox mode the test payments are
+// accepted as succeeded.
+func NewLiqPay(publicKey, privateKey string, sandbox bool) *LiqPay {
+	return &LiqPay{
+		publicKey:  publicKey,
+		privateKey: privateKey,
+		sandbox:    sandbox,
+		apiURL:     liqPayAPIURL,
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name returns "liqpay".
+func (p *LiqPay) Name() string {
+	return "liqpay"
+}
+
+// CreateCheckout returns the URL of the LiqPay checkout page with the signed payment parameters.
+func (p *LiqPay) CreateCheckout(ctx context.Context, checkout Checkout) (Session, error) {
+	params := map[string]any{
+		"version":     3,
+		"public_key":  p.publicKey,
+		"action":      "pay",
+		"amount":      liqPayAmount(checkout.Amount, checkout.Currency),
+		"currency":    checkout.Currency,
+		"description": checkout.Description,
+		"order_id":    liqPayOrderID(checkout.Kind, checkout.OrderID),
+		"result_url":  checkout.ResultURL,
+		"server_url":  checkout.CallbackURL,
+	}
+	if p.sandbox {
+		params["sandbox"] = 1
+	}
+	if checkout.Interval != "" {
+		params["action"] = "subscribe"
+		params["subscribe"] = 1
+		params["subscribe_date_start"] = time.Now().UTC().Format(time.DateTime)
+		params["subscribe_periodicity"] = checkout.Interval
+	}
+
+	q, err := p.signedParams(params)
+	if err != nil {
+		return Session{}, err
+	}
+
+	return Session{
+		Reference: params["order_id"].(string),
+		URL:       liqPayCheckoutURL + "?" + q.Encode(),
+	}, nil
+}
+
+// Refund requests the refund of the order payment with the LiqPay API.
+// The reference of the refund is the LiqPay payment ID, or the order reference when the
+// response has none.
+func (p *LiqPay) Refund(ctx context.Context, refund Refund) (string, error) {
+	result, err := p.request(ctx, map[string]any{
+		"action":   "refund",
+		"order_id": refund.Reference,
+		"amount":   liqPayAmount(refund.Amount, refund.Currency),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error requesting the refund of order %d: %v", refund.OrderID, err)
+	}
+
+	if result.Result != "ok" && result.Status != "reversed" {
+		return "", fmt.Errorf("refund of order %d rejected: %s %s", refund.OrderID, result.Status, result.Description)
+	}
+
+	if result.PaymentID == 0 {
+		return refund.Reference, nil
+	}
+	return strconv.FormatInt(result.PaymentID, 10), nil
+}
+
+// CancelRecurring unsubscribes the recurring payments of the checkout with the LiqPay API.
+func (p *LiqPay) CancelRecurring(ctx context.Context, reference string) error {
+	result, err := p.request(ctx, map[string]any{
+		"action":   "unsubscribe",
+		"order_id": reference,
+	})
+	if err != nil {
+		return fmt.Errorf("error unsubscribing %s: %v", reference, err)
+	}
+
+	if result.Result != "ok" && result.Status != "unsubscribed" {
+		return fmt.Errorf("unsubscribing %s rejected: %s %s", reference, result.Status, result.Description)
+	}
+
+	return nil
+}
+
+// liqPayResult is the response of the LiqPay API.
+type liqPayResult struct {
+	Result      string `json:"result"`
+	Status      string `json:"status"`
+	Description string `json:"err_description"`
+	PaymentID   int64  `json:"payment_id"`
+}
+
+// request posts the signed request of the action to the LiqPay API.
+func (p *LiqPay) request(ctx context.Context, params map[string]any) (liqPayResult, error) {
+	params["version"] = 3
+	params["public_key"] = p.publicKey
+
+	q, err := p.signedParams(params)
+	if err != nil {
+		return liqPayResult{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.apiURL, strings.NewReader(q.Encode()))
+	if err != nil {
+		return liqPayResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return liqPayResult{}, err
+	}
+	defer resp.Body.Close()
+
+	var result liqPayResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return liqPayResult{}, fmt.Errorf("invalid response: %v", err)
+	}
+
+	return result, nil
+}
+
+// ParseWebhook verifies the signature of the form-encoded webhook and returns its notification.
+func (p *LiqPay) ParseWebhook(header http.Header, body []byte) (Notification, error) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return Notification{}, fmt.Errorf("invalid webhook payload: %v", err)
+	}
+
+	data := form.Get("data")
+	if subtle.ConstantTimeCompare([]byte(form.Get("signature")), []byte(p.sign(data))) != 1 {
+		return Notification{}, ErrInvalidSignature
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return Notification{}, fmt.Errorf("invalid webhook payload: %v", err)
+	}
+
+	var callback liqPayCallback
+	if err := json.Unmarshal(raw, &callback); err != nil {
+		return Notification{}, fmt.Errorf("invalid webhook payload: %v", err)
+	}
+
+	id, kind := callback.OrderID, ""
+	if rest, ok := strings.CutPrefix(id, KindSubscription+"-"); ok {
+		id, kind = rest, KindSubscription
+	}
+
+	orderID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return Notification{}, fmt.Errorf("invalid webhook order %q", callback.OrderID)
+	}
+
+	return Notification{
+		EventID:  fmt.Sprintf("%d:%s", callback.PaymentID, callback.Status),
+		OrderID:  uint(orderID),
+		Kind:     kind,
+		Status:   p.status(callback.Status),
+		Amount:   minorAmount(callback.Amount, callback.Currency),
+		Currency: callback.Currency,
+	}, nil
+}
+
+// status maps the LiqPay payment status to the payment statuses.
+func (p *LiqPay) status(status string) string {
+	switch status {
+	case "success", "subscribed":
+		return StatusSucceeded
+	case "sandbox":
+		if p.sandbox {
+			return StatusSucceeded
+		}
+		return StatusFailed
+	case "failure", "error", "reversed":
+		return StatusFailed
+	}
+	return StatusPending
+}
+
+// liqPayOrderID returns the LiqPay order ID of the payment, prefixed with the kind of the
+// subscription payments.
+func liqPayOrderID(kind string, id uint) string {
+	if kind != "" {
+		return fmt.Sprintf("%s-%d", kind, id)
+	}
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// signedParams returns the data and signature parameters of the LiqPay request.
+func (p *LiqPay) signedParams(params map[string]any) (url.Values, error) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	data := base64.StdEncoding.EncodeToString(raw)
+
+	q := url.Values{}
+	q.Set("data", data)
+	q.Set("signature", p.sign(data))
+	return q, nil
+}
+
+// sign returns the LiqPay signature of the data: base64(sha1(private_key + data + private_key)).
+func (p *LiqPay) sign(data string) string {
+	sum := sha1.Sum([]byte(p.privateKey + data + p.privateKey))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// liqPayAmount returns the amount in the minor units of the currency in the main units
+// LiqPay expects, e.g. 599.00 UAH for 59900 but 1000 JPY for 1000.
+func liqPayAmount(amount uint, currency string) float64 {
+	return float64(amount) / math.Pow10(minorDigits(currency))
+}
+
+// minorAmount returns the amount in the main units of the currency in its minor units.
+func minorAmount(amount float64, currency string) uint {
+	return uint(math.Round(amount * math.Pow10(minorDigits(currency))))
+}
+
+// minorDigits returns the number of digits of the minor units of the currency, 2 for the
+// unsupported ones.
+func minorDigits(currency string) int {
+	if digits, ok := money.Digits[currency]; ok {
+		return int(digits)
+	}
+	return 2
+}
@@ -0,0 +1,190 @@
+package payments
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestLiqPayCheckout(t *testing.T) {
+	p := NewLiqPay("public", "private", false)
+
+	session, err := p.CreateCheckout(context.Background(), Checkout{OrderID: 42, Amount: 59900, Currency: "UAH"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(session.URL)
+	if err != nil || !strings.HasPrefix(session.URL, liqPayCheckoutURL) {
+		t.Fatalf("unexpected checkout URL %q", session.URL)
+	}
+
+	data := u.Query().Get("data")
+	if u.Query().Get("signature") != p.sign(data) {
+		t.Error("expected signed checkout data")
+	}
+
+	raw, _ := base64.StdEncoding.DecodeString(data)
+	var params map[string]any
+	json.Unmarshal(raw, &params)
+	if params["order_id"] != "42" || params["amount"] != 599.0 || params["public_key"] != "public" {
+		t.Errorf("unexpected checkout params %v", params)
+	}
+}
+
+func TestLiqPayWebhook(t *testing.T) {
+	p := NewLiqPay("public", "private", false)
+
+	webhook := func(callback string, signature string) []byte {
+		data := base64.StdEncoding.EncodeToString([]byte(callback))
+		if signature == "" {
+			signature = p.sign(data)
+		}
+		return []byte(url.Values{"data": {data}, "signature": {signature}}.Encode())
+	}
+
+	n, err := p.ParseWebhook(nil, webhook(`{"payment_id":7,"status":"success","order_id":"42","amount":599.0,"currency":"UAH"}`, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Notification{EventID: "7:success", OrderID: 42, Status: StatusSucceeded, Amount: 59900, Currency: "UAH"}
+	if n != want {
+		t.Errorf("got %+v, want %+v", n, want)
+	}
+
+	_, err = p.ParseWebhook(nil, webhook(`{"payment_id":7,"status":"success","order_id":"42"}`, "forged"))
+	if !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected invalid signature, got %v", err)
+	}
+
+	// test payments only succeed in the sandbox mode
+	n, _ = p.ParseWebhook(nil, webhook(`{"payment_id":8,"status":"sandbox","order_id":"42"}`, ""))
+	if n.Status != StatusFailed {
+		t.Errorf("expected sandbox payments to fail outside the sandbox mode, got %q", n.Status)
+	}
+}
+
+func TestLiqPayRefund(t *testing.T) {
+	p := NewLiqPay("public", "private", false)
+
+	var params map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("signature") != p.sign(r.PostForm.Get("data")) {
+			t.Error("expected signed refund data")
+		}
+
+		raw, _ := base64.StdEncoding.DecodeString(r.PostForm.Get("data"))
+		json.Unmarshal(raw, &params)
+
+		if params["order_id"] == "rejected" {
+			w.Write([]byte(`{"result":"error","status":"error","err_description":"payment not found"}`))
+			return
+		}
+		w.Write([]byte(`{"result":"ok","status":"reversed","payment_id":1234567}`))
+	}))
+	defer server.Close()
+	p.apiURL = server.URL
+
+	reference, err := p.Refund(context.Background(), Refund{OrderID: 42, Reference: "42", Amount: 59900})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reference != "1234567" {
+		t.Errorf("expected the LiqPay payment ID as the refund reference, got %q", reference)
+	}
+	if params["action"] != "refund" || params["order_id"] != "42" || params["amount"] != 599.0 {
+		t.Errorf("unexpected refund params %v", params)
+	}
+
+	_, err = p.Refund(context.Background(), Refund{OrderID: 43, Reference: "rejected", Amount: 59900})
+	if err == nil || !strings.Contains(err.Error(), "payment not found") {
+		t.Errorf("expected the rejected refund, got %v", err)
+	}
+}
+
+func TestLiqPaySubscription(t *testing.T) {
+	p := NewLiqPay("public", "private", false)
+
+	session, err := p.CreateCheckout(context.Background(), Checkout{
+		OrderID: 5, Kind: KindSubscription, Amount: 29900, Currency: "UAH", Interval: "month",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, _ := url.Parse(session.URL)
+	raw, _ := base64.StdEncoding.DecodeString(u.Query().Get("data"))
+	var params map[string]any
+	json.Unmarshal(raw, &params)
+	if session.Reference != "subscription-5" || params["action"] != "subscribe" ||
+		params["subscribe_periodicity"] != "month" || params["subscribe_date_start"] == nil {
+		t.Errorf("unexpected subscription checkout %q %v", session.Reference, params)
+	}
+
+	data := base64.StdEncoding.EncodeToString([]byte(`{"payment_id":9,"status":"subscribed","order_id":"subscription-5","amount":299,"currency":"UAH"}`))
+	n, err := p.ParseWebhook(nil, []byte(url.Values{"data": {data}, "signature": {p.sign(data)}}.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.OrderID != 5 || n.Kind != KindSubscription || n.Status != StatusSucceeded {
+		t.Errorf("unexpected subscription notification %+v", n)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		raw, _ := base64.StdEncoding.DecodeString(r.PostForm.Get("data"))
+		json.Unmarshal(raw, &params)
+		w.Write([]byte(`{"result":"ok","status":"unsubscribed"}`))
+	}))
+	defer server.Close()
+	p.apiURL = server.URL
+
+	if err := p.CancelRecurring(context.Background(), session.Reference); err != nil {
+		t.Fatal(err)
+	}
+	if params["action"] != "unsubscribe" || params["order_id"] != "subscription-5" {
+		t.Errorf("unexpected unsubscribe params %v", params)
+	}
+}
+
+func TestLiqPayCurrencyDigits(t *testing.T) {
+	p := NewLiqPay("public", "private", false)
+
+	// yen have no minor units, so 1000 JPY are sent and received as 1000
+	session, err := p.CreateCheckout(context.Background(), Checkout{OrderID: 42, Amount: 1000, Currency: "JPY"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, _ := url.Parse(session.URL)
+	raw, _ := base64.StdEncoding.DecodeString(u.Query().Get("data"))
+	var params map[string]any
+	json.Unmarshal(raw, &params)
+	if params["amount"] != 1000.0 || params["currency"] != "JPY" {
+		t.Errorf("unexpected checkout params %v", params)
+	}
+
+	data := base64.StdEncoding.EncodeToString([]byte(`{"payment_id":7,"status":"success","order_id":"42","amount":1000,"currency":"JPY"}`))
+	n, err := p.ParseWebhook(nil, []byte(url.Values{"data": {data}, "signature": {p.sign(data)}}.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Amount != 1000 || n.Currency != "JPY" {
+		t.Errorf("unexpected notification %+v", n)
+	}
+
+	// an underpayment in the main units is not rounded up to the order amount
+	data = base64.StdEncoding.EncodeToString([]byte(`{"payment_id":8,"status":"success","order_id":"42","amount":10,"currency":"JPY"}`))
+	n, _ = p.ParseWebhook(nil, []byte(url.Values{"data": {data}, "signature": {p.sign(data)}}.Encode()))
+	if n.Amount != 10 {
+		t.Errorf("expected 10 JPY, got %+v", n)
+	}
+}
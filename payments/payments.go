@@ -0,0 +1,84 @@
+// Package payments abstracts the payment providers collecting the course payments: the
+// checkout pages the learners are redirected to and the signed webhooks confirming the
+// payments, the refunds and the recurring payments of the subscriptions.
+package payments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Payment statuses reported by the webhooks.
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// KindSubscription is the kind of the recurring subscription payments; the kind of the
+// order payments is empty.
+const KindSubscription = "subscription"
+
+// ErrInvalidSignature is returned when the webhook signature does not match its payload.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Checkout is the payment of an order to collect. The subscriptions are charged every
+// Interval ("month" or "year") until canceled, with OrderID holding the subscription ID.
+type Checkout struct {
+	OrderID uint
+	Kind    string
+	// Amount is in the minor currency units, e.g. kopiyky.
+	Amount      uint
+	Currency    string
+	Description string
+	// ResultURL is the page the learner returns to after paying.
+	ResultURL string
+	// CallbackURL receives the webhooks of the payment.
+	CallbackURL string
+	Interval    string
+}
+
+// Session is a checkout session of the provider.
+type Session struct {
+	// Reference identifies the session at the provider.
+	Reference string
+	// URL is the checkout page the learner is redirected to.
+	URL string
+}
+
+// Notification is the payment status reported by a webhook.
+type Notification struct {
+	// EventID uniquely identifies the notification, so the duplicates can be ignored.
+	EventID  string
+	OrderID  uint
+	Kind     string
+	Status   string
+	Amount   uint
+	Currency string
+}
+
+// Refund is the full refund of a paid order.
+type Refund struct {
+	OrderID uint
+	// Reference is the reference of the checkout session of the order.
+	Reference string
+	Amount    uint
+	Currency  string
+}
+
+// Provider is a payment provider.
+type Provider interface {
+	// Name returns the name of the provider, e.g. "liqpay".
+	Name() string
+	// CreateCheckout creates the checkout session of the payment.
+	CreateCheckout(ctx context.Context, checkout Checkout) (Session, error)
+	// ParseWebhook verifies the signature of the webhook request and returns its notification.
+	ParseWebhook(header http.Header, body []byte) (Notification, error)
+	// Refund returns the payment of the order to the payer and returns the provider
+	// reference of the refund. Refunding a returned payment again returns the existing
+	// refund, so the interrupted refunds can be retried.
+	Refund(ctx context.Context, refund Refund) (string, error)
+	// CancelRecurring stops the recurring payments of the checkout session with the reference.
+	CancelRecurring(ctx context.Context, reference string) error
+}
@@ -0,0 +1,61 @@
+// Package pdf writes minimal PDF documents made of page images, e.g. the rendered
+// statements and invoices.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+)
+
+// Write writes the page images as a PDF document, each image filling an A4 page.
+func Write(w io.Writer, pages []image.Image) error {
+	var buf bytes.Buffer
+	var offsets []int
+
+	object := func(format string, args ...any) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", len(offsets))
+		fmt.Fprintf(&buf, format, args...)
+		buf.WriteString("\nendobj\n")
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// the catalog and the page tree come first, each page takes three objects
+	var kids string
+	for i := range pages {
+		kids += fmt.Sprintf("%d 0 R ", 3+3*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [%s] /Count %d >>", kids, len(pages))
+
+	for i, page := range pages {
+		var img bytes.Buffer
+		if err := jpeg.Encode(&img, page, &jpeg.Options{Quality: 90}); err != nil {
+			return err
+		}
+
+		content := "q 595 0 0 842 0 0 cm /Im0 Do Q"
+		bounds := page.Bounds()
+
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /XObject << /Im0 %d 0 R >> >> /Contents %d 0 R >>",
+			5+3*i, 4+3*i)
+		object("<< /Length %d >>\nstream\n%s\nendstream", len(content), content)
+		object("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n%s\nendstream",
+			bounds.Dx(), bounds.Dy(), img.Len(), img.Bytes())
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := buf.WriteTo(w)
+	return err
+}
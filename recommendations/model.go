@@ -0,0 +1,187 @@
+// Package recommendations recommends the courses to take next, combining the item-item
+// collaborative filtering over the enrollments with the similarity of the course
+// categories and levels and the popularity of the courses.
+package recommendations
+
+import (
+	"github.com/plaja-app/back-end/services"
+	"math"
+	"slices"
+	"sort"
+)
+
+// Weights of the recommendation components for the users with enrollments. The users
+// without them get the popular courses only.
+const (
+	weightAlsoTook = 0.6
+	weightSimilar  = 0.25
+	weightPopular  = 0.15
+)
+
+// Model is an immutable recommendation model computed from a snapshot of the data.
+type Model struct {
+	courses []services.RecommendationCourse
+	// index maps the course IDs to their positions in courses.
+	index map[uint]int
+	// coTaken holds the cosine similarity of the courses taken by the same learners.
+	coTaken map[uint]map[uint]float64
+	// popularity is the number of enrollments of the courses scaled to 0..1 logarithmically.
+	popularity map[uint]float64
+}
+
+// NewModel computes the model from the data.
+func NewModel(data services.RecommendationData) *Model {
+	m := &Model{
+		courses:    slices.Clone(data.Courses),
+		index:      make(map[uint]int, len(data.Courses)),
+		coTaken:    make(map[uint]map[uint]float64),
+		popularity: make(map[uint]float64, len(data.Courses)),
+	}
+	sort.Slice(m.courses, func(i, j int) bool { return m.courses[i].ID < m.courses[j].ID })
+	for i, c := range m.courses {
+		m.index[c.ID] = i
+	}
+
+	// the published courses of every learner
+	taken := make(map[uint][]uint)
+	counts := make(map[uint]int)
+	for _, e := range data.Enrollments {
+		if _, ok := m.index[e.CourseID]; ok && !slices.Contains(taken[e.UserID], e.CourseID) {
+			taken[e.UserID] = append(taken[e.UserID], e.CourseID)
+			counts[e.CourseID]++
+		}
+	}
+
+	together := make(map[[2]uint]int)
+	for _, courses := range taken {
+		for i, a := range courses {
+			for _, b := range courses[i+1:] {
+				if a > b {
+					a, b = b, a
+				}
+				together[[2]uint{a, b}]++
+			}
+		}
+	}
+
+	for pair, n := range together {
+		a, b := pair[0], pair[1]
+		similarity := float64(n) / math.Sqrt(float64(counts[a]*counts[b]))
+		for _, p := range [][2]uint{{a, b}, {b, a}} {
+			if m.coTaken[p[0]] == nil {
+				m.coTaken[p[0]] = make(map[uint]float64)
+			}
+			m.coTaken[p[0]][p[1]] = similarity
+		}
+	}
+
+	most := 0
+	for _, n := range counts {
+		most = max(most, n)
+	}
+	for id, n := range counts {
+		m.popularity[id] = math.Log1p(float64(n)) / math.Log1p(float64(most))
+	}
+
+	return m
+}
+
+// ForUser returns up to limit courses recommended to the learner of the courses, excluding
+// them.
+func (m *Model) ForUser(enrolled []uint, limit int) []services.Recommendation {
+	var taken []services.RecommendationCourse
+	for _, id := range enrolled {
+		if i, ok := m.index[id]; ok {
+			taken = append(taken, m.courses[i])
+		}
+	}
+
+	var recommendations []services.Recommendation
+	for _, c := range m.courses {
+		if slices.Contains(enrolled, c.ID) {
+			continue
+		}
+
+		popular := m.popularity[c.ID]
+		if len(taken) == 0 {
+			if popular > 0 {
+				recommendations = append(recommendations, services.Recommendation{CourseID: c.ID, Score: popular, Reason: services.ReasonPopular})
+			}
+			continue
+		}
+
+		var alsoTook, similar float64
+		for _, t := range taken {
+			alsoTook += m.coTaken[t.ID][c.ID]
+			similar += Similarity(t, c)
+		}
+		alsoTook /= float64(len(taken))
+		similar /= float64(len(taken))
+
+		r := services.Recommendation{
+			CourseID: c.ID,
+			Score:    weightAlsoTook*alsoTook + weightSimilar*similar + weightPopular*popular,
+			Reason:   services.ReasonPopular,
+		}
+		switch {
+		case weightAlsoTook*alsoTook >= weightSimilar*similar && alsoTook > 0:
+			r.Reason = services.ReasonAlsoTook
+		case weightSimilar*similar >= weightPopular*popular && similar > 0:
+			r.Reason = services.ReasonSimilar
+		}
+		if r.Score > 0 {
+			recommendations = append(recommendations, r)
+		}
+	}
+
+	return top(recommendations, limit)
+}
+
+// AlsoTook returns up to limit courses most often taken by the learners of the course.
+func (m *Model) AlsoTook(courseID uint, limit int) []services.Recommendation {
+	var recommendations []services.Recommendation
+	for id, similarity := range m.coTaken[courseID] {
+		recommendations = append(recommendations, services.Recommendation{CourseID: id, Score: similarity, Reason: services.ReasonAlsoTook})
+	}
+	return top(recommendations, limit)
+}
+
+// Similarity returns the similarity of the courses from 0 to 1: the Jaccard index of their
+// categories weighted with the closeness of their levels.
+func Similarity(a, b services.RecommendationCourse) float64 {
+	var common int
+	for _, id := range a.CategoryIDs {
+		if slices.Contains(b.CategoryIDs, id) {
+			common++
+		}
+	}
+
+	var categories float64
+	if union := len(a.CategoryIDs) + len(b.CategoryIDs) - common; union > 0 {
+		categories = float64(common) / float64(union)
+	}
+
+	distance := int(a.LevelID) - int(b.LevelID)
+	if distance < 0 {
+		distance = -distance
+	}
+	level := 1 / float64(1+distance)
+
+	return 0.7*categories + 0.3*level
+}
+
+// top returns up to limit recommendations with the highest scores.
+func top(recommendations []services.Recommendation, limit int) []services.Recommendation {
+	sort.Slice(recommendations, func(i, j int) bool {
+		a, b := recommendations[i], recommendations[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.CourseID < b.CourseID
+	})
+
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+	return recommendations
+}
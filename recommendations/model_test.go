@@ -0,0 +1,64 @@
+package recommendations
+
+import (
+	"github.com/plaja-app/back-end/services"
+	"testing"
+)
+
+func TestModel(t *testing.T) {
+	data := services.RecommendationData{
+		Courses: []services.RecommendationCourse{
+			{ID: 1, LevelID: 1, CategoryIDs: []uint{1}},
+			{ID: 2, LevelID: 2, CategoryIDs: []uint{1}},
+			{ID: 3, LevelID: 3, CategoryIDs: []uint{2}},
+			{ID: 4, LevelID: 1, CategoryIDs: []uint{3}},
+		},
+		Enrollments: []services.CoEnrollment{
+			{UserID: 1, CourseID: 1}, {UserID: 1, CourseID: 3},
+			{UserID: 2, CourseID: 1}, {UserID: 2, CourseID: 3},
+			{UserID: 3, CourseID: 1}, {UserID: 3, CourseID: 2},
+			{UserID: 4, CourseID: 4}, {UserID: 5, CourseID: 4}, {UserID: 6, CourseID: 4},
+			// the unpublished courses are ignored
+			{UserID: 1, CourseID: 9},
+		},
+	}
+	m := NewModel(data)
+
+	also := m.AlsoTook(1, 10)
+	if len(also) != 2 || also[0].CourseID != 3 || also[1].CourseID != 2 || also[0].Reason != services.ReasonAlsoTook {
+		t.Errorf("AlsoTook(1) = %+v", also)
+	}
+	if also := m.AlsoTook(9, 10); len(also) != 0 {
+		t.Errorf("AlsoTook(9) = %+v", also)
+	}
+
+	// the courses taken with the course come first, the unrelated popular ones last
+	recommended := m.ForUser([]uint{1}, 10)
+	if len(recommended) != 3 || recommended[0].Reason != services.ReasonAlsoTook || recommended[2].CourseID != 4 {
+		t.Errorf("ForUser([1]) = %+v", recommended)
+	}
+	for _, r := range recommended {
+		if r.CourseID == 1 {
+			t.Errorf("ForUser([1]) recommends the taken course: %+v", recommended)
+		}
+	}
+
+	// the users without enrollments get the popular courses
+	popular := m.ForUser(nil, 2)
+	if len(popular) != 2 || popular[0].CourseID != 1 || popular[0].Reason != services.ReasonPopular {
+		t.Errorf("ForUser(nil) = %+v", popular)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	a := services.RecommendationCourse{ID: 1, LevelID: 1, CategoryIDs: []uint{1, 2}}
+	b := services.RecommendationCourse{ID: 2, LevelID: 2, CategoryIDs: []uint{2}}
+	c := services.RecommendationCourse{ID: 3, LevelID: 3, CategoryIDs: []uint{3}}
+
+	if got := Similarity(a, a); got != 1 {
+		t.Errorf("Similarity(a, a) = %v, want 1", got)
+	}
+	if ab, ac := Similarity(a, b), Similarity(a, c); ab <= ac || ac <= 0 {
+		t.Errorf("Similarity(a, b) = %v, Similarity(a, c) = %v", ab, ac)
+	}
+}
@@ -0,0 +1,109 @@
+package recommendations
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/services"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// Source loads the data the model is computed from.
+type Source func(ctx context.Context) (services.RecommendationData, error)
+
+// Enrolled loads the IDs of the courses the user is enrolled in.
+type Enrolled func(ctx context.Context, userID uint) ([]uint, error)
+
+// Recommender serves the recommendations from a model recomputed from the source when
+// invalidated and periodically. The enrollments of the user are loaded on every request,
+// so the new ones are excluded right away. It implements services.RecommendationService.
+type Recommender struct {
+	source     Source
+	enrolled   Enrolled
+	model      atomic.Pointer[Model]
+	invalidate chan struct{}
+}
+
+// New creates a new Recommender computing the model from the source.
+func New(source Source, enrolled Enrolled) *Recommender {
+	return &Recommender{
+		source:     source,
+		enrolled:   enrolled,
+		invalidate: make(chan struct{}, 1),
+	}
+}
+
+// Rebuild recomputes the model from the source.
+func (r *Recommender) Rebuild(ctx context.Context) error {
+	data, err := r.source(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.model.Store(NewModel(data))
+	return nil
+}
+
+// Invalidate schedules a rebuild of the model by Run. It does not block.
+func (r *Recommender) Invalidate() {
+	select {
+	case r.invalidate <- struct{}{}:
+	default:
+	}
+}
+
+// Run recomputes the model when invalidated and every interval until the context is done.
+func (r *Recommender) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.invalidate:
+		case <-ticker.C:
+		}
+
+		if err := r.Rebuild(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("error rebuilding the recommendation model: %v", err)
+		}
+	}
+}
+
+// ForUser returns up to limit courses recommended to the user.
+func (r *Recommender) ForUser(ctx context.Context, userID uint, limit int) ([]services.Recommendation, error) {
+	model, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	enrolled, err := r.enrolled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ForUser(enrolled, limit), nil
+}
+
+// AlsoTook returns up to limit courses most often taken by the learners of the course.
+func (r *Recommender) AlsoTook(ctx context.Context, courseID uint, limit int) ([]services.Recommendation, error) {
+	model, err := r.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.AlsoTook(courseID, limit), nil
+}
+
+// load returns the model, computing it on the first call if it has not been computed yet.
+func (r *Recommender) load(ctx context.Context) (*Model, error) {
+	if model := r.model.Load(); model != nil {
+		return model, nil
+	}
+
+	if err := r.Rebuild(ctx); err != nil {
+		return nil, err
+	}
+	return r.model.Load(), nil
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+)
+
+// Audit actions.
+const (
+	AuditRefundRequested    = "refund.requested"
+	AuditRefundApproved     = "refund.approved"
+	AuditRefundRejected     = "refund.rejected"
+	AuditRefundFailed       = "refund.failed"
+	AuditOrderRefunded      = "order.refunded"
+	AuditEnrollmentRevoked  = "enrollment.revoked"
+	AuditCertificateRevoked = "certificate.revoked"
+	AuditPayoutCreated      = "payout.created"
+	AuditRatesImported      = "rates.imported"
+)
+
+// AuditFilter selects the audit entries to list. Zero values do not filter.
+type AuditFilter struct {
+	Entity   string
+	EntityID string
+	ActorID  uint
+	Actions  []string
+}
+
+// AuditService shows the audit log to the admins. The entries are written by the services
+// performing the audited actions.
+type AuditService interface {
+	// List returns a page of the audit entries matching the filter.
+	List(ctx context.Context, adminID uint, filter AuditFilter, page Page) ([]models.AuditEntry, PageInfo, error)
+}
+
+// AuditSortFields are the fields the audit entries can be sorted by.
+var AuditSortFields = map[string]bool{
+	"id":         true,
+	"created_at": true,
+}
+
+// NewAuditEntry returns the audit entry of the action performed by the actor, nil for the
+// system, on the record with the JSON-encoded details.
+func NewAuditEntry(actorID *uint, action, entity string, entityID any, details map[string]any) models.AuditEntry {
+	entry := models.AuditEntry{
+		ActorID:  actorID,
+		Action:   action,
+		Entity:   entity,
+		EntityID: fmt.Sprint(entityID),
+	}
+
+	if len(details) > 0 {
+		raw, _ := json.Marshal(details)
+		entry.Details = string(raw)
+	}
+
+	return entry
+}
+
+// EnrollmentKey returns the audit entity ID of the enrollment.
+func EnrollmentKey(userID, courseID uint) string {
+	return fmt.Sprintf("%d:%d", userID, courseID)
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"context"
+)
+
+// Suggestion kinds.
+const (
+	SuggestionCourse     = "course"
+	SuggestionInstructor = "instructor"
+	SuggestionCategory   = "category"
+)
+
+// Suggestion limits.
+const (
+	DefaultSuggestions = 10
+	MaxSuggestions     = 20
+)
+
+// Suggestion is an autocomplete suggestion.
+type Suggestion struct {
+	Kind string
+	ID   uint
+	Text string
+}
+
+// AutocompleteService suggests the courses, instructors and categories as the user types.
+type AutocompleteService interface {
+	// Suggest returns up to limit suggestions whose words start with the words of the query.
+	Suggest(ctx context.Context, query string, limit int) ([]Suggestion, error)
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+)
+
+// CertificateFilter selects the certificates to list. Zero values do not filter.
+type CertificateFilter struct {
+	IDs      []uint
+	UserID   uint
+	CourseID uint
+}
+
+// CertificateService issues the course certificates.
+type CertificateService interface {
+	// List returns a page of the certificates matching the filter with their courses.
+	List(ctx context.Context, filter CertificateFilter, page Page) ([]models.CourseCertificate, PageInfo, error)
+	// Issue creates the certificate for the course completed by the user and renders its image.
+	Issue(ctx context.Context, userID, courseID uint) (models.CourseCertificate, error)
+	// Regenerate renders the certificate image again and returns its storage-relative path.
+	Regenerate(ctx context.Context, id uint) (string, error)
+}
+
+// CertificateSortFields are the fields the certificates can be sorted by.
+var CertificateSortFields = map[string]bool{
+	"id":         true,
+	"created_at": true,
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Coupon kinds.
+const (
+	CouponPercentage = "percentage"
+	CouponFixed      = "fixed"
+)
+
+// couponCodePattern matches the normalized coupon codes.
+var couponCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,64}$`)
+
+// CouponFilter selects the coupons to list. Zero values do not filter.
+type CouponFilter struct {
+	IDs      []uint
+	CourseID uint
+}
+
+// CouponInput is the information needed to create a coupon. A coupon without a course
+// applies to all the courses. The fixed discounts are in the minor units of Currency,
+// the course currency by default.
+type CouponInput struct {
+	Code           string
+	CourseID       *uint
+	Kind           string
+	Value          uint
+	Currency       string
+	MaxRedemptions uint
+	ExpiresAt      *time.Time
+}
+
+// CouponUpdate is the update of the usage limits of a coupon.
+type CouponUpdate struct {
+	CouponID       uint
+	MaxRedemptions uint
+	ExpiresAt      *time.Time
+	Disabled       bool
+}
+
+// SaleUpdate sets the sale price of a course; a nil SalePrice ends the sale.
+type SaleUpdate struct {
+	CourseID  uint
+	SalePrice *uint
+	StartsAt  *time.Time
+	EndsAt    *time.Time
+}
+
+// CouponReport is the summary of the redemptions of a coupon by the orders in Currency.
+// A coupon redeemed in several currencies has a report per currency; an unused one has a
+// single report in the currency of the coupon.
+type CouponReport struct {
+	CouponID    uint
+	Code        string
+	CourseID    *uint
+	Currency    string
+	Redemptions uint
+	// Discount is the total amount taken off the prices, Revenue the total amount paid,
+	// both in the minor units of Currency.
+	Discount uint
+	Revenue  uint
+}
+
+// Price is the price of a course in Currency with the active sale and the coupon applied.
+type Price struct {
+	Currency string
+	// List is the course price; Base is the course price, or the sale price during the sale.
+	List     uint
+	Base     uint
+	Discount uint
+	Amount   uint
+}
+
+// CouponService manages the discount codes of the instructors and the admins.
+type CouponService interface {
+	// List returns a page of the coupons matching the filter created by the user, or of
+	// all the coupons for the admins.
+	List(ctx context.Context, userID uint, filter CouponFilter, page Page) ([]models.Coupon, PageInfo, error)
+	// Create creates a coupon of a course owned by the user, or a site-wide coupon if the
+	// user is an admin.
+	Create(ctx context.Context, userID uint, input CouponInput) (models.Coupon, error)
+	// Update updates the usage limits of a coupon created by the user.
+	Update(ctx context.Context, userID uint, input CouponUpdate) error
+	// Redeemable returns the coupon with the code if it can still be used.
+	Redeemable(ctx context.Context, code string) (models.Coupon, error)
+	// Report returns the redemption summaries of the coupons matching the filter visible to
+	// the user, per coupon and order currency.
+	Report(ctx context.Context, userID uint, filter CouponFilter) ([]CouponReport, error)
+}
+
+// CouponSortFields are the fields the coupons can be sorted by.
+var CouponSortFields = map[string]bool{
+	"id":               true,
+	"code":             true,
+	"redemption_count": true,
+	"created_at":       true,
+}
+
+// NormalizeCouponCode returns the coupon code in the stored form.
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ValidateCoupon normalizes the coupon code and validates the coupon input.
+func ValidateCoupon(input *CouponInput) error {
+	input.Code = NormalizeCouponCode(input.Code)
+	if !couponCodePattern.MatchString(input.Code) {
+		return Errorf(ErrInvalidInput, "coupon code must be 3 to 64 letters, digits, dashes or underscores")
+	}
+
+	switch input.Kind {
+	case CouponPercentage:
+		if input.Value < 1 || input.Value > 100 {
+			return Errorf(ErrInvalidInput, "percentage must be between 1 and 100")
+		}
+	case CouponFixed:
+		if input.Value == 0 {
+			return Errorf(ErrInvalidInput, "fixed discount must be positive")
+		}
+	default:
+		return Errorf(ErrInvalidInput, "unknown coupon kind %q", input.Kind)
+	}
+
+	if input.Currency = NormalizeCurrency(input.Currency); input.Currency != "" && !ValidCurrency(input.Currency) {
+		return Errorf(ErrInvalidInput, "unsupported currency %q", input.Currency)
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return Errorf(ErrInvalidInput, "coupon expiry must be in the future")
+	}
+
+	return nil
+}
+
+// CheckRedeemable returns services.ErrInvalidInput if the coupon is disabled, expired or used up.
+func CheckRedeemable(coupon models.Coupon, now time.Time) error {
+	switch {
+	case coupon.Disabled:
+		return Errorf(ErrInvalidInput, "coupon %s is disabled", coupon.Code)
+	case coupon.ExpiresAt != nil && !now.Before(*coupon.ExpiresAt):
+		return Errorf(ErrInvalidInput, "coupon %s has expired", coupon.Code)
+	case coupon.MaxRedemptions > 0 && coupon.RedemptionCount >= coupon.MaxRedemptions:
+		return Errorf(ErrInvalidInput, "coupon %s is used up", coupon.Code)
+	}
+	return nil
+}
+
+// ValidateSale validates the sale of the course.
+func ValidateSale(course models.Course, input SaleUpdate) error {
+	if input.SalePrice == nil {
+		return nil
+	}
+
+	if *input.SalePrice >= course.Price {
+		return Errorf(ErrInvalidInput, "sale price must be lower than the price")
+	}
+
+	if input.EndsAt == nil {
+		return Errorf(ErrInvalidInput, "sale must have an end")
+	}
+
+	if !input.EndsAt.After(time.Now()) || (input.StartsAt != nil && !input.EndsAt.After(*input.StartsAt)) {
+		return Errorf(ErrInvalidInput, "sale must end in the future and after its start")
+	}
+
+	return nil
+}
+
+// CurrentPrice returns the sale price of the course during the sale, or its price.
+func CurrentPrice(course models.Course, now time.Time) uint {
+	if course.SalePrice == nil || *course.SalePrice >= course.Price ||
+		(course.SaleStartsAt != nil && now.Before(*course.SaleStartsAt)) ||
+		(course.SaleEndsAt != nil && !now.Before(*course.SaleEndsAt)) {
+		return course.Price
+	}
+	return *course.SalePrice
+}
+
+// CouponApplies reports whether the coupon discounts the course.
+func CouponApplies(coupon *models.Coupon, courseID uint) bool {
+	return coupon != nil && (coupon.CourseID == nil || *coupon.CourseID == courseID)
+}
+
+// CalculatePrice returns the price of the course in the currency, the course currency if
+// empty, at now with the coupon, if any and if it applies to the course. The sale takes
+// the same share off the prices in the other currencies and the fixed discounts are
+// converted at the rates. The discount never exceeds the price.
+func CalculatePrice(course models.Course, coupon *models.Coupon, currency string, rates Rates, now time.Time) (Price, error) {
+	if currency == "" {
+		currency = course.Currency
+	}
+
+	list, err := ListPrice(course, currency, rates)
+	if err != nil {
+		return Price{}, err
+	}
+
+	price := Price{Currency: currency, List: list, Base: list}
+	if current := CurrentPrice(course, now); current < course.Price {
+		price.Base = uint(uint64(list) * uint64(current) / uint64(course.Price))
+	}
+
+	if CouponApplies(coupon, course.ID) {
+		switch coupon.Kind {
+		case CouponPercentage:
+			price.Discount = price.Base * coupon.Value / 100
+		case CouponFixed:
+			value, ok := rates.Convert(coupon.Value, coupon.Currency, currency)
+			if !ok {
+				return Price{}, Errorf(ErrInvalidInput, "coupon %s has no value in %s", coupon.Code, currency)
+			}
+			price.Discount = min(value, price.Base)
+		}
+	}
+
+	price.Amount = price.Base - price.Discount
+	return price, nil
+}
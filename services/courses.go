@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"io"
+	"strings"
+)
+
+// Course statuses.
+const (
+	CourseStatusDraft     uint = 1
+	CourseStatusPublished uint = 4
+)
+
+// CourseFilter selects the courses to list. Zero values do not filter. The multi-valued
+// filters match the courses having any of the values.
+type CourseFilter struct {
+	IDs            []uint
+	UserID         uint // courses the user is enrolled in
+	StatusID       uint
+	InstructorID   uint
+	HasCertificate *bool
+
+	// Catalogue facets.
+	CategoryIDs []uint
+	LevelIDs    []uint
+	// PriceBuckets and LengthBuckets are the keys of PriceBuckets and LengthBuckets.
+	PriceBuckets  []string
+	PriceMin      *uint
+	PriceMax      *uint
+	PriceCurrency string // courses priced in the currency
+	Free          *bool
+	LengthBuckets []string
+	LengthMin     *uint // minutes
+	LengthMax     *uint // minutes
+}
+
+// CourseInput is the information needed to create a course.
+type CourseInput struct {
+	Title          string
+	CategoryIDs    []uint
+	LevelID        uint
+	HasCertificate bool
+}
+
+// CourseGeneralUpdate is the update of the course general information.
+type CourseGeneralUpdate struct {
+	CourseID         uint
+	Title            string
+	ShortDescription string
+	Description      string
+	// Price is in the minor units of Currency; an empty Currency keeps the current one.
+	Price    uint
+	Currency string
+	// Thumbnail is the new thumbnail image; nil keeps the current one.
+	Thumbnail io.Reader
+}
+
+// ExerciseInput is a created (ID is zero) or updated exercise.
+type ExerciseInput struct {
+	ID      uint
+	Title   string
+	Content string
+}
+
+// ExercisesUpdate creates, updates and deletes the exercises of a course.
+type ExercisesUpdate struct {
+	CourseID  uint
+	Exercises []ExerciseInput
+	DeleteIDs []uint
+}
+
+// CategoryStat is a category and the number of courses associated with it.
+type CategoryStat struct {
+	Title        string
+	CoursesCount int
+}
+
+// CategoryLevelStat is a course level for a category and the number of courses associated with it.
+type CategoryLevelStat struct {
+	CategoryTitle string
+	LevelTitle    string
+	CoursesCount  int
+}
+
+// CourseService manages the courses, their exercises and the catalogue reference data.
+type CourseService interface {
+	// List returns a page of the courses matching the filter with the instructor, level and categories.
+	List(ctx context.Context, filter CourseFilter, page Page) ([]models.Course, PageInfo, error)
+	// Search returns a page of the published courses matching the query, the most relevant first.
+	Search(ctx context.Context, query string, page Page) ([]CourseSearchResult, PageInfo, error)
+	// Get returns the course with the given ID.
+	Get(ctx context.Context, id uint) (models.Course, error)
+	// Create creates a draft course of the instructor.
+	Create(ctx context.Context, instructorID uint, input CourseInput) (models.Course, error)
+	// UpdateGeneral updates the general information of a course owned by the instructor.
+	UpdateGeneral(ctx context.Context, instructorID uint, input CourseGeneralUpdate) error
+	// UpdateSale sets or ends the sale of a course owned by the instructor.
+	UpdateSale(ctx context.Context, instructorID uint, input SaleUpdate) error
+	// UpdatePrices replaces the prices in the other currencies of a course owned by the
+	// instructor; the currencies without a price are converted at the exchange rates.
+	UpdatePrices(ctx context.Context, instructorID, courseID uint, prices []PriceInput) ([]models.CoursePrice, error)
+	// UpdatePrerequisites replaces the prerequisites of a course owned by the instructor and
+	// sets whether they are enforced. The prerequisites must be published courses and must
+	// not form a cycle.
+	UpdatePrerequisites(ctx context.Context, instructorID uint, input PrerequisiteInput) ([]models.CoursePrerequisite, error)
+	// Prerequisites returns the prerequisite graph of the course, or of all the published
+	// courses if courseID is zero.
+	Prerequisites(ctx context.Context, courseID uint) (PrerequisiteGraph, error)
+	// SaveExercises creates, updates and deletes the exercises of a course owned by the
+	// instructor and recalculates the course length.
+	SaveExercises(ctx context.Context, instructorID uint, input ExercisesUpdate) error
+	// Exercises returns the exercises of the course with the given IDs, or all of them if ids is empty.
+	Exercises(ctx context.Context, courseID uint, ids []uint) ([]models.CourseExercise, error)
+	// Categories returns a page of the categories with the given IDs, or of all of them if ids is empty.
+	Categories(ctx context.Context, ids []uint, page Page) ([]models.CourseCategory, PageInfo, error)
+	// Levels returns the levels with the given IDs, or all of them if ids is empty.
+	Levels(ctx context.Context, ids []uint) ([]models.CourseLevel, error)
+	// Facets returns the number of courses matching the filter per value of each catalogue
+	// facet. The filter on the facet itself is ignored when counting its values.
+	Facets(ctx context.Context, filter CourseFilter) (CourseFacets, error)
+	// CategoryStats returns the number of courses per category.
+	CategoryStats(ctx context.Context) ([]CategoryStat, error)
+	// CategoryLevelStats returns the number of courses per category and level.
+	CategoryLevelStats(ctx context.Context) ([]CategoryLevelStat, error)
+}
+
+// CourseSortFields are the fields the courses can be sorted by. The prices in the different
+// currencies are not comparable, so sorting by the price requires the PriceCurrency filter.
+var CourseSortFields = map[string]bool{
+	"id":              true,
+	"title":           true,
+	"status_id":       true,
+	"instructor_id":   true,
+	"level_id":        true,
+	"has_certificate": true,
+	"price":           true,
+	"length":          true,
+	"rating_average":  true,
+	"rating_count":    true,
+	"updated_at":      true,
+	"created_at":      true,
+}
+
+// ValidateCourseSort checks that the courses sorted by the price are filtered by a currency.
+func ValidateCourseSort(filter CourseFilter, page Page) error {
+	field, _, err := page.SortField(CourseSortFields)
+	if err != nil {
+		return err
+	}
+
+	if field == "price" && filter.PriceCurrency == "" {
+		return Errorf(ErrInvalidInput, "sorting by price requires the price currency filter")
+	}
+	return nil
+}
+
+// CategorySortFields are the fields the course categories can be sorted by.
+var CategorySortFields = map[string]bool{
+	"id":    true,
+	"title": true,
+}
+
+// ParseSort splits the sort parameter into the field and the direction ("ASC" or "DESC")
+// and checks the field against the allowed ones.
+func ParseSort(sort string, allowed map[string]bool) (string, string, error) {
+	direction := "ASC"
+	if strings.HasPrefix(sort, "-") {
+		direction = "DESC"
+		sort = sort[1:]
+	}
+
+	if !allowed[sort] {
+		return "", "", Errorf(ErrInvalidInput, "invalid sort field")
+	}
+
+	return sort, direction, nil
+}
+
+// CalculateExerciseLength calculates and returns the approximate length of the exercise (in minutes).
+func CalculateExerciseLength(content string) uint {
+	words := strings.Fields(content)
+
+	wordCount := len(words)
+
+	length := uint(wordCount) / 200 // approximate reading speed in Ukrainian
+
+	return length
+}
+
+// CourseSearchResult is a course matching the search query.
+type CourseSearchResult struct {
+	models.Course
+	// Rank is the relevance of the course to the query, the higher the better.
+	Rank float64
+	// Snippet is an HTML-escaped excerpt of the course with the matched terms wrapped in <mark> tags.
+	Snippet string
+}
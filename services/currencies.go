@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/money"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PriceInput is the price of a course in a currency set by its instructor.
+type PriceInput struct {
+	Currency string
+	Amount   uint
+}
+
+// RateInput is an imported exchange rate: one unit of the base currency is worth Rate
+// units of the currency.
+type RateInput struct {
+	Currency string
+	Rate     float64
+}
+
+// Rates are the exchange rates of the currencies against Base.
+type Rates struct {
+	Base  string
+	Rates map[string]float64
+}
+
+// CurrencyService manages the exchange rates converting the course prices to the
+// currencies their instructors set no price in.
+type CurrencyService interface {
+	// Rates returns the current exchange rates.
+	Rates(ctx context.Context) ([]models.ExchangeRate, error)
+	// ImportRates replaces the exchange rates with the rates against the base currency.
+	// Only the admins may import the rates.
+	ImportRates(ctx context.Context, userID uint, base string, rates []RateInput) ([]models.ExchangeRate, error)
+}
+
+// ValidCurrency reports whether the currency is supported.
+func ValidCurrency(currency string) bool {
+	_, ok := money.Digits[currency]
+	return ok
+}
+
+// NormalizeCurrency returns the currency code in the stored form.
+func NormalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+// FormatAmount formats the amount in the minor units of the currency in its main units,
+// e.g. "599.00".
+func FormatAmount(amount int64, currency string) string {
+	digits, ok := money.Digits[currency]
+	if !ok {
+		digits = 2
+	}
+
+	return strconv.FormatFloat(float64(amount)/math.Pow10(int(digits)), 'f', int(digits), 64)
+}
+
+// FormatMoney formats the amount in the minor units with the currency, e.g. "599.00 UAH".
+func FormatMoney(amount int64, currency string) string {
+	return FormatAmount(amount, currency) + " " + currency
+}
+
+// NewRates returns the exchange rates of the imported rate table.
+func NewRates(rates []models.ExchangeRate) Rates {
+	r := Rates{Rates: make(map[string]float64, len(rates))}
+	for _, rate := range rates {
+		r.Base = rate.Base
+		r.Rates[rate.Currency] = rate.Rate
+	}
+	return r
+}
+
+// rate returns the number of units of the currency worth one unit of the base currency.
+func (r Rates) rate(currency string) (float64, bool) {
+	if currency == r.Base {
+		return 1, true
+	}
+	rate, ok := r.Rates[currency]
+	return rate, ok && rate > 0
+}
+
+// Convert converts the amount in the minor units of a currency to the minor units of
+// another one, rounding to the nearest unit. It reports false if a rate is missing.
+func (r Rates) Convert(amount uint, from, to string) (uint, bool) {
+	if from == to {
+		return amount, true
+	}
+
+	fromRate, ok := r.rate(from)
+	if !ok || !ValidCurrency(from) {
+		return 0, false
+	}
+	toRate, ok := r.rate(to)
+	if !ok || !ValidCurrency(to) {
+		return 0, false
+	}
+
+	major := float64(amount) / math.Pow10(int(money.Digits[from])) / fromRate * toRate
+	return uint(math.Round(major * math.Pow10(int(money.Digits[to])))), true
+}
+
+// ParseRates parses the CSV rate table with the currency and rate columns, e.g.
+// "USD,0.0243", skipping the header row if any.
+func ParseRates(r io.Reader) ([]RateInput, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, Errorf(ErrInvalidInput, "invalid rate table: %v", err)
+	}
+
+	var rates []RateInput
+	for i, row := range rows {
+		if len(row) != 2 {
+			return nil, Errorf(ErrInvalidInput, "row %d of the rate table must have the currency and the rate", i+1)
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(row[1]), 64)
+		if errors.Is(err, strconv.ErrSyntax) && i == 0 {
+			continue
+		}
+		if err != nil {
+			return nil, Errorf(ErrInvalidInput, "invalid rate in row %d of the rate table", i+1)
+		}
+
+		rates = append(rates, RateInput{Currency: row[0], Rate: rate})
+	}
+
+	return rates, nil
+}
+
+// ValidateRates normalizes and validates the base currency and the rates against it.
+func ValidateRates(base *string, rates []RateInput) error {
+	*base = NormalizeCurrency(*base)
+	if !ValidCurrency(*base) {
+		return Errorf(ErrInvalidInput, "unsupported base currency %q", *base)
+	}
+
+	if len(rates) == 0 {
+		return Errorf(ErrInvalidInput, "rate table is empty")
+	}
+
+	seen := make(map[string]bool)
+	for i := range rates {
+		r := &rates[i]
+		r.Currency = NormalizeCurrency(r.Currency)
+		switch {
+		case !ValidCurrency(r.Currency):
+			return Errorf(ErrInvalidInput, "unsupported currency %q", r.Currency)
+		case r.Currency == *base:
+			return Errorf(ErrInvalidInput, "base currency %s must not be rated", r.Currency)
+		case seen[r.Currency]:
+			return Errorf(ErrInvalidInput, "duplicate rate of %s", r.Currency)
+		case r.Rate <= 0 || math.IsInf(r.Rate, 0) || math.IsNaN(r.Rate):
+			return Errorf(ErrInvalidInput, "rate of %s must be positive", r.Currency)
+		}
+		seen[r.Currency] = true
+	}
+
+	return nil
+}
+
+// ValidatePrices normalizes and validates the prices of the course in the currencies
+// other than its own.
+func ValidatePrices(course models.Course, prices []PriceInput) error {
+	seen := make(map[string]bool)
+	for i := range prices {
+		p := &prices[i]
+		p.Currency = NormalizeCurrency(p.Currency)
+		switch {
+		case !ValidCurrency(p.Currency):
+			return Errorf(ErrInvalidInput, "unsupported currency %q", p.Currency)
+		case p.Currency == course.Currency:
+			return Errorf(ErrInvalidInput, "price in %s is the course price", p.Currency)
+		case seen[p.Currency]:
+			return Errorf(ErrInvalidInput, "duplicate price in %s", p.Currency)
+		case p.Amount == 0:
+			return Errorf(ErrInvalidInput, "price in %s must be positive", p.Currency)
+		}
+		seen[p.Currency] = true
+	}
+
+	return nil
+}
+
+// ListPrice returns the price of the course in the currency: its own price, the price
+// its instructor set in the currency, or its price converted at the rates.
+func ListPrice(course models.Course, currency string, rates Rates) (uint, error) {
+	if currency == "" || currency == course.Currency || course.Price == 0 {
+		return course.Price, nil
+	}
+
+	for _, p := range course.Prices {
+		if p.Currency == currency {
+			return p.Amount, nil
+		}
+	}
+
+	if amount, ok := rates.Convert(course.Price, course.Currency, currency); ok {
+		return amount, nil
+	}
+	return 0, Errorf(ErrInvalidInput, "course %d has no price in %q", course.ID, currency)
+}
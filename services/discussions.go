@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"strings"
+)
+
+// DiscussionFilter selects the discussions of a course to list. Zero values other than
+// CourseID do not filter.
+type DiscussionFilter struct {
+	CourseID   uint
+	ExerciseID uint
+	UserID     uint
+	// Answered selects the discussions by whether the instructor has posted in them, and
+	// Accepted by whether they have an accepted answer.
+	Answered *bool
+	Accepted *bool
+}
+
+// DiscussionInput is a new discussion of a course or of one of its exercises.
+type DiscussionInput struct {
+	CourseID   uint
+	ExerciseID uint
+	Title      string
+	Content    string
+}
+
+// PostInput is a post answering the discussion, or replying to its post ParentID.
+type PostInput struct {
+	DiscussionID uint
+	ParentID     uint
+	Content      string
+}
+
+// VoteInput adds or removes the upvote of the discussion, or of its post PostID.
+type VoteInput struct {
+	DiscussionID uint
+	PostID       uint
+	Up           bool
+}
+
+// DiscussionService manages the course discussions, their posts and upvotes. Only the
+// learners enrolled into the course and its instructor may read and write them.
+type DiscussionService interface {
+	// List returns a page of the discussions matching the filter with their users.
+	List(ctx context.Context, userID uint, filter DiscussionFilter, page Page) ([]models.Discussion, PageInfo, error)
+	// Get returns the discussion with its user and its posts in the order of posting.
+	Get(ctx context.Context, userID, discussionID uint) (models.Discussion, error)
+	// Create starts the discussion of the user.
+	Create(ctx context.Context, userID uint, input DiscussionInput) (models.Discussion, error)
+	// Post adds the post of the user to the discussion. The posts of the instructor mark
+	// the discussion as answered.
+	Post(ctx context.Context, userID uint, input PostInput) (models.DiscussionPost, error)
+	// Vote adds or removes the upvote of the user for a discussion or a post of another user.
+	Vote(ctx context.Context, userID uint, input VoteInput) error
+	// Accept marks the post answering the discussion as its accepted answer, or unmarks it.
+	// Only the author of the discussion and the instructor may accept the answers.
+	Accept(ctx context.Context, userID, postID uint, accepted bool) error
+}
+
+// DiscussionSortFields are the fields the discussions can be sorted by.
+var DiscussionSortFields = map[string]bool{
+	"id":         true,
+	"votes":      true,
+	"post_count": true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// ValidateDiscussion checks the discussion input and trims its title and content.
+func ValidateDiscussion(input *DiscussionInput) error {
+	input.Title = strings.TrimSpace(input.Title)
+
+	if input.CourseID == 0 {
+		return Errorf(ErrInvalidInput, "course is required")
+	}
+
+	if input.Title == "" {
+		return Errorf(ErrInvalidInput, "title is required")
+	}
+
+	if len(input.Title) > 255 {
+		return Errorf(ErrInvalidInput, "title is too long")
+	}
+
+	input.Content = strings.TrimSpace(input.Content)
+	return validateContent(input.Content, false)
+}
+
+// ValidatePost checks the post input and trims its content.
+func ValidatePost(input *PostInput) error {
+	input.Content = strings.TrimSpace(input.Content)
+	return validateContent(input.Content, true)
+}
+
+// validateContent checks the length of the Markdown content.
+func validateContent(content string, required bool) error {
+	if required && content == "" {
+		return Errorf(ErrInvalidInput, "content is required")
+	}
+
+	if len(content) > 65000 {
+		return Errorf(ErrInvalidInput, "content is too long")
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+)
+
+// Enrollment statuses.
+const (
+	EnrollmentStatusEnrolled     uint = 1
+	EnrollmentStatusCompleted    uint = 2
+	EnrollmentStatusCertificated uint = 3
+)
+
+// EnrollmentFilter selects the enrollments to list. Zero values do not filter.
+type EnrollmentFilter struct {
+	UserIDs   []uint
+	StatusIDs []uint
+	CourseID  uint
+}
+
+// EnrollmentService manages the enrollments of users into courses.
+type EnrollmentService interface {
+	// List returns a page of the enrollments matching the filter.
+	List(ctx context.Context, filter EnrollmentFilter, page Page) ([]models.Enrollment, PageInfo, error)
+	// Enroll enrolls the user into the free course, or into the paid published course
+	// through the subscription of the user, unless the enforced prerequisites of the course
+	// are incomplete. The paid courses are bought with the OrderService.
+	Enroll(ctx context.Context, userID, courseID uint) (models.Enrollment, error)
+	// CheckPrerequisites lists the prerequisites of the course the user has not completed.
+	CheckPrerequisites(ctx context.Context, userID, courseID uint) (PrerequisiteCheck, error)
+	// Access reports whether the user may see the content of the course: the free courses,
+	// the courses of the instructor, the bought courses and, while the subscription lasts,
+	// the courses enrolled into through it. The admins see all the courses.
+	Access(ctx context.Context, userID, courseID uint) (bool, error)
+}
+
+// EnrollmentSortFields are the fields the enrollments can be sorted by.
+var EnrollmentSortFields = map[string]bool{
+	"progress":   true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// NewEnrollment returns the new enrollment of the user into the course.
+func NewEnrollment(userID, courseID uint) models.Enrollment {
+	return models.Enrollment{
+		UserID:         userID,
+		CourseID:       courseID,
+		StatusID:       EnrollmentStatusEnrolled,
+		Progress:       0,
+		LastExerciseID: 1,
+	}
+}
+
+// HasAccess reports whether the enrollment, nil if the user is not enrolled, gives access to
+// the content of the course given whether the user is entitled by a subscription.
+func HasAccess(enrollment *models.Enrollment, entitled bool) bool {
+	return enrollment != nil && (enrollment.SubscriptionID == nil || entitled)
+}
+
+// IsCompleted reports whether the enrollment's course has been completed.
+func IsCompleted(e models.Enrollment) bool {
+	return e.StatusID != EnrollmentStatusEnrolled || e.Progress >= 100
+}
@@ -0,0 +1,96 @@
+package services
+
+// Catalogue facets.
+const (
+	FacetCategory = "category"
+	FacetLevel    = "level"
+	FacetPrice    = "price"
+	FacetLength   = "length"
+)
+
+// Bucket is a named range of values. A nil Max leaves the range open.
+type Bucket struct {
+	Key string
+	Min uint
+	Max *uint
+}
+
+// Contains reports whether the value falls into the bucket.
+func (b Bucket) Contains(v uint) bool {
+	return v >= b.Min && (b.Max == nil || v <= *b.Max)
+}
+
+// bound returns a pointer to the bucket bound.
+func bound(v uint) *uint {
+	return &v
+}
+
+// PriceBuckets are the price ranges of the catalogue (minor units of the course currency).
+var PriceBuckets = []Bucket{
+	{Key: "free", Min: 0, Max: bound(0)},
+	{Key: "1-499", Min: 1, Max: bound(49999)},
+	{Key: "500-999", Min: 50000, Max: bound(99999)},
+	{Key: "1000+", Min: 100000},
+}
+
+// LengthBuckets are the course length ranges of the catalogue (minutes).
+var LengthBuckets = []Bucket{
+	{Key: "0-1h", Min: 0, Max: bound(59)},
+	{Key: "1-3h", Min: 60, Max: bound(179)},
+	{Key: "3-6h", Min: 180, Max: bound(359)},
+	{Key: "6h+", Min: 360},
+}
+
+// FindBuckets returns the buckets with the given keys.
+func FindBuckets(buckets []Bucket, keys []string) ([]Bucket, error) {
+	var found []Bucket
+
+keys:
+	for _, key := range keys {
+		for _, b := range buckets {
+			if b.Key == key {
+				found = append(found, b)
+				continue keys
+			}
+		}
+		return nil, Errorf(ErrInvalidInput, "unknown bucket %q", key)
+	}
+
+	return found, nil
+}
+
+// FacetValue is a value of a facet and the number of matching courses.
+type FacetValue struct {
+	ID    uint
+	Title string
+	Count int64
+}
+
+// BucketValue is a bucket of a facet and the number of matching courses.
+type BucketValue struct {
+	Bucket
+	Count int64
+}
+
+// CourseFacets are the value counts of the catalogue facets.
+type CourseFacets struct {
+	Categories []FacetValue
+	Levels     []FacetValue
+	Prices     []BucketValue
+	Lengths    []BucketValue
+}
+
+// Without returns a copy of the filter without the filters on the facet.
+func (f CourseFilter) Without(facet string) CourseFilter {
+	switch facet {
+	case FacetCategory:
+		f.CategoryIDs = nil
+	case FacetLevel:
+		f.LevelIDs = nil
+	case FacetPrice:
+		f.PriceBuckets, f.PriceMin, f.PriceMax, f.Free = nil, nil, nil, nil
+	case FacetLength:
+		f.LengthBuckets, f.LengthMin, f.LengthMax = nil, nil, nil
+	}
+	return f
+}
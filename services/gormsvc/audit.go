@@ -0,0 +1,95 @@
+package gormsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+	"time"
+)
+
+// AuditService is the GORM implementation of services.AuditService.
+type AuditService struct {
+	db *gorm.DB
+}
+
+// NewAuditService creates a new AuditService.
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// List returns a page of the audit entries matching the filter.
+func (s *AuditService) List(ctx context.Context, adminID uint, filter services.AuditFilter, page services.Page) ([]models.AuditEntry, services.PageInfo, error) {
+	if err := requireAdmin(s.db.WithContext(ctx), adminID); err != nil {
+		return nil, services.PageInfo{}, err
+	}
+
+	query := s.db.WithContext(ctx)
+
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+
+	if len(filter.Actions) > 0 {
+		query = query.Where("action IN ?", filter.Actions)
+	}
+
+	return paginate[models.AuditEntry](query, page, services.AuditSortFields)
+}
+
+// audit writes the audit entry of the action.
+func audit(tx *gorm.DB, actorID *uint, action, entity string, entityID any, details map[string]any) error {
+	entry := services.NewAuditEntry(actorID, action, entity, entityID, details)
+	return tx.Create(&entry).Error
+}
+
+// revokeEnrollment deletes the enrollment of the user, together with the review of the
+// course, and revokes the certificates of the course.
+func revokeEnrollment(tx *gorm.DB, actorID *uint, userID, courseID uint, reason string) error {
+	// the review and its votes are deleted with the enrollment
+	result := tx.Where("user_id = ? AND course_id = ?", userID, courseID).Delete(&models.Enrollment{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		if err := refreshRating(tx, courseID); err != nil {
+			return err
+		}
+
+		err := audit(tx, actorID, services.AuditEnrollmentRevoked, "enrollments", services.EnrollmentKey(userID, courseID),
+			map[string]any{"reason": reason})
+		if err != nil {
+			return err
+		}
+	}
+
+	var certificateIDs []uint
+	err := tx.Model(&models.CourseCertificate{}).
+		Where("user_id = ? AND course_id = ? AND revoked_at IS NULL", userID, courseID).
+		Pluck("id", &certificateIDs).Error
+	if err != nil || len(certificateIDs) == 0 {
+		return err
+	}
+
+	err = tx.Model(&models.CourseCertificate{}).Where("id IN ?", certificateIDs).Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+
+	for _, id := range certificateIDs {
+		if err := audit(tx, actorID, services.AuditCertificateRevoked, "course_certificates", id, map[string]any{"reason": reason}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package gormsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/certificates"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+)
+
+// CertificateService is the GORM implementation of services.CertificateService.
+type CertificateService struct {
+	db        *gorm.DB
+	generator *certificates.Generator
+}
+
+// NewCertificateService creates a new CertificateService.
+func NewCertificateService(db *gorm.DB, generator *certificates.Generator) *CertificateService {
+	return &CertificateService{db: db, generator: generator}
+}
+
+// List returns a page of the certificates matching the filter with their courses.
+func (s *CertificateService) List(ctx context.Context, filter services.CertificateFilter, page services.Page) ([]models.CourseCertificate, services.PageInfo, error) {
+	query := s.db.WithContext(ctx)
+
+	if len(filter.IDs) > 0 {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+
+	if filter.CourseID != 0 {
+		query = query.Where("course_id = ?", filter.CourseID)
+	}
+
+	return paginate[models.CourseCertificate](query.Preload("Course"), page, services.CertificateSortFields)
+}
+
+// Issue creates the certificate for the course completed by the user and renders its image.
+func (s *CertificateService) Issue(ctx context.Context, userID, courseID uint) (models.CourseCertificate, error) {
+	db := s.db.WithContext(ctx)
+
+	var course models.Course
+	if err := db.First(&course, "id = ?", courseID).Error; err != nil {
+		return models.CourseCertificate{}, wrapNotFound(err, "course %d", courseID)
+	}
+
+	if !course.HasCertificate {
+		return models.CourseCertificate{}, services.Errorf(services.ErrInvalidInput, "course does not grant a certificate")
+	}
+
+	var enrollment models.Enrollment
+	if err := db.First(&enrollment, "user_id = ? AND course_id = ?", userID, courseID).Error; err != nil {
+		return models.CourseCertificate{}, wrapNotFound(err, "enrollment")
+	}
+
+	if !services.IsCompleted(enrollment) {
+		return models.CourseCertificate{}, services.Errorf(services.ErrInvalidInput, "course is not completed")
+	}
+
+	var count int64
+	db.Model(&models.CourseCertificate{}).Where("user_id = ? AND course_id = ? AND revoked_at IS NULL", userID, courseID).Count(&count)
+	if count > 0 {
+		return models.CourseCertificate{}, services.Errorf(services.ErrConflict, "certificate already exists")
+	}
+
+	certificate := models.CourseCertificate{UserID: userID, CourseID: courseID}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&certificate).Error; err != nil {
+			return err
+		}
+
+		data, err := certificates.LoadData(tx, certificate.ID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.generator.Generate(data); err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Enrollment{}).
+			Where("user_id = ? AND course_id = ?", userID, courseID).
+			Update("status_id", services.EnrollmentStatusCertificated).Error
+	})
+
+	return certificate, err
+}
+
+// Regenerate renders the certificate image again and returns its storage-relative path.
+func (s *CertificateService) Regenerate(ctx context.Context, id uint) (string, error) {
+	data, err := certificates.LoadData(s.db.WithContext(ctx), id)
+	if err != nil {
+		return "", wrapNotFound(err, "certificate %d", id)
+	}
+
+	return s.generator.Generate(data)
+}
@@ -0,0 +1,202 @@
+package gormsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+	"time"
+)
+
+// CouponService is the GORM implementation of services.CouponService.
+type CouponService struct {
+	db       *gorm.DB
+	currency string
+}
+
+// NewCouponService creates a new CouponService. The fixed discounts of the site-wide
+// coupons are in the currency by default.
+func NewCouponService(db *gorm.DB, currency string) *CouponService {
+	return &CouponService{db: db, currency: currency}
+}
+
+// visible returns the query of the coupons matching the filter created by the user, or of
+// all the coupons for the admins.
+func (s *CouponService) visible(ctx context.Context, userID uint, filter services.CouponFilter) (*gorm.DB, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).First(&user, "id = ?", userID).Error; err != nil {
+		return nil, wrapNotFound(err, "user %d", userID)
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.Coupon{})
+
+	if user.UserTypeID != services.UserTypeAdmin {
+		query = query.Where("coupons.creator_id = ?", userID)
+	}
+
+	if len(filter.IDs) > 0 {
+		query = query.Where("coupons.id IN ?", filter.IDs)
+	}
+
+	if filter.CourseID != 0 {
+		query = query.Where("coupons.course_id = ?", filter.CourseID)
+	}
+
+	return query, nil
+}
+
+// List returns a page of the coupons matching the filter created by the user, or of all
+// the coupons for the admins.
+func (s *CouponService) List(ctx context.Context, userID uint, filter services.CouponFilter, page services.Page) ([]models.Coupon, services.PageInfo, error) {
+	query, err := s.visible(ctx, userID, filter)
+	if err != nil {
+		return nil, services.PageInfo{}, err
+	}
+
+	return paginate[models.Coupon](query, page, services.CouponSortFields)
+}
+
+// Create creates a coupon of a course owned by the user, or a site-wide coupon if the user is an admin.
+func (s *CouponService) Create(ctx context.Context, userID uint, input services.CouponInput) (models.Coupon, error) {
+	if err := services.ValidateCoupon(&input); err != nil {
+		return models.Coupon{}, err
+	}
+
+	currency := s.currency
+	if input.CourseID == nil {
+		if err := requireAdmin(s.db.WithContext(ctx), userID); err != nil {
+			return models.Coupon{}, err
+		}
+	} else {
+		var course models.Course
+		if err := s.db.WithContext(ctx).First(&course, "id = ?", *input.CourseID).Error; err != nil {
+			return models.Coupon{}, wrapNotFound(err, "course %d", *input.CourseID)
+		}
+
+		if course.InstructorID != userID {
+			if err := requireAdmin(s.db.WithContext(ctx), userID); err != nil {
+				return models.Coupon{}, services.Errorf(services.ErrForbidden, "only the course instructor can create its coupons")
+			}
+		}
+		currency = course.Currency
+	}
+
+	if input.Currency != "" {
+		currency = input.Currency
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.Coupon{}).Where("code = ?", input.Code).Count(&count).Error; err != nil {
+		return models.Coupon{}, err
+	}
+	if count > 0 {
+		return models.Coupon{}, services.Errorf(services.ErrConflict, "coupon %s already exists", input.Code)
+	}
+
+	coupon := models.Coupon{
+		Code:           input.Code,
+		CreatorID:      userID,
+		CourseID:       input.CourseID,
+		Kind:           input.Kind,
+		Value:          input.Value,
+		Currency:       currency,
+		MaxRedemptions: input.MaxRedemptions,
+		ExpiresAt:      input.ExpiresAt,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&coupon).Error; err != nil {
+		return models.Coupon{}, err
+	}
+
+	return coupon, nil
+}
+
+// Update updates the usage limits of a coupon created by the user.
+func (s *CouponService) Update(ctx context.Context, userID uint, input services.CouponUpdate) error {
+	query, err := s.visible(ctx, userID, services.CouponFilter{IDs: []uint{input.CouponID}})
+	if err != nil {
+		return err
+	}
+
+	var coupon models.Coupon
+	if err := query.First(&coupon).Error; err != nil {
+		return wrapNotFound(err, "coupon %d", input.CouponID)
+	}
+
+	return s.db.WithContext(ctx).Model(&coupon).Updates(map[string]interface{}{
+		"MaxRedemptions": input.MaxRedemptions,
+		"ExpiresAt":      input.ExpiresAt,
+		"Disabled":       input.Disabled,
+	}).Error
+}
+
+// Redeemable returns the coupon with the code if it can still be used.
+func (s *CouponService) Redeemable(ctx context.Context, code string) (models.Coupon, error) {
+	return redeemableCoupon(s.db.WithContext(ctx), code)
+}
+
+// Report returns the redemption summaries of the coupons matching the filter visible to the user.
+func (s *CouponService) Report(ctx context.Context, userID uint, filter services.CouponFilter) ([]services.CouponReport, error) {
+	query, err := s.visible(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []services.CouponReport
+	err = query.
+		Select("coupons.id AS coupon_id, coupons.code, coupons.course_id, " +
+			"COALESCE(orders.currency, coupons.currency) AS currency, " +
+			"COUNT(coupon_redemptions.id) AS redemptions, " +
+			"COALESCE(SUM(coupon_redemptions.discount), 0) AS discount, " +
+			"COALESCE(SUM(coupon_redemptions.amount), 0) AS revenue").
+		Joins("LEFT JOIN coupon_redemptions ON coupon_redemptions.coupon_id = coupons.id").
+		Joins("LEFT JOIN orders ON orders.id = coupon_redemptions.order_id").
+		// the amounts in different currencies are not summed up
+		Group("coupons.id, coupons.code, coupons.course_id, COALESCE(orders.currency, coupons.currency)").
+		Order("coupons.id, currency").
+		Scan(&reports).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// redeemableCoupon returns the coupon with the code if it can still be used.
+func redeemableCoupon(db *gorm.DB, code string) (models.Coupon, error) {
+	code = services.NormalizeCouponCode(code)
+
+	var coupon models.Coupon
+	if err := db.First(&coupon, "code = ?", code).Error; err != nil {
+		return models.Coupon{}, wrapNotFound(err, "coupon %s", code)
+	}
+
+	if err := services.CheckRedeemable(coupon, time.Now()); err != nil {
+		return models.Coupon{}, err
+	}
+
+	return coupon, nil
+}
+
+// redeemCoupon records the use of the coupon of the paid order.
+func redeemCoupon(tx *gorm.DB, order models.Order) error {
+	if order.CouponID == nil {
+		return nil
+	}
+
+	redemption := models.CouponRedemption{
+		CouponID: *order.CouponID,
+		OrderID:  order.ID,
+		UserID:   order.UserID,
+		CourseID: order.CourseID,
+		Discount: order.Discount,
+		Amount:   order.Amount,
+	}
+	if err := tx.Create(&redemption).Error; err != nil {
+		return err
+	}
+
+	// the paid orders are honoured even if the limit was reached since their checkout
+	return tx.Model(&models.Coupon{}).Where("id = ?", *order.CouponID).
+		UpdateColumn("redemption_count", gorm.Expr("redemption_count + 1")).Error
+}
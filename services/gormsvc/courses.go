@@ -0,0 +1,325 @@
+package gormsvc
+
+import (
+	"context"
+	"fmt"
+	"github.com/plaja-app/back-end/events"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"github.com/plaja-app/back-end/storage"
+	"gorm.io/gorm"
+	"path"
+	"time"
+)
+
+// CourseService is the GORM implementation of services.CourseService.
+type CourseService struct {
+	db       *gorm.DB
+	store    storage.Storage
+	events   *events.Bus
+	currency string
+}
+
+// NewCourseService creates a new CourseService publishing the course changes on the bus.
+// The new courses are priced in the currency.
+func NewCourseService(db *gorm.DB, store storage.Storage, bus *events.Bus, currency string) *CourseService {
+	return &CourseService{db: db, store: store, events: bus, currency: currency}
+}
+
+// List returns a page of the courses matching the filter with the instructor, level and categories.
+func (s *CourseService) List(ctx context.Context, filter services.CourseFilter, page services.Page) ([]models.Course, services.PageInfo, error) {
+	if err := services.ValidateCourseSort(filter, page); err != nil {
+		return nil, services.PageInfo{}, err
+	}
+
+	query, err := filterCourses(s.db.WithContext(ctx).Model(&models.Course{}), filter)
+	if err != nil {
+		return nil, services.PageInfo{}, err
+	}
+
+	query = query.Preload("Instructor").Preload("Level").Preload("Categories").Preload("Prices")
+
+	return paginate[models.Course](query, page, services.CourseSortFields)
+}
+
+// Get returns the course with the given ID.
+func (s *CourseService) Get(ctx context.Context, id uint) (models.Course, error) {
+	var course models.Course
+	err := s.db.WithContext(ctx).Preload("Prices").First(&course, "id = ?", id).Error
+	return course, wrapNotFound(err, "course %d", id)
+}
+
+// owned returns the course if it is owned by the instructor.
+func (s *CourseService) owned(ctx context.Context, instructorID, courseID uint) (models.Course, error) {
+	course, err := s.Get(ctx, courseID)
+	if err != nil {
+		return course, err
+	}
+
+	if course.InstructorID != instructorID {
+		return course, services.Errorf(services.ErrForbidden, "instructor not authorized to update this course")
+	}
+
+	return course, nil
+}
+
+// Create creates a draft course of the instructor.
+func (s *CourseService) Create(ctx context.Context, instructorID uint, input services.CourseInput) (models.Course, error) {
+	var categories []models.CourseCategory
+	if len(input.CategoryIDs) > 0 {
+		if err := s.db.WithContext(ctx).Where("id IN ?", input.CategoryIDs).Find(&categories).Error; err != nil {
+			return models.Course{}, err
+		}
+	}
+
+	course := models.Course{
+		Title:          input.Title,
+		Thumbnail:      "service/courses/no-thumbnail.png",
+		Categories:     categories,
+		LevelID:        input.LevelID,
+		StatusID:       services.CourseStatusDraft,
+		Currency:       s.currency,
+		HasCertificate: input.HasCertificate,
+		InstructorID:   instructorID,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&course).Error; err != nil {
+		return models.Course{}, services.Errorf(services.ErrInvalidInput, "error creating course")
+	}
+
+	s.events.Publish(ctx, events.Event{Topic: events.CourseCreated, ID: course.ID})
+
+	return course, nil
+}
+
+// UpdateGeneral updates the general information of a course owned by the instructor.
+func (s *CourseService) UpdateGeneral(ctx context.Context, instructorID uint, input services.CourseGeneralUpdate) error {
+	course, err := s.owned(ctx, instructorID, input.CourseID)
+	if err != nil {
+		return err
+	}
+
+	updateData := map[string]interface{}{
+		"ShortDescription": input.ShortDescription,
+		"Title":            input.Title,
+		"Description":      input.Description,
+		"Price":            input.Price,
+	}
+
+	// the sale and the price in the new currency make no sense after changing it
+	if input.Currency = services.NormalizeCurrency(input.Currency); input.Currency != "" && input.Currency != course.Currency {
+		if !services.ValidCurrency(input.Currency) {
+			return services.Errorf(services.ErrInvalidInput, "unsupported currency %q", input.Currency)
+		}
+		updateData["Currency"] = input.Currency
+		updateData["SalePrice"], updateData["SaleStartsAt"], updateData["SaleEndsAt"] = nil, nil, nil
+
+		err := s.db.WithContext(ctx).Where("course_id = ? AND currency = ?", course.ID, input.Currency).Delete(&models.CoursePrice{}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if input.Thumbnail != nil {
+		filePath := path.Join("courses/thumbnails", fmt.Sprintf("%d-%s", input.CourseID, "thumbnail.png"))
+		if err := s.store.Save(filePath, input.Thumbnail); err != nil {
+			return fmt.Errorf("failed to save the file: %v", err)
+		}
+		updateData["Thumbnail"] = filePath
+	}
+
+	if err := s.db.WithContext(ctx).Model(&models.Course{}).Where("id = ?", input.CourseID).Updates(updateData).Error; err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, events.Event{Topic: events.CourseUpdated, ID: input.CourseID})
+
+	if input.Price < course.Price && updateData["Currency"] == nil {
+		s.events.Publish(ctx, events.Event{
+			Topic:   events.CoursePriceDropped,
+			ID:      input.CourseID,
+			Payload: events.PriceChange{Old: course.Price, New: input.Price},
+		})
+	}
+
+	return nil
+}
+
+// UpdateSale sets or ends the sale of a course owned by the instructor.
+func (s *CourseService) UpdateSale(ctx context.Context, instructorID uint, input services.SaleUpdate) error {
+	course, err := s.owned(ctx, instructorID, input.CourseID)
+	if err != nil {
+		return err
+	}
+
+	if err := services.ValidateSale(course, input); err != nil {
+		return err
+	}
+
+	if input.SalePrice == nil {
+		input.StartsAt, input.EndsAt = nil, nil
+	}
+
+	err = s.db.WithContext(ctx).Model(&models.Course{}).Where("id = ?", input.CourseID).Updates(map[string]interface{}{
+		"SalePrice":    input.SalePrice,
+		"SaleStartsAt": input.StartsAt,
+		"SaleEndsAt":   input.EndsAt,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, events.Event{Topic: events.CourseUpdated, ID: input.CourseID})
+
+	return nil
+}
+
+// UpdatePrices replaces the prices in the other currencies of a course owned by the instructor.
+func (s *CourseService) UpdatePrices(ctx context.Context, instructorID, courseID uint, prices []services.PriceInput) ([]models.CoursePrice, error) {
+	course, err := s.owned(ctx, instructorID, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := services.ValidatePrices(course, prices); err != nil {
+		return nil, err
+	}
+
+	list := make([]models.CoursePrice, len(prices))
+	for i, p := range prices {
+		list[i] = models.CoursePrice{CourseID: courseID, Currency: p.Currency, Amount: p.Amount}
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("course_id = ?", courseID).Delete(&models.CoursePrice{}).Error; err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			return nil
+		}
+		return tx.Create(&list).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.Publish(ctx, events.Event{Topic: events.CourseUpdated, ID: courseID})
+
+	return list, nil
+}
+
+// SaveExercises creates, updates and deletes the exercises of a course owned by the
+// instructor and recalculates the course length.
+func (s *CourseService) SaveExercises(ctx context.Context, instructorID uint, input services.ExercisesUpdate) error {
+	course, err := s.owned(ctx, instructorID, input.CourseID)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, ex := range input.Exercises {
+			if ex.ID != 0 {
+				var existingExercise models.CourseExercise
+				if err := tx.First(&existingExercise, "id = ? AND course_id = ?", ex.ID, course.ID).Error; err != nil {
+					return wrapNotFound(err, "exercise %d", ex.ID)
+				}
+
+				existingExercise.Title = ex.Title
+				existingExercise.Content = ex.Content
+				existingExercise.Length = services.CalculateExerciseLength(ex.Content)
+				existingExercise.UpdatedAt = time.Now()
+
+				if err := tx.Save(&existingExercise).Error; err != nil {
+					return fmt.Errorf("failed to update exercise: %v", err)
+				}
+			} else {
+				newExercise := models.CourseExercise{
+					CourseID: course.ID,
+					TypeID:   1, // article
+					Length:   services.CalculateExerciseLength(ex.Content),
+					Title:    ex.Title,
+					Content:  ex.Content,
+				}
+
+				if err := tx.Create(&newExercise).Error; err != nil {
+					return fmt.Errorf("failed to add exercise: %v", err)
+				}
+			}
+		}
+
+		if len(input.DeleteIDs) > 0 {
+			if err := tx.Where("id IN ? AND course_id = ?", input.DeleteIDs, course.ID).Delete(&models.CourseExercise{}).Error; err != nil {
+				return err
+			}
+		}
+
+		var totalCourseLength uint
+		if err := tx.Model(&models.CourseExercise{}).Where("course_id = ?", course.ID).Select("COALESCE(SUM(length), 0)").Row().Scan(&totalCourseLength); err != nil {
+			return fmt.Errorf("failed to calculate total course length: %v", err)
+		}
+
+		return tx.Model(&models.Course{}).Where("id = ?", course.ID).Update("length", totalCourseLength).Error
+	})
+}
+
+// Exercises returns the exercises of the course with the given IDs, or all of them if ids is empty.
+func (s *CourseService) Exercises(ctx context.Context, courseID uint, ids []uint) ([]models.CourseExercise, error) {
+	query := s.db.WithContext(ctx).Order("id").Where("course_id = ?", courseID)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+
+	var exercises []models.CourseExercise
+	err := query.Find(&exercises).Error
+	return exercises, err
+}
+
+// Categories returns a page of the categories with the given IDs, or of all of them if ids is empty.
+func (s *CourseService) Categories(ctx context.Context, ids []uint, page services.Page) ([]models.CourseCategory, services.PageInfo, error) {
+	query := s.db.WithContext(ctx)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+
+	return paginate[models.CourseCategory](query, page, services.CategorySortFields)
+}
+
+// Levels returns the levels with the given IDs, or all of them if ids is empty.
+func (s *CourseService) Levels(ctx context.Context, ids []uint) ([]models.CourseLevel, error) {
+	query := s.db.WithContext(ctx)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+
+	var levels []models.CourseLevel
+	err := query.Find(&levels).Error
+	return levels, err
+}
+
+// CategoryStats returns the number of courses per category.
+func (s *CourseService) CategoryStats(ctx context.Context) ([]services.CategoryStat, error) {
+	var stats []services.CategoryStat
+
+	err := s.db.WithContext(ctx).Table("course_categories").Select("course_categories.title, COUNT(course_categories_junction.course_id) as courses_count").
+		Joins("LEFT JOIN course_categories_junction ON course_categories.id = course_categories_junction.course_category_id").
+		Joins("LEFT JOIN courses ON course_categories_junction.course_id = courses.id").
+		Group("course_categories.title").
+		Scan(&stats).Error
+
+	return stats, err
+}
+
+// CategoryLevelStats returns the number of courses per category and level.
+func (s *CourseService) CategoryLevelStats(ctx context.Context) ([]services.CategoryLevelStat, error) {
+	var stats []services.CategoryLevelStat
+
+	err := s.db.WithContext(ctx).Table("course_categories").
+		Select("course_categories.title as category_title, course_levels.title as level_title, COUNT(courses.id) as courses_count").
+		Joins("LEFT JOIN course_categories_junction ON course_categories.id = course_categories_junction.course_category_id").
+		Joins("LEFT JOIN courses ON course_categories_junction.course_id = courses.id").
+		Joins("LEFT JOIN course_levels ON courses.level_id = course_levels.id").
+		Group("course_categories.title, course_levels.title").
+		Scan(&stats).Error
+
+	return stats, err
+}
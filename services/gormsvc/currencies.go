@@ -0,0 +1,63 @@
+package gormsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+	"time"
+)
+
+// CurrencyService is the GORM implementation of services.CurrencyService.
+type CurrencyService struct {
+	db *gorm.DB
+}
+
+// NewCurrencyService creates a new CurrencyService.
+func NewCurrencyService(db *gorm.DB) *CurrencyService {
+	return &CurrencyService{db: db}
+}
+
+// Rates returns the current exchange rates.
+func (s *CurrencyService) Rates(ctx context.Context) ([]models.ExchangeRate, error) {
+	return exchangeRates(s.db.WithContext(ctx))
+}
+
+// ImportRates replaces the exchange rates with the rates against the base currency.
+func (s *CurrencyService) ImportRates(ctx context.Context, userID uint, base string, rates []services.RateInput) ([]models.ExchangeRate, error) {
+	if err := requireAdmin(s.db.WithContext(ctx), userID); err != nil {
+		return nil, err
+	}
+
+	if err := services.ValidateRates(&base, rates); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	list := make([]models.ExchangeRate, len(rates))
+	for i, r := range rates {
+		list[i] = models.ExchangeRate{Currency: r.Currency, Base: base, Rate: r.Rate, UpdatedAt: now}
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.ExchangeRate{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&list).Error; err != nil {
+			return err
+		}
+		return audit(tx, &userID, services.AuditRatesImported, "exchange_rates", base, map[string]any{"count": len(list)})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// exchangeRates returns the exchange rates by currency.
+func exchangeRates(db *gorm.DB) ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+	err := db.Order("currency").Find(&rates).Error
+	return rates, err
+}
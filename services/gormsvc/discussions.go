@@ -0,0 +1,268 @@
+package gormsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/markdown"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// DiscussionService is the GORM implementation of services.DiscussionService.
+type DiscussionService struct {
+	db *gorm.DB
+}
+
+// NewDiscussionService creates a new DiscussionService.
+func NewDiscussionService(db *gorm.DB) *DiscussionService {
+	return &DiscussionService{db: db}
+}
+
+// List returns a page of the discussions matching the filter with their users.
+func (s *DiscussionService) List(ctx context.Context, userID uint, filter services.DiscussionFilter, page services.Page) ([]models.Discussion, services.PageInfo, error) {
+	if filter.CourseID == 0 {
+		return nil, services.PageInfo{}, services.Errorf(services.ErrInvalidInput, "course is required")
+	}
+
+	if _, err := discussionAccess(s.db.WithContext(ctx), userID, filter.CourseID); err != nil {
+		return nil, services.PageInfo{}, err
+	}
+
+	query := s.db.WithContext(ctx).Where("course_id = ?", filter.CourseID)
+
+	if filter.ExerciseID != 0 {
+		query = query.Where("exercise_id = ?", filter.ExerciseID)
+	}
+
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+
+	if filter.Answered != nil {
+		query = query.Where("instructor_answered = ?", *filter.Answered)
+	}
+
+	if filter.Accepted != nil {
+		if *filter.Accepted {
+			query = query.Where("accepted_post_id IS NOT NULL")
+		} else {
+			query = query.Where("accepted_post_id IS NULL")
+		}
+	}
+
+	return paginate[models.Discussion](query.Preload("User"), page, services.DiscussionSortFields)
+}
+
+// Get returns the discussion with its user and its posts in the order of posting.
+func (s *DiscussionService) Get(ctx context.Context, userID, discussionID uint) (models.Discussion, error) {
+	discussion, err := s.discussion(ctx, userID, discussionID)
+	if err != nil {
+		return models.Discussion{}, err
+	}
+
+	err = s.db.WithContext(ctx).Preload("User").Where("discussion_id = ?", discussionID).Order("id").Find(&discussion.Posts).Error
+	return discussion, err
+}
+
+// Create starts the discussion of the user.
+func (s *DiscussionService) Create(ctx context.Context, userID uint, input services.DiscussionInput) (models.Discussion, error) {
+	if err := services.ValidateDiscussion(&input); err != nil {
+		return models.Discussion{}, err
+	}
+
+	if _, err := discussionAccess(s.db.WithContext(ctx), userID, input.CourseID); err != nil {
+		return models.Discussion{}, err
+	}
+
+	discussion := models.Discussion{
+		CourseID:    input.CourseID,
+		UserID:      userID,
+		Title:       input.Title,
+		Content:     input.Content,
+		ContentHTML: markdown.Render(input.Content),
+	}
+
+	if input.ExerciseID != 0 {
+		var exercise models.CourseExercise
+		err := s.db.WithContext(ctx).First(&exercise, "id = ? AND course_id = ?", input.ExerciseID, input.CourseID).Error
+		if err != nil {
+			return models.Discussion{}, wrapNotFound(err, "exercise %d of course %d", input.ExerciseID, input.CourseID)
+		}
+		discussion.ExerciseID = &exercise.ID
+	}
+
+	if err := s.db.WithContext(ctx).Create(&discussion).Error; err != nil {
+		return models.Discussion{}, err
+	}
+
+	return discussion, nil
+}
+
+// Post adds the post of the user to the discussion.
+func (s *DiscussionService) Post(ctx context.Context, userID uint, input services.PostInput) (models.DiscussionPost, error) {
+	if err := services.ValidatePost(&input); err != nil {
+		return models.DiscussionPost{}, err
+	}
+
+	discussion, err := s.discussion(ctx, userID, input.DiscussionID)
+	if err != nil {
+		return models.DiscussionPost{}, err
+	}
+
+	post := models.DiscussionPost{
+		DiscussionID: discussion.ID,
+		UserID:       userID,
+		Content:      input.Content,
+		ContentHTML:  markdown.Render(input.Content),
+		ByInstructor: discussion.Course.InstructorID == userID,
+	}
+
+	if input.ParentID != 0 {
+		var parent models.DiscussionPost
+		err := s.db.WithContext(ctx).First(&parent, "id = ? AND discussion_id = ?", input.ParentID, discussion.ID).Error
+		if err != nil {
+			return models.DiscussionPost{}, wrapNotFound(err, "post %d of discussion %d", input.ParentID, discussion.ID)
+		}
+		post.ParentID = &parent.ID
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&post).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"post_count": gorm.Expr("post_count + 1"),
+			"updated_at": time.Now(),
+		}
+		if post.ByInstructor {
+			updates["instructor_answered"] = true
+		}
+
+		return tx.Model(&models.Discussion{}).Where("id = ?", discussion.ID).UpdateColumns(updates).Error
+	})
+	if err != nil {
+		return models.DiscussionPost{}, err
+	}
+
+	return post, nil
+}
+
+// Vote adds or removes the upvote of the user for a discussion or a post of another user.
+func (s *DiscussionService) Vote(ctx context.Context, userID uint, input services.VoteInput) error {
+	discussion, err := s.discussion(ctx, userID, input.DiscussionID)
+	if err != nil {
+		return err
+	}
+
+	if input.PostID == 0 {
+		if discussion.UserID == userID {
+			return services.Errorf(services.ErrForbidden, "users cannot vote for their own discussions")
+		}
+
+		vote := models.DiscussionVote{DiscussionID: discussion.ID, UserID: userID}
+		return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := toggleVote(tx, &vote, input.Up); err != nil {
+				return err
+			}
+
+			return tx.Model(&models.Discussion{}).Where("id = ?", discussion.ID).
+				UpdateColumn("votes", tx.Model(&models.DiscussionVote{}).Select("COUNT(*)").Where("discussion_id = ?", discussion.ID)).Error
+		})
+	}
+
+	var post models.DiscussionPost
+	if err := s.db.WithContext(ctx).First(&post, "id = ? AND discussion_id = ?", input.PostID, discussion.ID).Error; err != nil {
+		return wrapNotFound(err, "post %d of discussion %d", input.PostID, discussion.ID)
+	}
+
+	if post.UserID == userID {
+		return services.Errorf(services.ErrForbidden, "users cannot vote for their own posts")
+	}
+
+	vote := models.DiscussionPostVote{PostID: post.ID, UserID: userID}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := toggleVote(tx, &vote, input.Up); err != nil {
+			return err
+		}
+
+		return tx.Model(&models.DiscussionPost{}).Where("id = ?", post.ID).
+			UpdateColumn("votes", tx.Model(&models.DiscussionPostVote{}).Select("COUNT(*)").Where("post_id = ?", post.ID)).Error
+	})
+}
+
+// Accept marks the post answering the discussion as its accepted answer, or unmarks it.
+func (s *DiscussionService) Accept(ctx context.Context, userID, postID uint, accepted bool) error {
+	var post models.DiscussionPost
+	if err := s.db.WithContext(ctx).First(&post, "id = ?", postID).Error; err != nil {
+		return wrapNotFound(err, "post %d", postID)
+	}
+
+	discussion, err := s.discussion(ctx, userID, post.DiscussionID)
+	if err != nil {
+		return err
+	}
+
+	if discussion.UserID != userID && discussion.Course.InstructorID != userID {
+		return services.Errorf(services.ErrForbidden, "only the author of the discussion and the instructor can accept the answers")
+	}
+
+	if post.ParentID != nil {
+		return services.Errorf(services.ErrInvalidInput, "only the answers to the discussion can be accepted")
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.Discussion{}).Where("id = ?", discussion.ID)
+	if accepted {
+		return query.UpdateColumn("accepted_post_id", post.ID).Error
+	}
+	return query.Where("accepted_post_id = ?", post.ID).UpdateColumn("accepted_post_id", nil).Error
+}
+
+// discussion returns the discussion with its user and course if the user may take part in it.
+func (s *DiscussionService) discussion(ctx context.Context, userID, discussionID uint) (models.Discussion, error) {
+	var discussion models.Discussion
+	if err := s.db.WithContext(ctx).Preload("User").First(&discussion, "id = ?", discussionID).Error; err != nil {
+		return models.Discussion{}, wrapNotFound(err, "discussion %d", discussionID)
+	}
+
+	course, err := discussionAccess(s.db.WithContext(ctx), userID, discussion.CourseID)
+	if err != nil {
+		return models.Discussion{}, err
+	}
+	discussion.Course = course
+
+	return discussion, nil
+}
+
+// discussionAccess returns the course if the user is its instructor or enrolled into it,
+// and ErrForbidden otherwise.
+func discussionAccess(db *gorm.DB, userID, courseID uint) (models.Course, error) {
+	var course models.Course
+	if err := db.First(&course, "id = ?", courseID).Error; err != nil {
+		return models.Course{}, wrapNotFound(err, "course %d", courseID)
+	}
+
+	if course.InstructorID == userID {
+		return course, nil
+	}
+
+	var enrolled int64
+	if err := db.Model(&models.Enrollment{}).Where("user_id = ? AND course_id = ?", userID, courseID).Count(&enrolled).Error; err != nil {
+		return models.Course{}, err
+	}
+	if enrolled == 0 {
+		return models.Course{}, services.Errorf(services.ErrForbidden, "only the enrolled learners and the instructor can take part in the discussions of the course")
+	}
+
+	return course, nil
+}
+
+// toggleVote creates the vote if up is set and deletes it otherwise.
+func toggleVote(tx *gorm.DB, vote interface{}, up bool) error {
+	if up {
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(vote).Error
+	}
+	return tx.Delete(vote).Error
+}
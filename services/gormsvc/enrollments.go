@@ -0,0 +1,119 @@
+package gormsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+	"time"
+)
+
+// EnrollmentService is the GORM implementation of services.EnrollmentService.
+type EnrollmentService struct {
+	db    *gorm.DB
+	grace time.Duration
+}
+
+// NewEnrollmentService creates a new EnrollmentService. The subscriptions keep the access
+// for the grace period while a renewal is due.
+func NewEnrollmentService(db *gorm.DB, grace time.Duration) *EnrollmentService {
+	return &EnrollmentService{db: db, grace: grace}
+}
+
+// List returns a page of the enrollments matching the filter.
+func (s *EnrollmentService) List(ctx context.Context, filter services.EnrollmentFilter, page services.Page) ([]models.Enrollment, services.PageInfo, error) {
+	query := s.db.WithContext(ctx)
+
+	if len(filter.UserIDs) > 0 {
+		query = query.Where("user_id IN ?", filter.UserIDs)
+	}
+
+	if len(filter.StatusIDs) > 0 {
+		query = query.Where("status_id IN ?", filter.StatusIDs)
+	}
+
+	if filter.CourseID != 0 {
+		query = query.Where("course_id = ?", filter.CourseID)
+	}
+
+	return paginate[models.Enrollment](query, page, services.EnrollmentSortFields)
+}
+
+// Enroll enrolls the user into the free course, or into the paid published course through
+// the subscription of the user, unless the enforced prerequisites are incomplete.
+func (s *EnrollmentService) Enroll(ctx context.Context, userID, courseID uint) (models.Enrollment, error) {
+	var course models.Course
+	if err := s.db.WithContext(ctx).First(&course, "id = ?", courseID).Error; err != nil {
+		return models.Enrollment{}, wrapNotFound(err, "course %d", courseID)
+	}
+
+	check, err := checkPrerequisites(s.db.WithContext(ctx), userID, course)
+	if err != nil {
+		return models.Enrollment{}, err
+	}
+	if err := services.RequirePrerequisites(check); err != nil {
+		return models.Enrollment{}, err
+	}
+
+	enrollment := services.NewEnrollment(userID, courseID)
+
+	if course.Price > 0 {
+		var subscription *models.Subscription
+		if course.StatusID == services.CourseStatusPublished {
+			var err error
+			if subscription, err = entitledSubscription(s.db.WithContext(ctx), userID, s.grace); err != nil {
+				return models.Enrollment{}, err
+			}
+		}
+
+		if subscription == nil {
+			return models.Enrollment{}, services.Errorf(services.ErrForbidden, "course %d is paid, buy it with the checkout or subscribe", courseID)
+		}
+		enrollment.SubscriptionID = &subscription.ID
+	}
+
+	if err := s.db.WithContext(ctx).Create(&enrollment).Error; err != nil {
+		return models.Enrollment{}, services.Errorf(services.ErrInvalidInput, "error creating enrollment")
+	}
+
+	return enrollment, nil
+}
+
+// Access reports whether the user may see the content of the course.
+func (s *EnrollmentService) Access(ctx context.Context, userID, courseID uint) (bool, error) {
+	var course models.Course
+	if err := s.db.WithContext(ctx).First(&course, "id = ?", courseID).Error; err != nil {
+		return false, wrapNotFound(err, "course %d", courseID)
+	}
+
+	if course.Price == 0 || (userID != 0 && course.InstructorID == userID) {
+		return true, nil
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).Limit(1).Find(&user, "id = ?", userID).Error; err != nil || user.ID == 0 {
+		return false, err
+	}
+	if user.UserTypeID == services.UserTypeAdmin {
+		return true, nil
+	}
+
+	var enrollment models.Enrollment
+	if err := s.db.WithContext(ctx).Limit(1).Find(&enrollment, "user_id = ? AND course_id = ?", userID, courseID).Error; err != nil {
+		return false, err
+	}
+	if enrollment.UserID == 0 {
+		return false, nil
+	}
+
+	entitled := false
+	if enrollment.SubscriptionID != nil {
+		subscription, err := entitledSubscription(s.db.WithContext(ctx), userID, s.grace)
+		if err != nil {
+			return false, err
+		}
+		entitled = subscription != nil
+	}
+
+	return services.HasAccess(&enrollment, entitled), nil
+}
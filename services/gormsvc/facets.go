@@ -0,0 +1,193 @@
+package gormsvc
+
+import (
+	"context"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// filterCourses adds the conditions of the filter to the courses query.
+func filterCourses(query *gorm.DB, filter services.CourseFilter) (*gorm.DB, error) {
+	if len(filter.IDs) > 0 {
+		query = query.Where("courses.id IN ?", filter.IDs)
+	}
+
+	if filter.StatusID != 0 {
+		query = query.Where("courses.status_id = ?", filter.StatusID)
+	}
+
+	if filter.InstructorID != 0 {
+		query = query.Where("courses.instructor_id = ?", filter.InstructorID)
+	}
+
+	if filter.UserID != 0 {
+		query = query.Where("courses.id IN (SELECT course_id FROM enrollments WHERE user_id = ?)", filter.UserID)
+	}
+
+	if filter.HasCertificate != nil {
+		query = query.Where("courses.has_certificate = ?", *filter.HasCertificate)
+	}
+
+	if len(filter.CategoryIDs) > 0 {
+		query = query.Where("courses.id IN (SELECT course_id FROM course_categories_junction WHERE course_category_id IN ?)", filter.CategoryIDs)
+	}
+
+	if len(filter.LevelIDs) > 0 {
+		query = query.Where("courses.level_id IN ?", filter.LevelIDs)
+	}
+
+	query, err := filterRange(query, "COALESCE(courses.price, 0)", services.PriceBuckets, filter.PriceBuckets, filter.PriceMin, filter.PriceMax)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.PriceCurrency != "" {
+		query = query.Where("courses.currency = ?", filter.PriceCurrency)
+	}
+
+	if filter.Free != nil {
+		if *filter.Free {
+			query = query.Where("COALESCE(courses.price, 0) = 0")
+		} else {
+			query = query.Where("courses.price > 0")
+		}
+	}
+
+	return filterRange(query, "COALESCE(courses.length, 0)", services.LengthBuckets, filter.LengthBuckets, filter.LengthMin, filter.LengthMax)
+}
+
+// filterRange adds the conditions on a ranged column: any of the selected buckets and the bounds.
+func filterRange(query *gorm.DB, column string, buckets []services.Bucket, keys []string, min, max *uint) (*gorm.DB, error) {
+	selected, err := services.FindBuckets(buckets, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(selected) > 0 {
+		var conditions []string
+		var args []any
+		for _, b := range selected {
+			cond, bArgs := bucketCondition(column, b)
+			conditions = append(conditions, cond)
+			args = append(args, bArgs...)
+		}
+		query = query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+
+	if min != nil {
+		query = query.Where(column+" >= ?", *min)
+	}
+
+	if max != nil {
+		query = query.Where(column+" <= ?", *max)
+	}
+
+	return query, nil
+}
+
+// bucketCondition returns the condition matching the column values falling into the bucket.
+func bucketCondition(column string, b services.Bucket) (string, []any) {
+	if b.Max == nil {
+		return column + " >= ?", []any{b.Min}
+	}
+	return column + " BETWEEN ? AND ?", []any{b.Min, *b.Max}
+}
+
+// Facets returns the number of courses matching the filter per value of each catalogue
+// facet. The filter on the facet itself is ignored when counting its values.
+func (s *CourseService) Facets(ctx context.Context, filter services.CourseFilter) (services.CourseFacets, error) {
+	var facets services.CourseFacets
+
+	db := s.db.WithContext(ctx)
+
+	// courses returns the query of the courses matching the filter without the facet.
+	courses := func(facet string) (*gorm.DB, error) {
+		return filterCourses(db.Model(&models.Course{}), filter.Without(facet))
+	}
+
+	query, err := courses(services.FacetCategory)
+	if err != nil {
+		return facets, err
+	}
+	facets.Categories, err = facetValues(db, "course_categories",
+		db.Table("course_categories_junction").
+			Select("course_category_id AS id, COUNT(*) AS count").
+			Where("course_id IN (?)", query.Select("courses.id")).
+			Group("course_category_id"))
+	if err != nil {
+		return facets, err
+	}
+
+	query, err = courses(services.FacetLevel)
+	if err != nil {
+		return facets, err
+	}
+	facets.Levels, err = facetValues(db, "course_levels",
+		query.Select("courses.level_id AS id, COUNT(*) AS count").Group("courses.level_id"))
+	if err != nil {
+		return facets, err
+	}
+
+	query, err = courses(services.FacetPrice)
+	if err != nil {
+		return facets, err
+	}
+	if facets.Prices, err = bucketValues(query, "COALESCE(courses.price, 0)", services.PriceBuckets); err != nil {
+		return facets, err
+	}
+
+	query, err = courses(services.FacetLength)
+	if err != nil {
+		return facets, err
+	}
+	if facets.Lengths, err = bucketValues(query, "COALESCE(courses.length, 0)", services.LengthBuckets); err != nil {
+		return facets, err
+	}
+
+	return facets, nil
+}
+
+// facetValues returns the rows of the reference table ordered by ID with the counts
+// selected by the counts query (id, count).
+func facetValues(db *gorm.DB, table string, counts *gorm.DB) ([]services.FacetValue, error) {
+	var values []services.FacetValue
+
+	err := db.Table(table).
+		Select(table+".id, "+table+".title, COALESCE(counts.count, 0) AS count").
+		Joins("LEFT JOIN (?) counts ON counts.id = "+table+".id", counts).
+		Order(table + ".id").
+		Scan(&values).Error
+
+	return values, err
+}
+
+// bucketValues counts the courses selected by the query per bucket of the column values.
+func bucketValues(query *gorm.DB, column string, buckets []services.Bucket) ([]services.BucketValue, error) {
+	var selects []string
+	var args []any
+	for i, b := range buckets {
+		cond, bArgs := bucketCondition(column, b)
+		selects = append(selects, fmt.Sprintf("COALESCE(SUM(CASE WHEN %s THEN 1 ELSE 0 END), 0) AS b%d", cond, i))
+		args = append(args, bArgs...)
+	}
+
+	counts := make([]int64, len(buckets))
+	dest := make([]any, len(buckets))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+
+	if err := query.Select(strings.Join(selects, ", "), args...).Row().Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	values := make([]services.BucketValue, len(buckets))
+	for i, b := range buckets {
+		values[i] = services.BucketValue{Bucket: b, Count: counts[i]}
+	}
+
+	return values, nil
+}
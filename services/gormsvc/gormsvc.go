@@ -0,0 +1,81 @@
+// Package gormsvc implements the services on top of GORM.
+package gormsvc
+
+import (
+	"errors"
+	"github.com/plaja-app/back-end/certificates"
+	"github.com/plaja-app/back-end/events"
+	"github.com/plaja-app/back-end/invoices"
+	"github.com/plaja-app/back-end/mailer"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"github.com/plaja-app/back-end/storage"
+	"gorm.io/gorm"
+)
+
+// Options holds the dependencies of the services besides the database.
+type Options struct {
+	// Storage stores the uploaded files.
+	Storage storage.Storage
+	// Certificates renders the certificate images.
+	Certificates *certificates.Generator
+	// Events receives the changes of the courses and users.
+	Events *events.Bus
+	// Mailer emails the notifications.
+	Mailer   mailer.Mailer
+	Payments PaymentOptions
+	// Invoices renders the invoice documents.
+	Invoices *invoices.Renderer
+	// InvitationURL is the page the users invited by the imports set their password on.
+	InvitationURL string
+	// JoinURL is the page the existing users invited by the imports join the organization on.
+	JoinURL string
+}
+
+// New creates the GORM implementations of the services. The autocomplete and recommendation
+// services are not database-backed and are left to the caller.
+func New(db *gorm.DB, options Options) *services.Services {
+	return &services.Services{
+		Users:         NewUserService(db, options.Storage, options.Events),
+		Courses:       NewCourseService(db, options.Storage, options.Events, options.Payments.Currency),
+		Enrollments:   NewEnrollmentService(db, options.Payments.GracePeriod),
+		Certificates:  NewCertificateService(db, options.Certificates),
+		Reviews:       NewReviewService(db),
+		Discussions:   NewDiscussionService(db),
+		Wishlist:      NewWishlistService(db),
+		Notifications: NewNotificationService(db, options.Mailer),
+		Orders:        NewOrderService(db, options.Payments),
+		Coupons:       NewCouponService(db, options.Payments.Currency),
+		Refunds:       NewRefundService(db, options.Payments),
+		Audit:         NewAuditService(db),
+		Ledger:        NewLedgerService(db),
+		Subscriptions: NewSubscriptionService(db, options.Payments),
+		Invoices:      NewInvoiceService(db, options.Storage, options.Invoices),
+		Currencies:    NewCurrencyService(db),
+		Organizations: NewOrganizationService(db, options.Payments),
+		UserImports:   NewUserImportService(db, options.Mailer, options.Events, options.InvitationURL, options.JoinURL),
+		Paths:         NewLearningPathService(db, options.Certificates, options.Payments),
+	}
+}
+
+// wrapNotFound converts gorm.ErrRecordNotFound into services.ErrNotFound with the given message.
+func wrapNotFound(err error, format string, args ...any) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return services.Errorf(services.ErrNotFound, format, args...)
+	}
+	return err
+}
+
+// requireAdmin returns services.ErrForbidden unless the user is an admin.
+func requireAdmin(db *gorm.DB, userID uint) error {
+	var user models.User
+	if err := db.First(&user, "id = ?", userID).Error; err != nil {
+		return wrapNotFound(err, "user %d", userID)
+	}
+
+	if user.UserTypeID != services.UserTypeAdmin {
+		return services.Errorf(services.ErrForbidden, "only admins can perform this operation")
+	}
+
+	return nil
+}
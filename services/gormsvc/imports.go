@@ -0,0 +1,334 @@
+package gormsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/events"
+	"github.com/plaja-app/back-end/mailer"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"time"
+)
+
+// UserImportService is the GORM implementation of services.UserImportService.
+type UserImportService struct {
+	db     *gorm.DB
+	mailer mailer.Mailer
+	events *events.Bus
+	// invitationURL is the page the invited users set their password on.
+	invitationURL string
+	// joinURL is the page the invited existing users join the organization on.
+	joinURL string
+}
+
+// NewUserImportService creates a new UserImportService emailing the invitations to set the
+// password or to join the organization on the pages with the mailer and publishing the new
+// users on the bus.
+func NewUserImportService(db *gorm.DB, m mailer.Mailer, bus *events.Bus, invitationURL, joinURL string) *UserImportService {
+	return &UserImportService{db: db, mailer: m, events: bus, invitationURL: invitationURL, joinURL: joinURL}
+}
+
+// Start validates the import and processes its rows in the background.
+func (s *UserImportService) Start(ctx context.Context, userID uint, input services.UserImportInput) (models.UserImport, error) {
+	if err := services.ValidateUserImport(&input); err != nil {
+		return models.UserImport{}, err
+	}
+
+	db := s.db.WithContext(ctx)
+
+	var organization models.Organization
+	if input.OrganizationID != 0 {
+		role, err := memberRole(db, input.OrganizationID, userID)
+		if err != nil {
+			return models.UserImport{}, err
+		}
+		if !services.CanManage(role) {
+			return models.UserImport{}, services.Errorf(services.ErrForbidden, "only the managers can import the members")
+		}
+		if err := db.First(&organization, "id = ?", input.OrganizationID).Error; err != nil {
+			return models.UserImport{}, err
+		}
+	} else if err := requireAdmin(db, userID); err != nil {
+		return models.UserImport{}, err
+	}
+
+	var courses []models.Course
+	if len(input.CourseIDs) > 0 {
+		err := db.Where("id IN ? AND status_id = ?", input.CourseIDs, services.CourseStatusPublished).Order("id").Find(&courses).Error
+		if err != nil {
+			return models.UserImport{}, err
+		}
+		if len(courses) != len(input.CourseIDs) {
+			return models.UserImport{}, services.Errorf(services.ErrInvalidInput, "users can only be enrolled into the published courses")
+		}
+	}
+
+	userImport := models.UserImport{UserID: userID, Status: services.ImportStatusPending, Total: uint(len(input.Rows))}
+	if organization.ID != 0 {
+		userImport.OrganizationID = &organization.ID
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&userImport).Error; err != nil {
+			return err
+		}
+
+		// the courses are kept for the invited users joining the organization later
+		for _, course := range courses {
+			if err := tx.Create(&models.UserImportCourse{ImportID: userImport.ID, CourseID: course.ID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return models.UserImport{}, err
+	}
+
+	// the import outlives the request, so it must not be cancelled with it
+	go s.run(context.WithoutCancel(ctx), userImport, organization.Name, courses, input.Rows)
+
+	return userImport, nil
+}
+
+// Get returns the progress of the import with the results of the processed rows.
+func (s *UserImportService) Get(ctx context.Context, userID, importID uint) (models.UserImport, error) {
+	var userImport models.UserImport
+	err := s.db.WithContext(ctx).
+		Preload("Rows", func(db *gorm.DB) *gorm.DB { return db.Order("line, id") }).
+		First(&userImport, "id = ?", importID).Error
+	if err != nil {
+		return models.UserImport{}, wrapNotFound(err, "user import %d", importID)
+	}
+
+	if userImport.UserID != userID {
+		if err := requireAdmin(s.db.WithContext(ctx), userID); err != nil {
+			return models.UserImport{}, services.Errorf(services.ErrNotFound, "user import %d", importID)
+		}
+	}
+
+	return userImport, nil
+}
+
+// run imports the rows one by one, recording the result of every row with the progress,
+// and emails the invitations. An import stopped by a database error is marked as failed.
+func (s *UserImportService) run(ctx context.Context, userImport models.UserImport, organization string, courses []models.Course, rows []services.ImportRow) {
+	if err := s.process(ctx, userImport, organization, courses, rows); err != nil {
+		log.Printf("error processing user import %d: %v", userImport.ID, err)
+
+		err := s.db.WithContext(ctx).Model(&userImport).Updates(map[string]any{
+			"status":      services.ImportStatusFailed,
+			"error":       services.ImportRowError(err),
+			"finished_at": time.Now(),
+		}).Error
+		if err != nil {
+			log.Printf("error failing user import %d: %v", userImport.ID, err)
+		}
+	}
+}
+
+// process imports the rows of the import.
+func (s *UserImportService) process(ctx context.Context, userImport models.UserImport, organization string, courses []models.Course, rows []services.ImportRow) error {
+	db := s.db.WithContext(ctx)
+	if err := db.Model(&userImport).Update("status", services.ImportStatusRunning).Error; err != nil {
+		return err
+	}
+
+	lines := make(map[string]uint)
+	for _, row := range rows {
+		err := services.ValidateImportRow(&row)
+		if line, ok := lines[row.Email]; ok && err == nil {
+			err = services.Errorf(services.ErrInvalidInput, "duplicate email of line %d", line)
+		}
+		if err == nil {
+			lines[row.Email] = row.Line
+		}
+
+		result := models.UserImportRow{ImportID: userImport.ID, Line: row.Line, Email: row.Email}
+		var (
+			user  models.User
+			token string
+		)
+		if err == nil {
+			user, token, err = s.importRow(db, userImport, row, courses, &result)
+		}
+		if err != nil {
+			if !services.IsDomainError(err) {
+				log.Printf("error importing line %d of user import %d: %v", row.Line, userImport.ID, err)
+			}
+			result.UserID = nil
+			result.Result = services.ImportRowFailed
+			result.Error = services.ImportRowError(err)
+		}
+
+		if result.Result == services.ImportRowCreated {
+			s.events.Publish(ctx, events.Event{Topic: events.UserCreated, ID: user.ID})
+		}
+
+		if token != "" {
+			subject, body := services.InvitationEmail(user, organization, services.InvitationLink(s.invitationURL, token))
+			if result.Result == services.ImportRowPending {
+				subject, body = services.JoinEmail(user, organization, services.InvitationLink(s.joinURL, token))
+			}
+			if err := s.mailer.Send(ctx, mailer.Message{To: user.Email, Subject: subject, Body: body}); err != nil {
+				log.Printf("error emailing the invitation of user %d: %v", user.ID, err)
+			} else {
+				result.Invited = true
+			}
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&result).Error; err != nil {
+				return err
+			}
+
+			return tx.Model(&userImport).Updates(map[string]any{
+				"processed":   gorm.Expr("processed + 1"),
+				result.Result: gorm.Expr(result.Result + " + 1"),
+			}).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return db.Model(&userImport).Updates(map[string]any{
+		"status":      services.ImportStatusCompleted,
+		"finished_at": time.Now(),
+	}).Error
+}
+
+// importRow creates the user of the row or matches the existing one by the email, adds
+// them to the organization as a learner and enrolls them into the courses. It returns
+// the invitation token if the user has not set a password yet. The existing users who are
+// not members of the organization are not added, but invited to join it with the returned
+// token.
+func (s *UserImportService) importRow(db *gorm.DB, userImport models.UserImport, row services.ImportRow, courses []models.Course,
+	result *models.UserImportRow) (models.User, string, error) {
+	var (
+		user  models.User
+		token string
+	)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Limit(1).Find(&user, "email = ?", row.Email).Error; err != nil {
+			return err
+		}
+
+		result.Result = services.ImportRowMatched
+		if user.ID == 0 {
+			user = models.User{
+				FirstName:  row.FirstName,
+				LastName:   row.LastName,
+				Email:      row.Email,
+				UserTypeID: services.UserTypeLearner,
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+			result.Result = services.ImportRowCreated
+		}
+		result.UserID = &user.ID
+
+		invitation := models.Invitation{UserID: user.ID, ExpiresAt: time.Now().Add(services.InvitationValidity)}
+
+		if userImport.OrganizationID != nil {
+			if result.Result == services.ImportRowMatched {
+				var members int64
+				err := tx.Model(&models.OrganizationMember{}).
+					Where("organization_id = ? AND user_id = ?", *userImport.OrganizationID, user.ID).
+					Count(&members).Error
+				if err != nil {
+					return err
+				}
+
+				// the existing users join the organization only if they accept
+				if members == 0 {
+					result.Result = services.ImportRowPending
+					invitation.OrganizationID = userImport.OrganizationID
+					invitation.ImportID = &userImport.ID
+				}
+			}
+
+			if result.Result != services.ImportRowPending {
+				member := models.OrganizationMember{OrganizationID: *userImport.OrganizationID, UserID: user.ID, Role: services.RoleLearner}
+				if err := tx.Omit("User").Clauses(clause.OnConflict{DoNothing: true}).Create(&member).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if result.Result != services.ImportRowPending {
+			for _, course := range courses {
+				if err := enrollImported(tx, userImport, user.ID, course); err != nil {
+					return err
+				}
+			}
+
+			// the users who have not set a password yet are invited again
+			if user.Password != "" {
+				return nil
+			}
+		}
+
+		var err error
+		if token, invitation.TokenHash, err = services.NewInvitationToken(); err != nil {
+			return err
+		}
+
+		return tx.Create(&invitation).Error
+	})
+	if err != nil {
+		return models.User{}, "", err
+	}
+
+	return user, token, nil
+}
+
+// enrollImported assigns the course to the user imported into the organization, taking
+// a seat of a paid course, or enrolls the user imported into the platform directly.
+func enrollImported(tx *gorm.DB, userImport models.UserImport, userID uint, course models.Course) error {
+	if userImport.OrganizationID == nil {
+		enrollment := services.NewEnrollment(userID, course.ID)
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&enrollment).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Enrollment{}).
+			Where("user_id = ? AND course_id = ?", userID, course.ID).
+			Update("subscription_id", nil).Error
+	}
+
+	organizationID := *userImport.OrganizationID
+
+	var assigned int64
+	err := tx.Model(&models.CourseAssignment{}).
+		Where("organization_id = ? AND user_id = ? AND course_id = ?", organizationID, userID, course.ID).
+		Count(&assigned).Error
+	if err != nil || assigned > 0 {
+		return err
+	}
+
+	if course.Price > 0 {
+		license, err := seatLicense(tx, organizationID, course.ID)
+		if err != nil {
+			return err
+		}
+		if err := services.CheckSeats(license, course.ID, 1); err != nil {
+			return err
+		}
+	}
+
+	assignment := models.CourseAssignment{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		CourseID:       course.ID,
+		AssignedByID:   userImport.UserID,
+	}
+	if err := tx.Create(&assignment).Error; err != nil {
+		return err
+	}
+
+	return enrollThroughSeat(tx, organizationID, userID, course.ID)
+}
@@ -0,0 +1,213 @@
+package gormsvc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/plaja-app/back-end/invoices"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"github.com/plaja-app/back-end/storage"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"io"
+	"time"
+)
+
+// InvoiceService is the GORM implementation of services.InvoiceService.
+type InvoiceService struct {
+	db       *gorm.DB
+	store    storage.Storage
+	renderer *invoices.Renderer
+}
+
+// NewInvoiceService creates a new InvoiceService keeping the documents in the private
+// directory of the storage.
+func NewInvoiceService(db *gorm.DB, store storage.Storage, renderer *invoices.Renderer) *InvoiceService {
+	return &InvoiceService{db: db, store: store, renderer: renderer}
+}
+
+// List returns a page of the invoices of the user matching the filter, or of any user to the admins.
+func (s *InvoiceService) List(ctx context.Context, userID uint, filter services.InvoiceFilter, page services.Page) ([]models.Invoice, services.PageInfo, error) {
+	if filter.UserID == 0 {
+		filter.UserID = userID
+	}
+
+	if filter.UserID != userID {
+		if err := requireAdmin(s.db.WithContext(ctx), userID); err != nil {
+			return nil, services.PageInfo{}, err
+		}
+	}
+
+	query := s.db.WithContext(ctx).Where("user_id = ?", filter.UserID)
+
+	if filter.OrderID != 0 {
+		query = query.Where("order_id = ?", filter.OrderID)
+	}
+
+	if len(filter.Kinds) > 0 {
+		query = query.Where("kind IN ?", filter.Kinds)
+	}
+
+	return paginate[models.Invoice](query, page, services.InvoiceSortFields)
+}
+
+// Document returns the invoice with its PDF document, rendering it on the first download.
+func (s *InvoiceService) Document(ctx context.Context, userID, invoiceID uint) (models.Invoice, io.ReadCloser, error) {
+	var invoice models.Invoice
+	if err := s.db.WithContext(ctx).Preload("CreditedInvoice").First(&invoice, "id = ?", invoiceID).Error; err != nil {
+		return models.Invoice{}, nil, wrapNotFound(err, "invoice %d", invoiceID)
+	}
+
+	if invoice.UserID != userID {
+		if err := requireAdmin(s.db.WithContext(ctx), userID); err != nil {
+			return models.Invoice{}, nil, err
+		}
+	}
+
+	if invoice.StoragePath == "" {
+		if s.renderer == nil || s.store == nil {
+			return models.Invoice{}, nil, services.Errorf(services.ErrNotFound, "document of invoice %d", invoiceID)
+		}
+
+		var buf bytes.Buffer
+		if err := s.renderer.WritePDF(&buf, invoice); err != nil {
+			return models.Invoice{}, nil, fmt.Errorf("error rendering invoice %s: %v", invoice.Number, err)
+		}
+
+		path := invoices.Path(invoice)
+		if err := s.store.Save(path, &buf); err != nil {
+			return models.Invoice{}, nil, fmt.Errorf("error storing invoice %s: %v", invoice.Number, err)
+		}
+
+		invoice.StoragePath = path
+		if err := s.db.WithContext(ctx).Model(&invoice).Update("storage_path", path).Error; err != nil {
+			return models.Invoice{}, nil, err
+		}
+	}
+
+	document, err := s.store.Open(invoice.StoragePath)
+	if err != nil {
+		return models.Invoice{}, nil, fmt.Errorf("error opening invoice %s: %v", invoice.Number, err)
+	}
+
+	return invoice, document, nil
+}
+
+// BillingProfile returns the billing details of the user, empty if never set.
+func (s *InvoiceService) BillingProfile(ctx context.Context, userID uint) (models.BillingProfile, error) {
+	profile := models.BillingProfile{UserID: userID}
+	err := s.db.WithContext(ctx).Limit(1).Find(&profile, "user_id = ?", userID).Error
+	return profile, err
+}
+
+// UpdateBillingProfile sets the billing details of the user used by the next invoices.
+func (s *InvoiceService) UpdateBillingProfile(ctx context.Context, userID uint, input services.BillingInput) (models.BillingProfile, error) {
+	if err := services.ValidateBilling(&input); err != nil {
+		return models.BillingProfile{}, err
+	}
+
+	profile := models.BillingProfile{
+		UserID:  userID,
+		Company: input.Company,
+		TaxID:   input.TaxID,
+		Address: input.Address,
+		Country: input.Country,
+	}
+
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"company", "tax_id", "address", "country", "updated_at"}),
+	}).Create(&profile).Error
+	if err != nil {
+		return models.BillingProfile{}, err
+	}
+
+	return profile, nil
+}
+
+// billingDetails returns the user with the billing details, empty if never set.
+func billingDetails(tx *gorm.DB, userID uint) (models.User, models.BillingProfile, error) {
+	var user models.User
+	if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+		return models.User{}, models.BillingProfile{}, err
+	}
+
+	var profile models.BillingProfile
+	err := tx.Limit(1).Find(&profile, "user_id = ?", userID).Error
+	return user, profile, err
+}
+
+// invoiceOrder issues the invoice of the paid order.
+func invoiceOrder(tx *gorm.DB, seller services.Seller, order models.Order) error {
+	if order.Amount == 0 {
+		return nil
+	}
+
+	user, profile, err := billingDetails(tx, order.UserID)
+	if err != nil {
+		return err
+	}
+
+	var title string
+	if order.PathID != nil {
+		err = tx.Model(&models.LearningPath{}).Select("title").Where("id = ?", *order.PathID).Scan(&title).Error
+	} else {
+		err = tx.Model(&models.Course{}).Select("title").Where("id = ?", order.CourseID).Scan(&title).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	invoice := services.NewInvoice(seller, user, profile, services.OrderDescription(title, order), order.Currency, order.Amount, time.Now())
+	invoice.OrderID = &order.ID
+	return issueInvoice(tx, &invoice)
+}
+
+// invoiceSubscriptionPayment issues the invoice of the payment of a subscription period.
+func invoiceSubscriptionPayment(tx *gorm.DB, seller services.Seller, subscription models.Subscription, payment models.SubscriptionPayment) error {
+	user, profile, err := billingDetails(tx, subscription.UserID)
+	if err != nil {
+		return err
+	}
+
+	description := fmt.Sprintf("Підписка «%s», %s – %s", subscription.Plan.Name,
+		payment.PeriodStart.UTC().Format("02.01.2006"), payment.PeriodEnd.UTC().Format("02.01.2006"))
+
+	invoice := services.NewInvoice(seller, user, profile, description, payment.Currency, payment.Amount, time.Now())
+	invoice.SubscriptionPaymentID = &payment.ID
+	return issueInvoice(tx, &invoice)
+}
+
+// creditOrder issues the credit note of the invoice of the refunded order, if it was invoiced.
+func creditOrder(tx *gorm.DB, order models.Order) error {
+	var invoice models.Invoice
+	err := tx.Limit(1).Find(&invoice, "order_id = ? AND kind = ?", order.ID, services.InvoiceKindInvoice).Error
+	if err != nil || invoice.ID == 0 {
+		return err
+	}
+
+	note := services.NewCreditNote(invoice, time.Now())
+	return issueInvoice(tx, &note)
+}
+
+// issueInvoice numbers the invoice with the next number of its series and creates it.
+func issueInvoice(tx *gorm.DB, invoice *models.Invoice) error {
+	counter := models.InvoiceCounter{Series: services.InvoiceSeries(invoice.Kind, invoice.IssuedAt)}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&counter).Error; err != nil {
+		return err
+	}
+
+	// the update locks the counter until the transaction ends, so the numbers have no gaps
+	err := tx.Model(&counter).Where("series = ?", counter.Series).Update("last", gorm.Expr("last + 1")).Error
+	if err != nil {
+		return err
+	}
+
+	if err := tx.First(&counter, "series = ?", counter.Series).Error; err != nil {
+		return err
+	}
+
+	invoice.Number = services.InvoiceNumber(counter.Series, counter.Last)
+	return tx.Create(invoice).Error
+}
@@ -0,0 +1,296 @@
+package gormsvc
+
+import (
+	"context"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+	"time"
+)
+
+// LedgerService is the GORM implementation of services.LedgerService.
+type LedgerService struct {
+	db *gorm.DB
+}
+
+// NewLedgerService creates a new LedgerService.
+func NewLedgerService(db *gorm.DB) *LedgerService {
+	return &LedgerService{db: db}
+}
+
+// Transactions returns a page of the ledger transactions with their entries.
+func (s *LedgerService) Transactions(ctx context.Context, userID uint, filter services.LedgerFilter, page services.Page) ([]models.LedgerTransaction, services.PageInfo, error) {
+	instructorID := filter.InstructorID
+	if instructorID == 0 {
+		instructorID = userID
+	}
+
+	if err := requireLedgerAccess(s.db.WithContext(ctx), userID, instructorID); err != nil {
+		return nil, services.PageInfo{}, err
+	}
+
+	query := s.db.WithContext(ctx).Preload("Entries").Where("instructor_id = ?", instructorID)
+
+	if len(filter.Kinds) > 0 {
+		query = query.Where("kind IN ?", filter.Kinds)
+	}
+
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	return paginate[models.LedgerTransaction](query, page, services.LedgerSortFields)
+}
+
+// Earnings returns the balance of the instructor and the breakdown of the earnings.
+func (s *LedgerService) Earnings(ctx context.Context, userID uint, filter services.EarningsFilter) (services.Earnings, error) {
+	if filter.InstructorID == 0 {
+		filter.InstructorID = userID
+	}
+
+	if err := services.ValidateEarningsFilter(filter); err != nil {
+		return services.Earnings{}, err
+	}
+
+	db := s.db.WithContext(ctx)
+	if err := requireLedgerAccess(db, userID, filter.InstructorID); err != nil {
+		return services.Earnings{}, err
+	}
+
+	balances, err := instructorBalances(db, filter.InstructorID, nil)
+	if err != nil {
+		return services.Earnings{}, err
+	}
+
+	transactions, err := ledgerTransactions(db, filter.InstructorID, filter.From, filter.To)
+	if err != nil {
+		return services.Earnings{}, err
+	}
+
+	return services.Earnings{
+		InstructorID: filter.InstructorID,
+		Balances:     balances,
+		Periods:      services.SummarizeEarnings(transactions, filter.Period),
+	}, nil
+}
+
+// Statement returns the payout statement of the instructor for the month.
+func (s *LedgerService) Statement(ctx context.Context, userID, instructorID uint, month time.Time) (services.Statement, error) {
+	db := s.db.WithContext(ctx)
+	if err := requireLedgerAccess(db, userID, instructorID); err != nil {
+		return services.Statement{}, err
+	}
+
+	var instructor models.User
+	if err := db.First(&instructor, "id = ?", instructorID).Error; err != nil {
+		return services.Statement{}, wrapNotFound(err, "user %d", instructorID)
+	}
+
+	start := services.PeriodStart(month, services.PeriodMonth)
+	end := start.AddDate(0, 1, 0)
+
+	opening, err := instructorBalances(db, instructorID, &start)
+	if err != nil {
+		return services.Statement{}, err
+	}
+
+	transactions, err := ledgerTransactions(db, instructorID, start, end)
+	if err != nil {
+		return services.Statement{}, err
+	}
+
+	return services.BuildStatement(instructor, start, opening, transactions), nil
+}
+
+// Payout records the payout of the earnings to the instructor.
+func (s *LedgerService) Payout(ctx context.Context, adminID uint, input services.PayoutInput) (models.LedgerTransaction, error) {
+	if err := services.ValidatePayout(input); err != nil {
+		return models.LedgerTransaction{}, err
+	}
+
+	if err := requireAdmin(s.db.WithContext(ctx), adminID); err != nil {
+		return models.LedgerTransaction{}, err
+	}
+
+	var transaction models.LedgerTransaction
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var instructor models.User
+		if err := tx.First(&instructor, "id = ?", input.InstructorID).Error; err != nil {
+			return wrapNotFound(err, "user %d", input.InstructorID)
+		}
+
+		balances, err := instructorBalances(tx, input.InstructorID, nil)
+		if err != nil {
+			return err
+		}
+
+		amount, err := services.PayoutAmount(balances, input)
+		if err != nil {
+			return err
+		}
+
+		transaction = models.LedgerTransaction{
+			Kind:         services.LedgerPayout,
+			InstructorID: input.InstructorID,
+			Currency:     input.Currency,
+			Description:  input.Reference,
+			CreatedByID:  &adminID,
+			Entries:      services.PayoutEntries(amount),
+		}
+		if err := tx.Create(&transaction).Error; err != nil {
+			return err
+		}
+
+		return audit(tx, &adminID, services.AuditPayoutCreated, "ledger_transactions", transaction.ID,
+			map[string]any{"instructor_id": input.InstructorID, "amount": amount, "currency": input.Currency})
+	})
+	if err != nil {
+		return models.LedgerTransaction{}, err
+	}
+
+	return transaction, nil
+}
+
+// requireLedgerAccess returns services.ErrForbidden unless the user is the instructor or an admin.
+func requireLedgerAccess(db *gorm.DB, userID, instructorID uint) error {
+	if userID == instructorID {
+		return nil
+	}
+	return requireAdmin(db, userID)
+}
+
+// instructorBalances returns the balances of the instructor, before the given time if any.
+func instructorBalances(db *gorm.DB, instructorID uint, before *time.Time) ([]services.Balance, error) {
+	query := db.Table("ledger_entries").
+		Select("ledger_transactions.currency AS currency, SUM(ledger_entries.credit) - SUM(ledger_entries.debit) AS amount").
+		Joins("JOIN ledger_transactions ON ledger_transactions.id = ledger_entries.transaction_id").
+		Where("ledger_transactions.instructor_id = ? AND ledger_entries.account = ?", instructorID, services.AccountInstructorPayable)
+
+	if before != nil {
+		query = query.Where("ledger_transactions.created_at < ?", *before)
+	}
+
+	var balances []services.Balance
+	err := query.Group("ledger_transactions.currency").Order("ledger_transactions.currency").Scan(&balances).Error
+	return balances, err
+}
+
+// ledgerTransactions returns the transactions of the instructor between from (inclusive)
+// and to (exclusive) with their entries, in order.
+func ledgerTransactions(db *gorm.DB, instructorID uint, from, to time.Time) ([]models.LedgerTransaction, error) {
+	var transactions []models.LedgerTransaction
+	err := db.Preload("Entries").
+		Where("instructor_id = ? AND created_at >= ? AND created_at < ?", instructorID, from, to).
+		Order("created_at, id").
+		Find(&transactions).Error
+	return transactions, err
+}
+
+// postSale posts the sale of the paid order, split by the revenue share of the instructor.
+func postSale(tx *gorm.DB, order models.Order, share uint) error {
+	if order.Amount == 0 {
+		return nil
+	}
+
+	if order.PathID != nil {
+		return postBundleSale(tx, order, share)
+	}
+
+	var course models.Course
+	if err := tx.First(&course, "id = ?", order.CourseID).Error; err != nil {
+		return err
+	}
+
+	return postTransaction(tx, models.LedgerTransaction{
+		Kind:         services.LedgerSale,
+		InstructorID: course.InstructorID,
+		OrderID:      &order.ID,
+		Currency:     order.Currency,
+		Share:        share,
+		Description:  course.Title,
+		Entries:      services.SaleEntries(order.Amount, share),
+	})
+}
+
+// postBundleSale posts the sales of the paid courses of the learning path bought with the
+// bundle order, splitting the amount in proportion to their list prices.
+func postBundleSale(tx *gorm.DB, order models.Order, share uint) error {
+	var path models.LearningPath
+	if err := tx.Preload("Courses.Course.Prices").First(&path, "id = ?", *order.PathID).Error; err != nil {
+		return err
+	}
+
+	rates, err := exchangeRates(tx)
+	if err != nil {
+		return err
+	}
+
+	var courses []models.Course
+	var weights []uint
+	for _, c := range path.Courses {
+		if c.Course.Price == 0 {
+			continue
+		}
+		price, err := services.ListPrice(c.Course, order.Currency, services.NewRates(rates))
+		if err != nil {
+			price = c.Course.Price
+		}
+		courses = append(courses, c.Course)
+		weights = append(weights, price)
+	}
+
+	for i, amount := range services.SplitBundle(order.Amount, weights) {
+		if amount == 0 {
+			continue
+		}
+
+		err := postTransaction(tx, models.LedgerTransaction{
+			Kind:         services.LedgerSale,
+			InstructorID: courses[i].InstructorID,
+			OrderID:      &order.ID,
+			Currency:     order.Currency,
+			Share:        share,
+			Description:  fmt.Sprintf("%s (%s)", courses[i].Title, path.Title),
+			Entries:      services.SaleEntries(amount, share),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// postRefund posts the reversal of the sale of the refunded order, if it was posted.
+func postRefund(tx *gorm.DB, actorID *uint, order models.Order) error {
+	var sale models.LedgerTransaction
+	err := tx.Preload("Entries").Limit(1).
+		Find(&sale, "order_id = ? AND kind = ?", order.ID, services.LedgerSale).Error
+	if err != nil || sale.ID == 0 {
+		return err
+	}
+
+	return postTransaction(tx, models.LedgerTransaction{
+		Kind:         services.LedgerRefund,
+		InstructorID: sale.InstructorID,
+		OrderID:      &order.ID,
+		Currency:     sale.Currency,
+		Share:        sale.Share,
+		Description:  sale.Description,
+		CreatedByID:  actorID,
+		Entries:      services.ReverseEntries(sale.Entries),
+	})
+}
+
+// postTransaction creates the transaction with its entries, which must be balanced.
+func postTransaction(tx *gorm.DB, transaction models.LedgerTransaction) error {
+	if !services.Balanced(transaction.Entries) {
+		return fmt.Errorf("unbalanced %s transaction of instructor %d", transaction.Kind, transaction.InstructorID)
+	}
+	return tx.Create(&transaction).Error
+}
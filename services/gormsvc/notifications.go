@@ -0,0 +1,83 @@
+package gormsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/mailer"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+// NotificationService is the GORM implementation of services.NotificationService.
+type NotificationService struct {
+	db     *gorm.DB
+	mailer mailer.Mailer
+}
+
+// NewNotificationService creates a new NotificationService emailing the notifications with the mailer.
+func NewNotificationService(db *gorm.DB, m mailer.Mailer) *NotificationService {
+	return &NotificationService{db: db, mailer: m}
+}
+
+// List returns a page of the notifications of the user matching the filter.
+func (s *NotificationService) List(ctx context.Context, userID uint, filter services.NotificationFilter, page services.Page) ([]models.Notification, services.PageInfo, error) {
+	query := s.db.WithContext(ctx).Where("user_id = ?", userID)
+
+	if filter.Unread {
+		query = query.Where("read_at IS NULL")
+	}
+
+	return paginate[models.Notification](query, page, services.NotificationSortFields)
+}
+
+// MarkRead marks the notifications of the user with the given IDs, or all of them if ids is empty, as read.
+func (s *NotificationService) MarkRead(ctx context.Context, userID uint, ids []uint) error {
+	query := s.db.WithContext(ctx).Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+
+	return query.Update("read_at", time.Now()).Error
+}
+
+// NotifyPriceDrop notifies the users having the course in their wishlist that its price
+// dropped from oldPrice. Nothing is sent if the price went back up in the meantime.
+func (s *NotificationService) NotifyPriceDrop(ctx context.Context, courseID, oldPrice uint) error {
+	var course models.Course
+	if err := s.db.WithContext(ctx).First(&course, "id = ?", courseID).Error; err != nil {
+		return wrapNotFound(err, "course %d", courseID)
+	}
+
+	if course.Price >= oldPrice {
+		return nil
+	}
+
+	var users []models.User
+	err := s.db.WithContext(ctx).
+		Where("id IN (?)", s.db.Model(&models.WishlistItem{}).Select("user_id").Where("course_id = ?", courseID)).
+		Find(&users).Error
+	if err != nil || len(users) == 0 {
+		return err
+	}
+
+	notifications := make([]models.Notification, len(users))
+	for i, u := range users {
+		notifications[i] = services.PriceDropNotification(u.ID, course, oldPrice)
+	}
+
+	if err := s.db.WithContext(ctx).Create(&notifications).Error; err != nil {
+		return err
+	}
+
+	// the notifications are stored, so a failed email is only logged
+	for i, u := range users {
+		msg := mailer.Message{To: u.Email, Subject: notifications[i].Title, Body: notifications[i].Body}
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			log.Printf("error emailing notification %d: %v", notifications[i].ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,318 @@
+package gormsvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/payments"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PaymentOptions configures the payments of the orders.
+type PaymentOptions struct {
+	// Provider collects the payments; nil disables the purchases.
+	Provider payments.Provider
+	Currency string
+	// ResultURL is the page the learners return to after paying.
+	ResultURL string
+	// CallbackURL receives the payment webhooks.
+	CallbackURL string
+	// Refunds limits the refunds of the paid orders.
+	Refunds services.RefundPolicy
+	// RevenueShare is the percentage of the sales paid to the instructors.
+	RevenueShare uint
+	// GracePeriod is how long the subscriptions keep the access while a renewal is due.
+	GracePeriod time.Duration
+	// Seller is printed on the invoices of the payments.
+	Seller services.Seller
+}
+
+// OrderService is the GORM implementation of services.OrderService.
+type OrderService struct {
+	db      *gorm.DB
+	options PaymentOptions
+}
+
+// NewOrderService creates a new OrderService.
+func NewOrderService(db *gorm.DB, options PaymentOptions) *OrderService {
+	return &OrderService{db: db, options: options}
+}
+
+// List returns a page of the orders matching the filter.
+func (s *OrderService) List(ctx context.Context, filter services.OrderFilter, page services.Page) ([]models.Order, services.PageInfo, error) {
+	query := s.db.WithContext(ctx)
+
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+
+	if filter.CourseID != 0 {
+		query = query.Where("course_id = ?", filter.CourseID)
+	}
+
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+
+	return paginate[models.Order](query, page, services.OrderSortFields)
+}
+
+// Checkout creates the order of the paid course at its current price in the currency with
+// the coupon, or reuses the pending one, and its checkout session.
+func (s *OrderService) Checkout(ctx context.Context, userID, courseID uint, couponCode, currency string) (services.CheckoutSession, error) {
+	var course models.Course
+	err := s.db.WithContext(ctx).Preload("Prices").First(&course, "id = ? AND status_id = ?", courseID, services.CourseStatusPublished).Error
+	if err != nil {
+		return services.CheckoutSession{}, wrapNotFound(err, "course %d", courseID)
+	}
+
+	if course.Price == 0 {
+		return services.CheckoutSession{}, services.Errorf(services.ErrInvalidInput, "course %d is free, enroll directly", courseID)
+	}
+
+	// the courses enrolled into through a subscription may still be bought to keep them
+	var enrolled int64
+	err = s.db.WithContext(ctx).Model(&models.Enrollment{}).
+		Where("user_id = ? AND course_id = ? AND subscription_id IS NULL", userID, courseID).
+		Count(&enrolled).Error
+	if err != nil {
+		return services.CheckoutSession{}, err
+	}
+	if enrolled > 0 {
+		return services.CheckoutSession{}, services.Errorf(services.ErrConflict, "already enrolled into course %d", courseID)
+	}
+
+	check, err := checkPrerequisites(s.db.WithContext(ctx), userID, course)
+	if err != nil {
+		return services.CheckoutSession{}, err
+	}
+	if err := services.RequirePrerequisites(check); err != nil {
+		return services.CheckoutSession{}, err
+	}
+
+	var coupon *models.Coupon
+	if couponCode != "" {
+		c, err := redeemableCoupon(s.db.WithContext(ctx), couponCode)
+		if err != nil {
+			return services.CheckoutSession{}, err
+		}
+		if !services.CouponApplies(&c, courseID) {
+			return services.CheckoutSession{}, services.Errorf(services.ErrInvalidInput, "coupon %s does not apply to course %d", c.Code, courseID)
+		}
+		coupon = &c
+	}
+
+	if currency = services.NormalizeCurrency(currency); currency == "" {
+		var user models.User
+		if err := s.db.WithContext(ctx).First(&user, "id = ?", userID).Error; err != nil {
+			return services.CheckoutSession{}, wrapNotFound(err, "user %d", userID)
+		}
+		currency = user.Currency
+	}
+	if currency != "" && !services.ValidCurrency(currency) {
+		return services.CheckoutSession{}, services.Errorf(services.ErrInvalidInput, "unsupported currency %q", currency)
+	}
+
+	rates, err := exchangeRates(s.db.WithContext(ctx))
+	if err != nil {
+		return services.CheckoutSession{}, err
+	}
+
+	price, err := services.CalculatePrice(course, coupon, currency, services.NewRates(rates), time.Now())
+	if err != nil {
+		return services.CheckoutSession{}, err
+	}
+
+	order := models.Order{
+		UserID:   userID,
+		CourseID: courseID,
+		Amount:   price.Amount,
+		Discount: price.Discount,
+		Currency: price.Currency,
+		Status:   services.OrderStatusPending,
+	}
+	if coupon != nil {
+		order.CouponID = &coupon.ID
+	}
+
+	if price.Amount == 0 {
+		order.Provider = services.OrderProviderCoupon
+
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&order).Error; err != nil {
+				return err
+			}
+			return complete(tx, &order, s.options)
+		})
+		if err != nil {
+			return services.CheckoutSession{}, err
+		}
+
+		return services.CheckoutSession{Order: order}, nil
+	}
+
+	return startCheckout(ctx, s.db.WithContext(ctx), s.options, order, course.Title)
+}
+
+// startCheckout creates the pending order of the course, or reuses the one of the same
+// price, coupon, seats and path, and its checkout session at the payment provider.
+func startCheckout(ctx context.Context, db *gorm.DB, options PaymentOptions, order models.Order, title string) (services.CheckoutSession, error) {
+	provider := options.Provider
+	if provider == nil {
+		return services.CheckoutSession{}, services.Errorf(services.ErrInvalidInput, "payments are not available")
+	}
+	order.Provider = provider.Name()
+
+	// a pending order of the same price and coupon is paid again rather than duplicated
+	err := db.
+		Where(map[string]interface{}{
+			"user_id":         order.UserID,
+			"course_id":       order.CourseID,
+			"organization_id": order.OrganizationID,
+			"seats":           order.Seats,
+			"path_id":         order.PathID,
+			"status":          services.OrderStatusPending,
+			"amount":          order.Amount,
+			"currency":        order.Currency,
+			"coupon_id":       order.CouponID,
+			"provider":        order.Provider,
+		}).
+		FirstOrCreate(&order).Error
+	if err != nil {
+		return services.CheckoutSession{}, err
+	}
+
+	session, err := provider.CreateCheckout(ctx, payments.Checkout{
+		OrderID:     order.ID,
+		Amount:      order.Amount,
+		Currency:    order.Currency,
+		Description: services.OrderDescription(title, order),
+		ResultURL:   options.ResultURL,
+		CallbackURL: options.CallbackURL,
+	})
+	if err != nil {
+		return services.CheckoutSession{}, fmt.Errorf("error creating the checkout of order %d: %v", order.ID, err)
+	}
+
+	order.Reference = session.Reference
+	if err := db.Model(&order).Update("reference", order.Reference).Error; err != nil {
+		return services.CheckoutSession{}, err
+	}
+
+	return services.CheckoutSession{Order: order, URL: session.URL}, nil
+}
+
+// HandleWebhook verifies and applies the payment webhook of the provider.
+func (s *OrderService) HandleWebhook(ctx context.Context, header http.Header, body []byte) error {
+	provider := s.options.Provider
+	if provider == nil {
+		return services.Errorf(services.ErrNotFound, "payments are not available")
+	}
+
+	n, err := provider.ParseWebhook(header, body)
+	if errors.Is(err, payments.ErrInvalidSignature) {
+		return services.Errorf(services.ErrUnauthorized, "%v", err)
+	}
+	if err != nil {
+		return services.Errorf(services.ErrInvalidInput, "%v", err)
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the event is recorded first, so a duplicate delivery stops here
+		event := models.PaymentEvent{Provider: provider.Name(), EventID: n.EventID, OrderID: &n.OrderID, Status: n.Status}
+		if n.Kind == payments.KindSubscription {
+			event.OrderID, event.SubscriptionID = nil, &n.OrderID
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&event)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if n.Kind == payments.KindSubscription {
+			return applySubscriptionPayment(tx, provider.Name(), s.options.Seller, n)
+		}
+
+		var order models.Order
+		if err := tx.First(&order, "id = ? AND provider = ?", n.OrderID, provider.Name()).Error; err != nil {
+			return wrapNotFound(err, "order %d", n.OrderID)
+		}
+
+		switch n.Status {
+		case payments.StatusSucceeded:
+			return s.pay(tx, order, n)
+		case payments.StatusFailed:
+			return tx.Model(&order).Where("status = ?", services.OrderStatusPending).Update("status", services.OrderStatusFailed).Error
+		}
+
+		return nil
+	})
+}
+
+// pay marks the order as paid and enrolls the user into the course.
+func (s *OrderService) pay(tx *gorm.DB, order models.Order, n payments.Notification) error {
+	if order.Status == services.OrderStatusPaid || order.Status == services.OrderStatusRefunded {
+		return nil
+	}
+
+	if n.Amount != order.Amount || !strings.EqualFold(n.Currency, order.Currency) {
+		return services.Errorf(services.ErrInvalidInput, "payment of %d %s does not match order %d", n.Amount, n.Currency, order.ID)
+	}
+
+	return complete(tx, &order, s.options)
+}
+
+// complete marks the order as paid, redeems its coupon, posts the sale to the ledger,
+// issues the invoice and enrolls the user into the course, or keeps the enrollment through
+// a subscription. The seats bought for an organization are added to its licence instead,
+// and the bundle of a learning path enrolls the user into the path and all its courses.
+func complete(tx *gorm.DB, order *models.Order, options PaymentOptions) error {
+	now := time.Now()
+	order.Status = services.OrderStatusPaid
+	order.PaidAt = &now
+
+	err := tx.Model(order).Updates(map[string]interface{}{
+		"Status": order.Status,
+		"PaidAt": order.PaidAt,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	if err := redeemCoupon(tx, *order); err != nil {
+		return err
+	}
+
+	if err := postSale(tx, *order, options.RevenueShare); err != nil {
+		return err
+	}
+
+	if err := invoiceOrder(tx, options.Seller, *order); err != nil {
+		return err
+	}
+
+	if order.OrganizationID != nil {
+		return addSeats(tx, *order.OrganizationID, order.CourseID, order.Seats)
+	}
+
+	if order.PathID != nil {
+		return enrollPath(tx, order.UserID, *order.PathID, true)
+	}
+
+	enrollment := services.NewEnrollment(order.UserID, order.CourseID)
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&enrollment).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.Enrollment{}).
+		Where("user_id = ? AND course_id = ?", order.UserID, order.CourseID).
+		Update("subscription_id", nil).Error
+}
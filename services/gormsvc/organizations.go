@@ -0,0 +1,565 @@
+package gormsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// OrganizationService is the GORM implementation of services.OrganizationService.
+type OrganizationService struct {
+	db      *gorm.DB
+	options PaymentOptions
+}
+
+// NewOrganizationService creates a new OrganizationService selling the seats with the
+// payment options of the orders.
+func NewOrganizationService(db *gorm.DB, options PaymentOptions) *OrganizationService {
+	return &OrganizationService{db: db, options: options}
+}
+
+// List returns the organizations the user is a member of.
+func (s *OrganizationService) List(ctx context.Context, userID uint) ([]models.Organization, error) {
+	var organizations []models.Organization
+	err := s.db.WithContext(ctx).
+		Where("id IN (?)", s.db.Model(&models.OrganizationMember{}).Select("organization_id").Where("user_id = ?", userID)).
+		Order("name, id").
+		Find(&organizations).Error
+	return organizations, err
+}
+
+// Create creates an organization owned by the user.
+func (s *OrganizationService) Create(ctx context.Context, userID uint, input services.OrganizationInput) (models.Organization, error) {
+	if err := services.ValidateOrganization(&input); err != nil {
+		return models.Organization{}, err
+	}
+
+	organization := models.Organization{Name: input.Name}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&organization).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OrganizationMember{OrganizationID: organization.ID, UserID: userID, Role: services.RoleOwner}).Error
+	})
+	if err != nil {
+		return models.Organization{}, err
+	}
+
+	return organization, nil
+}
+
+// Members returns a page of the members of the organization of the user.
+func (s *OrganizationService) Members(ctx context.Context, userID, organizationID uint, page services.Page) ([]models.OrganizationMember, services.PageInfo, error) {
+	if _, err := memberRole(s.db.WithContext(ctx), organizationID, userID); err != nil {
+		return nil, services.PageInfo{}, err
+	}
+
+	query := s.db.WithContext(ctx).Preload("User").Where("organization_id = ?", organizationID)
+	return paginate[models.OrganizationMember](query, page, services.OrganizationMemberSortFields)
+}
+
+// AddMember adds a registered user to the organization.
+func (s *OrganizationService) AddMember(ctx context.Context, userID uint, input services.MemberInput) (models.OrganizationMember, error) {
+	role, err := memberRole(s.db.WithContext(ctx), input.OrganizationID, userID)
+	if err != nil {
+		return models.OrganizationMember{}, err
+	}
+
+	if err := services.CheckMemberChange(role, "", input.Role); err != nil {
+		return models.OrganizationMember{}, err
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).First(&user, "email = ?", input.Email).Error; err != nil {
+		return models.OrganizationMember{}, wrapNotFound(err, "user %s", input.Email)
+	}
+
+	member := models.OrganizationMember{OrganizationID: input.OrganizationID, UserID: user.ID, User: user, Role: input.Role}
+	result := s.db.WithContext(ctx).Omit("User").Clauses(clause.OnConflict{DoNothing: true}).Create(&member)
+	if result.Error != nil {
+		return models.OrganizationMember{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.OrganizationMember{}, services.Errorf(services.ErrConflict, "user %s is already a member", input.Email)
+	}
+
+	return member, nil
+}
+
+// UpdateMember changes the role of a member of the organization.
+func (s *OrganizationService) UpdateMember(ctx context.Context, userID uint, input services.MemberUpdate) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		role, err := memberRole(tx, input.OrganizationID, userID)
+		if err != nil {
+			return err
+		}
+
+		var member models.OrganizationMember
+		if err := tx.First(&member, "organization_id = ? AND user_id = ?", input.OrganizationID, input.UserID).Error; err != nil {
+			return wrapNotFound(err, "member %d", input.UserID)
+		}
+
+		if err := services.CheckMemberChange(role, member.Role, input.Role); err != nil {
+			return err
+		}
+		if role != services.RoleOwner {
+			return services.Errorf(services.ErrForbidden, "only the owners can change the roles")
+		}
+
+		if err := keepOwner(tx, member, input.Role); err != nil {
+			return err
+		}
+
+		return tx.Model(&member).Update("role", input.Role).Error
+	})
+}
+
+// RemoveMember removes the member from the organization with the assignments and the
+// enrollments through the seats.
+func (s *OrganizationService) RemoveMember(ctx context.Context, userID, organizationID, memberID uint) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		role, err := memberRole(tx, organizationID, userID)
+		if err != nil {
+			return err
+		}
+
+		var member models.OrganizationMember
+		if err := tx.First(&member, "organization_id = ? AND user_id = ?", organizationID, memberID).Error; err != nil {
+			return wrapNotFound(err, "member %d", memberID)
+		}
+
+		if err := services.CheckMemberChange(role, member.Role, ""); err != nil {
+			return err
+		}
+
+		if err := keepOwner(tx, member, ""); err != nil {
+			return err
+		}
+
+		err = tx.Where("organization_id = ? AND user_id = ?", organizationID, memberID).Delete(&models.CourseAssignment{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("organization_id = ? AND user_id = ?", organizationID, memberID).Delete(&models.Enrollment{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&member).Error
+	})
+}
+
+// BuySeats creates the order of the seats of the paid course for the organization at its
+// current price and its checkout session.
+func (s *OrganizationService) BuySeats(ctx context.Context, userID uint, input services.SeatPurchase) (services.CheckoutSession, error) {
+	if err := services.ValidateSeatPurchase(&input); err != nil {
+		return services.CheckoutSession{}, err
+	}
+
+	role, err := memberRole(s.db.WithContext(ctx), input.OrganizationID, userID)
+	if err != nil {
+		return services.CheckoutSession{}, err
+	}
+	if !services.CanManage(role) {
+		return services.CheckoutSession{}, services.Errorf(services.ErrForbidden, "only the managers can buy the seats")
+	}
+
+	var course models.Course
+	err = s.db.WithContext(ctx).Preload("Prices").First(&course, "id = ? AND status_id = ?", input.CourseID, services.CourseStatusPublished).Error
+	if err != nil {
+		return services.CheckoutSession{}, wrapNotFound(err, "course %d", input.CourseID)
+	}
+
+	if course.Price == 0 {
+		return services.CheckoutSession{}, services.Errorf(services.ErrInvalidInput, "course %d is free, assign it directly", input.CourseID)
+	}
+
+	rates, err := exchangeRates(s.db.WithContext(ctx))
+	if err != nil {
+		return services.CheckoutSession{}, err
+	}
+
+	price, err := services.CalculatePrice(course, nil, input.Currency, services.NewRates(rates), time.Now())
+	if err != nil {
+		return services.CheckoutSession{}, err
+	}
+
+	order := models.Order{
+		UserID:         userID,
+		CourseID:       course.ID,
+		OrganizationID: &input.OrganizationID,
+		Seats:          input.Seats,
+		Amount:         price.Amount * input.Seats,
+		Currency:       price.Currency,
+		Status:         services.OrderStatusPending,
+	}
+
+	return startCheckout(ctx, s.db.WithContext(ctx), s.options, order, course.Title)
+}
+
+// Assign assigns the published course to the members of the organization, enrolling them.
+func (s *OrganizationService) Assign(ctx context.Context, userID uint, input services.AssignmentInput) ([]models.CourseAssignment, error) {
+	if err := services.ValidateAssignment(input); err != nil {
+		return nil, err
+	}
+
+	var assignments []models.CourseAssignment
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		role, err := memberRole(tx, input.OrganizationID, userID)
+		if err != nil {
+			return err
+		}
+		if !services.CanManage(role) {
+			return services.Errorf(services.ErrForbidden, "only the managers can assign the courses")
+		}
+
+		var course models.Course
+		if err := tx.First(&course, "id = ? AND status_id = ?", input.CourseID, services.CourseStatusPublished).Error; err != nil {
+			return wrapNotFound(err, "course %d", input.CourseID)
+		}
+
+		var members int64
+		err = tx.Model(&models.OrganizationMember{}).
+			Where("organization_id = ? AND user_id IN ?", input.OrganizationID, input.UserIDs).
+			Count(&members).Error
+		if err != nil {
+			return err
+		}
+		if int(members) != len(input.UserIDs) {
+			return services.Errorf(services.ErrInvalidInput, "course can only be assigned to the members")
+		}
+
+		var existing []models.CourseAssignment
+		err = tx.Where("organization_id = ? AND course_id = ? AND user_id IN ?", input.OrganizationID, input.CourseID, input.UserIDs).
+			Find(&existing).Error
+		if err != nil {
+			return err
+		}
+
+		if course.Price > 0 {
+			license, err := seatLicense(tx, input.OrganizationID, input.CourseID)
+			if err != nil {
+				return err
+			}
+			if err := services.CheckSeats(license, input.CourseID, uint(len(input.UserIDs)-len(existing))); err != nil {
+				return err
+			}
+		}
+
+		for _, memberID := range input.UserIDs {
+			assignment := models.CourseAssignment{
+				OrganizationID: input.OrganizationID,
+				UserID:         memberID,
+				CourseID:       input.CourseID,
+				AssignedByID:   userID,
+				DueAt:          input.DueAt,
+			}
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}, {Name: "course_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"due_at", "assigned_by_id", "updated_at"}),
+			}).Create(&assignment).Error
+			if err != nil {
+				return err
+			}
+
+			if err := enrollThroughSeat(tx, input.OrganizationID, memberID, input.CourseID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Where("organization_id = ? AND course_id = ? AND user_id IN ?", input.OrganizationID, input.CourseID, input.UserIDs).
+			Order("user_id").Find(&assignments).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+// Unassign ends the assignment of the course to the member and the enrollment through it.
+func (s *OrganizationService) Unassign(ctx context.Context, userID, organizationID, courseID, memberID uint) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		role, err := memberRole(tx, organizationID, userID)
+		if err != nil {
+			return err
+		}
+		if !services.CanManage(role) {
+			return services.Errorf(services.ErrForbidden, "only the managers can assign the courses")
+		}
+
+		result := tx.Where("organization_id = ? AND course_id = ? AND user_id = ?", organizationID, courseID, memberID).
+			Delete(&models.CourseAssignment{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return services.Errorf(services.ErrNotFound, "assignment of course %d to member %d", courseID, memberID)
+		}
+
+		return tx.Where("organization_id = ? AND course_id = ? AND user_id = ?", organizationID, courseID, memberID).
+			Delete(&models.Enrollment{}).Error
+	})
+}
+
+// Assignments returns the courses assigned to the user by the organizations.
+func (s *OrganizationService) Assignments(ctx context.Context, userID uint) ([]models.CourseAssignment, error) {
+	var assignments []models.CourseAssignment
+	err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("due_at IS NULL, due_at, id").Find(&assignments).Error
+	return assignments, err
+}
+
+// Join adds the user to the organization they were invited to by an import with the token,
+// assigning the courses of the import while the seats last.
+func (s *OrganizationService) Join(ctx context.Context, userID uint, token string) (models.OrganizationMember, error) {
+	var member models.OrganizationMember
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var invitation models.Invitation
+		err := tx.First(&invitation, "token_hash = ? AND user_id = ? AND organization_id IS NOT NULL AND accepted_at IS NULL AND expires_at > ?",
+			services.HashInvitationToken(token), userID, time.Now()).Error
+		if err != nil {
+			return wrapNotFound(err, "invitation")
+		}
+
+		// the invitation is accepted atomically, so it is used once
+		result := tx.Model(&models.Invitation{}).Where("id = ? AND accepted_at IS NULL", invitation.ID).Update("accepted_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return services.Errorf(services.ErrNotFound, "invitation")
+		}
+
+		member = models.OrganizationMember{OrganizationID: *invitation.OrganizationID, UserID: userID, Role: services.RoleLearner}
+		if err := tx.Omit("User").Clauses(clause.OnConflict{DoNothing: true}).Create(&member).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&member, "organization_id = ? AND user_id = ?", member.OrganizationID, userID).Error; err != nil {
+			return err
+		}
+
+		if invitation.ImportID == nil {
+			return nil
+		}
+
+		var userImport models.UserImport
+		if err := tx.First(&userImport, "id = ?", *invitation.ImportID).Error; err != nil {
+			return err
+		}
+
+		var courses []models.Course
+		err = tx.Joins("JOIN user_import_courses ON user_import_courses.course_id = courses.id").
+			Where("user_import_courses.import_id = ? AND courses.status_id = ?", userImport.ID, services.CourseStatusPublished).
+			Order("courses.id").Find(&courses).Error
+		if err != nil {
+			return err
+		}
+
+		for _, course := range courses {
+			// the courses without free seats are left to the managers to assign later
+			err := tx.Transaction(func(tx *gorm.DB) error { return enrollImported(tx, userImport, userID, course) })
+			if err != nil && !services.IsDomainError(err) {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return models.OrganizationMember{}, err
+	}
+
+	return member, nil
+}
+
+// Dashboard returns the seats of the organization and the progress of the members in the
+// assigned courses.
+func (s *OrganizationService) Dashboard(ctx context.Context, userID, organizationID uint) (services.OrganizationDashboard, error) {
+	db := s.db.WithContext(ctx)
+
+	role, err := memberRole(db, organizationID, userID)
+	if err != nil {
+		return services.OrganizationDashboard{}, err
+	}
+	if !services.CanManage(role) {
+		return services.OrganizationDashboard{}, services.Errorf(services.ErrForbidden, "only the managers can see the dashboard")
+	}
+
+	var dashboard services.OrganizationDashboard
+	if err := db.First(&dashboard.Organization, "id = ?", organizationID).Error; err != nil {
+		return services.OrganizationDashboard{}, err
+	}
+
+	if err := db.Where("organization_id = ?", organizationID).Order("course_id").Find(&dashboard.Licenses).Error; err != nil {
+		return services.OrganizationDashboard{}, err
+	}
+
+	var members []models.OrganizationMember
+	err = db.Preload("User").Where("organization_id = ?", organizationID).Order("user_id").Find(&members).Error
+	if err != nil {
+		return services.OrganizationDashboard{}, err
+	}
+
+	var assignments []models.CourseAssignment
+	err = db.Preload("Course").Where("organization_id = ?", organizationID).Order("user_id, course_id").Find(&assignments).Error
+	if err != nil {
+		return services.OrganizationDashboard{}, err
+	}
+
+	memberIDs := make([]uint, 0, len(members))
+	for _, m := range members {
+		memberIDs = append(memberIDs, m.UserID)
+	}
+
+	var enrollments []models.Enrollment
+	if err := db.Where("user_id IN ?", memberIDs).Find(&enrollments).Error; err != nil {
+		return services.OrganizationDashboard{}, err
+	}
+
+	var certificates []models.CourseCertificate
+	if err := db.Where("user_id IN ? AND revoked_at IS NULL", memberIDs).Find(&certificates).Error; err != nil {
+		return services.OrganizationDashboard{}, err
+	}
+
+	dashboard.Members = memberProgress(members, assignments, enrollments, certificates, dashboard.Licenses, time.Now())
+	return dashboard, nil
+}
+
+// memberProgress returns the progress of the members in the assigned courses and counts
+// the used seats of the licences.
+func memberProgress(members []models.OrganizationMember, assignments []models.CourseAssignment, enrollments []models.Enrollment,
+	certificates []models.CourseCertificate, licenses []models.SeatLicense, now time.Time) []services.MemberProgress {
+	type key struct{ userID, courseID uint }
+
+	enrolled := make(map[key]*models.Enrollment)
+	for i, e := range enrollments {
+		enrolled[key{e.UserID, e.CourseID}] = &enrollments[i]
+	}
+
+	certified := make(map[key]*models.CourseCertificate)
+	for i, c := range certificates {
+		certified[key{c.UserID, c.CourseID}] = &certificates[i]
+	}
+
+	progress := make([]services.MemberProgress, 0, len(members))
+	index := make(map[uint]int)
+	for _, m := range members {
+		index[m.UserID] = len(progress)
+		progress = append(progress, services.MemberProgress{
+			UserID:    m.UserID,
+			FirstName: m.User.FirstName,
+			LastName:  m.User.LastName,
+			Email:     m.User.Email,
+			Role:      m.Role,
+			Courses:   []services.AssignmentProgress{},
+		})
+	}
+
+	for _, a := range assignments {
+		for i := range licenses {
+			if licenses[i].CourseID == a.CourseID {
+				licenses[i].Used++
+			}
+		}
+
+		if i, ok := index[a.UserID]; ok {
+			k := key{a.UserID, a.CourseID}
+			progress[i].Courses = append(progress[i].Courses, services.NewAssignmentProgress(a, enrolled[k], certified[k], now))
+		}
+	}
+
+	return progress
+}
+
+// memberRole returns the role of the user in the organization; the admins act as its
+// owners.
+func memberRole(db *gorm.DB, organizationID, userID uint) (string, error) {
+	var organization models.Organization
+	if err := db.First(&organization, "id = ?", organizationID).Error; err != nil {
+		return "", wrapNotFound(err, "organization %d", organizationID)
+	}
+
+	var member models.OrganizationMember
+	if err := db.Limit(1).Find(&member, "organization_id = ? AND user_id = ?", organizationID, userID).Error; err != nil {
+		return "", err
+	}
+	if member.UserID != 0 {
+		return member.Role, nil
+	}
+
+	if err := requireAdmin(db, userID); err != nil {
+		return "", services.Errorf(services.ErrForbidden, "not a member of organization %d", organizationID)
+	}
+	return services.RoleOwner, nil
+}
+
+// keepOwner returns services.ErrInvalidInput if the member is the last owner of the
+// organization and would lose the role, empty for the removal.
+func keepOwner(tx *gorm.DB, member models.OrganizationMember, role string) error {
+	if member.Role != services.RoleOwner || role == services.RoleOwner {
+		return nil
+	}
+
+	var owners int64
+	err := tx.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND role = ?", member.OrganizationID, services.RoleOwner).
+		Count(&owners).Error
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return services.Errorf(services.ErrInvalidInput, "organization %d must keep an owner", member.OrganizationID)
+	}
+	return nil
+}
+
+// seatLicense returns the licence of the course of the organization with the used seats,
+// or nil if none was bought.
+func seatLicense(tx *gorm.DB, organizationID, courseID uint) (*models.SeatLicense, error) {
+	var license models.SeatLicense
+	if err := tx.Limit(1).Find(&license, "organization_id = ? AND course_id = ?", organizationID, courseID).Error; err != nil {
+		return nil, err
+	}
+	if license.ID == 0 {
+		return nil, nil
+	}
+
+	var used int64
+	err := tx.Model(&models.CourseAssignment{}).
+		Where("organization_id = ? AND course_id = ?", organizationID, courseID).
+		Count(&used).Error
+	if err != nil {
+		return nil, err
+	}
+	license.Used = uint(used)
+
+	return &license, nil
+}
+
+// addSeats adds the paid seats to the licence of the course of the organization.
+func addSeats(tx *gorm.DB, organizationID, courseID, seats uint) error {
+	license := models.SeatLicense{OrganizationID: organizationID, CourseID: courseID, Seats: seats}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "course_id"}},
+		DoUpdates: clause.Assignments(map[string]any{"seats": gorm.Expr("seat_licenses.seats + ?", seats), "updated_at": time.Now()}),
+	}).Create(&license).Error
+}
+
+// enrollThroughSeat enrolls the member into the assigned course, or moves the enrollment
+// through a subscription to the seat. The other enrollments are kept.
+func enrollThroughSeat(tx *gorm.DB, organizationID, userID, courseID uint) error {
+	enrollment := services.NewEnrollment(userID, courseID)
+	enrollment.OrganizationID = &organizationID
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&enrollment).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.Enrollment{}).
+		Where("user_id = ? AND course_id = ? AND subscription_id IS NOT NULL", userID, courseID).
+		Updates(map[string]any{"subscription_id": nil, "organization_id": organizationID}).Error
+}
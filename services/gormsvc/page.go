@@ -0,0 +1,137 @@
+package gormsvc
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"strings"
+)
+
+// paginate loads a page of the records selected by the query, ordered by the page sort
+// and the primary key. The cursor holds the sort field value and the primary key of the
+// last returned record, so the next page is selected with a row value comparison.
+func paginate[T any](query *gorm.DB, page services.Page, allowed map[string]bool) ([]T, services.PageInfo, error) {
+	var info services.PageInfo
+
+	field, desc, err := page.SortField(allowed)
+	if err != nil {
+		return nil, info, err
+	}
+
+	cursor, err := page.DecodeCursor()
+	if err != nil {
+		return nil, info, err
+	}
+
+	stmt := &gorm.Statement{DB: query}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, info, err
+	}
+
+	var sortField *schema.Field
+	if field != "" {
+		f := stmt.Schema.LookUpField(field)
+		if f == nil {
+			return nil, info, services.Errorf(services.ErrInvalidInput, "invalid sort field")
+		}
+		if !f.PrimaryKey {
+			sortField = f
+		}
+	}
+
+	if page.Count {
+		var total int64
+		if err := query.Session(&gorm.Session{}).Model(new(T)).Count(&total).Error; err != nil {
+			return nil, info, err
+		}
+		info.Total = &total
+	}
+
+	// the key columns: the sort field followed by the primary key
+	keyFields := stmt.Schema.PrimaryFields
+	if sortField != nil {
+		keyFields = append([]*schema.Field{sortField}, keyFields...)
+	}
+
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+
+	columns := make([]string, len(keyFields))
+	for i, f := range keyFields {
+		columns[i] = stmt.Schema.Table + "." + f.DBName
+		query = query.Order(columns[i] + " " + direction)
+	}
+
+	if cursor != nil {
+		values, err := cursorValues(cursor, sortField, len(stmt.Schema.PrimaryFields))
+		if err != nil {
+			return nil, info, err
+		}
+
+		op := ">"
+		if desc {
+			op = "<"
+		}
+
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+		query = query.Where(fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), op, placeholders), values...)
+	}
+
+	size := page.Size()
+
+	var rows []T
+	if err := query.Limit(size + 1).Find(&rows).Error; err != nil {
+		return nil, info, err
+	}
+
+	if len(rows) > size {
+		rows = rows[:size]
+
+		ctx := query.Statement.Context
+		last := reflect.ValueOf(&rows[size-1]).Elem()
+		next := services.Cursor{Sort: page.Sort}
+
+		if sortField != nil {
+			value, _ := sortField.ValueOf(ctx, last)
+			next.Value, _ = json.Marshal(value)
+		}
+
+		for _, f := range stmt.Schema.PrimaryFields {
+			value, _ := f.ValueOf(ctx, last)
+			next.Key = append(next.Key, uint(reflect.ValueOf(value).Uint()))
+		}
+
+		info.NextCursor = next.Encode()
+	}
+
+	return rows, info, nil
+}
+
+// cursorValues returns the query arguments of the cursor: the sort field value decoded
+// into the field type followed by the primary key.
+func cursorValues(cursor *services.Cursor, sortField *schema.Field, keyLen int) ([]any, error) {
+	if len(cursor.Key) != keyLen {
+		return nil, services.Errorf(services.ErrInvalidInput, "invalid cursor")
+	}
+
+	var values []any
+
+	if sortField != nil {
+		value := reflect.New(sortField.FieldType)
+		if err := json.Unmarshal(cursor.Value, value.Interface()); err != nil {
+			return nil, services.Errorf(services.ErrInvalidInput, "invalid cursor")
+		}
+		values = append(values, value.Elem().Interface())
+	}
+
+	for _, k := range cursor.Key {
+		values = append(values, k)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,313 @@
+package gormsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/certificates"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LearningPathService is the GORM implementation of services.LearningPathService.
+type LearningPathService struct {
+	db        *gorm.DB
+	generator *certificates.Generator
+	options   PaymentOptions
+}
+
+// NewLearningPathService creates a new LearningPathService selling the bundles with the
+// payment options of the orders and rendering the certificates with the generator.
+func NewLearningPathService(db *gorm.DB, generator *certificates.Generator, options PaymentOptions) *LearningPathService {
+	return &LearningPathService{db: db, generator: generator, options: options}
+}
+
+// List returns a page of the learning paths with the given IDs, or of all paths if ids is
+// empty, with their courses in order.
+func (s *LearningPathService) List(ctx context.Context, ids []uint, page services.Page) ([]models.LearningPath, services.PageInfo, error) {
+	query := withPathCourses(s.db.WithContext(ctx))
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+
+	return paginate[models.LearningPath](query, page, services.PathSortFields)
+}
+
+// Create creates a learning path of the published courses.
+func (s *LearningPathService) Create(ctx context.Context, userID uint, input services.PathInput) (models.LearningPath, error) {
+	if err := services.ValidatePath(&input, s.options.Currency); err != nil {
+		return models.LearningPath{}, err
+	}
+
+	var path models.LearningPath
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := requireAdmin(tx, userID); err != nil {
+			return err
+		}
+
+		path = models.LearningPath{Title: input.Title, Description: input.Description, Price: input.Price, Currency: input.Currency}
+		if err := tx.Create(&path).Error; err != nil {
+			return err
+		}
+
+		return setPathCourses(tx, path.ID, input.CourseIDs)
+	})
+	if err != nil {
+		return models.LearningPath{}, err
+	}
+
+	return learningPath(s.db.WithContext(ctx), path.ID)
+}
+
+// Update replaces the details and the courses of the learning path.
+func (s *LearningPathService) Update(ctx context.Context, userID, pathID uint, input services.PathInput) (models.LearningPath, error) {
+	if err := services.ValidatePath(&input, s.options.Currency); err != nil {
+		return models.LearningPath{}, err
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := requireAdmin(tx, userID); err != nil {
+			return err
+		}
+
+		path, err := learningPath(tx, pathID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&path).Updates(map[string]any{
+			"title":       input.Title,
+			"description": input.Description,
+			"price":       input.Price,
+			"currency":    input.Currency,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("path_id = ?", pathID).Delete(&models.LearningPathCourse{}).Error; err != nil {
+			return err
+		}
+		return setPathCourses(tx, pathID, input.CourseIDs)
+	})
+	if err != nil {
+		return models.LearningPath{}, err
+	}
+
+	return learningPath(s.db.WithContext(ctx), pathID)
+}
+
+// Enroll enrolls the user into the learning path and its free courses.
+func (s *LearningPathService) Enroll(ctx context.Context, userID, pathID uint) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return enrollPath(tx, userID, pathID, false)
+	})
+}
+
+// Checkout creates the order of the bundle of the learning path and its checkout session.
+func (s *LearningPathService) Checkout(ctx context.Context, userID, pathID uint, currency string) (services.CheckoutSession, error) {
+	db := s.db.WithContext(ctx)
+
+	path, err := learningPath(db, pathID)
+	if err != nil {
+		return services.CheckoutSession{}, err
+	}
+
+	if currency = services.NormalizeCurrency(currency); currency == "" {
+		var user models.User
+		if err := db.First(&user, "id = ?", userID).Error; err != nil {
+			return services.CheckoutSession{}, wrapNotFound(err, "user %d", userID)
+		}
+		currency = user.Currency
+	}
+	if currency != "" && !services.ValidCurrency(currency) {
+		return services.CheckoutSession{}, services.Errorf(services.ErrInvalidInput, "unsupported currency %q", currency)
+	}
+
+	rates, err := exchangeRates(db)
+	if err != nil {
+		return services.CheckoutSession{}, err
+	}
+
+	price, currency, err := services.PathPrice(path, currency, services.NewRates(rates))
+	if err != nil {
+		return services.CheckoutSession{}, err
+	}
+
+	// the bundle is of no use to the users who already bought all its courses
+	var courseIDs []uint
+	for _, c := range path.Courses {
+		courseIDs = append(courseIDs, c.CourseID)
+	}
+
+	var owned int64
+	err = db.Model(&models.Enrollment{}).
+		Where("user_id = ? AND course_id IN ? AND subscription_id IS NULL AND organization_id IS NULL", userID, courseIDs).
+		Count(&owned).Error
+	if err != nil {
+		return services.CheckoutSession{}, err
+	}
+	if int(owned) == len(courseIDs) {
+		return services.CheckoutSession{}, services.Errorf(services.ErrConflict, "already enrolled into all the courses of path %d", pathID)
+	}
+
+	order := models.Order{
+		UserID:   userID,
+		CourseID: path.Courses[0].CourseID,
+		PathID:   &path.ID,
+		Amount:   price,
+		Currency: currency,
+		Status:   services.OrderStatusPending,
+	}
+
+	return startCheckout(ctx, db, s.options, order, path.Title)
+}
+
+// Progress returns the progress of the user in the learning path.
+func (s *LearningPathService) Progress(ctx context.Context, userID, pathID uint) (services.PathProgress, error) {
+	return pathProgress(s.db.WithContext(ctx), userID, pathID)
+}
+
+// IssueCertificate creates the certificate of the learning path completed by the user and
+// renders its image.
+func (s *LearningPathService) IssueCertificate(ctx context.Context, userID, pathID uint) (models.PathCertificate, error) {
+	db := s.db.WithContext(ctx)
+
+	progress, err := pathProgress(db, userID, pathID)
+	if err != nil {
+		return models.PathCertificate{}, err
+	}
+
+	switch {
+	case progress.CertificateID != nil:
+		return models.PathCertificate{}, services.Errorf(services.ErrConflict, "certificate already exists")
+	case !progress.Completed:
+		return models.PathCertificate{}, services.Errorf(services.ErrInvalidInput, "path is not completed")
+	}
+
+	certificate := models.PathCertificate{UserID: userID, PathID: pathID}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&certificate).Error; err != nil {
+			return err
+		}
+
+		data, err := certificates.LoadPathData(tx, certificate.ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.generator.Generate(data)
+		return err
+	})
+	if err != nil {
+		return models.PathCertificate{}, err
+	}
+
+	return certificate, nil
+}
+
+// withPathCourses preloads the courses of the learning paths in order.
+func withPathCourses(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Courses", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Courses.Course")
+}
+
+// learningPath returns the learning path with its courses in order.
+func learningPath(db *gorm.DB, pathID uint) (models.LearningPath, error) {
+	var path models.LearningPath
+	err := withPathCourses(db).First(&path, "id = ?", pathID).Error
+	return path, wrapNotFound(err, "path %d", pathID)
+}
+
+// setPathCourses adds the published courses to the learning path in order.
+func setPathCourses(tx *gorm.DB, pathID uint, courseIDs []uint) error {
+	var published int64
+	err := tx.Model(&models.Course{}).
+		Where("id IN ? AND status_id = ?", courseIDs, services.CourseStatusPublished).
+		Count(&published).Error
+	if err != nil {
+		return err
+	}
+	if int(published) != len(courseIDs) {
+		return services.Errorf(services.ErrInvalidInput, "path can only have the published courses")
+	}
+
+	courses := make([]models.LearningPathCourse, len(courseIDs))
+	for i, id := range courseIDs {
+		courses[i] = models.LearningPathCourse{PathID: pathID, CourseID: id, Position: uint(i + 1)}
+	}
+	return tx.Omit("Course").Create(&courses).Error
+}
+
+// enrollPath enrolls the user into the learning path and its free courses, or all its
+// courses once the bundle is paid, keeping the bought courses after the subscription ends.
+func enrollPath(tx *gorm.DB, userID, pathID uint, paid bool) error {
+	path, err := learningPath(tx, pathID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.PathEnrollment{PathID: pathID, UserID: userID}).Error
+	if err != nil {
+		return err
+	}
+
+	for _, c := range path.Courses {
+		if c.Course.Price > 0 && !paid {
+			continue
+		}
+
+		enrollment := services.NewEnrollment(userID, c.CourseID)
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&enrollment).Error; err != nil {
+			return err
+		}
+
+		if paid {
+			err := tx.Model(&models.Enrollment{}).
+				Where("user_id = ? AND course_id = ?", userID, c.CourseID).
+				Update("subscription_id", nil).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// pathProgress returns the progress of the user in the learning path.
+func pathProgress(db *gorm.DB, userID, pathID uint) (services.PathProgress, error) {
+	path, err := learningPath(db, pathID)
+	if err != nil {
+		return services.PathProgress{}, err
+	}
+
+	var enrolled int64
+	if err := db.Model(&models.PathEnrollment{}).Where("path_id = ? AND user_id = ?", pathID, userID).Count(&enrolled).Error; err != nil {
+		return services.PathProgress{}, err
+	}
+
+	courseIDs := make([]uint, 0, len(path.Courses))
+	for _, c := range path.Courses {
+		courseIDs = append(courseIDs, c.CourseID)
+	}
+
+	var enrollments []models.Enrollment
+	if err := db.Where("user_id = ? AND course_id IN ?", userID, courseIDs).Find(&enrollments).Error; err != nil {
+		return services.PathProgress{}, err
+	}
+
+	var certificate models.PathCertificate
+	if err := db.Limit(1).Find(&certificate, "path_id = ? AND user_id = ?", pathID, userID).Error; err != nil {
+		return services.PathProgress{}, err
+	}
+
+	var issued *models.PathCertificate
+	if certificate.ID != 0 {
+		issued = &certificate
+	}
+
+	return services.NewPathProgress(path, enrolled > 0, enrollments, issued), nil
+}
@@ -0,0 +1,166 @@
+package gormsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/events"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+)
+
+// UpdatePrerequisites replaces the prerequisites of a course owned by the instructor and
+// sets whether they are enforced.
+func (s *CourseService) UpdatePrerequisites(ctx context.Context, instructorID uint, input services.PrerequisiteInput) ([]models.CoursePrerequisite, error) {
+	if _, err := s.owned(ctx, instructorID, input.CourseID); err != nil {
+		return nil, err
+	}
+
+	if err := services.ValidatePrerequisites(&input); err != nil {
+		return nil, err
+	}
+
+	list := make([]models.CoursePrerequisite, len(input.PrerequisiteIDs))
+	for i, id := range input.PrerequisiteIDs {
+		list[i] = models.CoursePrerequisite{CourseID: input.CourseID, PrerequisiteID: id}
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockPrerequisites(tx); err != nil {
+			return err
+		}
+
+		if len(input.PrerequisiteIDs) > 0 {
+			var published int64
+			err := tx.Model(&models.Course{}).
+				Where("id IN ? AND status_id = ?", input.PrerequisiteIDs, services.CourseStatusPublished).
+				Count(&published).Error
+			if err != nil {
+				return err
+			}
+			if int(published) != len(input.PrerequisiteIDs) {
+				return services.Errorf(services.ErrInvalidInput, "prerequisites must be published courses")
+			}
+		}
+
+		edges, err := prerequisiteEdges(tx)
+		if err != nil {
+			return err
+		}
+		if err := services.CheckAcyclic(services.ReplacePrerequisites(edges, input.CourseID, input.PrerequisiteIDs)); err != nil {
+			return err
+		}
+
+		if err := tx.Where("course_id = ?", input.CourseID).Delete(&models.CoursePrerequisite{}).Error; err != nil {
+			return err
+		}
+		if len(list) > 0 {
+			if err := tx.Omit("Prerequisite").Create(&list).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.Course{ID: input.CourseID}).Update("enforce_prerequisites", input.Enforce).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.Publish(ctx, events.Event{Topic: events.CourseUpdated, ID: input.CourseID})
+
+	return list, nil
+}
+
+// Prerequisites returns the prerequisite graph of the course, or of all the published
+// courses if courseID is zero.
+func (s *CourseService) Prerequisites(ctx context.Context, courseID uint) (services.PrerequisiteGraph, error) {
+	db := s.db.WithContext(ctx)
+
+	edges, err := prerequisiteEdges(db)
+	if err != nil {
+		return services.PrerequisiteGraph{}, err
+	}
+
+	query := db.Model(&models.Course{})
+	if courseID != 0 {
+		if _, err := s.Get(ctx, courseID); err != nil {
+			return services.PrerequisiteGraph{}, err
+		}
+		query = query.Where("id IN ?", services.PrerequisiteClosure(edges, courseID))
+	} else {
+		var ids []uint
+		for _, e := range edges {
+			ids = append(ids, e.CourseID, e.PrerequisiteID)
+		}
+		if len(ids) == 0 {
+			return services.NewPrerequisiteGraph(nil, nil), nil
+		}
+		query = query.Where("id IN ? AND status_id = ?", ids, services.CourseStatusPublished)
+	}
+
+	var courses []models.Course
+	if err := query.Find(&courses).Error; err != nil {
+		return services.PrerequisiteGraph{}, err
+	}
+
+	nodes := make([]services.GraphCourse, len(courses))
+	for i, c := range courses {
+		nodes[i] = services.NewGraphCourse(c)
+	}
+
+	return services.NewPrerequisiteGraph(nodes, edges), nil
+}
+
+// CheckPrerequisites lists the prerequisites of the course the user has not completed.
+func (s *EnrollmentService) CheckPrerequisites(ctx context.Context, userID, courseID uint) (services.PrerequisiteCheck, error) {
+	var course models.Course
+	if err := s.db.WithContext(ctx).First(&course, "id = ?", courseID).Error; err != nil {
+		return services.PrerequisiteCheck{}, wrapNotFound(err, "course %d", courseID)
+	}
+
+	return checkPrerequisites(s.db.WithContext(ctx), userID, course)
+}
+
+// lockPrerequisites locks the prerequisite graph until the end of the transaction, so two
+// concurrent updates cannot both pass the cycle check and commit a cycle together. The lock
+// conflicts with itself only, the readers are not blocked. SQLite already runs the
+// transactions one at a time on its single connection (PostgreSQL only).
+func lockPrerequisites(tx *gorm.DB) error {
+	if tx.Dialector.Name() != "postgres" {
+		return nil
+	}
+
+	return tx.Exec("LOCK TABLE course_prerequisites IN SHARE ROW EXCLUSIVE MODE").Error
+}
+
+// prerequisiteEdges returns all the edges of the prerequisite graph.
+func prerequisiteEdges(db *gorm.DB) ([]services.PrerequisiteEdge, error) {
+	var edges []services.PrerequisiteEdge
+	err := db.Model(&models.CoursePrerequisite{}).Select("course_id, prerequisite_id").Scan(&edges).Error
+	return edges, err
+}
+
+// checkPrerequisites lists the prerequisites of the course the user has not completed.
+func checkPrerequisites(db *gorm.DB, userID uint, course models.Course) (services.PrerequisiteCheck, error) {
+	var prerequisites []models.Course
+	err := db.
+		Joins("JOIN course_prerequisites ON course_prerequisites.prerequisite_id = courses.id").
+		Where("course_prerequisites.course_id = ?", course.ID).
+		Order("courses.id").
+		Find(&prerequisites).Error
+	if err != nil {
+		return services.PrerequisiteCheck{}, err
+	}
+
+	var enrollments []models.Enrollment
+	if len(prerequisites) > 0 {
+		ids := make([]uint, len(prerequisites))
+		for i, p := range prerequisites {
+			ids[i] = p.ID
+		}
+		if err := db.Where("user_id = ? AND course_id IN ?", userID, ids).Find(&enrollments).Error; err != nil {
+			return services.PrerequisiteCheck{}, err
+		}
+	}
+
+	return services.NewPrerequisiteCheck(course, prerequisites, enrollments), nil
+}
@@ -0,0 +1,47 @@
+package gormsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+)
+
+// RecommendationData returns the function loading the data of the recommendations: the
+// published courses with their categories and the enrollments into them.
+func RecommendationData(db *gorm.DB) func(ctx context.Context) (services.RecommendationData, error) {
+	return func(ctx context.Context) (services.RecommendationData, error) {
+		var data services.RecommendationData
+		db := db.WithContext(ctx)
+
+		var courses []models.Course
+		err := db.Preload("Categories").Where("status_id = ?", services.CourseStatusPublished).Find(&courses).Error
+		if err != nil {
+			return data, err
+		}
+
+		for _, c := range courses {
+			course := services.RecommendationCourse{ID: c.ID, LevelID: c.LevelID}
+			for _, category := range c.Categories {
+				course.CategoryIDs = append(course.CategoryIDs, category.ID)
+			}
+			data.Courses = append(data.Courses, course)
+		}
+
+		err = db.Table("enrollments").
+			Select("enrollments.user_id, enrollments.course_id").
+			Joins("JOIN courses ON courses.id = enrollments.course_id").
+			Where("courses.status_id = ?", services.CourseStatusPublished).
+			Scan(&data.Enrollments).Error
+		return data, err
+	}
+}
+
+// EnrolledCourses returns the function loading the IDs of the courses the user is enrolled in.
+func EnrolledCourses(db *gorm.DB) func(ctx context.Context, userID uint) ([]uint, error) {
+	return func(ctx context.Context, userID uint) ([]uint, error) {
+		var ids []uint
+		err := db.WithContext(ctx).Model(&models.Enrollment{}).Where("user_id = ?", userID).Pluck("course_id", &ids).Error
+		return ids, err
+	}
+}
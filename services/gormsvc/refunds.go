@@ -0,0 +1,251 @@
+package gormsvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/payments"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+	"time"
+)
+
+// RefundService is the GORM implementation of services.RefundService.
+type RefundService struct {
+	db      *gorm.DB
+	options PaymentOptions
+}
+
+// NewRefundService creates a new RefundService.
+func NewRefundService(db *gorm.DB, options PaymentOptions) *RefundService {
+	return &RefundService{db: db, options: options}
+}
+
+// List returns a page of the refunds matching the filter.
+func (s *RefundService) List(ctx context.Context, filter services.RefundFilter, page services.Page) ([]models.Refund, services.PageInfo, error) {
+	query := s.db.WithContext(ctx)
+
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+
+	if filter.OrderID != 0 {
+		query = query.Where("order_id = ?", filter.OrderID)
+	}
+
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+
+	return paginate[models.Refund](query, page, services.RefundSortFields)
+}
+
+// Requests returns a page of the refunds matching the filter to the admins.
+func (s *RefundService) Requests(ctx context.Context, adminID uint, filter services.RefundFilter, page services.Page) ([]models.Refund, services.PageInfo, error) {
+	if err := requireAdmin(s.db.WithContext(ctx), adminID); err != nil {
+		return nil, services.PageInfo{}, err
+	}
+
+	return s.List(ctx, filter, page)
+}
+
+// Request requests the refund of the order paid by the user under the refund policy.
+func (s *RefundService) Request(ctx context.Context, userID, orderID uint, reason string) (models.Refund, error) {
+	if len(reason) > 65000 {
+		return models.Refund{}, services.Errorf(services.ErrInvalidInput, "reason is too long")
+	}
+
+	var order models.Order
+	if err := s.db.WithContext(ctx).First(&order, "id = ? AND user_id = ?", orderID, userID).Error; err != nil {
+		return models.Refund{}, wrapNotFound(err, "order %d", orderID)
+	}
+
+	var enrollment models.Enrollment
+	err := s.db.WithContext(ctx).First(&enrollment, "user_id = ? AND course_id = ?", userID, order.CourseID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Refund{}, err
+	}
+
+	enrolled := &enrollment
+	if err != nil {
+		enrolled = nil
+	}
+
+	if err := services.CheckRefundable(order, enrolled, s.options.Refunds, time.Now()); err != nil {
+		return models.Refund{}, err
+	}
+
+	refund := models.Refund{
+		OrderID: orderID,
+		UserID:  userID,
+		Reason:  reason,
+		Status:  services.RefundStatusRequested,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Model(&models.Refund{}).
+			Where("order_id = ? AND status <> ?", orderID, services.RefundStatusRejected).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return services.Errorf(services.ErrConflict, "refund of order %d already requested", orderID)
+		}
+
+		if err := tx.Create(&refund).Error; err != nil {
+			return err
+		}
+
+		return audit(tx, &userID, services.AuditRefundRequested, "refunds", refund.ID,
+			map[string]any{"order_id": orderID, "reason": reason})
+	})
+	if err != nil {
+		return models.Refund{}, err
+	}
+
+	return refund, nil
+}
+
+// Decide approves or rejects the requested refund, or retries the failed one. The payment
+// is returned before the enrollment is revoked, so a failed provider refund keeps the
+// learner enrolled. The returned payment is recorded as processing before the order is
+// refunded, so approving a processing refund again only completes the order refund, and
+// approving an approved refund interrupted before that asks the provider again.
+func (s *RefundService) Decide(ctx context.Context, adminID uint, decision services.RefundDecision) error {
+	if err := requireAdmin(s.db.WithContext(ctx), adminID); err != nil {
+		return err
+	}
+
+	var refund models.Refund
+	if err := s.db.WithContext(ctx).Preload("Order").First(&refund, "id = ?", decision.RefundID).Error; err != nil {
+		return wrapNotFound(err, "refund %d", decision.RefundID)
+	}
+
+	if refund.Status == services.RefundStatusProcessing && decision.Approve {
+		return s.complete(ctx, adminID, refund)
+	}
+	if refund.Status == services.RefundStatusApproved && decision.Approve {
+		return s.returnPayment(ctx, adminID, refund)
+	}
+
+	status := services.RefundStatusRejected
+	action := services.AuditRefundRejected
+	if decision.Approve {
+		status = services.RefundStatusApproved
+		action = services.AuditRefundApproved
+	}
+
+	// the status is switched atomically, so the payment is never refunded twice
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Refund{}).
+			Where("id = ? AND status IN ?", refund.ID, []string{services.RefundStatusRequested, services.RefundStatusFailed}).
+			Updates(map[string]interface{}{
+				"Status":     status,
+				"ReviewerID": adminID,
+				"Note":       decision.Note,
+				"DecidedAt":  time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return services.Errorf(services.ErrConflict, "refund %d is already %s", refund.ID, refund.Status)
+		}
+
+		return audit(tx, &adminID, action, "refunds", refund.ID, map[string]any{"note": decision.Note})
+	})
+	if err != nil || !decision.Approve {
+		return err
+	}
+
+	return s.returnPayment(ctx, adminID, refund)
+}
+
+// returnPayment returns the payment of the approved refund with its provider, records it
+// as processing and completes the refund. The providers return a payment once, so asking
+// again for an approved refund left by an interrupted Decide returns the existing refund.
+func (s *RefundService) returnPayment(ctx context.Context, adminID uint, refund models.Refund) error {
+	reference, refundErr := s.refundPayment(ctx, refund.Order)
+	if refundErr != nil {
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&models.Refund{}).
+				Where("id = ? AND status = ?", refund.ID, services.RefundStatusApproved).
+				Update("status", services.RefundStatusFailed)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			return audit(tx, &adminID, services.AuditRefundFailed, "refunds", refund.ID, map[string]any{"error": refundErr.Error()})
+		})
+		return errors.Join(refundErr, err)
+	}
+
+	result := s.db.WithContext(ctx).Model(&models.Refund{}).
+		Where("id = ? AND status = ?", refund.ID, services.RefundStatusApproved).
+		Updates(map[string]interface{}{"Status": services.RefundStatusProcessing, "Reference": reference})
+	if result.Error != nil {
+		return fmt.Errorf("error recording the returned payment %s of refund %d: %w", reference, refund.ID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return services.Errorf(services.ErrConflict, "refund %d is no longer %s", refund.ID, services.RefundStatusApproved)
+	}
+	refund.Reference = reference
+
+	return s.complete(ctx, adminID, refund)
+}
+
+// complete refunds the order of the processing refund whose payment has been returned. The
+// status is switched in the same transaction, so the order is refunded exactly once.
+func (s *RefundService) complete(ctx context.Context, adminID uint, refund models.Refund) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Refund{}).
+			Where("id = ? AND status = ?", refund.ID, services.RefundStatusProcessing).
+			Update("status", services.RefundStatusRefunded)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return services.Errorf(services.ErrConflict, "refund %d is no longer %s", refund.ID, services.RefundStatusProcessing)
+		}
+
+		if err := tx.Model(&refund.Order).Update("status", services.OrderStatusRefunded).Error; err != nil {
+			return err
+		}
+
+		err := audit(tx, &adminID, services.AuditOrderRefunded, "orders", refund.OrderID, map[string]any{
+			"refund_id": refund.ID, "amount": refund.Order.Amount, "currency": refund.Order.Currency,
+			"reference": refund.Reference,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := postRefund(tx, &adminID, refund.Order); err != nil {
+			return err
+		}
+
+		if err := creditOrder(tx, refund.Order); err != nil {
+			return err
+		}
+
+		return revokeEnrollment(tx, &adminID, refund.UserID, refund.Order.CourseID, fmt.Sprintf("refund %d", refund.ID))
+	})
+}
+
+// refundPayment returns the payment of the order with its provider and returns the
+// reference of the refund.
+func (s *RefundService) refundPayment(ctx context.Context, order models.Order) (string, error) {
+	provider := s.options.Provider
+	if provider == nil || provider.Name() != order.Provider {
+		return "", fmt.Errorf("payment provider %s of order %d is not available", order.Provider, order.ID)
+	}
+
+	return provider.Refund(ctx, payments.Refund{
+		OrderID:   order.ID,
+		Reference: order.Reference,
+		Amount:    order.Amount,
+		Currency:  order.Currency,
+	})
+}
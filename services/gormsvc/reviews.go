@@ -0,0 +1,235 @@
+package gormsvc
+
+import (
+	"context"
+	"errors"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// ReviewService is the GORM implementation of services.ReviewService.
+type ReviewService struct {
+	db *gorm.DB
+}
+
+// NewReviewService creates a new ReviewService.
+func NewReviewService(db *gorm.DB) *ReviewService {
+	return &ReviewService{db: db}
+}
+
+// List returns a page of the visible reviews matching the filter with their users.
+func (s *ReviewService) List(ctx context.Context, filter services.ReviewFilter, page services.Page) ([]models.CourseReview, services.PageInfo, error) {
+	filter.Flagged = nil
+	filter.Hidden = new(bool)
+
+	return paginate[models.CourseReview](filterReviews(s.db.WithContext(ctx), filter).Preload("User"), page, services.ReviewSortFields)
+}
+
+// Moderation returns a page of the reviews matching the filter, hidden ones included.
+func (s *ReviewService) Moderation(ctx context.Context, moderatorID uint, filter services.ReviewFilter, page services.Page) ([]models.CourseReview, services.PageInfo, error) {
+	if err := requireAdmin(s.db.WithContext(ctx), moderatorID); err != nil {
+		return nil, services.PageInfo{}, err
+	}
+
+	return paginate[models.CourseReview](filterReviews(s.db.WithContext(ctx), filter).Preload("User"), page, services.ReviewSortFields)
+}
+
+// filterReviews adds the conditions of the filter to the query.
+func filterReviews(query *gorm.DB, filter services.ReviewFilter) *gorm.DB {
+	if len(filter.IDs) > 0 {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+
+	if filter.CourseID != 0 {
+		query = query.Where("course_id = ?", filter.CourseID)
+	}
+
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+
+	if len(filter.Ratings) > 0 {
+		query = query.Where("rating IN ?", filter.Ratings)
+	}
+
+	if filter.Flagged != nil {
+		query = query.Where("flagged = ?", *filter.Flagged)
+	}
+
+	if filter.Hidden != nil {
+		query = query.Where("hidden = ?", *filter.Hidden)
+	}
+
+	return query
+}
+
+// Create creates the review of the course the user is enrolled into.
+func (s *ReviewService) Create(ctx context.Context, userID uint, input services.ReviewInput) (models.CourseReview, error) {
+	if err := services.ValidateReview(&input); err != nil {
+		return models.CourseReview{}, err
+	}
+
+	var enrollment models.Enrollment
+	err := s.db.WithContext(ctx).First(&enrollment, "user_id = ? AND course_id = ?", userID, input.CourseID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.CourseReview{}, services.Errorf(services.ErrForbidden, "only enrolled learners can review the course")
+	}
+	if err != nil {
+		return models.CourseReview{}, err
+	}
+
+	review := models.CourseReview{
+		UserID:   userID,
+		CourseID: input.CourseID,
+		Rating:   input.Rating,
+		Text:     input.Text,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.CourseReview{}).Where("user_id = ? AND course_id = ?", userID, input.CourseID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return services.Errorf(services.ErrConflict, "course %d already reviewed", input.CourseID)
+		}
+
+		if err := tx.Create(&review).Error; err != nil {
+			return err
+		}
+
+		return refreshRating(tx, input.CourseID)
+	})
+	if err != nil {
+		return models.CourseReview{}, err
+	}
+
+	return review, nil
+}
+
+// Update updates the review of the course written by the user.
+func (s *ReviewService) Update(ctx context.Context, userID uint, input services.ReviewInput) error {
+	if err := services.ValidateReview(&input); err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var review models.CourseReview
+		if err := tx.First(&review, "user_id = ? AND course_id = ?", userID, input.CourseID).Error; err != nil {
+			return wrapNotFound(err, "review of course %d", input.CourseID)
+		}
+
+		err := tx.Model(&review).Updates(map[string]interface{}{
+			"Rating": input.Rating,
+			"Text":   input.Text,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return refreshRating(tx, input.CourseID)
+	})
+}
+
+// Reply sets the reply of the instructor of the reviewed course.
+func (s *ReviewService) Reply(ctx context.Context, instructorID, reviewID uint, reply string) error {
+	var review models.CourseReview
+	if err := s.db.WithContext(ctx).Preload("Course").First(&review, "id = ?", reviewID).Error; err != nil {
+		return wrapNotFound(err, "review %d", reviewID)
+	}
+
+	if review.Course.InstructorID != instructorID {
+		return services.Errorf(services.ErrForbidden, "only the course instructor can reply to the review")
+	}
+
+	if len(reply) > 65000 {
+		return services.Errorf(services.ErrInvalidInput, "reply is too long")
+	}
+
+	var repliedAt *time.Time
+	if reply != "" {
+		now := time.Now()
+		repliedAt = &now
+	}
+
+	return s.db.WithContext(ctx).Model(&review).Updates(map[string]interface{}{
+		"Reply":     reply,
+		"RepliedAt": repliedAt,
+	}).Error
+}
+
+// Vote adds or removes the helpful vote of the user for a review of another user.
+func (s *ReviewService) Vote(ctx context.Context, userID, reviewID uint, helpful bool) error {
+	var review models.CourseReview
+	if err := s.db.WithContext(ctx).First(&review, "id = ? AND hidden = ?", reviewID, false).Error; err != nil {
+		return wrapNotFound(err, "review %d", reviewID)
+	}
+
+	if review.UserID == userID {
+		return services.Errorf(services.ErrForbidden, "users cannot vote for their own reviews")
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		vote := models.CourseReviewVote{ReviewID: reviewID, UserID: userID}
+
+		var err error
+		if helpful {
+			err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&vote).Error
+		} else {
+			err = tx.Delete(&vote).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.CourseReview{}).Where("id = ?", reviewID).
+			UpdateColumn("helpful_count", tx.Model(&models.CourseReviewVote{}).Select("COUNT(*)").Where("review_id = ?", reviewID)).Error
+	})
+}
+
+// Moderate flags or hides a review.
+func (s *ReviewService) Moderate(ctx context.Context, moderatorID uint, input services.ReviewModeration) error {
+	if err := requireAdmin(s.db.WithContext(ctx), moderatorID); err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var review models.CourseReview
+		if err := tx.First(&review, "id = ?", input.ReviewID).Error; err != nil {
+			return wrapNotFound(err, "review %d", input.ReviewID)
+		}
+
+		err := tx.Model(&review).Updates(map[string]interface{}{
+			"Flagged": input.Flagged,
+			"Hidden":  input.Hidden,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return refreshRating(tx, review.CourseID)
+	})
+}
+
+// refreshRating recalculates the ratings of the course from its visible reviews.
+func refreshRating(tx *gorm.DB, courseID uint) error {
+	var ratings []uint
+	err := tx.Model(&models.CourseReview{}).
+		Where("course_id = ? AND hidden = ?", courseID, false).
+		Pluck("rating", &ratings).Error
+	if err != nil {
+		return err
+	}
+
+	average, distribution := services.RatingSummary(ratings)
+
+	// the cached ratings are not a change of the course, so updated_at is kept
+	return tx.Model(&models.Course{}).Where("id = ?", courseID).UpdateColumns(map[string]interface{}{
+		"rating_average":      average,
+		"rating_count":        len(ratings),
+		"rating_distribution": distribution,
+	}).Error
+}
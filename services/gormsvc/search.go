@@ -0,0 +1,171 @@
+package gormsvc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"strings"
+)
+
+// headlineOptions are the ts_headline options of the PostgreSQL snippets.
+var headlineOptions = fmt.Sprintf(`StartSel=%s, StopSel=%s, MinWords=10, MaxWords=30, MaxFragments=2, FragmentDelimiter=" … "`,
+	services.SnippetStart, services.SnippetStop)
+
+// searchQuery holds the dialect-specific SQL of a course search.
+type searchQuery struct {
+	// ranked selects the id and rank of the matching courses.
+	ranked string
+	args   []any
+	// snippets selects the id and snippet of the courses with the given ids (the last argument).
+	snippets     string
+	snippetsArgs []any
+}
+
+// postgresSearch matches the prefixes of all terms against the tsvector of the courses. Titles
+// similar to the query (trigram word similarity) match as well, catching typos.
+func postgresSearch(query string, terms []string) searchQuery {
+	tsquery := strings.Join(terms, ":* & ") + ":*"
+
+	return searchQuery{
+		ranked: `SELECT c.id, (ts_rank(c.search_vector, to_tsquery('plaja_uk', ?)) + word_similarity(?, coalesce(c.title, '')))::float8 AS rank
+			FROM courses c
+			WHERE c.status_id = ? AND (c.search_vector @@ to_tsquery('plaja_uk', ?) OR ? <% c.title)`,
+		args: []any{tsquery, query, services.CourseStatusPublished, tsquery, query},
+		snippets: `SELECT id, ts_headline('plaja_uk', concat_ws(' ', title, short_description, description), to_tsquery('plaja_uk', ?), ?) AS snippet
+			FROM courses
+			WHERE id IN ?`,
+		snippetsArgs: []any{tsquery, headlineOptions},
+	}
+}
+
+// sqliteSearch matches the prefixes of all terms against the FTS5 index of the courses.
+// There is no typo tolerance on SQLite.
+func sqliteSearch(terms []string) searchQuery {
+	match := `"` + strings.Join(terms, `"* "`) + `"*`
+
+	return searchQuery{
+		ranked: `SELECT c.id, -bm25(course_search, 10.0, 5.0, 3.0, 1.0) AS rank
+			FROM course_search JOIN courses c ON c.id = course_search.rowid
+			WHERE course_search MATCH ? AND c.status_id = ?`,
+		args: []any{match, services.CourseStatusPublished},
+		snippets: `SELECT rowid AS id, snippet(course_search, -1, ?, ?, '…', 16) AS snippet
+			FROM course_search
+			WHERE course_search MATCH ? AND rowid IN ?`,
+		snippetsArgs: []any{services.SnippetStart, services.SnippetStop, match},
+	}
+}
+
+// searchRow is a ranked search match.
+type searchRow struct {
+	ID      uint
+	Rank    float64
+	Snippet string
+}
+
+// Search returns a page of the published courses matching the query, the most relevant first.
+// The cursor holds the rank and id of the last returned course.
+func (s *CourseService) Search(ctx context.Context, query string, page services.Page) ([]services.CourseSearchResult, services.PageInfo, error) {
+	var info services.PageInfo
+
+	if page.Sort != "" {
+		return nil, info, services.Errorf(services.ErrInvalidInput, "search results are sorted by relevance")
+	}
+
+	terms := services.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, info, services.Errorf(services.ErrInvalidInput, "empty search query")
+	}
+
+	cursor, err := page.DecodeCursor()
+	if err != nil {
+		return nil, info, err
+	}
+
+	var q searchQuery
+	if s.db.Dialector.Name() == "postgres" {
+		q = postgresSearch(query, terms)
+	} else {
+		q = sqliteSearch(terms)
+	}
+
+	db := s.db.WithContext(ctx)
+
+	if page.Count {
+		var total int64
+		if err := db.Raw("SELECT COUNT(*) FROM ("+q.ranked+") ranked", q.args...).Scan(&total).Error; err != nil {
+			return nil, info, err
+		}
+		info.Total = &total
+	}
+
+	sql := "SELECT id, rank FROM (" + q.ranked + ") ranked"
+	args := append([]any{}, q.args...)
+
+	if cursor != nil {
+		var rank float64
+		if err := json.Unmarshal(cursor.Value, &rank); err != nil || len(cursor.Key) != 1 {
+			return nil, info, services.Errorf(services.ErrInvalidInput, "invalid cursor")
+		}
+		sql += " WHERE rank < ? OR (rank = ? AND id > ?)"
+		args = append(args, rank, rank, cursor.Key[0])
+	}
+
+	size := page.Size()
+
+	var rows []searchRow
+	err = db.Raw(sql+" ORDER BY rank DESC, id LIMIT ?", append(args, size+1)...).Scan(&rows).Error
+	if err != nil {
+		return nil, info, err
+	}
+
+	if len(rows) > size {
+		rows = rows[:size]
+
+		last := rows[size-1]
+		value, _ := json.Marshal(last.Rank)
+		info.NextCursor = services.Cursor{Value: value, Key: []uint{last.ID}}.Encode()
+	}
+
+	if len(rows) == 0 {
+		return []services.CourseSearchResult{}, info, nil
+	}
+
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+
+	var snippets []searchRow
+	if err := db.Raw(q.snippets, append(q.snippetsArgs, ids)...).Scan(&snippets).Error; err != nil {
+		return nil, info, err
+	}
+
+	var courses []models.Course
+	err = db.Preload("Instructor").Preload("Level").Preload("Categories").Preload("Prices").Where("id IN ?", ids).Find(&courses).Error
+	if err != nil {
+		return nil, info, err
+	}
+
+	byID := make(map[uint]models.Course, len(courses))
+	for _, c := range courses {
+		byID[c.ID] = c
+	}
+
+	snippetByID := make(map[uint]string, len(snippets))
+	for _, s := range snippets {
+		snippetByID[s.ID] = s.Snippet
+	}
+
+	results := make([]services.CourseSearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, services.CourseSearchResult{
+			Course:  byID[row.ID],
+			Rank:    row.Rank,
+			Snippet: services.HighlightSnippet(snippetByID[row.ID]),
+		})
+	}
+
+	return results, info, nil
+}
@@ -0,0 +1,269 @@
+package gormsvc
+
+import (
+	"context"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/payments"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// SubscriptionService is the GORM implementation of services.SubscriptionService.
+type SubscriptionService struct {
+	db      *gorm.DB
+	options PaymentOptions
+}
+
+// NewSubscriptionService creates a new SubscriptionService.
+func NewSubscriptionService(db *gorm.DB, options PaymentOptions) *SubscriptionService {
+	return &SubscriptionService{db: db, options: options}
+}
+
+// Plans returns the active plans, and the inactive ones to the admins.
+func (s *SubscriptionService) Plans(ctx context.Context, userID uint) ([]models.SubscriptionPlan, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Limit(1).Find(&user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+
+	query := s.db.WithContext(ctx).Order("price, id")
+	if user.UserTypeID != services.UserTypeAdmin {
+		query = query.Where("active = ?", true)
+	}
+
+	var plans []models.SubscriptionPlan
+	err := query.Find(&plans).Error
+	return plans, err
+}
+
+// CreatePlan creates a subscription plan in the currency of the payments.
+func (s *SubscriptionService) CreatePlan(ctx context.Context, adminID uint, input services.PlanInput) (models.SubscriptionPlan, error) {
+	if err := services.ValidatePlan(&input); err != nil {
+		return models.SubscriptionPlan{}, err
+	}
+
+	if err := requireAdmin(s.db.WithContext(ctx), adminID); err != nil {
+		return models.SubscriptionPlan{}, err
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.SubscriptionPlan{}).Where("code = ?", input.Code).Count(&count).Error; err != nil {
+		return models.SubscriptionPlan{}, err
+	}
+	if count > 0 {
+		return models.SubscriptionPlan{}, services.Errorf(services.ErrConflict, "plan %s already exists", input.Code)
+	}
+
+	plan := models.SubscriptionPlan{
+		Code:     input.Code,
+		Name:     strings.TrimSpace(input.Name),
+		Interval: input.Interval,
+		Price:    input.Price,
+		Currency: s.options.Currency,
+		Active:   true,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&plan).Error; err != nil {
+		return models.SubscriptionPlan{}, err
+	}
+
+	return plan, nil
+}
+
+// UpdatePlan updates the name, the price and the availability of a plan.
+func (s *SubscriptionService) UpdatePlan(ctx context.Context, adminID uint, update services.PlanUpdate) error {
+	if err := services.ValidatePlanUpdate(update); err != nil {
+		return err
+	}
+
+	if err := requireAdmin(s.db.WithContext(ctx), adminID); err != nil {
+		return err
+	}
+
+	var plan models.SubscriptionPlan
+	if err := s.db.WithContext(ctx).First(&plan, "id = ?", update.PlanID).Error; err != nil {
+		return wrapNotFound(err, "plan %d", update.PlanID)
+	}
+
+	return s.db.WithContext(ctx).Model(&plan).Updates(map[string]interface{}{
+		"Name":   strings.TrimSpace(update.Name),
+		"Price":  update.Price,
+		"Active": update.Active,
+	}).Error
+}
+
+// List returns a page of the subscriptions of the user with their current status.
+func (s *SubscriptionService) List(ctx context.Context, userID uint, page services.Page) ([]models.Subscription, services.PageInfo, error) {
+	query := s.db.WithContext(ctx).Preload("Plan").Where("user_id = ?", userID)
+
+	subscriptions, info, err := paginate[models.Subscription](query, page, services.SubscriptionSortFields)
+	if err != nil {
+		return nil, services.PageInfo{}, err
+	}
+
+	now := time.Now()
+	for i := range subscriptions {
+		subscriptions[i].Status = services.SubscriptionState(subscriptions[i], now, s.options.GracePeriod)
+	}
+
+	return subscriptions, info, nil
+}
+
+// Subscribe creates the subscription of the user to the plan, or reuses the pending one,
+// and its checkout session.
+func (s *SubscriptionService) Subscribe(ctx context.Context, userID, planID uint) (services.SubscriptionCheckout, error) {
+	var plan models.SubscriptionPlan
+	if err := s.db.WithContext(ctx).First(&plan, "id = ? AND active = ?", planID, true).Error; err != nil {
+		return services.SubscriptionCheckout{}, wrapNotFound(err, "plan %d", planID)
+	}
+
+	current, err := entitledSubscription(s.db.WithContext(ctx), userID, s.options.GracePeriod)
+	if err != nil {
+		return services.SubscriptionCheckout{}, err
+	}
+	if current != nil {
+		return services.SubscriptionCheckout{}, services.Errorf(services.ErrConflict, "already subscribed until %s", current.CurrentPeriodEnd.Format(time.DateOnly))
+	}
+
+	provider := s.options.Provider
+	if provider == nil {
+		return services.SubscriptionCheckout{}, services.Errorf(services.ErrInvalidInput, "payments are not available")
+	}
+
+	// a pending subscription of the same plan and price is paid again rather than duplicated
+	subscription := models.Subscription{
+		UserID:   userID,
+		PlanID:   plan.ID,
+		Status:   services.SubscriptionStatusPending,
+		Amount:   plan.Price,
+		Currency: plan.Currency,
+		Provider: provider.Name(),
+	}
+	err = s.db.WithContext(ctx).
+		Where(map[string]interface{}{
+			"user_id":  userID,
+			"plan_id":  plan.ID,
+			"status":   services.SubscriptionStatusPending,
+			"amount":   plan.Price,
+			"provider": subscription.Provider,
+		}).
+		FirstOrCreate(&subscription).Error
+	if err != nil {
+		return services.SubscriptionCheckout{}, err
+	}
+
+	session, err := provider.CreateCheckout(ctx, payments.Checkout{
+		OrderID:     subscription.ID,
+		Kind:        payments.KindSubscription,
+		Amount:      subscription.Amount,
+		Currency:    subscription.Currency,
+		Description: fmt.Sprintf("Підписка «%s»", plan.Name),
+		ResultURL:   s.options.ResultURL,
+		CallbackURL: s.options.CallbackURL,
+		Interval:    plan.Interval,
+	})
+	if err != nil {
+		return services.SubscriptionCheckout{}, fmt.Errorf("error creating the checkout of subscription %d: %v", subscription.ID, err)
+	}
+
+	subscription.Reference = session.Reference
+	if err := s.db.WithContext(ctx).Model(&subscription).Update("reference", subscription.Reference).Error; err != nil {
+		return services.SubscriptionCheckout{}, err
+	}
+	subscription.Plan = plan
+
+	return services.SubscriptionCheckout{Subscription: subscription, URL: session.URL}, nil
+}
+
+// Cancel stops the renewals of the subscription of the user at the payment provider.
+func (s *SubscriptionService) Cancel(ctx context.Context, userID, subscriptionID uint) error {
+	var subscription models.Subscription
+	err := s.db.WithContext(ctx).First(&subscription, "id = ? AND user_id = ?", subscriptionID, userID).Error
+	if err != nil {
+		return wrapNotFound(err, "subscription %d", subscriptionID)
+	}
+
+	now := time.Now()
+	if err := services.CheckCancelable(subscription, now, s.options.GracePeriod); err != nil {
+		return err
+	}
+
+	if provider := s.options.Provider; provider != nil && provider.Name() == subscription.Provider && subscription.Reference != "" {
+		if err := provider.CancelRecurring(ctx, subscription.Reference); err != nil {
+			return fmt.Errorf("error canceling subscription %d: %v", subscription.ID, err)
+		}
+	}
+
+	return s.db.WithContext(ctx).Model(&subscription).Updates(map[string]interface{}{
+		"Status":     services.SubscriptionStatusCanceled,
+		"CanceledAt": &now,
+	}).Error
+}
+
+// Entitled reports whether the user has a subscription granting access to the courses.
+func (s *SubscriptionService) Entitled(ctx context.Context, userID uint) (bool, error) {
+	subscription, err := entitledSubscription(s.db.WithContext(ctx), userID, s.options.GracePeriod)
+	return subscription != nil, err
+}
+
+// entitledSubscription returns the subscription of the user granting access to the courses,
+// or nil if there is none.
+func entitledSubscription(db *gorm.DB, userID uint, grace time.Duration) (*models.Subscription, error) {
+	var subscriptions []models.Subscription
+	err := db.Where("user_id = ? AND status IN ?", userID, []string{
+		services.SubscriptionStatusActive,
+		services.SubscriptionStatusPastDue,
+		services.SubscriptionStatusCanceled,
+	}).Order("current_period_end DESC").Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, subscription := range subscriptions {
+		if services.IsEntitled(subscription, now, grace) {
+			return &subscription, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// applySubscriptionPayment applies the payment webhook of a subscription: a successful
+// payment extends the subscription by a period and is invoiced, a failed one makes it
+// past due.
+func applySubscriptionPayment(tx *gorm.DB, provider string, seller services.Seller, n payments.Notification) error {
+	var subscription models.Subscription
+	if err := tx.Preload("Plan").First(&subscription, "id = ? AND provider = ?", n.OrderID, provider).Error; err != nil {
+		return wrapNotFound(err, "subscription %d", n.OrderID)
+	}
+
+	switch n.Status {
+	case payments.StatusSucceeded:
+		if n.Amount != subscription.Amount || !strings.EqualFold(n.Currency, subscription.Currency) {
+			return services.Errorf(services.ErrInvalidInput, "payment of %d %s does not match subscription %d", n.Amount, n.Currency, subscription.ID)
+		}
+
+		payment := services.RenewSubscription(&subscription, subscription.Plan.Interval, time.Now())
+		if err := tx.Create(&payment).Error; err != nil {
+			return err
+		}
+
+		if err := invoiceSubscriptionPayment(tx, seller, subscription, payment); err != nil {
+			return err
+		}
+	case payments.StatusFailed:
+		services.FailSubscription(&subscription)
+	default:
+		return nil
+	}
+
+	return tx.Model(&subscription).Updates(map[string]interface{}{
+		"Status":             subscription.Status,
+		"CurrentPeriodStart": subscription.CurrentPeriodStart,
+		"CurrentPeriodEnd":   subscription.CurrentPeriodEnd,
+	}).Error
+}
@@ -0,0 +1,43 @@
+package gormsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+)
+
+// Suggestions returns the function loading the autocomplete suggestions: the published
+// courses, their instructors and all categories.
+func Suggestions(db *gorm.DB) func(ctx context.Context) ([]services.Suggestion, error) {
+	return func(ctx context.Context) ([]services.Suggestion, error) {
+		var suggestions, rows []services.Suggestion
+		db := db.WithContext(ctx)
+
+		err := db.Table("courses").
+			Select("? AS kind, id, title AS text", services.SuggestionCourse).
+			Where("status_id = ?", services.CourseStatusPublished).
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, rows...)
+
+		err = db.Table("users").
+			Select("? AS kind, id, first_name || ' ' || last_name AS text", services.SuggestionInstructor).
+			Where("id IN (SELECT instructor_id FROM courses WHERE status_id = ?)", services.CourseStatusPublished).
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, rows...)
+
+		err = db.Table("course_categories").
+			Select("? AS kind, id, title AS text", services.SuggestionCategory).
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+
+		return append(suggestions, rows...), nil
+	}
+}
@@ -0,0 +1,187 @@
+package gormsvc
+
+import (
+	"context"
+	"fmt"
+	"github.com/plaja-app/back-end/events"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"github.com/plaja-app/back-end/storage"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"path"
+	"time"
+)
+
+// UserService is the GORM implementation of services.UserService.
+type UserService struct {
+	db     *gorm.DB
+	store  storage.Storage
+	events *events.Bus
+}
+
+// NewUserService creates a new UserService publishing the user changes on the bus.
+func NewUserService(db *gorm.DB, store storage.Storage, bus *events.Bus) *UserService {
+	return &UserService{db: db, store: store, events: bus}
+}
+
+// Get returns the user with the given ID, including the user type.
+func (s *UserService) Get(ctx context.Context, id uint) (models.User, error) {
+	var user models.User
+	err := s.db.WithContext(ctx).Preload("UserType").First(&user, "id = ?", id).Error
+	return user, wrapNotFound(err, "user %d", id)
+}
+
+// List returns a page of the users with the given IDs, or of all users if ids is empty.
+func (s *UserService) List(ctx context.Context, ids []uint, page services.Page) ([]models.User, services.PageInfo, error) {
+	query := s.db.WithContext(ctx).Preload("UserType")
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+
+	return paginate[models.User](query, page, services.UserSortFields)
+}
+
+// SignUp registers a new learner.
+func (s *UserService) SignUp(ctx context.Context, input services.SignUpInput) (models.User, error) {
+	if err := services.ValidateSignUp(input); err != nil {
+		return models.User{}, err
+	}
+
+	// hash password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), 10)
+	if err != nil {
+		return models.User{}, fmt.Errorf("error hashing password: %v", err)
+	}
+
+	user := models.User{
+		FirstName:  input.FirstName,
+		LastName:   input.LastName,
+		Email:      input.Email,
+		Password:   string(hashedPassword),
+		UserTypeID: services.UserTypeLearner,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&user).Error; err != nil {
+		return models.User{}, services.Errorf(services.ErrConflict, "error creating user")
+	}
+
+	s.events.Publish(ctx, events.Event{Topic: events.UserCreated, ID: user.ID})
+
+	return user, nil
+}
+
+// Authenticate returns the user with the given credentials.
+func (s *UserService) Authenticate(ctx context.Context, email, password string) (models.User, error) {
+	var user models.User
+	s.db.WithContext(ctx).First(&user, "email = ?", email)
+
+	if user.ID == 0 {
+		return models.User{}, services.Errorf(services.ErrUnauthorized, "invalid email")
+	}
+
+	// compare sent in password with saved user password hash
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return models.User{}, services.Errorf(services.ErrUnauthorized, "invalid password")
+	}
+
+	return user, nil
+}
+
+// UpdateProfile updates the general information of the user.
+func (s *UserService) UpdateProfile(ctx context.Context, userID uint, input services.ProfileUpdate) error {
+	if err := services.ValidateProfile(&input); err != nil {
+		return err
+	}
+
+	updateData := map[string]interface{}{
+		"FirstName": input.FirstName,
+		"LastName":  input.LastName,
+		"Currency":  input.Currency,
+	}
+
+	if input.ProfilePic != nil {
+		filePath := path.Join("users/profile-pictures", fmt.Sprintf("%d-%s", userID, "pp.png"))
+		if err := s.store.Save(filePath, input.ProfilePic); err != nil {
+			return fmt.Errorf("failed to save the file: %v", err)
+		}
+		updateData["ProfilePic"] = filePath
+	}
+
+	if err := s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Updates(updateData).Error; err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, events.Event{Topic: events.UserUpdated, ID: userID})
+
+	return nil
+}
+
+// ApplyToTeach stores the teaching application and promotes the user to Educator.
+func (s *UserService) ApplyToTeach(ctx context.Context, userID uint, input services.TeachingApplicationInput) error {
+	now := time.Now()
+
+	application := models.TeachingApplication{
+		UserID:         userID,
+		Experience:     input.Experience,
+		Motivation:     input.Motivation,
+		PlatformChoice: input.PlatformChoice,
+		ApprovedAt:     &now, // applications are approved automatically
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&application).Error; err != nil {
+			return services.Errorf(services.ErrConflict, "error creating teaching application")
+		}
+
+		// never demote admins
+		return tx.Model(&models.User{}).
+			Where("id = ? AND user_type_id < ?", userID, services.UserTypeEducator).
+			Update("user_type_id", services.UserTypeEducator).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, events.Event{Topic: events.UserUpdated, ID: userID})
+
+	return nil
+}
+
+// AcceptInvitation sets the password of the user invited with the token.
+func (s *UserService) AcceptInvitation(ctx context.Context, token, password string) error {
+	if err := services.ValidatePassword(password); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 10)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %v", err)
+	}
+
+	var invitation models.Invitation
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the invitations to join an organization are accepted by the signed-in users instead
+		err := tx.First(&invitation, "token_hash = ? AND organization_id IS NULL AND accepted_at IS NULL AND expires_at > ?",
+			services.HashInvitationToken(token), time.Now()).Error
+		if err != nil {
+			return wrapNotFound(err, "invitation")
+		}
+
+		err = tx.Model(&models.User{}).Where("id = ?", invitation.UserID).Update("password", string(hashedPassword)).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Invitation{}).
+			Where("user_id = ? AND organization_id IS NULL AND accepted_at IS NULL", invitation.UserID).
+			Update("accepted_at", time.Now()).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, events.Event{Topic: events.UserUpdated, ID: invitation.UserID})
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package gormsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WishlistService is the GORM implementation of services.WishlistService.
+type WishlistService struct {
+	db *gorm.DB
+}
+
+// NewWishlistService creates a new WishlistService.
+func NewWishlistService(db *gorm.DB) *WishlistService {
+	return &WishlistService{db: db}
+}
+
+// List returns a page of the courses in the wishlist of the user with the instructor, level and categories.
+func (s *WishlistService) List(ctx context.Context, userID uint, page services.Page) ([]models.Course, services.PageInfo, error) {
+	query := s.db.WithContext(ctx).Model(&models.Course{}).
+		Where("courses.id IN (?)", s.db.Model(&models.WishlistItem{}).Select("course_id").Where("user_id = ?", userID)).
+		Preload("Instructor").Preload("Level").Preload("Categories").Preload("Prices")
+
+	return paginate[models.Course](query, page, services.CourseSortFields)
+}
+
+// Add adds the published course to the wishlist of the user.
+func (s *WishlistService) Add(ctx context.Context, userID, courseID uint) error {
+	var course models.Course
+	err := s.db.WithContext(ctx).First(&course, "id = ? AND status_id = ?", courseID, services.CourseStatusPublished).Error
+	if err != nil {
+		return wrapNotFound(err, "course %d", courseID)
+	}
+
+	item := models.WishlistItem{UserID: userID, CourseID: courseID}
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&item).Error
+}
+
+// Remove removes the course from the wishlist of the user.
+func (s *WishlistService) Remove(ctx context.Context, userID, courseID uint) error {
+	return s.db.WithContext(ctx).Delete(&models.WishlistItem{UserID: userID, CourseID: courseID}).Error
+}
+
+// Contains returns the IDs of the given courses which are in the wishlist of the user.
+func (s *WishlistService) Contains(ctx context.Context, userID uint, courseIDs []uint) (map[uint]bool, error) {
+	contained := make(map[uint]bool)
+	if len(courseIDs) == 0 {
+		return contained, nil
+	}
+
+	var ids []uint
+	err := s.db.WithContext(ctx).Model(&models.WishlistItem{}).
+		Where("user_id = ? AND course_id IN ?", userID, courseIDs).
+		Pluck("course_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		contained[id] = true
+	}
+
+	return contained, nil
+}
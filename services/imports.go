@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// User import statuses. The pending imports wait for their rows to be processed in the
+// background; the failed ones stopped before processing all the rows.
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+// Results of the imported rows: a new user was created, an existing one was matched by
+// the email, an existing user who is not a member of the organization was invited to join
+// it, or the row was not imported.
+const (
+	ImportRowCreated = "created"
+	ImportRowMatched = "matched"
+	ImportRowPending = "pending"
+	ImportRowFailed  = "failed"
+)
+
+// MaxImportRows is the largest number of users imported from one file.
+const MaxImportRows = 5000
+
+// InvitationValidity is how long the set-password links of the invitations are valid.
+const InvitationValidity = 7 * 24 * time.Hour
+
+// ImportRow is a user read from the line of the import file.
+type ImportRow struct {
+	Line      uint
+	Email     string
+	FirstName string
+	LastName  string
+}
+
+// UserImportInput imports the users into the organization, or the platform if
+// OrganizationID is zero, and enrolls them into the courses.
+type UserImportInput struct {
+	OrganizationID uint
+	CourseIDs      []uint
+	Rows           []ImportRow
+}
+
+// UserImportService imports the users in bulk. The new users are invited to set their
+// password by email.
+type UserImportService interface {
+	// Start validates the import and processes its rows in the background. The managers
+	// import the learners of their organization, assigning them the courses and taking the
+	// seats of the paid ones; the existing users who are not members yet are only invited
+	// to join. The admins also import the users into the platform, enrolling them directly.
+	Start(ctx context.Context, userID uint, input UserImportInput) (models.UserImport, error)
+	// Get returns the progress of the import with the results of the processed rows to the
+	// user who started it and the admins.
+	Get(ctx context.Context, userID, importID uint) (models.UserImport, error)
+}
+
+// ParseImport parses the CSV file of the users. The header row names the email,
+// first_name and last_name columns, in any order; the other columns are ignored.
+func ParseImport(r io.Reader) ([]ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, Errorf(ErrInvalidInput, "import file is empty")
+	}
+	if err != nil {
+		return nil, Errorf(ErrInvalidInput, "invalid import file: %v", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"email", "first_name", "last_name"} {
+		if _, ok := columns[name]; !ok {
+			return nil, Errorf(ErrInvalidInput, "import file has no %s column", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i := columns[name]; i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	var rows []ImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, Errorf(ErrInvalidInput, "invalid import file: %v", err)
+		}
+
+		if len(rows) == MaxImportRows {
+			return nil, Errorf(ErrInvalidInput, "import file must have at most %d users", MaxImportRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, ImportRow{
+			Line:      uint(line),
+			Email:     field(record, "email"),
+			FirstName: field(record, "first_name"),
+			LastName:  field(record, "last_name"),
+		})
+	}
+
+	return rows, nil
+}
+
+// ValidateUserImport checks the import has users and removes the duplicate courses.
+func ValidateUserImport(input *UserImportInput) error {
+	if len(input.Rows) == 0 {
+		return Errorf(ErrInvalidInput, "import file has no users")
+	}
+	if len(input.Rows) > MaxImportRows {
+		return Errorf(ErrInvalidInput, "import file must have at most %d users", MaxImportRows)
+	}
+
+	var courseIDs []uint
+	seen := make(map[uint]bool)
+	for _, id := range input.CourseIDs {
+		if !seen[id] {
+			courseIDs = append(courseIDs, id)
+			seen[id] = true
+		}
+	}
+	input.CourseIDs = courseIDs
+
+	return nil
+}
+
+// ValidateImportRow normalizes and validates the user of the imported row.
+func ValidateImportRow(row *ImportRow) error {
+	row.Email = strings.ToLower(strings.TrimSpace(row.Email))
+	row.FirstName = strings.TrimSpace(row.FirstName)
+	row.LastName = strings.TrimSpace(row.LastName)
+
+	switch {
+	case !ValidateEmail(row.Email) || strings.ContainsAny(row.Email, "<> "):
+		return Errorf(ErrInvalidInput, "invalid email %q", row.Email)
+	case row.FirstName == "" || len(row.FirstName) > 255:
+		return Errorf(ErrInvalidInput, "first name must be 1 to 255 characters")
+	case row.LastName == "" || len(row.LastName) > 255:
+		return Errorf(ErrInvalidInput, "last name must be 1 to 255 characters")
+	}
+	return nil
+}
+
+// IsDomainError reports whether the error is one of the domain errors, e.g. ErrInvalidInput,
+// whose message may be shown to the users.
+func IsDomainError(err error) bool {
+	for _, kind := range []error{ErrNotFound, ErrInvalidInput, ErrUnauthorized, ErrForbidden, ErrConflict} {
+		if errors.Is(err, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportRowError returns the error reported for the row: the message of the domain errors,
+// or a generic one for the internal errors.
+func ImportRowError(err error) string {
+	if IsDomainError(err) {
+		return err.Error()
+	}
+	return "internal error"
+}
+
+// NewInvitationToken returns a random invitation token and its hash, which is stored in
+// place of the token.
+func NewInvitationToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("error generating invitation token: %v", err)
+	}
+
+	token = hex.EncodeToString(b)
+	return token, HashInvitationToken(token), nil
+}
+
+// HashInvitationToken returns the stored hash of the invitation token.
+func HashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// InvitationLink returns the set-password link of the invitation token on the page.
+func InvitationLink(page, token string) string {
+	u, err := url.Parse(page)
+	if err != nil {
+		return page + "?token=" + url.QueryEscape(token)
+	}
+
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// InvitationEmail returns the subject and the body of the email inviting the user to the
+// organization, or the platform if empty, with the set-password link.
+func InvitationEmail(user models.User, organization, link string) (subject, body string) {
+	subject = "Запрошення на Plaja"
+	invitation := "Вас запрошено навчатися на Plaja."
+	if organization != "" {
+		subject = fmt.Sprintf("Запрошення до %s на Plaja", organization)
+		invitation = fmt.Sprintf("%s запрошує вас навчатися на Plaja.", organization)
+	}
+
+	body = fmt.Sprintf("Вітаємо, %s!\n\n%s Щоб увійти, встановіть пароль за посиланням:\n\n%s\n\nПосилання дійсне %d днів.",
+		user.FirstName, invitation, link, int(InvitationValidity.Hours()/24))
+	return subject, body
+}
+
+// JoinEmail returns the subject and the body of the email inviting the existing user to
+// join the organization with the join link.
+func JoinEmail(user models.User, organization, link string) (subject, body string) {
+	subject = fmt.Sprintf("Запрошення до %s на Plaja", organization)
+	body = fmt.Sprintf("Вітаємо, %s!\n\n%s запрошує вас приєднатися до організації на Plaja. Щоб прийняти запрошення, увійдіть і перейдіть за посиланням:\n\n%s\n\nПосилання дійсне %d днів.",
+		user.FirstName, organization, link, int(InvitationValidity.Hours()/24))
+	return subject, body
+}
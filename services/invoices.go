@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Invoice kinds.
+const (
+	InvoiceKindInvoice    = "invoice"
+	InvoiceKindCreditNote = "credit_note"
+)
+
+// Seller holds the details of the seller printed on the invoices.
+type Seller struct {
+	Name    string
+	Address string
+	TaxID   string
+	Country string
+}
+
+// DefaultSeller is the seller of the in-memory services.
+var DefaultSeller = Seller{Name: "Plaja", Country: "UA"}
+
+// VATRates are the standard VAT rates of the digital services in hundredths of a percent
+// by the ISO 3166 country code of the buyer. The other countries are charged no VAT.
+var VATRates = map[string]uint{
+	"AT": 2000, "BE": 2100, "BG": 2000, "CY": 1900, "CZ": 2100, "DE": 1900, "DK": 2500,
+	"EE": 2200, "ES": 2100, "FI": 2550, "FR": 2000, "GB": 2000, "GR": 2400, "HR": 2500,
+	"HU": 2700, "IE": 2300, "IT": 2200, "LT": 2100, "LU": 1700, "LV": 2100, "MD": 2000,
+	"MT": 1800, "NL": 2100, "PL": 2300, "PT": 2300, "RO": 1900, "SE": 2500, "SI": 2200,
+	"SK": 2300, "UA": 2000,
+}
+
+// countryPattern matches the ISO 3166 country codes.
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// BillingInput is the update of the billing details of a user.
+type BillingInput struct {
+	Company string
+	TaxID   string
+	Address string
+	Country string
+}
+
+// InvoiceFilter selects the invoices to list. Zero values do not filter; a zero UserID
+// selects the current user.
+type InvoiceFilter struct {
+	UserID  uint
+	OrderID uint
+	Kinds   []string
+}
+
+// InvoiceService shows the invoices of the purchases and the credit notes of the refunds
+// to the buyers. The documents are issued by the order and refund services and rendered
+// to the private storage when first downloaded.
+type InvoiceService interface {
+	// List returns a page of the invoices of the user matching the filter, or of any user
+	// to the admins.
+	List(ctx context.Context, userID uint, filter InvoiceFilter, page Page) ([]models.Invoice, PageInfo, error)
+	// Document returns the invoice of the user, or of any user to the admins, with its PDF
+	// document. The document must be closed.
+	Document(ctx context.Context, userID, invoiceID uint) (models.Invoice, io.ReadCloser, error)
+	// BillingProfile returns the billing details of the user, empty if never set.
+	BillingProfile(ctx context.Context, userID uint) (models.BillingProfile, error)
+	// UpdateBillingProfile sets the billing details of the user used by the next invoices.
+	UpdateBillingProfile(ctx context.Context, userID uint, input BillingInput) (models.BillingProfile, error)
+}
+
+// InvoiceSortFields are the fields the invoices can be sorted by.
+var InvoiceSortFields = map[string]bool{
+	"id":        true,
+	"issued_at": true,
+}
+
+// ValidateBilling normalizes and validates the billing details.
+func ValidateBilling(input *BillingInput) error {
+	input.Company = strings.TrimSpace(input.Company)
+	input.TaxID = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(input.TaxID), " ", ""))
+	input.Address = strings.TrimSpace(input.Address)
+	input.Country = strings.ToUpper(strings.TrimSpace(input.Country))
+
+	if input.Country != "" && !countryPattern.MatchString(input.Country) {
+		return Errorf(ErrInvalidInput, "invalid country code %q", input.Country)
+	}
+
+	if len(input.Company) > 255 || len(input.Address) > 255 || len(input.TaxID) > 32 {
+		return Errorf(ErrInvalidInput, "billing details are too long")
+	}
+
+	if input.TaxID != "" && (input.Company == "" || input.Country == "") {
+		return Errorf(ErrInvalidInput, "tax ID requires the company name and country")
+	}
+
+	return nil
+}
+
+// VATRate returns the VAT rate of a sale to the buyer in the country of the buyer, or of
+// the seller if unknown. The sales to the businesses abroad are reverse charged.
+func VATRate(seller Seller, profile models.BillingProfile) (rate uint, reverseCharge bool) {
+	country := profile.Country
+	if country == "" {
+		country = seller.Country
+	}
+
+	if profile.TaxID != "" && country != seller.Country {
+		return 0, true
+	}
+
+	return VATRates[country], false
+}
+
+// SplitVAT splits the total including the VAT at the rate into the net amount and the VAT,
+// rounding the net amount to the nearest unit.
+func SplitVAT(total, rate uint) (net, vat uint) {
+	net = (total*10000*2 + 10000 + rate) / (2 * (10000 + rate))
+	return net, total - net
+}
+
+// InvoiceSeries returns the numbering series of the invoices of the kind issued at t, e.g.
+// "INV-2026" or "CN-2026" for the credit notes.
+func InvoiceSeries(kind string, t time.Time) string {
+	prefix := "INV"
+	if kind == InvoiceKindCreditNote {
+		prefix = "CN"
+	}
+	return fmt.Sprintf("%s-%d", prefix, t.UTC().Year())
+}
+
+// InvoiceNumber returns the number of the invoice issued n-th in the series.
+func InvoiceNumber(series string, n uint) string {
+	return fmt.Sprintf("%s-%06d", series, n)
+}
+
+// NewInvoice returns the unnumbered invoice of the purchase of the user at the total price
+// including the VAT.
+func NewInvoice(seller Seller, user models.User, profile models.BillingProfile, description, currency string, total uint, now time.Time) models.Invoice {
+	rate, reverseCharge := VATRate(seller, profile)
+	net, vat := SplitVAT(total, rate)
+
+	buyer := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if profile.Company != "" {
+		buyer = profile.Company
+	}
+
+	return models.Invoice{
+		Kind:          InvoiceKindInvoice,
+		UserID:        user.ID,
+		Description:   description,
+		Currency:      currency,
+		Net:           net,
+		VAT:           vat,
+		Total:         total,
+		VATRate:       rate,
+		ReverseCharge: reverseCharge,
+		SellerName:    seller.Name,
+		SellerAddress: seller.Address,
+		SellerTaxID:   seller.TaxID,
+		SellerCountry: seller.Country,
+		BuyerName:     buyer,
+		BuyerEmail:    user.Email,
+		BuyerAddress:  profile.Address,
+		BuyerTaxID:    profile.TaxID,
+		BuyerCountry:  profile.Country,
+		IssuedAt:      now,
+	}
+}
+
+// NewCreditNote returns the unnumbered credit note crediting the whole invoice.
+func NewCreditNote(invoice models.Invoice, now time.Time) models.Invoice {
+	note := invoice
+	note.ID = 0
+	note.Number = ""
+	note.Kind = InvoiceKindCreditNote
+	note.CreditedInvoiceID = &invoice.ID
+	note.StoragePath = ""
+	note.IssuedAt = now
+	note.CreatedAt = time.Time{}
+	return note
+}
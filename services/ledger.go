@@ -0,0 +1,371 @@
+package services
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Ledger transaction kinds.
+const (
+	LedgerSale   = "sale"
+	LedgerRefund = "refund"
+	LedgerPayout = "payout"
+)
+
+// Ledger accounts. The instructor payable account holds the earnings owed to the instructor
+// of the transaction.
+const (
+	AccountCash              = "cash"
+	AccountInstructorPayable = "instructor_payable"
+	AccountPlatformRevenue   = "platform_revenue"
+)
+
+// Earnings periods.
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// DefaultRevenueShare is the percentage of the sales paid to the instructors by default.
+const DefaultRevenueShare = 70
+
+// currencyPattern matches the ISO 4217 currency codes.
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// LedgerFilter selects the ledger transactions of an instructor to list. Zero values do not
+// filter; a zero InstructorID selects the current user.
+type LedgerFilter struct {
+	InstructorID uint
+	Kinds        []string
+	From         *time.Time
+	To           *time.Time
+}
+
+// EarningsFilter selects the period breakdown of the earnings of an instructor between
+// From (inclusive) and To (exclusive). A zero InstructorID selects the current user.
+type EarningsFilter struct {
+	InstructorID uint
+	From         time.Time
+	To           time.Time
+	Period       string
+}
+
+// EarningsPeriod sums up the ledger transactions of an instructor in a period and currency.
+// Sales and Refunds are the amounts paid and returned to the learners, Fees the net share
+// of the platform, Earnings the net share of the instructor and Payouts the amount paid
+// out to the instructor.
+type EarningsPeriod struct {
+	Start    time.Time
+	Currency string
+	Sales    int64
+	Refunds  int64
+	Fees     int64
+	Earnings int64
+	Payouts  int64
+}
+
+// Balance is the amount owed to an instructor in a currency.
+type Balance struct {
+	Currency string
+	Amount   int64
+}
+
+// Earnings is the current balance of an instructor with the period breakdown.
+type Earnings struct {
+	InstructorID uint
+	Balances     []Balance
+	Periods      []EarningsPeriod
+}
+
+// PayoutInput is the payout of the earnings to an instructor. A zero Amount pays out the
+// whole balance in the currency.
+type PayoutInput struct {
+	InstructorID uint
+	Amount       uint
+	Currency     string
+	Reference    string
+}
+
+// StatementLine is a ledger transaction on a statement. Amount is the amount paid,
+// returned or paid out, Fee the share of the platform and Earnings the change of the
+// instructor balance.
+type StatementLine struct {
+	TransactionID uint
+	Date          time.Time
+	Kind          string
+	OrderID       *uint
+	Description   string
+	Currency      string
+	Amount        int64
+	Fee           int64
+	Earnings      int64
+}
+
+// Statement is the monthly payout statement of an instructor.
+type Statement struct {
+	InstructorID uint
+	Instructor   string
+	Month        time.Time
+	Opening      []Balance
+	Closing      []Balance
+	Totals       []EarningsPeriod
+	Lines        []StatementLine
+}
+
+// LedgerService keeps the double-entry ledger of the instructor earnings. The sales and
+// the refunds are posted by the order and refund services; the instructors see their own
+// ledger and the admins see and pay out all of them.
+type LedgerService interface {
+	// Transactions returns a page of the ledger transactions with their entries.
+	Transactions(ctx context.Context, userID uint, filter LedgerFilter, page Page) ([]models.LedgerTransaction, PageInfo, error)
+	// Earnings returns the balance of the instructor and the breakdown of the earnings.
+	Earnings(ctx context.Context, userID uint, filter EarningsFilter) (Earnings, error)
+	// Statement returns the payout statement of the instructor for the month.
+	Statement(ctx context.Context, userID, instructorID uint, month time.Time) (Statement, error)
+	// Payout records the payout of the earnings to the instructor.
+	Payout(ctx context.Context, adminID uint, input PayoutInput) (models.LedgerTransaction, error)
+}
+
+// LedgerSortFields are the fields the ledger transactions can be sorted by.
+var LedgerSortFields = map[string]bool{
+	"id":         true,
+	"created_at": true,
+}
+
+// SaleEntries returns the entries of a sale of the amount split by the revenue share of the
+// instructor in percent. The platform keeps the rounding remainder.
+func SaleEntries(amount, share uint) []models.LedgerEntry {
+	earnings := amount * share / 100
+
+	return nonZeroEntries([]models.LedgerEntry{
+		{Account: AccountCash, Debit: amount},
+		{Account: AccountInstructorPayable, Credit: earnings},
+		{Account: AccountPlatformRevenue, Credit: amount - earnings},
+	})
+}
+
+// PayoutEntries returns the entries of a payout of the amount to an instructor.
+func PayoutEntries(amount uint) []models.LedgerEntry {
+	return []models.LedgerEntry{
+		{Account: AccountInstructorPayable, Debit: amount},
+		{Account: AccountCash, Credit: amount},
+	}
+}
+
+// ReverseEntries returns the entries undoing the given ones, e.g. of a refunded sale.
+func ReverseEntries(entries []models.LedgerEntry) []models.LedgerEntry {
+	reversed := make([]models.LedgerEntry, len(entries))
+	for i, e := range entries {
+		reversed[i] = models.LedgerEntry{Account: e.Account, Debit: e.Credit, Credit: e.Debit}
+	}
+	return reversed
+}
+
+// nonZeroEntries drops the entries moving no money.
+func nonZeroEntries(entries []models.LedgerEntry) []models.LedgerEntry {
+	var kept []models.LedgerEntry
+	for _, e := range entries {
+		if e.Debit != 0 || e.Credit != 0 {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// Balanced reports whether the debits of the entries equal their credits.
+func Balanced(entries []models.LedgerEntry) bool {
+	var debit, credit uint
+	for _, e := range entries {
+		debit += e.Debit
+		credit += e.Credit
+	}
+	return debit == credit
+}
+
+// accountChange returns the debits minus the credits of the account in the transaction.
+func accountChange(transaction models.LedgerTransaction, account string) int64 {
+	var change int64
+	for _, e := range transaction.Entries {
+		if e.Account == account {
+			change += int64(e.Debit) - int64(e.Credit)
+		}
+	}
+	return change
+}
+
+// InstructorChange returns the change of the instructor balance by the transaction.
+func InstructorChange(transaction models.LedgerTransaction) int64 {
+	return -accountChange(transaction, AccountInstructorPayable)
+}
+
+// ValidateEarningsFilter validates the period and the range of the earnings filter.
+func ValidateEarningsFilter(filter EarningsFilter) error {
+	switch filter.Period {
+	case PeriodDay, PeriodWeek, PeriodMonth:
+	default:
+		return Errorf(ErrInvalidInput, "unknown period %q", filter.Period)
+	}
+
+	if !filter.From.Before(filter.To) {
+		return Errorf(ErrInvalidInput, "earnings range must end after its start")
+	}
+
+	return nil
+}
+
+// ValidatePayout validates the payout input.
+func ValidatePayout(input PayoutInput) error {
+	if !currencyPattern.MatchString(input.Currency) {
+		return Errorf(ErrInvalidInput, "invalid currency %q", input.Currency)
+	}
+
+	if len(input.Reference) > 255 {
+		return Errorf(ErrInvalidInput, "payout reference is too long")
+	}
+
+	return nil
+}
+
+// PayoutAmount returns the amount of the payout, the whole balance for a zero amount, if the
+// balance of the instructor covers it.
+func PayoutAmount(balances []Balance, input PayoutInput) (uint, error) {
+	var balance int64
+	for _, b := range balances {
+		if b.Currency == input.Currency {
+			balance = b.Amount
+		}
+	}
+
+	amount := int64(input.Amount)
+	if amount == 0 {
+		amount = balance
+	}
+
+	switch {
+	case amount <= 0:
+		return 0, Errorf(ErrInvalidInput, "nothing to pay out in %s", input.Currency)
+	case amount > balance:
+		return 0, Errorf(ErrInvalidInput, "payout exceeds the balance of %d %s", balance, input.Currency)
+	}
+
+	return uint(amount), nil
+}
+
+// PeriodStart returns the start of the period containing t in UTC. The weeks start on Monday.
+func PeriodStart(t time.Time, period string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case PeriodWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case PeriodMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	default:
+		return day
+	}
+}
+
+// SummarizeEarnings sums up the transactions by period and currency, ordered by the
+// period start and the currency.
+func SummarizeEarnings(transactions []models.LedgerTransaction, period string) []EarningsPeriod {
+	type key struct {
+		start    time.Time
+		currency string
+	}
+
+	sums := make(map[key]*EarningsPeriod)
+	var periods []*EarningsPeriod
+
+	for _, t := range transactions {
+		k := key{PeriodStart(t.CreatedAt, period), t.Currency}
+		p, ok := sums[k]
+		if !ok {
+			p = &EarningsPeriod{Start: k.start, Currency: k.currency}
+			sums[k] = p
+			periods = append(periods, p)
+		}
+
+		cash := accountChange(t, AccountCash)
+		switch t.Kind {
+		case LedgerSale:
+			p.Sales += cash
+		case LedgerRefund:
+			p.Refunds -= cash
+		case LedgerPayout:
+			p.Payouts -= InstructorChange(t)
+			continue
+		}
+		p.Fees -= accountChange(t, AccountPlatformRevenue)
+		p.Earnings += InstructorChange(t)
+	}
+
+	sort.Slice(periods, func(i, j int) bool {
+		if !periods[i].Start.Equal(periods[j].Start) {
+			return periods[i].Start.Before(periods[j].Start)
+		}
+		return periods[i].Currency < periods[j].Currency
+	})
+
+	summary := make([]EarningsPeriod, len(periods))
+	for i, p := range periods {
+		summary[i] = *p
+	}
+	return summary
+}
+
+// AddBalance adds the amount to the balance in the currency, keeping the balances ordered
+// by currency.
+func AddBalance(balances []Balance, currency string, amount int64) []Balance {
+	for i := range balances {
+		if balances[i].Currency == currency {
+			balances[i].Amount += amount
+			return balances
+		}
+	}
+
+	balances = append(balances, Balance{Currency: currency, Amount: amount})
+	sort.Slice(balances, func(i, j int) bool { return balances[i].Currency < balances[j].Currency })
+	return balances
+}
+
+// BuildStatement returns the statement of the instructor for the month starting with the
+// opening balances and listing the transactions of the month in order.
+func BuildStatement(instructor models.User, month time.Time, opening []Balance, transactions []models.LedgerTransaction) Statement {
+	statement := Statement{
+		InstructorID: instructor.ID,
+		Instructor:   instructor.FirstName + " " + instructor.LastName,
+		Month:        PeriodStart(month, PeriodMonth),
+		Opening:      opening,
+		Totals:       SummarizeEarnings(transactions, PeriodMonth),
+	}
+
+	closing := append([]Balance(nil), opening...)
+	for _, t := range transactions {
+		line := StatementLine{
+			TransactionID: t.ID,
+			Date:          t.CreatedAt,
+			Kind:          t.Kind,
+			OrderID:       t.OrderID,
+			Description:   t.Description,
+			Currency:      t.Currency,
+			Fee:           -accountChange(t, AccountPlatformRevenue),
+			Earnings:      InstructorChange(t),
+		}
+
+		line.Amount = accountChange(t, AccountCash)
+		if t.Kind != LedgerSale {
+			line.Amount = -line.Amount
+		}
+
+		statement.Lines = append(statement.Lines, line)
+		closing = AddBalance(closing, t.Currency, line.Earnings)
+	}
+	statement.Closing = closing
+
+	return statement
+}
@@ -0,0 +1,114 @@
+package memsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/certificates"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"sort"
+	"time"
+)
+
+// CertificateService is the in-memory implementation of services.CertificateService.
+// No certificate images are rendered.
+type CertificateService struct {
+	store *Store
+}
+
+// List returns a page of the certificates matching the filter with their courses.
+func (s *CertificateService) List(ctx context.Context, filter services.CertificateFilter, page services.Page) ([]models.CourseCertificate, services.PageInfo, error) {
+	field, desc, err := page.SortField(services.CertificateSortFields)
+	if err != nil {
+		return nil, services.PageInfo{}, err
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	var list []models.CourseCertificate
+	for _, c := range s.store.Certificates {
+		if containsID(filter.IDs, c.ID) &&
+			(filter.UserID == 0 || c.UserID == filter.UserID) &&
+			(filter.CourseID == 0 || c.CourseID == filter.CourseID) {
+			certificate := *c
+			if course, ok := s.store.Courses[c.CourseID]; ok {
+				certificate.Course = *course
+			}
+			list = append(list, certificate)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if desc {
+			a, b = b, a
+		}
+		if field == "created_at" && !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	return paginate(list, func(c models.CourseCertificate) []uint { return []uint{c.ID} }, page)
+}
+
+// Issue creates the certificate for the course completed by the user.
+func (s *CertificateService) Issue(ctx context.Context, userID, courseID uint) (models.CourseCertificate, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	course, ok := s.store.Courses[courseID]
+	if !ok {
+		return models.CourseCertificate{}, services.Errorf(services.ErrNotFound, "course %d", courseID)
+	}
+
+	if !course.HasCertificate {
+		return models.CourseCertificate{}, services.Errorf(services.ErrInvalidInput, "course does not grant a certificate")
+	}
+
+	var enrollment *models.Enrollment
+	for _, e := range s.store.Enrollments {
+		if e.UserID == userID && e.CourseID == courseID {
+			enrollment = e
+		}
+	}
+
+	if enrollment == nil {
+		return models.CourseCertificate{}, services.Errorf(services.ErrNotFound, "enrollment")
+	}
+
+	if !services.IsCompleted(*enrollment) {
+		return models.CourseCertificate{}, services.Errorf(services.ErrInvalidInput, "course is not completed")
+	}
+
+	for _, c := range s.store.Certificates {
+		if c.UserID == userID && c.CourseID == courseID && c.RevokedAt == nil {
+			return models.CourseCertificate{}, services.Errorf(services.ErrConflict, "certificate already exists")
+		}
+	}
+
+	certificate := &models.CourseCertificate{
+		ID:        s.store.id(),
+		UserID:    userID,
+		CourseID:  courseID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	s.store.Certificates = append(s.store.Certificates, certificate)
+	enrollment.StatusID = services.EnrollmentStatusCertificated
+
+	return *certificate, nil
+}
+
+// Regenerate returns the storage-relative path of the certificate.
+func (s *CertificateService) Regenerate(ctx context.Context, id uint) (string, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	for _, c := range s.store.Certificates {
+		if c.ID == id {
+			return certificates.Path(id), nil
+		}
+	}
+	return "", services.Errorf(services.ErrNotFound, "certificate %d", id)
+}
@@ -0,0 +1,424 @@
+package memsvc
+
+import (
+	"context"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"io"
+	"slices"
+	"sort"
+	"time"
+)
+
+// CourseService is the in-memory implementation of services.CourseService.
+type CourseService struct {
+	store *Store
+}
+
+// List returns a page of the courses matching the filter with the instructor, level and categories.
+func (s *CourseService) List(ctx context.Context, filter services.CourseFilter, page services.Page) ([]models.Course, services.PageInfo, error) {
+	if err := services.ValidateCourseSort(filter, page); err != nil {
+		return nil, services.PageInfo{}, err
+	}
+
+	field, desc, err := page.SortField(services.CourseSortFields)
+	if err != nil {
+		return nil, services.PageInfo{}, err
+	}
+
+	match, err := s.matcher(filter)
+	if err != nil {
+		return nil, services.PageInfo{}, err
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	var courses []models.Course
+	for _, c := range s.store.Courses {
+		if !match(c) {
+			continue
+		}
+
+		course := *c
+		if instructor, ok := s.store.Users[c.InstructorID]; ok {
+			course.Instructor = withType(instructor)
+		}
+		for _, l := range s.store.Levels {
+			if l.ID == c.LevelID {
+				course.Level = l
+			}
+		}
+		courses = append(courses, course)
+	}
+
+	sort.Slice(courses, func(i, j int) bool {
+		if desc {
+			return courseLess(courses[j], courses[i], field)
+		}
+		return courseLess(courses[i], courses[j], field)
+	})
+
+	return paginate(courses, func(c models.Course) []uint { return []uint{c.ID} }, page)
+}
+
+// courseLess compares the courses by the sort field, falling back to the ID.
+func courseLess(a, b models.Course, field string) bool {
+	switch field {
+	case "title":
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+	case "price":
+		if a.Price != b.Price {
+			return a.Price < b.Price
+		}
+	case "length":
+		if a.Length != b.Length {
+			return a.Length < b.Length
+		}
+	case "rating_average":
+		if a.RatingAverage != b.RatingAverage {
+			return a.RatingAverage < b.RatingAverage
+		}
+	case "rating_count":
+		if a.RatingCount != b.RatingCount {
+			return a.RatingCount < b.RatingCount
+		}
+	case "status_id":
+		if a.StatusID != b.StatusID {
+			return a.StatusID < b.StatusID
+		}
+	case "instructor_id":
+		if a.InstructorID != b.InstructorID {
+			return a.InstructorID < b.InstructorID
+		}
+	case "level_id":
+		if a.LevelID != b.LevelID {
+			return a.LevelID < b.LevelID
+		}
+	case "created_at":
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+	case "updated_at":
+		if !a.UpdatedAt.Equal(b.UpdatedAt) {
+			return a.UpdatedAt.Before(b.UpdatedAt)
+		}
+	}
+	return a.ID < b.ID
+}
+
+// enrolled reports whether the user is enrolled in the course. The caller holds the lock.
+func (s *CourseService) enrolled(userID, courseID uint) bool {
+	for _, e := range s.store.Enrollments {
+		if e.UserID == userID && e.CourseID == courseID {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the course with the given ID.
+func (s *CourseService) Get(ctx context.Context, id uint) (models.Course, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	c, ok := s.store.Courses[id]
+	if !ok {
+		return models.Course{}, services.Errorf(services.ErrNotFound, "course %d", id)
+	}
+	return *c, nil
+}
+
+// owned returns the course if it is owned by the instructor. The caller holds the lock.
+func (s *CourseService) owned(instructorID, courseID uint) (*models.Course, error) {
+	c, ok := s.store.Courses[courseID]
+	if !ok {
+		return nil, services.Errorf(services.ErrNotFound, "course %d", courseID)
+	}
+
+	if c.InstructorID != instructorID {
+		return nil, services.Errorf(services.ErrForbidden, "instructor not authorized to update this course")
+	}
+
+	return c, nil
+}
+
+// Create creates a draft course of the instructor.
+func (s *CourseService) Create(ctx context.Context, instructorID uint, input services.CourseInput) (models.Course, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	var categories []models.CourseCategory
+	for _, category := range s.store.Categories {
+		if len(input.CategoryIDs) > 0 && containsID(input.CategoryIDs, category.ID) {
+			categories = append(categories, category)
+		}
+	}
+
+	course := &models.Course{
+		ID:             s.store.id(),
+		Title:          input.Title,
+		Thumbnail:      "service/courses/no-thumbnail.png",
+		Categories:     categories,
+		LevelID:        input.LevelID,
+		StatusID:       services.CourseStatusDraft,
+		Currency:       currency,
+		HasCertificate: input.HasCertificate,
+		InstructorID:   instructorID,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	s.store.Courses[course.ID] = course
+
+	return *course, nil
+}
+
+// UpdateGeneral updates the general information of a course owned by the instructor.
+// The thumbnail content is discarded.
+func (s *CourseService) UpdateGeneral(ctx context.Context, instructorID uint, input services.CourseGeneralUpdate) error {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	c, err := s.owned(instructorID, input.CourseID)
+	if err != nil {
+		return err
+	}
+
+	// the sale and the price in the new currency make no sense after changing it
+	if input.Currency = services.NormalizeCurrency(input.Currency); input.Currency != "" && input.Currency != c.Currency {
+		if !services.ValidCurrency(input.Currency) {
+			return services.Errorf(services.ErrInvalidInput, "unsupported currency %q", input.Currency)
+		}
+		c.Currency = input.Currency
+		c.SalePrice, c.SaleStartsAt, c.SaleEndsAt = nil, nil, nil
+		c.Prices = slices.DeleteFunc(c.Prices, func(p models.CoursePrice) bool { return p.Currency == input.Currency })
+	}
+
+	c.Title = input.Title
+	c.ShortDescription = input.ShortDescription
+	c.Description = input.Description
+	c.Price = input.Price
+	if input.Thumbnail != nil {
+		io.Copy(io.Discard, input.Thumbnail)
+		c.Thumbnail = fmt.Sprintf("courses/thumbnails/%d-thumbnail.png", c.ID)
+	}
+	c.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// UpdateSale sets or ends the sale of a course owned by the instructor.
+func (s *CourseService) UpdateSale(ctx context.Context, instructorID uint, input services.SaleUpdate) error {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	c, err := s.owned(instructorID, input.CourseID)
+	if err != nil {
+		return err
+	}
+
+	if err := services.ValidateSale(*c, input); err != nil {
+		return err
+	}
+
+	if input.SalePrice == nil {
+		input.StartsAt, input.EndsAt = nil, nil
+	}
+
+	c.SalePrice = input.SalePrice
+	c.SaleStartsAt = input.StartsAt
+	c.SaleEndsAt = input.EndsAt
+
+	return nil
+}
+
+// UpdatePrices replaces the prices in the other currencies of a course owned by the instructor.
+func (s *CourseService) UpdatePrices(ctx context.Context, instructorID, courseID uint, prices []services.PriceInput) ([]models.CoursePrice, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	c, err := s.owned(instructorID, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := services.ValidatePrices(*c, prices); err != nil {
+		return nil, err
+	}
+
+	c.Prices = make([]models.CoursePrice, len(prices))
+	for i, p := range prices {
+		c.Prices[i] = models.CoursePrice{
+			ID:        s.store.id(),
+			CourseID:  courseID,
+			Currency:  p.Currency,
+			Amount:    p.Amount,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+	}
+
+	return slices.Clone(c.Prices), nil
+}
+
+// SaveExercises creates, updates and deletes the exercises of a course owned by the
+// instructor and recalculates the course length.
+func (s *CourseService) SaveExercises(ctx context.Context, instructorID uint, input services.ExercisesUpdate) error {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	c, err := s.owned(instructorID, input.CourseID)
+	if err != nil {
+		return err
+	}
+
+	for _, ex := range input.Exercises {
+		if ex.ID != 0 {
+			existing, ok := s.store.Exercises[ex.ID]
+			if !ok || existing.CourseID != c.ID {
+				return services.Errorf(services.ErrNotFound, "exercise %d", ex.ID)
+			}
+			existing.Title = ex.Title
+			existing.Content = ex.Content
+			existing.Length = services.CalculateExerciseLength(ex.Content)
+			existing.UpdatedAt = time.Now()
+		} else {
+			exercise := &models.CourseExercise{
+				ID:        s.store.id(),
+				CourseID:  c.ID,
+				TypeID:    1, // article
+				Title:     ex.Title,
+				Content:   ex.Content,
+				Length:    services.CalculateExerciseLength(ex.Content),
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+			}
+			s.store.Exercises[exercise.ID] = exercise
+		}
+	}
+
+	for _, id := range input.DeleteIDs {
+		if ex, ok := s.store.Exercises[id]; ok && ex.CourseID == c.ID {
+			delete(s.store.Exercises, id)
+		}
+	}
+
+	var length uint
+	for _, ex := range s.store.Exercises {
+		if ex.CourseID == c.ID {
+			length += ex.Length
+		}
+	}
+	c.Length = length
+
+	return nil
+}
+
+// Exercises returns the exercises of the course with the given IDs, or all of them if ids is empty.
+func (s *CourseService) Exercises(ctx context.Context, courseID uint, ids []uint) ([]models.CourseExercise, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	var exercises []models.CourseExercise
+	for _, ex := range s.store.Exercises {
+		if ex.CourseID == courseID && containsID(ids, ex.ID) {
+			exercises = append(exercises, *ex)
+		}
+	}
+
+	sort.Slice(exercises, func(i, j int) bool { return exercises[i].ID < exercises[j].ID })
+	return exercises, nil
+}
+
+// Categories returns a page of the categories with the given IDs, or of all of them if ids is empty.
+func (s *CourseService) Categories(ctx context.Context, ids []uint, page services.Page) ([]models.CourseCategory, services.PageInfo, error) {
+	field, desc, err := page.SortField(services.CategorySortFields)
+	if err != nil {
+		return nil, services.PageInfo{}, err
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	var categories []models.CourseCategory
+	for _, category := range s.store.Categories {
+		if containsID(ids, category.ID) {
+			categories = append(categories, category)
+		}
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		a, b := categories[i], categories[j]
+		if desc {
+			a, b = b, a
+		}
+		if field == "title" && a.Title != b.Title {
+			return a.Title < b.Title
+		}
+		return a.ID < b.ID
+	})
+
+	return paginate(categories, func(c models.CourseCategory) []uint { return []uint{c.ID} }, page)
+}
+
+// Levels returns the levels with the given IDs, or all of them if ids is empty.
+func (s *CourseService) Levels(ctx context.Context, ids []uint) ([]models.CourseLevel, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	var levels []models.CourseLevel
+	for _, level := range s.store.Levels {
+		if containsID(ids, level.ID) {
+			levels = append(levels, level)
+		}
+	}
+	return levels, nil
+}
+
+// CategoryStats returns the number of courses per category.
+func (s *CourseService) CategoryStats(ctx context.Context) ([]services.CategoryStat, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	var stats []services.CategoryStat
+	for _, category := range s.store.Categories {
+		stat := services.CategoryStat{Title: category.Title}
+		for _, c := range s.store.Courses {
+			for _, cc := range c.Categories {
+				if cc.ID == category.ID {
+					stat.CoursesCount++
+				}
+			}
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+// CategoryLevelStats returns the number of courses per category and level.
+func (s *CourseService) CategoryLevelStats(ctx context.Context) ([]services.CategoryLevelStat, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	var stats []services.CategoryLevelStat
+	for _, category := range s.store.Categories {
+		for _, level := range s.store.Levels {
+			stat := services.CategoryLevelStat{CategoryTitle: category.Title, LevelTitle: level.Title}
+			for _, c := range s.store.Courses {
+				if c.LevelID != level.ID {
+					continue
+				}
+				for _, cc := range c.Categories {
+					if cc.ID == category.ID {
+						stat.CoursesCount++
+					}
+				}
+			}
+			stats = append(stats, stat)
+		}
+	}
+	return stats, nil
+}
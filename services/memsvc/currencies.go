@@ -0,0 +1,56 @@
+package memsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CurrencyService is the in-memory implementation of services.CurrencyService.
+type CurrencyService struct {
+	store *Store
+}
+
+// Rates returns the current exchange rates.
+func (s *CurrencyService) Rates(ctx context.Context) ([]models.ExchangeRate, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	return slices.Clone(s.store.Rates), nil
+}
+
+// ImportRates replaces the exchange rates with the rates against the base currency.
+func (s *CurrencyService) ImportRates(ctx context.Context, userID uint, base string, rates []services.RateInput) ([]models.ExchangeRate, error) {
+	if err := s.store.requireAdmin(userID); err != nil {
+		return nil, err
+	}
+
+	if err := services.ValidateRates(&base, rates); err != nil {
+		return nil, err
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	now := time.Now()
+	s.store.Rates = make([]models.ExchangeRate, len(rates))
+	for i, r := range rates {
+		s.store.Rates[i] = models.ExchangeRate{Currency: r.Currency, Base: base, Rate: r.Rate, UpdatedAt: now}
+	}
+	slices.SortFunc(s.store.Rates, func(a, b models.ExchangeRate) int { return strings.Compare(a.Currency, b.Currency) })
+
+	s.store.audit(&userID, services.AuditRatesImported, "exchange_rates", base, map[string]any{"count": len(rates)})
+
+	return slices.Clone(s.store.Rates), nil
+}
+
+// audit appends the audit entry of the action. The store must be locked.
+func (s *Store) audit(actorID *uint, action, entity string, entityID any, details map[string]any) {
+	entry := services.NewAuditEntry(actorID, action, entity, entityID, details)
+	entry.ID = s.id()
+	entry.CreatedAt = time.Now()
+	s.AuditEntries = append(s.AuditEntries, entry)
+}
@@ -0,0 +1,143 @@
+package memsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"sort"
+	"time"
+)
+
+// EnrollmentService is the in-memory implementation of services.EnrollmentService.
+type EnrollmentService struct {
+	store *Store
+}
+
+// List returns a page of the enrollments matching the filter.
+func (s *EnrollmentService) List(ctx context.Context, filter services.EnrollmentFilter, page services.Page) ([]models.Enrollment, services.PageInfo, error) {
+	field, desc, err := page.SortField(services.EnrollmentSortFields)
+	if err != nil {
+		return nil, services.PageInfo{}, err
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	var enrollments []models.Enrollment
+	for _, e := range s.store.Enrollments {
+		if containsID(filter.UserIDs, e.UserID) &&
+			containsID(filter.StatusIDs, e.StatusID) &&
+			(filter.CourseID == 0 || e.CourseID == filter.CourseID) {
+			enrollments = append(enrollments, *e)
+		}
+	}
+
+	sort.Slice(enrollments, func(i, j int) bool {
+		a, b := enrollments[i], enrollments[j]
+		if desc {
+			a, b = b, a
+		}
+		switch {
+		case field == "progress" && a.Progress != b.Progress:
+			return a.Progress < b.Progress
+		case field == "created_at" && !a.CreatedAt.Equal(b.CreatedAt):
+			return a.CreatedAt.Before(b.CreatedAt)
+		case field == "updated_at" && !a.UpdatedAt.Equal(b.UpdatedAt):
+			return a.UpdatedAt.Before(b.UpdatedAt)
+		case a.UserID != b.UserID:
+			return a.UserID < b.UserID
+		}
+		return a.CourseID < b.CourseID
+	})
+
+	return paginate(enrollments, func(e models.Enrollment) []uint { return []uint{e.UserID, e.CourseID} }, page)
+}
+
+// Enroll enrolls the user into the free course, or into the paid published course through
+// the subscription of the user.
+func (s *EnrollmentService) Enroll(ctx context.Context, userID, courseID uint) (models.Enrollment, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	course, ok := s.store.Courses[courseID]
+	if !ok {
+		return models.Enrollment{}, services.Errorf(services.ErrNotFound, "course %d", courseID)
+	}
+
+	if err := services.RequirePrerequisites(s.store.checkPrerequisites(userID, *course)); err != nil {
+		return models.Enrollment{}, err
+	}
+
+	var subscription *models.Subscription
+	if course.Price > 0 {
+		if course.StatusID == services.CourseStatusPublished {
+			subscription = s.store.entitledSubscription(userID)
+		}
+		if subscription == nil {
+			return models.Enrollment{}, services.Errorf(services.ErrForbidden, "course %d is paid, buy it with the checkout or subscribe", courseID)
+		}
+	}
+
+	if s.store.enrollment(userID, courseID) != nil {
+		return models.Enrollment{}, services.Errorf(services.ErrInvalidInput, "error creating enrollment")
+	}
+
+	enrollment := s.store.enroll(userID, courseID)
+	if subscription != nil {
+		enrollment.SubscriptionID = &subscription.ID
+	}
+
+	return *enrollment, nil
+}
+
+// Access reports whether the user may see the content of the course.
+func (s *EnrollmentService) Access(ctx context.Context, userID, courseID uint) (bool, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	course, ok := s.store.Courses[courseID]
+	if !ok {
+		return false, services.Errorf(services.ErrNotFound, "course %d", courseID)
+	}
+
+	if course.Price == 0 || (userID != 0 && course.InstructorID == userID) {
+		return true, nil
+	}
+
+	if user, ok := s.store.Users[userID]; ok && user.UserTypeID == services.UserTypeAdmin {
+		return true, nil
+	}
+
+	return services.HasAccess(s.store.enrollment(userID, courseID), s.store.entitledSubscription(userID) != nil), nil
+}
+
+// enrollment returns the enrollment of the user into the course, or nil. The store must be locked.
+func (s *Store) enrollment(userID, courseID uint) *models.Enrollment {
+	for _, e := range s.Enrollments {
+		if e.UserID == userID && e.CourseID == courseID {
+			return e
+		}
+	}
+	return nil
+}
+
+// enroll enrolls the user into the course. The store must be locked.
+func (s *Store) enroll(userID, courseID uint) *models.Enrollment {
+	enrollment := services.NewEnrollment(userID, courseID)
+	enrollment.CreatedAt = time.Now()
+	enrollment.UpdatedAt = time.Now()
+	s.Enrollments = append(s.Enrollments, &enrollment)
+	return &enrollment
+}
+
+// entitledSubscription returns the subscription of the user granting access to the courses,
+// or nil. The store must be locked.
+func (s *Store) entitledSubscription(userID uint) *models.Subscription {
+	now := time.Now()
+	for _, sub := range s.Subscriptions {
+		if sub.UserID == userID && services.IsEntitled(*sub, now, services.DefaultGracePeriod) {
+			return sub
+		}
+	}
+	return nil
+}
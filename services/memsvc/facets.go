@@ -0,0 +1,123 @@
+package memsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+)
+
+// matcher returns the function matching the courses against the filter. The caller of
+// the returned function holds the lock.
+func (s *CourseService) matcher(filter services.CourseFilter) (func(c *models.Course) bool, error) {
+	prices, err := services.FindBuckets(services.PriceBuckets, filter.PriceBuckets)
+	if err != nil {
+		return nil, err
+	}
+
+	lengths, err := services.FindBuckets(services.LengthBuckets, filter.LengthBuckets)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *models.Course) bool {
+		return containsID(filter.IDs, c.ID) &&
+			(filter.StatusID == 0 || c.StatusID == filter.StatusID) &&
+			(filter.InstructorID == 0 || c.InstructorID == filter.InstructorID) &&
+			(filter.HasCertificate == nil || c.HasCertificate == *filter.HasCertificate) &&
+			(filter.UserID == 0 || s.enrolled(filter.UserID, c.ID)) &&
+			(len(filter.CategoryIDs) == 0 || inCategories(c, filter.CategoryIDs)) &&
+			containsID(filter.LevelIDs, c.LevelID) &&
+			inRange(c.Price, prices, filter.PriceMin, filter.PriceMax) &&
+			(filter.PriceCurrency == "" || c.Currency == filter.PriceCurrency) &&
+			(filter.Free == nil || (c.Price == 0) == *filter.Free) &&
+			inRange(c.Length, lengths, filter.LengthMin, filter.LengthMax)
+	}, nil
+}
+
+// inCategories reports whether the course has any of the categories.
+func inCategories(c *models.Course, ids []uint) bool {
+	for _, category := range c.Categories {
+		if containsID(ids, category.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// inRange reports whether the value falls into any of the buckets (if any) and the bounds.
+func inRange(v uint, buckets []services.Bucket, min, max *uint) bool {
+	if min != nil && v < *min || max != nil && v > *max {
+		return false
+	}
+
+	if len(buckets) == 0 {
+		return true
+	}
+
+	for _, b := range buckets {
+		if b.Contains(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Facets returns the number of courses matching the filter per value of each catalogue
+// facet. The filter on the facet itself is ignored when counting its values.
+func (s *CourseService) Facets(ctx context.Context, filter services.CourseFilter) (services.CourseFacets, error) {
+	var facets services.CourseFacets
+
+	matchers := make(map[string]func(c *models.Course) bool)
+	for _, facet := range []string{services.FacetCategory, services.FacetLevel, services.FacetPrice, services.FacetLength} {
+		match, err := s.matcher(filter.Without(facet))
+		if err != nil {
+			return facets, err
+		}
+		matchers[facet] = match
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	for _, category := range s.store.Categories {
+		value := services.FacetValue{ID: category.ID, Title: category.Title}
+		for _, c := range s.store.Courses {
+			if matchers[services.FacetCategory](c) && inCategories(c, []uint{category.ID}) {
+				value.Count++
+			}
+		}
+		facets.Categories = append(facets.Categories, value)
+	}
+
+	for _, level := range s.store.Levels {
+		value := services.FacetValue{ID: level.ID, Title: level.Title}
+		for _, c := range s.store.Courses {
+			if matchers[services.FacetLevel](c) && c.LevelID == level.ID {
+				value.Count++
+			}
+		}
+		facets.Levels = append(facets.Levels, value)
+	}
+
+	for _, b := range services.PriceBuckets {
+		value := services.BucketValue{Bucket: b}
+		for _, c := range s.store.Courses {
+			if matchers[services.FacetPrice](c) && b.Contains(c.Price) {
+				value.Count++
+			}
+		}
+		facets.Prices = append(facets.Prices, value)
+	}
+
+	for _, b := range services.LengthBuckets {
+		value := services.BucketValue{Bucket: b}
+		for _, c := range s.store.Courses {
+			if matchers[services.FacetLength](c) && b.Contains(c.Length) {
+				value.Count++
+			}
+		}
+		facets.Lengths = append(facets.Lengths, value)
+	}
+
+	return facets, nil
+}
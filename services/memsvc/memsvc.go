@@ -0,0 +1,108 @@
+// Package memsvc implements the user, course, enrollment, certificate and currency
+// services in memory. It is meant for the unit tests of the controllers, not for
+// production use; the other services are tested against the database.
+package memsvc
+
+import (
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"slices"
+	"sync"
+)
+
+// currency is the currency of the new courses.
+const currency = "UAH"
+
+// Store holds the records shared by the in-memory services. The exported fields may be
+// used to set up and inspect the state in tests.
+type Store struct {
+	mu            sync.Mutex
+	Users         map[uint]*models.User
+	Courses       map[uint]*models.Course
+	Categories    []models.CourseCategory
+	Levels        []models.CourseLevel
+	Exercises     map[uint]*models.CourseExercise
+	Enrollments   []*models.Enrollment
+	Certificates  []*models.CourseCertificate
+	Applications  []models.TeachingApplication
+	Subscriptions []*models.Subscription
+	Invitations   []*models.Invitation
+	Prerequisites []models.CoursePrerequisite
+	Rates         []models.ExchangeRate
+	AuditEntries  []models.AuditEntry
+	nextID        uint
+}
+
+// NewStore creates a new empty Store.
+func NewStore() *Store {
+	return &Store{
+		Users:     make(map[uint]*models.User),
+		Courses:   make(map[uint]*models.Course),
+		Exercises: make(map[uint]*models.CourseExercise),
+	}
+}
+
+// id returns a new unique record ID.
+func (s *Store) id() uint {
+	s.nextID++
+	return s.nextID
+}
+
+// New creates the in-memory implementations of the user, course, enrollment, certificate
+// and currency services sharing the store. The other services are left nil.
+func New(store *Store) *services.Services {
+	return &services.Services{
+		Users:        &UserService{store: store},
+		Courses:      &CourseService{store: store},
+		Enrollments:  &EnrollmentService{store: store},
+		Certificates: &CertificateService{store: store},
+		Currencies:   &CurrencyService{store: store},
+	}
+}
+
+// containsID reports whether ids is empty or contains id.
+func containsID(ids []uint, id uint) bool {
+	if len(ids) == 0 {
+		return true
+	}
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// paginate returns the page of the sorted records. The cursor holds the key of the last
+// returned record, so the records must be sorted the same way for every page.
+func paginate[T any](rows []T, key func(T) []uint, page services.Page) ([]T, services.PageInfo, error) {
+	var info services.PageInfo
+
+	cursor, err := page.DecodeCursor()
+	if err != nil {
+		return nil, info, err
+	}
+
+	if page.Count {
+		total := int64(len(rows))
+		info.Total = &total
+	}
+
+	if cursor != nil {
+		start := len(rows)
+		for i, row := range rows {
+			if slices.Equal(key(row), cursor.Key) {
+				start = i + 1
+				break
+			}
+		}
+		rows = rows[start:]
+	}
+
+	if size := page.Size(); len(rows) > size {
+		rows = rows[:size]
+		info.NextCursor = services.Cursor{Sort: page.Sort, Key: key(rows[size-1])}.Encode()
+	}
+
+	return rows, info, nil
+}
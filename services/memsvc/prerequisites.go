@@ -0,0 +1,120 @@
+package memsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"time"
+)
+
+// UpdatePrerequisites replaces the prerequisites of a course owned by the instructor and
+// sets whether they are enforced.
+func (s *CourseService) UpdatePrerequisites(ctx context.Context, instructorID uint, input services.PrerequisiteInput) ([]models.CoursePrerequisite, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	c, err := s.owned(instructorID, input.CourseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := services.ValidatePrerequisites(&input); err != nil {
+		return nil, err
+	}
+
+	for _, id := range input.PrerequisiteIDs {
+		if p, ok := s.store.Courses[id]; !ok || p.StatusID != services.CourseStatusPublished {
+			return nil, services.Errorf(services.ErrInvalidInput, "prerequisites must be published courses")
+		}
+	}
+
+	edges := services.ReplacePrerequisites(s.store.prerequisiteEdges(), input.CourseID, input.PrerequisiteIDs)
+	if err := services.CheckAcyclic(edges); err != nil {
+		return nil, err
+	}
+
+	var kept []models.CoursePrerequisite
+	for _, p := range s.store.Prerequisites {
+		if p.CourseID != input.CourseID {
+			kept = append(kept, p)
+		}
+	}
+
+	list := make([]models.CoursePrerequisite, len(input.PrerequisiteIDs))
+	for i, id := range input.PrerequisiteIDs {
+		list[i] = models.CoursePrerequisite{CourseID: input.CourseID, PrerequisiteID: id, CreatedAt: time.Now()}
+	}
+	s.store.Prerequisites = append(kept, list...)
+	c.EnforcePrerequisites = input.Enforce
+
+	return list, nil
+}
+
+// Prerequisites returns the prerequisite graph of the course, or of all the published
+// courses if courseID is zero.
+func (s *CourseService) Prerequisites(ctx context.Context, courseID uint) (services.PrerequisiteGraph, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	edges := s.store.prerequisiteEdges()
+
+	var ids []uint
+	if courseID != 0 {
+		if _, ok := s.store.Courses[courseID]; !ok {
+			return services.PrerequisiteGraph{}, services.Errorf(services.ErrNotFound, "course %d", courseID)
+		}
+		ids = services.PrerequisiteClosure(edges, courseID)
+	} else {
+		for _, e := range edges {
+			ids = append(ids, e.CourseID, e.PrerequisiteID)
+		}
+	}
+
+	var nodes []services.GraphCourse
+	for id, c := range s.store.Courses {
+		if len(ids) > 0 && containsID(ids, id) && (courseID != 0 || c.StatusID == services.CourseStatusPublished) {
+			nodes = append(nodes, services.NewGraphCourse(*c))
+		}
+	}
+
+	return services.NewPrerequisiteGraph(nodes, edges), nil
+}
+
+// CheckPrerequisites lists the prerequisites of the course the user has not completed.
+func (s *EnrollmentService) CheckPrerequisites(ctx context.Context, userID, courseID uint) (services.PrerequisiteCheck, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	course, ok := s.store.Courses[courseID]
+	if !ok {
+		return services.PrerequisiteCheck{}, services.Errorf(services.ErrNotFound, "course %d", courseID)
+	}
+
+	return s.store.checkPrerequisites(userID, *course), nil
+}
+
+// prerequisiteEdges returns all the edges of the prerequisite graph. The store must be locked.
+func (s *Store) prerequisiteEdges() []services.PrerequisiteEdge {
+	edges := make([]services.PrerequisiteEdge, len(s.Prerequisites))
+	for i, p := range s.Prerequisites {
+		edges[i] = services.PrerequisiteEdge{CourseID: p.CourseID, PrerequisiteID: p.PrerequisiteID}
+	}
+	return edges
+}
+
+// checkPrerequisites lists the prerequisites of the course the user has not completed. The
+// store must be locked.
+func (s *Store) checkPrerequisites(userID uint, course models.Course) services.PrerequisiteCheck {
+	var prerequisites []models.Course
+	var enrollments []models.Enrollment
+	for _, p := range s.Prerequisites {
+		if c, ok := s.Courses[p.PrerequisiteID]; ok && p.CourseID == course.ID {
+			prerequisites = append(prerequisites, *c)
+			if e := s.enrollment(userID, c.ID); e != nil {
+				enrollments = append(enrollments, *e)
+			}
+		}
+	}
+
+	return services.NewPrerequisiteCheck(course, prerequisites, enrollments)
+}
@@ -0,0 +1,126 @@
+package memsvc
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/services"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// searchWeights are the weights of the matches in the course fields.
+var searchWeights = struct{ title, shortDescription, exercises, description float64 }{1, 0.4, 0.2, 0.1}
+
+// Search returns a page of the published courses matching the query, the most relevant first.
+// Every term must be a prefix of a word of the course.
+func (s *CourseService) Search(ctx context.Context, query string, page services.Page) ([]services.CourseSearchResult, services.PageInfo, error) {
+	if page.Sort != "" {
+		return nil, services.PageInfo{}, services.Errorf(services.ErrInvalidInput, "search results are sorted by relevance")
+	}
+
+	terms := services.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, services.PageInfo{}, services.Errorf(services.ErrInvalidInput, "empty search query")
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	var results []services.CourseSearchResult
+	for _, c := range s.store.Courses {
+		if c.StatusID != services.CourseStatusPublished {
+			continue
+		}
+
+		var exercises []string
+		for _, ex := range s.store.Exercises {
+			if ex.CourseID == c.ID {
+				exercises = append(exercises, ex.Title)
+			}
+		}
+
+		var rank float64
+		matched := true
+		for _, term := range terms {
+			hits := matches(c.Title, term)*searchWeights.title +
+				matches(c.ShortDescription, term)*searchWeights.shortDescription +
+				matches(strings.Join(exercises, " "), term)*searchWeights.exercises +
+				matches(c.Description, term)*searchWeights.description
+			if hits == 0 {
+				matched = false
+				break
+			}
+			rank += hits
+		}
+
+		if matched {
+			results = append(results, services.CourseSearchResult{
+				Course:  *c,
+				Rank:    rank,
+				Snippet: services.HighlightSnippet(highlight(c.Title+" "+c.ShortDescription, terms)),
+			})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	return paginate(results, func(r services.CourseSearchResult) []uint { return []uint{r.ID} }, page)
+}
+
+// isSeparator reports whether the rune separates words, as in services.SearchTerms.
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// matchesTerm reports whether the word starts with any of the terms.
+func matchesTerm(word string, terms ...string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(strings.ToLower(word), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns the number of words of the text starting with the term.
+func matches(text, term string) float64 {
+	var n float64
+	for _, w := range strings.FieldsFunc(text, isSeparator) {
+		if matchesTerm(w, term) {
+			n++
+		}
+	}
+	return n
+}
+
+// highlight wraps the words of the text starting with any of the terms in the snippet markers.
+func highlight(text string, terms []string) string {
+	var b strings.Builder
+	var word strings.Builder
+
+	flush := func() {
+		if w := word.String(); w != "" && matchesTerm(w, terms...) {
+			b.WriteString(services.SnippetStart + w + services.SnippetStop)
+		} else {
+			b.WriteString(w)
+		}
+		word.Reset()
+	}
+
+	for _, r := range text {
+		if isSeparator(r) {
+			flush()
+			b.WriteRune(r)
+		} else {
+			word.WriteRune(r)
+		}
+	}
+	flush()
+
+	return b.String()
+}
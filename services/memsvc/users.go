@@ -0,0 +1,248 @@
+package memsvc
+
+import (
+	"context"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"slices"
+	"sort"
+	"time"
+)
+
+// UserService is the in-memory implementation of services.UserService.
+type UserService struct {
+	store *Store
+}
+
+// userTypes are the titles of the user types.
+var userTypes = map[uint]string{
+	services.UserTypeLearner:  "Learner",
+	services.UserTypeEducator: "Educator",
+	services.UserTypeAdmin:    "Admin",
+}
+
+// withType returns a copy of the user with the user type filled in.
+func withType(u *models.User) models.User {
+	user := *u
+	user.UserType = models.UserType{ID: user.UserTypeID, Title: userTypes[user.UserTypeID]}
+	return user
+}
+
+// Get returns the user with the given ID, including the user type.
+func (s *UserService) Get(ctx context.Context, id uint) (models.User, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	u, ok := s.store.Users[id]
+	if !ok {
+		return models.User{}, services.Errorf(services.ErrNotFound, "user %d", id)
+	}
+	return withType(u), nil
+}
+
+// List returns a page of the users with the given IDs, or of all users if ids is empty.
+func (s *UserService) List(ctx context.Context, ids []uint, page services.Page) ([]models.User, services.PageInfo, error) {
+	field, desc, err := page.SortField(services.UserSortFields)
+	if err != nil {
+		return nil, services.PageInfo{}, err
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	var users []models.User
+	for id, u := range s.store.Users {
+		if containsID(ids, id) {
+			users = append(users, withType(u))
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		a, b := users[i], users[j]
+		if desc {
+			a, b = b, a
+		}
+		switch {
+		case field == "last_name" && a.LastName != b.LastName:
+			return a.LastName < b.LastName
+		case field == "created_at" && !a.CreatedAt.Equal(b.CreatedAt):
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	return paginate(users, func(u models.User) []uint { return []uint{u.ID} }, page)
+}
+
+// SignUp registers a new learner.
+func (s *UserService) SignUp(ctx context.Context, input services.SignUpInput) (models.User, error) {
+	if err := services.ValidateSignUp(input); err != nil {
+		return models.User{}, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.MinCost)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	for _, u := range s.store.Users {
+		if u.Email == input.Email {
+			return models.User{}, services.Errorf(services.ErrConflict, "error creating user")
+		}
+	}
+
+	user := &models.User{
+		ID:         s.store.id(),
+		FirstName:  input.FirstName,
+		LastName:   input.LastName,
+		Email:      input.Email,
+		Password:   string(hashedPassword),
+		UserTypeID: services.UserTypeLearner,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	s.store.Users[user.ID] = user
+
+	return withType(user), nil
+}
+
+// Authenticate returns the user with the given credentials.
+func (s *UserService) Authenticate(ctx context.Context, email, password string) (models.User, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	for _, u := range s.store.Users {
+		if u.Email != email {
+			continue
+		}
+		if bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)) != nil {
+			return models.User{}, services.Errorf(services.ErrUnauthorized, "invalid password")
+		}
+		return withType(u), nil
+	}
+
+	return models.User{}, services.Errorf(services.ErrUnauthorized, "invalid email")
+}
+
+// UpdateProfile updates the general information of the user. The picture content is discarded.
+func (s *UserService) UpdateProfile(ctx context.Context, userID uint, input services.ProfileUpdate) error {
+	if err := services.ValidateProfile(&input); err != nil {
+		return err
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	u, ok := s.store.Users[userID]
+	if !ok {
+		return services.Errorf(services.ErrNotFound, "user %d", userID)
+	}
+
+	u.FirstName = input.FirstName
+	u.LastName = input.LastName
+	u.Currency = input.Currency
+	if input.ProfilePic != nil {
+		io.Copy(io.Discard, input.ProfilePic)
+		u.ProfilePic = fmt.Sprintf("users/profile-pictures/%d-pp.png", userID)
+	}
+	u.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// ApplyToTeach stores the teaching application and promotes the user to Educator.
+func (s *UserService) ApplyToTeach(ctx context.Context, userID uint, input services.TeachingApplicationInput) error {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	u, ok := s.store.Users[userID]
+	if !ok {
+		return services.Errorf(services.ErrNotFound, "user %d", userID)
+	}
+
+	for _, a := range s.store.Applications {
+		if a.UserID == userID {
+			return services.Errorf(services.ErrConflict, "error creating teaching application")
+		}
+	}
+
+	now := time.Now()
+	s.store.Applications = append(s.store.Applications, models.TeachingApplication{
+		UserID:         userID,
+		Experience:     input.Experience,
+		Motivation:     input.Motivation,
+		PlatformChoice: input.PlatformChoice,
+		ApprovedAt:     &now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+
+	if u.UserTypeID < services.UserTypeEducator {
+		u.UserTypeID = services.UserTypeEducator
+	}
+
+	return nil
+}
+
+// AcceptInvitation sets the password of the user invited with the token.
+func (s *UserService) AcceptInvitation(ctx context.Context, token, password string) error {
+	if err := services.ValidatePassword(password); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		return err
+	}
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	hash := services.HashInvitationToken(token)
+	i := slices.IndexFunc(s.store.Invitations, func(i *models.Invitation) bool {
+		return i.TokenHash == hash && i.AcceptedAt == nil && i.ExpiresAt.After(time.Now())
+	})
+	if i < 0 {
+		return services.Errorf(services.ErrNotFound, "invitation")
+	}
+
+	userID := s.store.Invitations[i].UserID
+	u, ok := s.store.Users[userID]
+	if !ok {
+		return services.Errorf(services.ErrNotFound, "user %d", userID)
+	}
+	u.Password = string(hashedPassword)
+	u.UpdatedAt = time.Now()
+
+	now := time.Now()
+	for _, invitation := range s.store.Invitations {
+		if invitation.UserID == userID && invitation.AcceptedAt == nil {
+			invitation.AcceptedAt = &now
+		}
+	}
+
+	return nil
+}
+
+// requireAdmin returns services.ErrForbidden unless the user is an admin.
+func (s *Store) requireAdmin(userID uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.Users[userID]
+	if !ok {
+		return services.Errorf(services.ErrNotFound, "user %d", userID)
+	}
+
+	if user.UserTypeID != services.UserTypeAdmin {
+		return services.Errorf(services.ErrForbidden, "only admins can perform this operation")
+	}
+
+	return nil
+}
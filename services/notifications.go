@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+)
+
+// Notification kinds.
+const (
+	NotificationPriceDrop = "price_drop"
+)
+
+// NotificationFilter selects the notifications to list. Zero values do not filter.
+type NotificationFilter struct {
+	Unread bool
+}
+
+// NotificationService notifies the users in the app and by email.
+type NotificationService interface {
+	// List returns a page of the notifications of the user matching the filter.
+	List(ctx context.Context, userID uint, filter NotificationFilter, page Page) ([]models.Notification, PageInfo, error)
+	// MarkRead marks the notifications of the user with the given IDs, or all of them if
+	// ids is empty, as read.
+	MarkRead(ctx context.Context, userID uint, ids []uint) error
+	// NotifyPriceDrop notifies the users having the course in their wishlist that its
+	// price dropped from oldPrice.
+	NotifyPriceDrop(ctx context.Context, courseID, oldPrice uint) error
+}
+
+// NotificationSortFields are the fields the notifications can be sorted by.
+var NotificationSortFields = map[string]bool{
+	"id":         true,
+	"created_at": true,
+}
+
+// PriceDropNotification returns the notification of the users having the course in their
+// wishlist that its price dropped from oldPrice.
+func PriceDropNotification(userID uint, course models.Course, oldPrice uint) models.Notification {
+	courseID := course.ID
+
+	return models.Notification{
+		UserID:   userID,
+		Kind:     NotificationPriceDrop,
+		CourseID: &courseID,
+		Title:    fmt.Sprintf("Ціна курсу «%s» знизилася", course.Title),
+		Body: fmt.Sprintf("Курс «%s» з вашого списку бажань тепер коштує %s замість %s.",
+			course.Title, FormatMoney(int64(course.Price), course.Currency), FormatMoney(int64(oldPrice), course.Currency)),
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+	"net/http"
+)
+
+// Order statuses.
+const (
+	OrderStatusPending  = "pending"
+	OrderStatusPaid     = "paid"
+	OrderStatusFailed   = "failed"
+	OrderStatusRefunded = "refunded"
+)
+
+// OrderProviderCoupon is the provider of the orders fully discounted by a coupon.
+const OrderProviderCoupon = "coupon"
+
+// OrderFilter selects the orders to list. Zero values do not filter.
+type OrderFilter struct {
+	UserID   uint
+	CourseID uint
+	Statuses []string
+}
+
+// CheckoutSession is the order being paid and the checkout page of the payment provider.
+// The URL is empty if the order was fully discounted and is already paid.
+type CheckoutSession struct {
+	Order models.Order
+	URL   string
+}
+
+// OrderService sells the paid courses: the learners pay the orders at the payment
+// provider, which confirms the payments with webhooks enrolling the learners.
+type OrderService interface {
+	// List returns a page of the orders matching the filter.
+	List(ctx context.Context, filter OrderFilter, page Page) ([]models.Order, PageInfo, error)
+	// Checkout creates the order of the paid course at its current price with the coupon,
+	// if the code is not empty, or reuses the pending one, and its checkout session. The
+	// order is paid in the currency, or else the preferred currency of the user or the
+	// course currency. The order fully discounted by the coupon is paid right away,
+	// without a checkout page. The courses with the enforced prerequisites are only sold
+	// to the users who completed them.
+	Checkout(ctx context.Context, userID, courseID uint, couponCode, currency string) (CheckoutSession, error)
+	// HandleWebhook verifies and applies the payment webhook of the provider, enrolling the
+	// user into the course of a paid order or renewing the subscription. Duplicate webhooks
+	// are ignored.
+	HandleWebhook(ctx context.Context, header http.Header, body []byte) error
+}
+
+// OrderSortFields are the fields the orders can be sorted by.
+var OrderSortFields = map[string]bool{
+	"id":         true,
+	"amount":     true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// OrderDescription returns the description of the order of the course, or the learning
+// path of a bundle order, printed on the checkout page and the invoice, e.g. "Курс «Go»"
+// or "Курс «Go», місць: 10".
+func OrderDescription(title string, order models.Order) string {
+	if order.PathID != nil {
+		return fmt.Sprintf("Навчальний шлях «%s»", title)
+	}
+	if order.OrganizationID != nil {
+		return fmt.Sprintf("Курс «%s», місць: %d", title, order.Seats)
+	}
+	return fmt.Sprintf("Курс «%s»", title)
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"strings"
+	"time"
+)
+
+// Organization member roles. The owners manage the members and their roles, the managers
+// buy the seats, assign the courses to the members and follow their progress, and the
+// learners take the assigned courses.
+const (
+	RoleOwner   = "owner"
+	RoleManager = "manager"
+	RoleLearner = "learner"
+)
+
+// MaxSeats is the largest number of seats bought with one order.
+const MaxSeats = 1000
+
+// OrganizationInput is the information needed to create an organization.
+type OrganizationInput struct {
+	Name string
+}
+
+// MemberInput adds the user with the email to the organization with the role.
+type MemberInput struct {
+	OrganizationID uint
+	Email          string
+	Role           string
+}
+
+// MemberUpdate changes the role of a member of the organization.
+type MemberUpdate struct {
+	OrganizationID uint
+	UserID         uint
+	Role           string
+}
+
+// SeatPurchase is the purchase of the seats of a paid course for the members of the
+// organization, paid in the currency, or else the course currency.
+type SeatPurchase struct {
+	OrganizationID uint
+	CourseID       uint
+	Seats          uint
+	Currency       string
+}
+
+// AssignmentInput assigns the course to the members of the organization, to be completed
+// by DueAt if set. Assigning the course again changes the due date.
+type AssignmentInput struct {
+	OrganizationID uint
+	CourseID       uint
+	UserIDs        []uint
+	DueAt          *time.Time
+}
+
+// OrganizationDashboard is the overview of the seats of the organization and the progress
+// of its members in the assigned courses.
+type OrganizationDashboard struct {
+	Organization models.Organization
+	Licenses     []models.SeatLicense
+	Members      []MemberProgress
+}
+
+// MemberProgress is the progress of a member in the assigned courses.
+type MemberProgress struct {
+	UserID    uint
+	FirstName string
+	LastName  string
+	Email     string
+	Role      string
+	Courses   []AssignmentProgress
+}
+
+// AssignmentProgress is the progress of a member in an assigned course. The assignment is
+// overdue if it is not completed by the due date.
+type AssignmentProgress struct {
+	CourseID      uint
+	Title         string
+	DueAt         *time.Time
+	Progress      uint
+	Completed     bool
+	Overdue       bool
+	CertificateID *uint
+}
+
+// OrganizationService manages the organizations training their members: the members and
+// their roles, the seats of the paid courses and the assignments of the courses. The admins
+// act as the owners of all the organizations.
+type OrganizationService interface {
+	// List returns the organizations the user is a member of.
+	List(ctx context.Context, userID uint) ([]models.Organization, error)
+	// Create creates an organization owned by the user.
+	Create(ctx context.Context, userID uint, input OrganizationInput) (models.Organization, error)
+	// Members returns a page of the members of the organization of the user.
+	Members(ctx context.Context, userID, organizationID uint, page Page) ([]models.OrganizationMember, PageInfo, error)
+	// AddMember adds a registered user to the organization. The managers add the learners,
+	// the owners also the managers and the owners.
+	AddMember(ctx context.Context, userID uint, input MemberInput) (models.OrganizationMember, error)
+	// UpdateMember changes the role of a member. Only the owners change the roles.
+	UpdateMember(ctx context.Context, userID uint, input MemberUpdate) error
+	// RemoveMember removes the member from the organization, ending the assignments and
+	// freeing their seats. The organization keeps at least one owner.
+	RemoveMember(ctx context.Context, userID, organizationID, memberID uint) error
+	// BuySeats creates the order of the seats of the paid course for the organization and
+	// its checkout session. The seats are added to the licence once the order is paid.
+	BuySeats(ctx context.Context, userID uint, input SeatPurchase) (CheckoutSession, error)
+	// Assign assigns the published course to the members of the organization, enrolling
+	// them. Every new assignment of a paid course takes a seat of its licence.
+	Assign(ctx context.Context, userID uint, input AssignmentInput) ([]models.CourseAssignment, error)
+	// Unassign ends the assignment of the course to the member, freeing the seat and
+	// ending the enrollment through it.
+	Unassign(ctx context.Context, userID, organizationID, courseID, memberID uint) error
+	// Assignments returns the courses assigned to the user by the organizations.
+	Assignments(ctx context.Context, userID uint) ([]models.CourseAssignment, error)
+	// Join adds the user to the organization they were invited to by an import with the
+	// token, assigning the courses of the import while the seats last.
+	Join(ctx context.Context, userID uint, token string) (models.OrganizationMember, error)
+	// Dashboard returns the seats of the organization and the progress of the members in
+	// the assigned courses to its managers.
+	Dashboard(ctx context.Context, userID, organizationID uint) (OrganizationDashboard, error)
+}
+
+// OrganizationMemberSortFields are the fields the organization members can be sorted by.
+var OrganizationMemberSortFields = map[string]bool{
+	"user_id":    true,
+	"created_at": true,
+}
+
+// ValidateOrganization validates the organization input.
+func ValidateOrganization(input *OrganizationInput) error {
+	if input.Name = strings.TrimSpace(input.Name); input.Name == "" || len(input.Name) > 255 {
+		return Errorf(ErrInvalidInput, "organization name must be 1 to 255 characters")
+	}
+	return nil
+}
+
+// ValidRole reports whether the role is an organization member role.
+func ValidRole(role string) bool {
+	return role == RoleOwner || role == RoleManager || role == RoleLearner
+}
+
+// CanManage reports whether the members with the role manage the organization.
+func CanManage(role string) bool {
+	return role == RoleOwner || role == RoleManager
+}
+
+// CheckMemberChange returns services.ErrForbidden unless a member with the actor role may
+// change the role of a member from one role to another. The from role is empty for the
+// new members and the to role for the removed ones.
+func CheckMemberChange(actor, from, to string) error {
+	if to != "" && !ValidRole(to) {
+		return Errorf(ErrInvalidInput, "unknown member role %q", to)
+	}
+
+	switch {
+	case !CanManage(actor):
+		return Errorf(ErrForbidden, "only the managers can manage the members")
+	case actor != RoleOwner && (CanManage(from) || CanManage(to)):
+		return Errorf(ErrForbidden, "only the owners can manage the managers and the owners")
+	}
+	return nil
+}
+
+// ValidateSeatPurchase normalizes and validates the seat purchase.
+func ValidateSeatPurchase(input *SeatPurchase) error {
+	if input.Seats == 0 || input.Seats > MaxSeats {
+		return Errorf(ErrInvalidInput, "seats must be between 1 and %d", MaxSeats)
+	}
+
+	if input.Currency = NormalizeCurrency(input.Currency); input.Currency != "" && !ValidCurrency(input.Currency) {
+		return Errorf(ErrInvalidInput, "unsupported currency %q", input.Currency)
+	}
+
+	return nil
+}
+
+// ValidateAssignment validates the assignment input.
+func ValidateAssignment(input AssignmentInput) error {
+	if len(input.UserIDs) == 0 {
+		return Errorf(ErrInvalidInput, "no members to assign the course to")
+	}
+
+	if input.DueAt != nil && !input.DueAt.After(time.Now()) {
+		return Errorf(ErrInvalidInput, "due date must be in the future")
+	}
+
+	return nil
+}
+
+// CheckSeats returns services.ErrConflict unless the licence, nil if none was bought, has
+// the free seats for the new assignments of a paid course.
+func CheckSeats(license *models.SeatLicense, courseID uint, needed uint) error {
+	if needed == 0 {
+		return nil
+	}
+
+	var free uint
+	if license != nil && license.Seats > license.Used {
+		free = license.Seats - license.Used
+	}
+	if free < needed {
+		return Errorf(ErrConflict, "%d free seats of course %d, %d needed", free, courseID, needed)
+	}
+	return nil
+}
+
+// NewAssignmentProgress returns the progress in the assigned course at now given the
+// enrollment and the certificate of the member, nil if none.
+func NewAssignmentProgress(a models.CourseAssignment, enrollment *models.Enrollment, certificate *models.CourseCertificate, now time.Time) AssignmentProgress {
+	p := AssignmentProgress{CourseID: a.CourseID, Title: a.Course.Title, DueAt: a.DueAt}
+
+	if enrollment != nil {
+		p.Progress = enrollment.Progress
+		p.Completed = IsCompleted(*enrollment)
+	}
+
+	if certificate != nil && certificate.RevokedAt == nil {
+		p.CertificateID = &certificate.ID
+		p.Completed = true
+	}
+
+	p.Overdue = !p.Completed && a.DueAt != nil && now.After(*a.DueAt)
+	return p
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// Page size limits.
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// Page selects a window of a sorted list using keyset (cursor) pagination.
+type Page struct {
+	// Sort is the sort field, prefixed with "-" for the descending order. Empty sorts by ID.
+	Sort string
+	// Limit is the maximum number of records; zero means DefaultPageLimit.
+	Limit int
+	// Cursor is the NextCursor of the previous page; empty starts from the beginning.
+	Cursor string
+	// Count requests the total number of matching records.
+	Count bool
+}
+
+// PageInfo describes the returned page.
+type PageInfo struct {
+	// NextCursor continues the list after the returned page; empty on the last page.
+	NextCursor string
+	// Total is the number of matching records, set only if requested by Page.Count.
+	Total *int64
+}
+
+// Cursor is the decoded position after the last record of a page: the value of the
+// sort field and the primary key breaking the ties.
+type Cursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v,omitempty"`
+	Key   []uint          `json:"k"`
+}
+
+// Size returns the page limit capped to MaxPageLimit.
+func (p Page) Size() int {
+	switch {
+	case p.Limit <= 0:
+		return DefaultPageLimit
+	case p.Limit > MaxPageLimit:
+		return MaxPageLimit
+	default:
+		return p.Limit
+	}
+}
+
+// SortField validates the sort against the allowed fields and returns the field and
+// whether the order is descending. An empty sort yields "" (primary key order).
+func (p Page) SortField(allowed map[string]bool) (string, bool, error) {
+	if p.Sort == "" {
+		return "", false, nil
+	}
+
+	field, direction, err := ParseSort(p.Sort, allowed)
+	return field, direction == "DESC", err
+}
+
+// DecodeCursor decodes the page cursor. It returns nil if the page has no cursor.
+func (p Page) DecodeCursor() (*Cursor, error) {
+	if p.Cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+	if err != nil {
+		return nil, Errorf(ErrInvalidInput, "invalid cursor")
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, Errorf(ErrInvalidInput, "invalid cursor")
+	}
+
+	if !strings.EqualFold(c.Sort, p.Sort) {
+		return nil, Errorf(ErrInvalidInput, "cursor does not match the sort")
+	}
+
+	return &c, nil
+}
+
+// Encode encodes the cursor into the opaque form returned to the clients.
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
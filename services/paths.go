@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"strings"
+)
+
+// MaxPathCourses is the largest number of courses of a learning path.
+const MaxPathCourses = 50
+
+// PathInput is the information needed to create or update a learning path. CourseIDs are
+// its courses in order; Price is the bundle price in the minor units of Currency, zero if
+// the courses are only sold separately.
+type PathInput struct {
+	Title       string
+	Description string
+	CourseIDs   []uint
+	Price       uint
+	Currency    string
+}
+
+// PathProgress is the progress of the user in the learning path: the average progress in
+// its courses. The path is completed once all its courses are.
+type PathProgress struct {
+	PathID        uint
+	Enrolled      bool
+	Progress      uint
+	Completed     bool
+	CertificateID *uint
+	Courses       []PathCourseProgress
+}
+
+// PathCourseProgress is the progress of the user in a course of the learning path.
+type PathCourseProgress struct {
+	CourseID  uint
+	Title     string
+	Enrolled  bool
+	Progress  uint
+	Completed bool
+}
+
+// LearningPathService manages the learning paths: the curated sequences of courses sold
+// separately or as a bundle, the enrollments into them and their certificates.
+type LearningPathService interface {
+	// List returns a page of the learning paths with the given IDs, or of all paths if ids
+	// is empty, with their courses in order.
+	List(ctx context.Context, ids []uint, page Page) ([]models.LearningPath, PageInfo, error)
+	// Create creates a learning path of the published courses. Only the admins may create
+	// the paths.
+	Create(ctx context.Context, userID uint, input PathInput) (models.LearningPath, error)
+	// Update replaces the details and the courses of the learning path. Only the admins may
+	// update the paths.
+	Update(ctx context.Context, userID, pathID uint, input PathInput) (models.LearningPath, error)
+	// Enroll enrolls the user into the learning path and its free courses. The paid courses
+	// are bought separately or with the bundle.
+	Enroll(ctx context.Context, userID, pathID uint) error
+	// Checkout creates the order of the bundle of the learning path at its price in the
+	// currency, or else the preferred currency of the user or the path currency, and its
+	// checkout session. Once paid, the user is enrolled into the path and all its courses.
+	Checkout(ctx context.Context, userID, pathID uint, currency string) (CheckoutSession, error)
+	// Progress returns the progress of the user in the learning path.
+	Progress(ctx context.Context, userID, pathID uint) (PathProgress, error)
+	// IssueCertificate creates the certificate of the learning path completed by the user
+	// and renders its image.
+	IssueCertificate(ctx context.Context, userID, pathID uint) (models.PathCertificate, error)
+}
+
+// PathSortFields are the fields the learning paths can be sorted by.
+var PathSortFields = map[string]bool{
+	"id":         true,
+	"created_at": true,
+}
+
+// ValidatePath normalizes and validates the learning path input; the bundle price is in
+// the default currency unless set.
+func ValidatePath(input *PathInput, currency string) error {
+	input.Title = strings.TrimSpace(input.Title)
+	if input.Title == "" || len(input.Title) > 255 {
+		return Errorf(ErrInvalidInput, "path title must be 1 to 255 characters")
+	}
+
+	if len(input.CourseIDs) < 2 || len(input.CourseIDs) > MaxPathCourses {
+		return Errorf(ErrInvalidInput, "path must have 2 to %d courses", MaxPathCourses)
+	}
+
+	seen := make(map[uint]bool)
+	for _, id := range input.CourseIDs {
+		if seen[id] {
+			return Errorf(ErrInvalidInput, "duplicate course %d", id)
+		}
+		seen[id] = true
+	}
+
+	if input.Currency = NormalizeCurrency(input.Currency); input.Currency == "" {
+		input.Currency = currency
+	}
+	if !ValidCurrency(input.Currency) {
+		return Errorf(ErrInvalidInput, "unsupported currency %q", input.Currency)
+	}
+
+	return nil
+}
+
+// PathPrice returns the bundle price of the learning path in the currency, converted at
+// the rates, or in the path currency if empty.
+func PathPrice(path models.LearningPath, currency string, rates Rates) (uint, string, error) {
+	if path.Price == 0 {
+		return 0, "", Errorf(ErrInvalidInput, "path %d is not sold as a bundle", path.ID)
+	}
+
+	if currency == "" {
+		currency = path.Currency
+	}
+
+	price, ok := rates.Convert(path.Price, path.Currency, currency)
+	if !ok {
+		return 0, "", Errorf(ErrInvalidInput, "no price of path %d in %s", path.ID, currency)
+	}
+	return price, currency, nil
+}
+
+// NewPathProgress returns the progress of the user in the learning path given the
+// enrollments of the user into its courses and the path certificate, nil if none.
+func NewPathProgress(path models.LearningPath, enrolled bool, enrollments []models.Enrollment, certificate *models.PathCertificate) PathProgress {
+	p := PathProgress{PathID: path.ID, Enrolled: enrolled, Completed: len(path.Courses) > 0, Courses: []PathCourseProgress{}}
+	if certificate != nil {
+		p.CertificateID = &certificate.ID
+	}
+
+	var total uint
+	for _, c := range path.Courses {
+		course := PathCourseProgress{CourseID: c.CourseID, Title: c.Course.Title}
+		for _, e := range enrollments {
+			if e.CourseID == c.CourseID {
+				course.Enrolled = true
+				course.Progress = min(e.Progress, 100)
+				course.Completed = IsCompleted(e)
+			}
+		}
+		if course.Completed {
+			course.Progress = 100
+		}
+
+		total += course.Progress
+		p.Completed = p.Completed && course.Completed
+		p.Courses = append(p.Courses, course)
+	}
+
+	if len(path.Courses) > 0 {
+		p.Progress = total / uint(len(path.Courses))
+	}
+	return p
+}
+
+// SplitBundle splits the amount paid for a bundle between its courses in proportion to
+// their weights, e.g. their list prices, or evenly if all are zero. The last course gets
+// the rounding remainder.
+func SplitBundle(amount uint, weights []uint) []uint {
+	parts := make([]uint, len(weights))
+	if len(weights) == 0 {
+		return parts
+	}
+
+	var total uint64
+	for _, w := range weights {
+		total += uint64(w)
+	}
+
+	var split uint
+	for i, w := range weights {
+		if i == len(weights)-1 {
+			parts[i] = amount - split
+			break
+		}
+
+		if total == 0 {
+			parts[i] = amount / uint(len(weights))
+		} else {
+			parts[i] = uint(uint64(amount) * uint64(w) / total)
+		}
+		split += parts[i]
+	}
+
+	return parts
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MaxPrerequisites is the largest number of prerequisites of a course.
+const MaxPrerequisites = 20
+
+// PrerequisiteInput replaces the prerequisites of a course and sets whether they block the
+// enrollments or only warn about the incomplete ones.
+type PrerequisiteInput struct {
+	CourseID        uint
+	PrerequisiteIDs []uint
+	Enforce         bool
+}
+
+// PrerequisiteGraph is the DAG of the course prerequisites. Order lists the courses in the
+// recommended order of taking them: every course after its prerequisites, the lower levels
+// first.
+type PrerequisiteGraph struct {
+	Courses []GraphCourse
+	Edges   []PrerequisiteEdge
+	Order   []uint
+}
+
+// GraphCourse is a course of the prerequisite graph.
+type GraphCourse struct {
+	ID                   uint
+	Title                string
+	LevelID              uint
+	EnforcePrerequisites bool
+}
+
+// PrerequisiteEdge links the course to one of its prerequisites.
+type PrerequisiteEdge struct {
+	CourseID       uint
+	PrerequisiteID uint
+}
+
+// PrerequisiteCheck lists the prerequisites of the course the user has not completed yet.
+// The enrollment is blocked if they are Enforced, and only warned about otherwise.
+type PrerequisiteCheck struct {
+	CourseID uint
+	Enforced bool
+	Missing  []GraphCourse
+}
+
+// ValidatePrerequisites removes the duplicate prerequisites and checks the course is not
+// its own prerequisite.
+func ValidatePrerequisites(input *PrerequisiteInput) error {
+	var ids []uint
+	for _, id := range input.PrerequisiteIDs {
+		if id == input.CourseID {
+			return Errorf(ErrInvalidInput, "course %d cannot be its own prerequisite", id)
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) > MaxPrerequisites {
+		return Errorf(ErrInvalidInput, "course must have at most %d prerequisites", MaxPrerequisites)
+	}
+	input.PrerequisiteIDs = ids
+
+	return nil
+}
+
+// ReplacePrerequisites returns the edges with the prerequisites of the course replaced.
+func ReplacePrerequisites(edges []PrerequisiteEdge, courseID uint, prerequisiteIDs []uint) []PrerequisiteEdge {
+	var replaced []PrerequisiteEdge
+	for _, e := range edges {
+		if e.CourseID != courseID {
+			replaced = append(replaced, e)
+		}
+	}
+	for _, id := range prerequisiteIDs {
+		replaced = append(replaced, PrerequisiteEdge{CourseID: courseID, PrerequisiteID: id})
+	}
+	return replaced
+}
+
+// FindCycle returns a cycle of the prerequisites as the course IDs from a course back to
+// itself, e.g. [1 2 1], or nil if the edges form a DAG.
+func FindCycle(edges []PrerequisiteEdge) []uint {
+	prerequisites := make(map[uint][]uint)
+	var courses []uint
+	for _, e := range edges {
+		if _, ok := prerequisites[e.CourseID]; !ok {
+			courses = append(courses, e.CourseID)
+		}
+		prerequisites[e.CourseID] = append(prerequisites[e.CourseID], e.PrerequisiteID)
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[uint]int)
+	var path []uint
+
+	var visit func(id uint) []uint
+	visit = func(id uint) []uint {
+		switch state[id] {
+		case visiting:
+			i := slices.Index(path, id)
+			return append(slices.Clone(path[i:]), id)
+		case visited:
+			return nil
+		}
+
+		state[id] = visiting
+		path = append(path, id)
+		for _, p := range prerequisites[id] {
+			if cycle := visit(p); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		return nil
+	}
+
+	for _, id := range courses {
+		if cycle := visit(id); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// CheckAcyclic returns ErrInvalidInput naming the cycle if the edges do not form a DAG.
+func CheckAcyclic(edges []PrerequisiteEdge) error {
+	cycle := FindCycle(edges)
+	if cycle == nil {
+		return nil
+	}
+
+	ids := make([]string, len(cycle))
+	for i, id := range cycle {
+		ids[i] = fmt.Sprint(id)
+	}
+	return Errorf(ErrInvalidInput, "prerequisites form a cycle: %s", strings.Join(ids, " → "))
+}
+
+// PrerequisiteClosure returns the course and all its direct and indirect prerequisites.
+func PrerequisiteClosure(edges []PrerequisiteEdge, courseID uint) []uint {
+	ids := []uint{courseID}
+	for i := 0; i < len(ids); i++ {
+		for _, e := range edges {
+			if e.CourseID == ids[i] && !slices.Contains(ids, e.PrerequisiteID) {
+				ids = append(ids, e.PrerequisiteID)
+			}
+		}
+	}
+	return ids
+}
+
+// NewPrerequisiteGraph returns the graph of the courses and the edges between them, with
+// the recommended order of the courses. The edges must form a DAG.
+func NewPrerequisiteGraph(courses []GraphCourse, edges []PrerequisiteEdge) PrerequisiteGraph {
+	if courses == nil {
+		courses = []GraphCourse{}
+	}
+	sort.Slice(courses, func(i, j int) bool { return courses[i].ID < courses[j].ID })
+
+	known := make(map[uint]GraphCourse, len(courses))
+	for _, c := range courses {
+		known[c.ID] = c
+	}
+
+	g := PrerequisiteGraph{Courses: courses, Edges: []PrerequisiteEdge{}, Order: []uint{}}
+	remaining := make(map[uint]int)
+	for _, e := range edges {
+		if _, ok := known[e.CourseID]; !ok {
+			continue
+		}
+		if _, ok := known[e.PrerequisiteID]; !ok {
+			continue
+		}
+		g.Edges = append(g.Edges, e)
+		remaining[e.CourseID]++
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.CourseID != b.CourseID {
+			return a.CourseID < b.CourseID
+		}
+		return a.PrerequisiteID < b.PrerequisiteID
+	})
+
+	// Kahn's algorithm taking the easiest of the available courses first
+	var available []GraphCourse
+	for _, c := range courses {
+		if remaining[c.ID] == 0 {
+			available = append(available, c)
+		}
+	}
+
+	for len(available) > 0 {
+		sort.Slice(available, func(i, j int) bool {
+			a, b := available[i], available[j]
+			if a.LevelID != b.LevelID {
+				return a.LevelID < b.LevelID
+			}
+			return a.ID < b.ID
+		})
+
+		next := available[0]
+		available = available[1:]
+		g.Order = append(g.Order, next.ID)
+
+		for _, e := range g.Edges {
+			if e.PrerequisiteID == next.ID {
+				if remaining[e.CourseID]--; remaining[e.CourseID] == 0 {
+					available = append(available, known[e.CourseID])
+				}
+			}
+		}
+	}
+
+	return g
+}
+
+// NewGraphCourse returns the course of the prerequisite graph.
+func NewGraphCourse(course models.Course) GraphCourse {
+	return GraphCourse{
+		ID:                   course.ID,
+		Title:                course.Title,
+		LevelID:              course.LevelID,
+		EnforcePrerequisites: course.EnforcePrerequisites,
+	}
+}
+
+// NewPrerequisiteCheck returns the check of the prerequisites of the course given the
+// enrollments of the user into them.
+func NewPrerequisiteCheck(course models.Course, prerequisites []models.Course, enrollments []models.Enrollment) PrerequisiteCheck {
+	check := PrerequisiteCheck{CourseID: course.ID, Enforced: course.EnforcePrerequisites, Missing: []GraphCourse{}}
+	for _, p := range prerequisites {
+		completed := slices.ContainsFunc(enrollments, func(e models.Enrollment) bool {
+			return e.CourseID == p.ID && IsCompleted(e)
+		})
+		if !completed {
+			check.Missing = append(check.Missing, NewGraphCourse(p))
+		}
+	}
+	return check
+}
+
+// RequirePrerequisites returns ErrForbidden if the check blocks the enrollment.
+func RequirePrerequisites(check PrerequisiteCheck) error {
+	if check.Enforced && len(check.Missing) > 0 {
+		return Errorf(ErrForbidden, "complete the prerequisites of course %d first", check.CourseID)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+)
+
+// Recommendation reasons: the learners of the user's courses also took the course, it is
+// similar to them by its categories and level, or it is popular.
+const (
+	ReasonAlsoTook = "also_took"
+	ReasonSimilar  = "similar"
+	ReasonPopular  = "popular"
+)
+
+// Recommendation limits.
+const (
+	DefaultRecommendations = 10
+	MaxRecommendations     = 50
+)
+
+// Recommendation is a course recommended with its score, the higher the better, and the
+// main reason of the recommendation.
+type Recommendation struct {
+	CourseID uint
+	Score    float64
+	Reason   string
+}
+
+// RecommendationCourse is a published course the recommendations are made of.
+type RecommendationCourse struct {
+	ID          uint
+	LevelID     uint
+	CategoryIDs []uint
+}
+
+// CoEnrollment is an enrollment of the user into the course.
+type CoEnrollment struct {
+	UserID   uint
+	CourseID uint
+}
+
+// RecommendationData is the data the recommendations are computed from: the published
+// courses and all the enrollments.
+type RecommendationData struct {
+	Courses     []RecommendationCourse
+	Enrollments []CoEnrollment
+}
+
+// RecommendationService recommends the courses to take next.
+type RecommendationService interface {
+	// ForUser returns up to limit courses recommended to the user, excluding the courses
+	// the user is enrolled in. The users without enrollments get the popular courses.
+	ForUser(ctx context.Context, userID uint, limit int) ([]Recommendation, error)
+	// AlsoTook returns up to limit courses most often taken by the learners of the course.
+	AlsoTook(ctx context.Context, courseID uint, limit int) ([]Recommendation, error)
+}
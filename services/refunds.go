@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"time"
+)
+
+// Refund statuses. An approved refund is being returned by the payment provider and
+// approving it again, e.g. after a crash, asks the provider again; a failed one may be
+// approved again. A processing refund has been returned by the provider and approving it
+// again completes the order refund without returning the payment twice.
+const (
+	RefundStatusRequested  = "requested"
+	RefundStatusApproved   = "approved"
+	RefundStatusProcessing = "processing"
+	RefundStatusRefunded   = "refunded"
+	RefundStatusRejected   = "rejected"
+	RefundStatusFailed     = "failed"
+)
+
+// RefundPolicy limits the refunds to the orders paid within the Window by the learners
+// whose progress is below MaxProgress percent.
+type RefundPolicy struct {
+	Window      time.Duration
+	MaxProgress uint
+}
+
+// DefaultRefundPolicy allows the refunds within 14 days below 30% progress.
+var DefaultRefundPolicy = RefundPolicy{Window: 14 * 24 * time.Hour, MaxProgress: 30}
+
+// RefundFilter selects the refunds to list. Zero values do not filter.
+type RefundFilter struct {
+	UserID   uint
+	OrderID  uint
+	Statuses []string
+}
+
+// RefundDecision is the decision of an admin on a refund request.
+type RefundDecision struct {
+	RefundID uint
+	Approve  bool
+	Note     string
+}
+
+// RefundService handles the refund requests of the learners. An approved refund returns
+// the payment, revokes the enrollment and the certificate of the course, and every step
+// is written to the audit log.
+type RefundService interface {
+	// List returns a page of the refunds matching the filter.
+	List(ctx context.Context, filter RefundFilter, page Page) ([]models.Refund, PageInfo, error)
+	// Requests returns a page of the refunds matching the filter to the admins.
+	Requests(ctx context.Context, adminID uint, filter RefundFilter, page Page) ([]models.Refund, PageInfo, error)
+	// Request requests the refund of the order paid by the user under the refund policy.
+	Request(ctx context.Context, userID, orderID uint, reason string) (models.Refund, error)
+	// Decide approves or rejects the requested refund, retries the failed or interrupted
+	// approved one, or completes the processing one.
+	Decide(ctx context.Context, adminID uint, decision RefundDecision) error
+}
+
+// RefundSortFields are the fields the refunds can be sorted by.
+var RefundSortFields = map[string]bool{
+	"id":         true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// CheckRefundable returns services.ErrInvalidInput unless the order can be refunded at now
+// under the policy. The enrollment is nil if it was already revoked. The seats bought for
+// an organization are not refundable, as they may already be assigned.
+func CheckRefundable(order models.Order, enrollment *models.Enrollment, policy RefundPolicy, now time.Time) error {
+	switch {
+	case order.Status != OrderStatusPaid || order.PaidAt == nil:
+		return Errorf(ErrInvalidInput, "order %d is not paid", order.ID)
+	case order.Amount == 0:
+		return Errorf(ErrInvalidInput, "order %d has nothing to refund", order.ID)
+	case order.OrganizationID != nil:
+		return Errorf(ErrInvalidInput, "seats of order %d are not refundable", order.ID)
+	case order.PathID != nil:
+		return Errorf(ErrInvalidInput, "bundle of order %d is not refundable", order.ID)
+	case now.Sub(*order.PaidAt) > policy.Window:
+		return Errorf(ErrInvalidInput, "refund window of order %d has passed", order.ID)
+	case enrollment != nil && enrollment.Progress >= policy.MaxProgress:
+		return Errorf(ErrInvalidInput, "progress of %d%% is too high for a refund", enrollment.Progress)
+	}
+	return nil
+}
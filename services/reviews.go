@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"strings"
+)
+
+// Review ratings range from MinRating to MaxRating stars.
+const (
+	MinRating uint = 1
+	MaxRating uint = 5
+)
+
+// ReviewFilter selects the reviews to list. Zero values do not filter.
+type ReviewFilter struct {
+	IDs      []uint
+	CourseID uint
+	UserID   uint
+	Ratings  []uint
+	// Flagged and Hidden select the reviews by their moderation state. They are only
+	// used by ReviewService.Moderation; List never returns hidden reviews.
+	Flagged *bool
+	Hidden  *bool
+}
+
+// ReviewInput is the review of a course by an enrolled learner.
+type ReviewInput struct {
+	CourseID uint
+	Rating   uint
+	Text     string
+}
+
+// ReviewModeration is the moderation decision on a review.
+type ReviewModeration struct {
+	ReviewID uint
+	Flagged  bool
+	Hidden   bool
+}
+
+// ReviewService manages the course reviews, their replies and helpful votes. The
+// ratings of the visible reviews are cached on the course.
+type ReviewService interface {
+	// List returns a page of the visible reviews matching the filter with their users.
+	List(ctx context.Context, filter ReviewFilter, page Page) ([]models.CourseReview, PageInfo, error)
+	// Create creates the review of the course the user is enrolled into.
+	Create(ctx context.Context, userID uint, input ReviewInput) (models.CourseReview, error)
+	// Update updates the review of the course written by the user.
+	Update(ctx context.Context, userID uint, input ReviewInput) error
+	// Reply sets the reply of the instructor of the reviewed course.
+	Reply(ctx context.Context, instructorID, reviewID uint, reply string) error
+	// Vote adds or removes the helpful vote of the user for a review of another user.
+	Vote(ctx context.Context, userID, reviewID uint, helpful bool) error
+	// Moderation returns a page of the reviews matching the filter, hidden ones included.
+	// Only admins may moderate the reviews.
+	Moderation(ctx context.Context, moderatorID uint, filter ReviewFilter, page Page) ([]models.CourseReview, PageInfo, error)
+	// Moderate flags or hides a review. Only admins may moderate the reviews.
+	Moderate(ctx context.Context, moderatorID uint, input ReviewModeration) error
+}
+
+// ReviewSortFields are the fields the reviews can be sorted by.
+var ReviewSortFields = map[string]bool{
+	"id":            true,
+	"rating":        true,
+	"helpful_count": true,
+	"created_at":    true,
+	"updated_at":    true,
+}
+
+// ValidateReview checks the review input and trims its text.
+func ValidateReview(input *ReviewInput) error {
+	input.Text = strings.TrimSpace(input.Text)
+
+	if input.Rating < MinRating || input.Rating > MaxRating {
+		return Errorf(ErrInvalidInput, "rating must be between %d and %d", MinRating, MaxRating)
+	}
+
+	if len(input.Text) > 65000 {
+		return Errorf(ErrInvalidInput, "review text is too long")
+	}
+
+	return nil
+}
+
+// RatingSummary returns the average and the distribution of the ratings.
+func RatingSummary(ratings []uint) (float64, models.RatingDistribution) {
+	var distribution models.RatingDistribution
+	if len(ratings) == 0 {
+		return 0, distribution
+	}
+
+	var sum uint
+	for _, r := range ratings {
+		sum += r
+		distribution[r-MinRating]++
+	}
+
+	return float64(sum) / float64(len(ratings)), distribution
+}
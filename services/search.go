@@ -0,0 +1,38 @@
+package services
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// MaxSearchTerms is the maximum number of terms of a search query.
+const MaxSearchTerms = 8
+
+// Snippet markers. The search backends wrap the matched terms in these control characters,
+// which HighlightSnippet turns into <mark> tags after escaping the text.
+const (
+	SnippetStart = "\x02"
+	SnippetStop  = "\x03"
+)
+
+// SearchTerms splits the search query into lowercase words. Everything except letters and
+// digits separates the words, the same way the full-text indexes tokenize the courses.
+func SearchTerms(query string) []string {
+	terms := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	if len(terms) > MaxSearchTerms {
+		terms = terms[:MaxSearchTerms]
+	}
+
+	return terms
+}
+
+// HighlightSnippet escapes the snippet and replaces the snippet markers with <mark> tags.
+func HighlightSnippet(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, SnippetStart, "<mark>")
+	return strings.ReplaceAll(snippet, SnippetStop, "</mark>")
+}
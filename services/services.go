@@ -0,0 +1,59 @@
+// Package services defines the domain services used by the controllers. The services
+// validate the input, enforce the authorization rules and persist the models; the
+// implementations live in the gormsvc (database) and memsvc (in-memory fakes) packages.
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+)
+
+// Services bundles the domain services.
+type Services struct {
+	Users           UserService
+	Courses         CourseService
+	Enrollments     EnrollmentService
+	Certificates    CertificateService
+	Reviews         ReviewService
+	Discussions     DiscussionService
+	Wishlist        WishlistService
+	Notifications   NotificationService
+	Orders          OrderService
+	Coupons         CouponService
+	Refunds         RefundService
+	Audit           AuditService
+	Ledger          LedgerService
+	Subscriptions   SubscriptionService
+	Invoices        InvoiceService
+	Currencies      CurrencyService
+	Organizations   OrganizationService
+	UserImports     UserImportService
+	Paths           LearningPathService
+	Autocomplete    AutocompleteService
+	Recommendations RecommendationService
+}
+
+var (
+	// ErrNotFound is returned when the requested record does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidInput is returned when the input fails validation.
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrUnauthorized is returned when the credentials are invalid.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is returned when the user is not allowed to perform the operation.
+	ErrForbidden = errors.New("forbidden")
+	// ErrConflict is returned when the operation conflicts with the existing records.
+	ErrConflict = errors.New("conflict")
+)
+
+// Errorf wraps the domain error with a message describing it, e.g. Errorf(ErrNotFound, "course %d", id).
+func Errorf(kind error, format string, args ...any) error {
+	return fmt.Errorf("%w: %s", kind, fmt.Sprintf(format, args...))
+}
+
+// ValidateEmail validates the email address.
+func ValidateEmail(email string) bool {
+	_, err := mail.ParseAddress(email)
+	return err == nil
+}
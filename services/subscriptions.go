@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Subscription statuses. A pending subscription awaits its first payment and expires if it
+// fails; an active one is past due once a renewal fails. A canceled subscription is not
+// renewed and lasts until the end of the paid period.
+const (
+	SubscriptionStatusPending  = "pending"
+	SubscriptionStatusActive   = "active"
+	SubscriptionStatusPastDue  = "past_due"
+	SubscriptionStatusCanceled = "canceled"
+	SubscriptionStatusExpired  = "expired"
+)
+
+// Subscription plan intervals.
+const (
+	IntervalMonth = "month"
+	IntervalYear  = "year"
+)
+
+// DefaultGracePeriod is how long the subscriptions keep the access after the end of the
+// period while the renewal payment is due.
+const DefaultGracePeriod = 3 * 24 * time.Hour
+
+// planCodePattern matches the normalized plan codes.
+var planCodePattern = regexp.MustCompile(`^[a-z0-9_-]{3,64}$`)
+
+// PlanInput is the information needed to create a subscription plan.
+type PlanInput struct {
+	Code     string
+	Name     string
+	Interval string
+	Price    uint
+}
+
+// PlanUpdate is the update of a subscription plan. The new price applies to the new
+// subscriptions; the inactive plans cannot be subscribed to.
+type PlanUpdate struct {
+	PlanID uint
+	Name   string
+	Price  uint
+	Active bool
+}
+
+// SubscriptionCheckout is the subscription being paid and the checkout page of the
+// payment provider.
+type SubscriptionCheckout struct {
+	Subscription models.Subscription
+	URL          string
+}
+
+// SubscriptionService sells the subscriptions granting access to all the paid published
+// courses. The renewals are charged by the payment provider and confirmed by the payment
+// webhooks handled by the OrderService.
+type SubscriptionService interface {
+	// Plans returns the active plans, and the inactive ones to the admins.
+	Plans(ctx context.Context, userID uint) ([]models.SubscriptionPlan, error)
+	// CreatePlan creates a subscription plan.
+	CreatePlan(ctx context.Context, adminID uint, input PlanInput) (models.SubscriptionPlan, error)
+	// UpdatePlan updates the name, the price and the availability of a plan.
+	UpdatePlan(ctx context.Context, adminID uint, update PlanUpdate) error
+	// List returns a page of the subscriptions of the user with their current status.
+	List(ctx context.Context, userID uint, page Page) ([]models.Subscription, PageInfo, error)
+	// Subscribe creates the subscription of the user to the plan, or reuses the pending
+	// one, and its checkout session.
+	Subscribe(ctx context.Context, userID, planID uint) (SubscriptionCheckout, error)
+	// Cancel stops the renewals of the subscription of the user, which lasts until the end
+	// of the paid period.
+	Cancel(ctx context.Context, userID, subscriptionID uint) error
+	// Entitled reports whether the user has a subscription granting access to the courses.
+	Entitled(ctx context.Context, userID uint) (bool, error)
+}
+
+// SubscriptionSortFields are the fields the subscriptions can be sorted by.
+var SubscriptionSortFields = map[string]bool{
+	"id":         true,
+	"created_at": true,
+}
+
+// ValidatePlan normalizes the plan code and validates the plan input.
+func ValidatePlan(input *PlanInput) error {
+	input.Code = strings.ToLower(strings.TrimSpace(input.Code))
+	if !planCodePattern.MatchString(input.Code) {
+		return Errorf(ErrInvalidInput, "plan code must be 3 to 64 letters, digits, dashes or underscores")
+	}
+
+	switch input.Interval {
+	case IntervalMonth, IntervalYear:
+	default:
+		return Errorf(ErrInvalidInput, "unknown plan interval %q", input.Interval)
+	}
+
+	return validatePlanTerms(input.Name, input.Price)
+}
+
+// ValidatePlanUpdate validates the plan update.
+func ValidatePlanUpdate(update PlanUpdate) error {
+	return validatePlanTerms(update.Name, update.Price)
+}
+
+// validatePlanTerms validates the name and the price of a plan.
+func validatePlanTerms(name string, price uint) error {
+	if name = strings.TrimSpace(name); name == "" || len(name) > 255 {
+		return Errorf(ErrInvalidInput, "plan name must be 1 to 255 characters")
+	}
+
+	if price == 0 {
+		return Errorf(ErrInvalidInput, "plan price must be positive")
+	}
+
+	return nil
+}
+
+// SubscriptionState returns the status of the subscription at now: the active and past
+// due subscriptions are past due after the end of the period and expire after the grace
+// period, the canceled ones expire at the end of the period.
+func SubscriptionState(sub models.Subscription, now time.Time, grace time.Duration) string {
+	if sub.CurrentPeriodEnd == nil {
+		return sub.Status
+	}
+	end := *sub.CurrentPeriodEnd
+
+	switch sub.Status {
+	case SubscriptionStatusActive, SubscriptionStatusPastDue:
+		switch {
+		case now.Before(end):
+			return sub.Status
+		case now.Before(end.Add(grace)):
+			return SubscriptionStatusPastDue
+		}
+		return SubscriptionStatusExpired
+	case SubscriptionStatusCanceled:
+		if now.Before(end) {
+			return sub.Status
+		}
+		return SubscriptionStatusExpired
+	}
+
+	return sub.Status
+}
+
+// IsEntitled reports whether the subscription grants access to the courses at now.
+func IsEntitled(sub models.Subscription, now time.Time, grace time.Duration) bool {
+	switch SubscriptionState(sub, now, grace) {
+	case SubscriptionStatusActive, SubscriptionStatusPastDue, SubscriptionStatusCanceled:
+		return sub.CurrentPeriodEnd != nil
+	}
+	return false
+}
+
+// PeriodEnd returns the end of the period of the plan interval starting at start.
+func PeriodEnd(start time.Time, interval string) time.Time {
+	if interval == IntervalYear {
+		return start.AddDate(1, 0, 0)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+// RenewSubscription extends the subscription paid at now by a period of the plan interval
+// and returns the payment of the period. The period starts at the end of the current one
+// unless it has already passed.
+func RenewSubscription(sub *models.Subscription, interval string, now time.Time) models.SubscriptionPayment {
+	start := now
+	if sub.CurrentPeriodEnd != nil && sub.CurrentPeriodEnd.After(now) {
+		start = *sub.CurrentPeriodEnd
+	}
+	end := PeriodEnd(start, interval)
+
+	if sub.CurrentPeriodStart == nil || sub.CurrentPeriodEnd == nil || !sub.CurrentPeriodEnd.After(now) {
+		sub.CurrentPeriodStart = &start
+	}
+	sub.CurrentPeriodEnd = &end
+	if sub.Status != SubscriptionStatusCanceled {
+		sub.Status = SubscriptionStatusActive
+	}
+
+	return models.SubscriptionPayment{
+		SubscriptionID: sub.ID,
+		Amount:         sub.Amount,
+		Currency:       sub.Currency,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+	}
+}
+
+// FailSubscription applies a failed payment to the subscription: the first payment expires
+// it and a renewal makes it past due.
+func FailSubscription(sub *models.Subscription) {
+	switch sub.Status {
+	case SubscriptionStatusPending:
+		sub.Status = SubscriptionStatusExpired
+	case SubscriptionStatusActive:
+		sub.Status = SubscriptionStatusPastDue
+	}
+}
+
+// CheckCancelable returns services.ErrInvalidInput unless the subscription can be canceled at now.
+func CheckCancelable(sub models.Subscription, now time.Time, grace time.Duration) error {
+	switch SubscriptionState(sub, now, grace) {
+	case SubscriptionStatusActive, SubscriptionStatusPastDue:
+		return nil
+	}
+	return Errorf(ErrInvalidInput, "subscription %d is not active", sub.ID)
+}
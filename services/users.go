@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+	"io"
+)
+
+// User types. Note: 1 ("Learner") < 2 ("Educator") < 3 ("Admin").
+const (
+	UserTypeLearner  uint = 1
+	UserTypeEducator uint = 2
+	UserTypeAdmin    uint = 3
+)
+
+// SignUpInput is the information needed to register a new user.
+type SignUpInput struct {
+	FirstName string
+	LastName  string
+	Email     string
+	Password  string
+}
+
+// ProfileUpdate is the update of the user's general information.
+type ProfileUpdate struct {
+	FirstName string
+	LastName  string
+	// Currency is the preferred currency of the catalogue prices, empty for the course currencies.
+	Currency string
+	// ProfilePic is the new profile picture; nil keeps the current one.
+	ProfilePic io.Reader
+}
+
+// TeachingApplicationInput is the teaching application of a user.
+type TeachingApplicationInput struct {
+	Experience     string
+	Motivation     string
+	PlatformChoice string
+}
+
+// UserService manages the users and their teaching applications.
+type UserService interface {
+	// Get returns the user with the given ID, including the user type.
+	Get(ctx context.Context, id uint) (models.User, error)
+	// List returns a page of the users with the given IDs, or of all users if ids is empty.
+	List(ctx context.Context, ids []uint, page Page) ([]models.User, PageInfo, error)
+	// SignUp registers a new learner.
+	SignUp(ctx context.Context, input SignUpInput) (models.User, error)
+	// Authenticate returns the user with the given credentials.
+	Authenticate(ctx context.Context, email, password string) (models.User, error)
+	// UpdateProfile updates the general information of the user.
+	UpdateProfile(ctx context.Context, userID uint, input ProfileUpdate) error
+	// ApplyToTeach stores the teaching application and promotes the user to Educator.
+	ApplyToTeach(ctx context.Context, userID uint, input TeachingApplicationInput) error
+	// AcceptInvitation sets the password of the user invited with the token, ending all
+	// the invitations of the user.
+	AcceptInvitation(ctx context.Context, token, password string) error
+}
+
+// UserSortFields are the fields the users can be sorted by.
+var UserSortFields = map[string]bool{
+	"id":         true,
+	"last_name":  true,
+	"created_at": true,
+}
+
+// ValidateSignUp checks the sign-up input.
+func ValidateSignUp(input SignUpInput) error {
+	if input.FirstName == "" ||
+		input.LastName == "" ||
+		!ValidateEmail(input.Email) ||
+		len(input.Password) < 8 {
+		return Errorf(ErrInvalidInput, "bad credentials provided")
+	}
+	return nil
+}
+
+// ValidatePassword checks the new password of the user.
+func ValidatePassword(password string) error {
+	if len(password) < 8 {
+		return Errorf(ErrInvalidInput, "password must be at least 8 characters")
+	}
+	return nil
+}
+
+// ValidateProfile normalizes the preferred currency of the profile update and checks it
+// is supported.
+func ValidateProfile(input *ProfileUpdate) error {
+	input.Currency = NormalizeCurrency(input.Currency)
+	if input.Currency != "" && !ValidCurrency(input.Currency) {
+		return Errorf(ErrInvalidInput, "unsupported currency %q", input.Currency)
+	}
+	return nil
+}
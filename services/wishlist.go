@@ -0,0 +1,19 @@
+package services
+
+import (
+	"context"
+	"github.com/plaja-app/back-end/models"
+)
+
+// WishlistService manages the courses saved by the users for later.
+type WishlistService interface {
+	// List returns a page of the courses in the wishlist of the user with the instructor,
+	// level and categories.
+	List(ctx context.Context, userID uint, page Page) ([]models.Course, PageInfo, error)
+	// Add adds the published course to the wishlist of the user. Adding a course twice does nothing.
+	Add(ctx context.Context, userID, courseID uint) error
+	// Remove removes the course from the wishlist of the user.
+	Remove(ctx context.Context, userID, courseID uint) error
+	// Contains returns the IDs of the given courses which are in the wishlist of the user.
+	Contains(ctx context.Context, userID uint, courseIDs []uint) (map[uint]bool, error)
+}
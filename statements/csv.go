@@ -0,0 +1,60 @@
+// Package statements renders the payout statements of the instructors as CSV and PDF.
+package statements
+
+import (
+	"encoding/csv"
+	"github.com/plaja-app/back-end/services"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Statement line kinds besides the ledger transaction kinds.
+const (
+	kindOpening = "opening"
+	kindClosing = "closing"
+)
+
+// WriteCSV writes the statement as CSV: the opening balances, the transactions of the
+// month and the closing balances, one per row.
+func WriteCSV(w io.Writer, statement services.Statement) error {
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{"date", "kind", "order_id", "description", "currency", "amount", "fee", "earnings"})
+
+	month := statement.Month.Format(time.DateOnly)
+	for _, b := range statement.Opening {
+		cw.Write([]string{month, kindOpening, "", "", b.Currency, "", "", formatAmount(b.Amount, b.Currency)})
+	}
+
+	for _, l := range statement.Lines {
+		var orderID string
+		if l.OrderID != nil {
+			orderID = strconv.FormatUint(uint64(*l.OrderID), 10)
+		}
+
+		cw.Write([]string{
+			l.Date.UTC().Format(time.DateOnly),
+			l.Kind,
+			orderID,
+			l.Description,
+			l.Currency,
+			formatAmount(l.Amount, l.Currency),
+			formatAmount(l.Fee, l.Currency),
+			formatAmount(l.Earnings, l.Currency),
+		})
+	}
+
+	end := statement.Month.AddDate(0, 1, -1).Format(time.DateOnly)
+	for _, b := range statement.Closing {
+		cw.Write([]string{end, kindClosing, "", "", b.Currency, "", "", formatAmount(b.Amount, b.Currency)})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatAmount formats the amount in the minor units in the main currency units, e.g. "599.00".
+func formatAmount(amount int64, currency string) string {
+	return services.FormatAmount(amount, currency)
+}
@@ -0,0 +1,232 @@
+package statements
+
+import (
+	"fmt"
+	"github.com/fogleman/gg"
+	"github.com/plaja-app/back-end/pdf"
+	"github.com/plaja-app/back-end/services"
+	"image"
+	"io"
+	"path/filepath"
+	"strconv"
+)
+
+// Page layout: A4 rendered at 150 DPI and placed on a 595x842 pt PDF page.
+const (
+	pageWidth    = 1240
+	pageHeight   = 1754
+	pageMargin   = 90
+	lineHeight   = 34
+	linesPerPage = 38
+)
+
+// months are the Ukrainian month names.
+var months = [...]string{
+	"січень", "лютий", "березень", "квітень", "травень", "червень",
+	"липень", "серпень", "вересень", "жовтень", "листопад", "грудень",
+}
+
+// kinds are the Ukrainian names of the statement line kinds.
+var kinds = map[string]string{
+	services.LedgerSale:   "продаж",
+	services.LedgerRefund: "повернення",
+	services.LedgerPayout: "виплата",
+}
+
+// columns are the statement table columns: the header and the right edge of the
+// right-aligned amounts, or the left edge of the text.
+var columns = []struct {
+	title string
+	x     float64
+	right bool
+}{
+	{"дата", pageMargin, false},
+	{"операція", 250, false},
+	{"замовлення", 420, false},
+	{"опис", 560, false},
+	{"сума", 900, true},
+	{"комісія", 1020, true},
+	{"заробіток", pageWidth - pageMargin, true},
+}
+
+// Renderer renders the statements as PDF using the fonts of the storage.
+type Renderer struct {
+	StorageRoot string
+}
+
+// NewRenderer creates a new Renderer using the given storage root.
+func NewRenderer(storageRoot string) *Renderer {
+	return &Renderer{StorageRoot: storageRoot}
+}
+
+// WritePDF renders the statement and writes it as PDF, a page image per page.
+func (r *Renderer) WritePDF(w io.Writer, statement services.Statement) error {
+	pages, err := r.render(statement)
+	if err != nil {
+		return err
+	}
+	return pdf.Write(w, pages)
+}
+
+// render draws the statement pages.
+func (r *Renderer) render(statement services.Statement) ([]image.Image, error) {
+	regular, err := gg.LoadFontFace(r.fontPath("Onest-Regular"), 22)
+	if err != nil {
+		return nil, fmt.Errorf("error loading font Onest-Regular: %v", err)
+	}
+
+	medium, err := gg.LoadFontFace(r.fontPath("Onest-Medium"), 22)
+	if err != nil {
+		return nil, fmt.Errorf("error loading font Onest-Medium: %v", err)
+	}
+
+	title, err := gg.LoadFontFace(r.fontPath("Onest-Medium"), 40)
+	if err != nil {
+		return nil, fmt.Errorf("error loading font Onest-Medium: %v", err)
+	}
+
+	var pages []image.Image
+	var dc *gg.Context
+	y := 0.0
+
+	newPage := func() {
+		if dc != nil {
+			pages = append(pages, dc.Image())
+		}
+		dc = gg.NewContext(pageWidth, pageHeight)
+		dc.SetRGB(1, 1, 1)
+		dc.Clear()
+		dc.SetRGB(0, 0, 0)
+		y = pageMargin
+	}
+
+	header := func() {
+		dc.SetFontFace(medium)
+		for _, c := range columns {
+			drawCell(dc, c.title, c.x, y, c.right)
+		}
+		y += lineHeight / 2
+		dc.DrawLine(pageMargin, y, pageWidth-pageMargin, y)
+		dc.Stroke()
+		y += lineHeight
+		dc.SetFontFace(regular)
+	}
+
+	newPage()
+
+	dc.SetFontFace(title)
+	dc.DrawString(fmt.Sprintf("Виписка за %s %d", months[statement.Month.Month()-1], statement.Month.Year()), pageMargin, y)
+	y += lineHeight * 2
+
+	dc.SetFontFace(regular)
+	dc.DrawString(fmt.Sprintf("інструктор: %s (ідентифікатор %d)", statement.Instructor, statement.InstructorID), pageMargin, y)
+	y += lineHeight
+	dc.DrawString("залишок на початок місяця: "+formatBalances(statement.Opening), pageMargin, y)
+	y += lineHeight * 2
+
+	header()
+	rows := 0
+	for _, l := range statement.Lines {
+		if rows == linesPerPage {
+			newPage()
+			header()
+			rows = 0
+		}
+
+		var orderID string
+		if l.OrderID != nil {
+			orderID = strconv.FormatUint(uint64(*l.OrderID), 10)
+		}
+
+		cells := []string{
+			l.Date.UTC().Format("02.01.2006"),
+			kinds[l.Kind],
+			orderID,
+			truncate(dc, l.Description, columns[4].x-columns[3].x-120),
+			formatMoney(l.Amount, l.Currency),
+			formatMoney(l.Fee, l.Currency),
+			formatMoney(l.Earnings, l.Currency),
+		}
+		for i, c := range columns {
+			drawCell(dc, cells[i], c.x, y, c.right)
+		}
+
+		y += lineHeight
+		rows++
+	}
+
+	if len(statement.Lines) == 0 {
+		dc.DrawString("операцій не було", pageMargin, y)
+		y += lineHeight
+	}
+
+	if y > pageHeight-pageMargin-lineHeight*float64(3+2*len(statement.Totals)) {
+		newPage()
+	}
+
+	y += lineHeight
+	dc.SetFontFace(medium)
+	for _, t := range statement.Totals {
+		dc.DrawString(fmt.Sprintf("разом: продажі %s, повернення %s, комісія %s",
+			formatMoney(t.Sales, t.Currency), formatMoney(t.Refunds, t.Currency), formatMoney(t.Fees, t.Currency)), pageMargin, y)
+		y += lineHeight
+		dc.DrawString(fmt.Sprintf("заробіток %s, виплати %s",
+			formatMoney(t.Earnings, t.Currency), formatMoney(t.Payouts, t.Currency)), pageMargin, y)
+		y += lineHeight
+	}
+	dc.DrawString("залишок на кінець місяця: "+formatBalances(statement.Closing), pageMargin, y)
+
+	pages = append(pages, dc.Image())
+	return pages, nil
+}
+
+// fontPath returns the filesystem path of the service font.
+func (r *Renderer) fontPath(font string) string {
+	return filepath.Join(r.StorageRoot, "service", "fonts", font+".ttf")
+}
+
+// drawCell draws the table cell text starting at x, or ending at x if right-aligned.
+func drawCell(dc *gg.Context, text string, x, y float64, right bool) {
+	if right {
+		dc.DrawStringAnchored(text, x, y, 1, 0)
+		return
+	}
+	dc.DrawString(text, x, y)
+}
+
+// truncate shortens the text to fit the width, ending it with an ellipsis.
+func truncate(dc *gg.Context, text string, width float64) string {
+	if w, _ := dc.MeasureString(text); w <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if w, _ := dc.MeasureString(string(runes) + "…"); w <= width {
+			break
+		}
+	}
+	return string(runes) + "…"
+}
+
+// formatMoney formats the amount with the currency, e.g. "599.00 UAH".
+func formatMoney(amount int64, currency string) string {
+	return services.FormatMoney(amount, currency)
+}
+
+// formatBalances formats the balances, e.g. "419.30 UAH, 10.00 USD".
+func formatBalances(balances []services.Balance) string {
+	if len(balances) == 0 {
+		return "0"
+	}
+
+	var s string
+	for i, b := range balances {
+		if i > 0 {
+			s += ", "
+		}
+		s += formatMoney(b.Amount, b.Currency)
+	}
+	return s
+}
@@ -0,0 +1,98 @@
+package statements
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"github.com/plaja-app/back-end/models"
+	"github.com/plaja-app/back-end/services"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testStatement() services.Statement {
+	month := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	orderID := uint(7)
+
+	sale := models.LedgerTransaction{
+		ID:          1,
+		Kind:        services.LedgerSale,
+		OrderID:     &orderID,
+		Currency:    "UAH",
+		Description: "Використання мікросервісів у Go",
+		Entries:     services.SaleEntries(59900, 70),
+		CreatedAt:   month.Add(48 * time.Hour),
+	}
+	payout := models.LedgerTransaction{
+		ID:        2,
+		Kind:      services.LedgerPayout,
+		Currency:  "UAH",
+		Entries:   services.PayoutEntries(40000),
+		CreatedAt: month.Add(72 * time.Hour),
+	}
+
+	instructor := models.User{ID: 3, FirstName: "Леся", LastName: "Українка"}
+	opening := []services.Balance{{Currency: "UAH", Amount: 10000}}
+	return services.BuildStatement(instructor, month, opening, []models.LedgerTransaction{sale, payout})
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, testStatement()); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{
+		{"date", "kind", "order_id", "description", "currency", "amount", "fee", "earnings"},
+		{"2024-03-01", "opening", "", "", "UAH", "", "", "100.00"},
+		{"2024-03-03", "sale", "7", "Використання мікросервісів у Go", "UAH", "599.00", "179.70", "419.30"},
+		{"2024-03-04", "payout", "", "", "UAH", "400.00", "0.00", "-400.00"},
+		{"2024-03-31", "closing", "", "", "UAH", "", "", "119.30"},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("expected %d rows, got %v", len(expected), rows)
+	}
+	for i := range rows {
+		if strings.Join(rows[i], ",") != strings.Join(expected[i], ",") {
+			t.Errorf("row %d: expected %v, got %v", i, expected[i], rows[i])
+		}
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	r := NewRenderer(filepath.Join("..", "storage"))
+
+	var buf bytes.Buffer
+	if err := r.WritePDF(&buf, testStatement()); err != nil {
+		t.Fatal(err)
+	}
+
+	pdf := buf.String()
+	if !strings.HasPrefix(pdf, "%PDF-1.4") || !strings.HasSuffix(pdf, "%%EOF\n") ||
+		!strings.Contains(pdf, "/Count 1") || !strings.Contains(pdf, "/Filter /DCTDecode") {
+		t.Fatalf("unexpected PDF structure")
+	}
+
+	// the cross-reference table points at the objects
+	start := strings.LastIndex(pdf, "startxref\n") + len("startxref\n")
+	offset, err := strconv.Atoi(strings.TrimSuffix(pdf[start:], "\n%%EOF\n"))
+	if err != nil || !strings.HasPrefix(pdf[offset:], "xref\n0 6\n") {
+		t.Fatalf("unexpected cross-reference offset %d", offset)
+	}
+
+	entries := strings.Split(pdf[offset:], "\n")[3:8]
+	for i, entry := range entries {
+		object, _ := strconv.Atoi(entry[:10])
+		if !strings.HasPrefix(pdf[object:], fmt.Sprintf("%d 0 obj", i+1)) {
+			t.Errorf("object %d is not at offset %d", i+1, object)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Private is the directory of the files that are not served publicly, e.g. the invoices.
+const Private = "private"
+
+// Storage stores the uploaded files under storage-relative paths.
+type Storage interface {
+	// Save writes the content of r to the file at the given storage-relative path, replacing it if it exists.
+	Save(path string, r io.Reader) error
+	// Open opens the file at the given storage-relative path for reading.
+	Open(path string) (io.ReadCloser, error)
+}
+
+// IsPrivate reports whether the storage-relative path is in the Private directory.
+func IsPrivate(p string) bool {
+	p = path.Clean("/" + filepath.ToSlash(p))
+	return p == "/"+Private || strings.HasPrefix(p, "/"+Private+"/")
+}
+
+// Local is a Storage keeping the files in a directory of the local filesystem.
+type Local struct {
+	Root string
+}
+
+// NewLocal creates a new Local storage rooted at the given directory.
+func NewLocal(root string) *Local {
+	return &Local{Root: root}
+}
+
+// Path returns the filesystem path of the given storage-relative path.
+func (s *Local) Path(path string) string {
+	return filepath.Join(s.Root, filepath.FromSlash(path))
+}
+
+// Save writes the content of r to the file at the given storage-relative path.
+func (s *Local) Save(path string, r io.Reader) error {
+	fullPath := s.Path(path)
+	if err := os.MkdirAll(filepath.Dir(fullPath), os.ModePerm); err != nil {
+		return err
+	}
+
+	dst, err := os.Create(fullPath)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, r)
+	return err
+}
+
+// Open opens the file at the given storage-relative path for reading.
+func (s *Local) Open(path string) (io.ReadCloser, error) {
+	return os.Open(s.Path(path))
+}